@@ -22,16 +22,29 @@ import { CurrentUserCtx, GuestProfile } from './contexts/CurrentUserCtx';
 import { NotificationsProvider } from './contexts/NotificationsCtx';
 import { UserFlashCtx } from './contexts/UserFlashCtx';
 import { AdminAppealsPage } from './page/AdminAppealsPage';
+import { AdminApprovalsPage } from './page/AdminApprovalsPage';
 import { AdminBanPage } from './page/AdminBanPage';
+import { AdminBotDefensePage } from './page/AdminBotDefensePage';
 import { AdminContestsPage } from './page/AdminContestsPage';
+import { AdminCvarTemplatesPage } from './page/AdminCvarTemplatesPage';
 import { AdminFiltersPage } from './page/AdminFiltersPage';
+import { AdminMapRotationPage } from './page/AdminMapRotationPage';
 import { AdminNetworkPage } from './page/AdminNetworkPage';
 import { AdminNewsPage } from './page/AdminNewsPage';
+import { AdminPatreonPage } from './page/AdminPatreonPage';
+import { AdminPlayerCountsPage } from './page/AdminPlayerCountsPage';
 import { AdminPeoplePage } from './page/AdminPeoplePage';
+import { AdminRconPage } from './page/AdminRconPage';
+import { AdminRconTasksPage } from './page/AdminRconTasksPage';
 import { AdminReportsPage } from './page/AdminReportsPage';
+import { AdminServerGroupsPage } from './page/AdminServerGroupsPage';
+import { AdminServerHealthPage } from './page/AdminServerHealthPage';
+import { AdminServerPerformancePage } from './page/AdminServerPerformancePage';
 import { AdminServersPage } from './page/AdminServersPage';
+import { AdminSuspicionPage } from './page/AdminSuspicionPage';
 import { BanPage } from './page/BanPage';
 import { ChatLogPage } from './page/ChatLogPage';
+import { CommunityServersPage } from './page/CommunityServersPage';
 import { ContestListPage } from './page/ContestListPage';
 import { ContestPage } from './page/ContestPage';
 import { ForumOverviewPage } from './page/ForumOverviewPage';
@@ -39,6 +52,7 @@ import { ForumPage } from './page/ForumPage';
 import { ForumThreadPage } from './page/ForumThreadPage';
 import { HomePage } from './page/HomePage';
 import { LoginDiscordSuccessPage } from './page/LoginDiscordSuccessPage';
+import { LiveEventsPage } from './page/LiveEventsPage';
 import { LoginPage } from './page/LoginPage';
 import { LoginSteamSuccessPage } from './page/LoginSteamSuccessPage';
 import { LogoutPage } from './page/LogoutPage';
@@ -50,6 +64,7 @@ import { PlayerStatsPage } from './page/PlayerStatsPage';
 import { PrivacyPolicyPage } from './page/PrivacyPolicyPage';
 import { ProfilePage } from './page/ProfilePage';
 import { ProfileSettingsPage } from './page/ProfileSettingsPage';
+import { QuickPlayPage } from './page/QuickPlayPage';
 import { ReportCreatePage } from './page/ReportCreatePage';
 import { ReportViewPage } from './page/ReportViewPage';
 import { STVPage } from './page/STVPage';
@@ -167,6 +182,26 @@ export const App = ({ initialTheme }: AppProps): JSX.Element => {
                                                                         </ErrorBoundary>
                                                                     }
                                                                 />
+                                                                <Route
+                                                                    path={
+                                                                        '/quickplay'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <QuickPlayPage />
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/community_servers'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <CommunityServersPage />
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
                                                                 <Route
                                                                     path={
                                                                         '/stv'
@@ -599,6 +634,214 @@ export const App = ({ initialTheme }: AppProps): JSX.Element => {
                                                                         </ErrorBoundary>
                                                                     }
                                                                 />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/approvals'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Moderator
+                                                                                }
+                                                                            >
+                                                                                <AdminApprovalsPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/rcon_tasks'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminRconTasksPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/cvar_templates'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminCvarTemplatesPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/map_rotation'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminMapRotationPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/server_groups'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminServerGroupsPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/player_counts'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Moderator
+                                                                                }
+                                                                            >
+                                                                                <AdminPlayerCountsPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/server_performance'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Moderator
+                                                                                }
+                                                                            >
+                                                                                <AdminServerPerformancePage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/patreon'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminPatreonPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/health'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminServerHealthPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/bot_defense'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Admin
+                                                                                }
+                                                                            >
+                                                                                <AdminBotDefensePage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/live'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.User
+                                                                                }
+                                                                            >
+                                                                                <LiveEventsPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/rcon'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Moderator
+                                                                                }
+                                                                            >
+                                                                                <AdminRconPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
+                                                                <Route
+                                                                    path={
+                                                                        '/admin/suspicion'
+                                                                    }
+                                                                    element={
+                                                                        <ErrorBoundary>
+                                                                            <PrivateRoute
+                                                                                permission={
+                                                                                    PermissionLevel.Moderator
+                                                                                }
+                                                                            >
+                                                                                <AdminSuspicionPage />
+                                                                            </PrivateRoute>
+                                                                        </ErrorBoundary>
+                                                                    }
+                                                                />
 
                                                                 <Route
                                                                     path={
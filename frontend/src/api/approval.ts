@@ -0,0 +1,101 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import { parseDateTime } from '../util/text';
+import {
+    apiCall,
+    QueryFilter,
+    TimeStamped,
+    transformTimeStampedDates
+} from './common';
+
+export enum PendingActionType {
+    BanSteam = 1,
+    BanASN = 2,
+    BanCIDR = 3,
+    UnbanSteam = 4
+}
+
+export const pendingActionTypeString = (t: PendingActionType) => {
+    switch (t) {
+        case PendingActionType.BanSteam:
+            return 'Steam Ban';
+        case PendingActionType.BanASN:
+            return 'ASN Ban';
+        case PendingActionType.BanCIDR:
+            return 'CIDR Ban';
+        case PendingActionType.UnbanSteam:
+            return 'Steam Unban';
+        default:
+            return 'Unknown';
+    }
+};
+
+export enum PendingActionState {
+    Any = -1,
+    Open = 0,
+    Approved = 1,
+    Rejected = 2,
+    Expired = 3,
+    Failed = 4
+}
+
+export const pendingActionStateString = (s: PendingActionState) => {
+    switch (s) {
+        case PendingActionState.Open:
+            return 'Pending';
+        case PendingActionState.Approved:
+            return 'Approved';
+        case PendingActionState.Rejected:
+            return 'Rejected';
+        case PendingActionState.Expired:
+            return 'Expired';
+        case PendingActionState.Failed:
+            return 'Failed';
+        default:
+            return 'Unknown';
+    }
+};
+
+export interface PendingAction extends TimeStamped {
+    pending_action_id: number;
+    action_type: PendingActionType;
+    state: PendingActionState;
+    source_id: string;
+    target_id: string;
+    approver_id: string;
+    reason: string;
+    expires_on: Date;
+}
+
+export interface PendingActionQueryFilter extends QueryFilter<PendingAction> {
+    state: PendingActionState;
+}
+
+export const apiGetPendingActions = async (
+    opts: PendingActionQueryFilter,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        LazyResult<PendingAction>,
+        PendingActionQueryFilter
+    >(`/api/pending_actions`, 'POST', opts, abortController);
+    resp.data = resp.data.map((action) => {
+        action.expires_on = parseDateTime(
+            action.expires_on as unknown as string
+        );
+        return transformTimeStampedDates(action);
+    });
+    return resp;
+};
+
+export const apiResolvePendingAction = async (
+    pending_action_id: number,
+    approve: boolean,
+    note: string = ''
+) =>
+    await apiCall<PendingAction, { note: string }>(
+        `/api/pending_actions/${pending_action_id}/${
+            approve ? 'approve' : 'reject'
+        }`,
+        'POST',
+        { note }
+    );
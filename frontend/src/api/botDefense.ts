@@ -0,0 +1,115 @@
+import {
+    apiCall,
+    EmptyBody,
+    TimeStamped,
+    transformTimeStampedDates,
+    transformTimeStampedDatesList
+} from './common';
+
+export enum BotDetectionMethod {
+    Name,
+    SteamList,
+    Wave,
+    ChatSpam
+}
+
+export const botDetectionMethodString = (method: BotDetectionMethod) => {
+    switch (method) {
+        case BotDetectionMethod.Name:
+            return 'Name Pattern';
+        case BotDetectionMethod.SteamList:
+            return 'SteamID List';
+        case BotDetectionMethod.Wave:
+            return 'Connection Wave';
+        case BotDetectionMethod.ChatSpam:
+            return 'Chat Spam';
+        default:
+            return 'Unknown';
+    }
+};
+
+export interface BotNamePattern extends TimeStamped {
+    bot_name_pattern_id: number;
+    pattern: string;
+    note: string;
+}
+
+export interface BotReportEntry {
+    server_id: number;
+    server_name: string;
+    method: BotDetectionMethod;
+    detections: number;
+    accounts: number;
+}
+
+export const apiGetBotNamePatterns = async (
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<BotNamePattern[]>(
+        `/api/bot_defense/patterns`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return transformTimeStampedDatesList(resp);
+};
+
+export const apiCreateBotNamePattern = async (
+    pattern: string,
+    note: string,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<BotNamePattern>(
+        `/api/bot_defense/patterns`,
+        'POST',
+        { pattern, note },
+        abortController
+    );
+    return transformTimeStampedDates(resp);
+};
+
+export const apiDeleteBotNamePattern = async (
+    bot_name_pattern_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/bot_defense/patterns/${bot_name_pattern_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
+
+export const apiImportBotSteamIDs = async (
+    steam_ids: string,
+    source: string,
+    abortController?: AbortController
+) => {
+    return await apiCall<{ count: number }>(
+        `/api/bot_defense/steam_ids`,
+        'POST',
+        { steam_ids, source },
+        abortController
+    );
+};
+
+export const apiDeleteBotSteamID = async (
+    steam_id: string,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/bot_defense/steam_ids/${steam_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
+
+export const apiGetBotReport = async (abortController?: AbortController) => {
+    return await apiCall<BotReportEntry[]>(
+        `/api/bot_defense/report`,
+        'GET',
+        undefined,
+        abortController
+    );
+};
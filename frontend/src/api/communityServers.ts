@@ -0,0 +1,37 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import { apiCall, QueryFilter } from './common';
+
+export interface CommunityServer {
+    addr: string;
+    name: string;
+    map: string;
+    game: string;
+    version: string;
+    app_id: number;
+    players: number;
+    max_players: number;
+    bots: number;
+    password: boolean;
+    vac: boolean;
+    keywords: string[];
+    last_seen: string;
+}
+
+export interface CommunityServerQuery extends QueryFilter<CommunityServer> {
+    map?: string;
+    tags?: string[];
+    not_empty?: boolean;
+    not_full?: boolean;
+    no_password?: boolean;
+}
+
+export const apiGetCommunityServers = async (
+    opts: CommunityServerQuery,
+    abortController?: AbortController
+) =>
+    await apiCall<LazyResult<CommunityServer>, CommunityServerQuery>(
+        `/api/community_servers`,
+        'POST',
+        opts,
+        abortController
+    );
@@ -0,0 +1,127 @@
+import { parseDateTime } from '../util/text';
+import {
+    apiCall,
+    EmptyBody,
+    TimeStamped,
+    transformTimeStampedDates,
+    transformTimeStampedDatesList
+} from './common';
+
+export interface CvarTemplate extends TimeStamped {
+    cvar_template_id: number;
+    name: string;
+    note: string;
+    values: Record<string, string>;
+}
+
+export type SaveCvarTemplateOpts = Pick<
+    CvarTemplate,
+    'name' | 'note' | 'values'
+>;
+
+export interface CvarTemplates {
+    templates: CvarTemplate[];
+    // Template ids keyed by the server_id they are assigned to
+    servers: Record<number, number>;
+}
+
+export interface CvarDrift {
+    server_id: number;
+    server_name: string;
+    cvar: string;
+    expected: string;
+    actual: string;
+    checked_on: Date;
+}
+
+const transformCvarDrift = (drift: CvarDrift) => {
+    drift.checked_on = parseDateTime(drift.checked_on as unknown as string);
+    return drift;
+};
+
+export const apiGetCvarTemplates = async (
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<CvarTemplates>(
+        `/api/cvar_templates`,
+        'GET',
+        undefined,
+        abortController
+    );
+    resp.templates = transformTimeStampedDatesList(resp.templates);
+    return resp;
+};
+
+export const apiSaveCvarTemplate = async (
+    opts: SaveCvarTemplateOpts,
+    cvar_template_id?: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<CvarTemplate, SaveCvarTemplateOpts>(
+        cvar_template_id
+            ? `/api/cvar_templates/${cvar_template_id}`
+            : `/api/cvar_templates`,
+        'POST',
+        opts,
+        abortController
+    );
+    return transformTimeStampedDates(resp);
+};
+
+export const apiDeleteCvarTemplate = async (
+    cvar_template_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/cvar_templates/${cvar_template_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
+
+export const apiSetServerCvarTemplate = async (
+    server_id: number,
+    cvar_template_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody, { cvar_template_id: number }>(
+        `/api/servers/${server_id}/cvar_template`,
+        'PUT',
+        { cvar_template_id },
+        abortController
+    );
+};
+
+export const apiApplyServerCvarTemplate = async (
+    server_id: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<CvarDrift[]>(
+        `/api/servers/${server_id}/cvar_template/apply`,
+        'POST',
+        undefined,
+        abortController
+    );
+    return resp.map(transformCvarDrift);
+};
+
+export const apiGetCvarDrift = async (abortController?: AbortController) => {
+    const resp = await apiCall<CvarDrift[]>(
+        `/api/cvar_drift`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return resp.map(transformCvarDrift);
+};
+
+export const apiCheckCvarDrift = async (abortController?: AbortController) => {
+    const resp = await apiCall<CvarDrift[]>(
+        `/api/cvar_drift/check`,
+        'POST',
+        undefined,
+        abortController
+    );
+    return resp.map(transformCvarDrift);
+};
@@ -0,0 +1,53 @@
+import { apiStream } from './stream';
+
+export type StreamEventCategory = 'chat' | 'kill' | 'connect' | 'round';
+
+export interface StreamPlayer {
+    name: string;
+    pid: number;
+    sid: string;
+    team: number;
+    bot: boolean;
+}
+
+export interface StreamEvent {
+    server_id: number;
+    server_name: string;
+    category: StreamEventCategory;
+    event_type: number;
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    event: Record<string, any>;
+}
+
+export interface StreamEventFilter {
+    server_ids?: number[];
+    categories?: StreamEventCategory[];
+}
+
+// apiStreamEvents subscribes to live game events, calling onEvent for each received event until the
+// abortController is aborted.
+export const apiStreamEvents = async (
+    filter: StreamEventFilter,
+    onEvent: (event: StreamEvent) => void,
+    abortController?: AbortController
+) => {
+    const params = new URLSearchParams();
+    if (filter.server_ids?.length) {
+        params.set('server_ids', filter.server_ids.join(','));
+    }
+    if (filter.categories?.length) {
+        params.set('categories', filter.categories.join(','));
+    }
+
+    await apiStream<StreamEvent>(
+        `/api/events?${params.toString()}`,
+        'GET',
+        undefined,
+        (event, data) => {
+            if (event == 'event') {
+                onEvent(data);
+            }
+        },
+        abortController
+    );
+};
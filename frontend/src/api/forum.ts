@@ -178,6 +178,7 @@ export interface ForumMessage extends TimeStamped {
     title: string;
     permission_level: PermissionLevel;
     signature: string;
+    forum_badge: string;
 }
 
 export interface ForumThread extends TimeStamped {
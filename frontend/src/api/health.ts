@@ -0,0 +1,72 @@
+import { parseDateTime } from '../util/text';
+import { apiCall, transformCreatedOnDate } from './common';
+
+export enum HealthState {
+    Up,
+    Degraded,
+    Down
+}
+
+export const healthStateString = (state: HealthState) => {
+    switch (state) {
+        case HealthState.Up:
+            return 'Up';
+        case HealthState.Degraded:
+            return 'Degraded';
+        case HealthState.Down:
+            return 'Down';
+        default:
+            return 'Unknown';
+    }
+};
+
+export interface ServerHealthStatus {
+    server_id: number;
+    server_name: string;
+    known: boolean;
+    state: HealthState;
+    reason: string;
+    since: Date;
+    uptime: number;
+}
+
+export interface ServerHealthEvent {
+    server_health_id: number;
+    server_id: number;
+    state: HealthState;
+    reason: string;
+    created_on: Date;
+}
+
+export interface ServerHealthHistory {
+    uptime: number;
+    events: ServerHealthEvent[];
+}
+
+export const apiGetServerHealth = async (abortController?: AbortController) => {
+    const resp = await apiCall<ServerHealthStatus[]>(
+        `/api/servers_health`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return resp.map((status) => {
+        status.since = parseDateTime(status.since as unknown as string);
+        return status;
+    });
+};
+
+export const apiGetServerHealthHistory = async (
+    server_id: number,
+    days: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerHealthHistory>(
+        `/api/servers_health/${server_id}?days=${days}`,
+        'GET',
+        undefined,
+        abortController
+    );
+    resp.events = resp.events.map(transformCreatedOnDate);
+    return resp;
+};
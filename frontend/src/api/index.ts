@@ -10,3 +10,22 @@ export * from './demo';
 export * from './match';
 export * from './contests';
 export * from './network';
+export * from './approval';
+export * from './suspicion';
+export * from './stac';
+export * from './botDefense';
+export * from './rcon';
+export * from './events';
+export * from './stream';
+export * from './health';
+export * from './rconTasks';
+export * from './cvarTemplates';
+export * from './mapRotation';
+export * from './serverGroups';
+export * from './quickplay';
+export * from './communityServers';
+export * from './patreon';
+export * from './serverCredentials';
+export * from './serverEnrollment';
+export * from './playerCounts';
+export * from './serverPerformance';
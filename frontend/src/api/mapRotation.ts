@@ -0,0 +1,131 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import {
+    apiCall,
+    EmptyBody,
+    QueryFilter,
+    TimeStamped,
+    transformCreatedOnDate,
+    transformTimeStampedDates,
+    transformTimeStampedDatesList
+} from './common';
+
+export interface MapRotationEntry {
+    map_name: string;
+    weight: number;
+    min_players: number;
+    // 0 for no upper limit
+    max_players: number;
+}
+
+export interface MapRotation extends TimeStamped {
+    server_id: number;
+    server_name: string;
+    enabled: boolean;
+    exclude_recent: number;
+    use_stats: boolean;
+    maps: MapRotationEntry[];
+}
+
+export type SaveMapRotationOpts = Pick<
+    MapRotation,
+    'enabled' | 'exclude_recent' | 'use_stats' | 'maps'
+>;
+
+export interface MapCandidate {
+    map_name: string;
+    weight: number;
+}
+
+export interface MapRotationChange {
+    map_rotation_change_id: number;
+    server_id: number;
+    server_name: string;
+    previous_map: string;
+    map_name: string;
+    players: number;
+    error: string;
+    created_on: Date;
+}
+
+export interface MapRotationChangeQueryFilter
+    extends QueryFilter<MapRotationChange> {
+    server_id?: number;
+}
+
+export const apiGetMapRotations = async (
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<MapRotation[]>(
+        `/api/map_rotations`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return transformTimeStampedDatesList(resp);
+};
+
+export const apiSaveMapRotation = async (
+    server_id: number,
+    opts: SaveMapRotationOpts,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<MapRotation, SaveMapRotationOpts>(
+        `/api/map_rotations/${server_id}`,
+        'POST',
+        opts,
+        abortController
+    );
+    return transformTimeStampedDates(resp);
+};
+
+export const apiDeleteMapRotation = async (
+    server_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/map_rotations/${server_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
+
+export const apiGetMapRotationCandidates = async (
+    server_id: number,
+    players?: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<MapCandidate[]>(
+        players != undefined
+            ? `/api/map_rotations/${server_id}/candidates?players=${players}`
+            : `/api/map_rotations/${server_id}/candidates`,
+        'GET',
+        undefined,
+        abortController
+    );
+};
+
+export const apiRotateMap = async (
+    server_id: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<MapRotationChange>(
+        `/api/map_rotations/${server_id}/rotate`,
+        'POST',
+        undefined,
+        abortController
+    );
+    return transformCreatedOnDate(resp);
+};
+
+export const apiGetMapRotationChanges = async (
+    opts: MapRotationChangeQueryFilter,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        LazyResult<MapRotationChange>,
+        MapRotationChangeQueryFilter
+    >(`/api/map_rotation_changes`, 'POST', opts, abortController);
+    resp.data = resp.data.map(transformCreatedOnDate);
+    return resp;
+};
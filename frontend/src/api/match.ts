@@ -121,6 +121,7 @@ export const apiGetMatch = async (match_id: string) => {
 export interface MatchesQueryOpts extends QueryFilter<MatchSummary> {
     steam_id?: string;
     server_id?: number;
+    server_group_id?: number;
     map?: string;
     time_start?: Date;
     time_end?: Date;
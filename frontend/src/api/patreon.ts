@@ -0,0 +1,75 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import {
+    apiCall,
+    EmptyBody,
+    QueryFilter,
+    transformCreatedOnDate
+} from './common';
+
+export interface PatreonLink {
+    patreon_id: string;
+    steam_id: string;
+    created_on: Date;
+}
+
+export interface PatreonPerkLog {
+    patreon_perk_log_id: number;
+    steam_id: string;
+    patreon_id: string;
+    tier_id: string;
+    action: 'grant' | 'revoke' | 'lapse' | 'renew';
+    perk: string;
+    value: string;
+    created_on: Date;
+}
+
+export interface PatreonPerkLogQuery extends QueryFilter<PatreonPerkLog> {
+    steam_id?: string;
+}
+
+export const apiGetPatreonLinks = async (abortController?: AbortController) => {
+    const resp = await apiCall<PatreonLink[]>(
+        `/api/patreon/links`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return resp.map(transformCreatedOnDate);
+};
+
+export const apiSavePatreonLink = async (
+    patreon_id: string,
+    steam_id: string,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<PatreonLink>(
+        `/api/patreon/links`,
+        'POST',
+        { patreon_id, steam_id },
+        abortController
+    );
+    return transformCreatedOnDate(resp);
+};
+
+export const apiDeletePatreonLink = async (
+    patreon_id: string,
+    abortController?: AbortController
+) =>
+    await apiCall<EmptyBody>(
+        `/api/patreon/links/${patreon_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+
+export const apiGetPatreonPerkLogs = async (
+    opts: PatreonPerkLogQuery,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        LazyResult<PatreonPerkLog>,
+        PatreonPerkLogQuery
+    >(`/api/patreon/perk_logs`, 'POST', opts, abortController);
+    resp.data = resp.data.map(transformCreatedOnDate);
+    return resp;
+};
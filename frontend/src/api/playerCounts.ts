@@ -0,0 +1,65 @@
+import { parseDateTime } from '../util/text';
+import { apiCall } from './common';
+
+export type PlayerCountResolution = 'minute' | 'hour' | 'day';
+
+export interface PlayerCountSummary {
+    samples: number;
+    peak: number;
+    average: number;
+    p50: number;
+    p90: number;
+    p95: number;
+    p99: number;
+}
+
+export interface PlayerCountPoint {
+    bucket: Date;
+    samples: number;
+    peak: number;
+    average: number;
+}
+
+export interface PlayerCountSeries {
+    server_id: number;
+    short_name: string;
+    region: string;
+    summary: PlayerCountSummary;
+    points: PlayerCountPoint[];
+}
+
+export interface PlayerCountResult {
+    resolution: PlayerCountResolution;
+    summary: PlayerCountSummary;
+    regions: Record<string, PlayerCountSummary>;
+    servers: PlayerCountSeries[];
+}
+
+export interface PlayerCountQuery {
+    server_ids?: number[];
+    region?: string;
+    // Chosen automatically from the range when not set
+    resolution?: PlayerCountResolution;
+    from: Date;
+    to: Date;
+}
+
+export const apiGetPlayerCounts = async (
+    opts: PlayerCountQuery,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<PlayerCountResult, PlayerCountQuery>(
+        `/api/player_counts`,
+        'POST',
+        opts,
+        abortController
+    );
+    resp.servers = resp.servers.map((series) => {
+        series.points = series.points.map((point) => {
+            point.bucket = parseDateTime(point.bucket as unknown as string);
+            return point;
+        });
+        return series;
+    });
+    return resp;
+};
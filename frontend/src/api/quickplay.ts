@@ -0,0 +1,23 @@
+import { apiCall } from './common';
+import { BaseServer } from './server';
+
+export interface QuickPlayPrefs {
+    game_modes: string[];
+    maps: string[];
+}
+
+export interface QuickPlayResult extends BaseServer {
+    score: number;
+    connect_url: string;
+}
+
+export const apiQuickPlay = async (
+    prefs: QuickPlayPrefs,
+    abortController?: AbortController
+) =>
+    await apiCall<QuickPlayResult[], QuickPlayPrefs>(
+        `/api/quickplay`,
+        'POST',
+        prefs,
+        abortController
+    );
@@ -0,0 +1,81 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import { apiCall, QueryFilter, transformCreatedOnDate } from './common';
+import { apiStream } from './stream';
+
+export interface RconTarget {
+    server_ids?: number[];
+    server_group_ids?: number[];
+    region?: string;
+    all?: boolean;
+}
+
+export interface RconResult {
+    server_id: number;
+    server_name: string;
+    response: string;
+    error: string;
+}
+
+export interface RconAudit {
+    rcon_audit_id: number;
+    steam_id: string;
+    personaname: string;
+    server_id: number;
+    server_name: string;
+    command: string;
+    response: string;
+    error: string;
+    created_on: Date;
+}
+
+export interface RconAuditQueryFilter extends QueryFilter<RconAudit> {
+    steam_id?: string;
+    server_id?: number;
+}
+
+// apiRconExec executes the command on the target servers. The results for each server are
+// streamed back as server-sent events and passed to onResult as they arrive.
+export const apiRconExec = async (
+    command: string,
+    target: RconTarget,
+    onResult: (result: RconResult) => void,
+    abortController?: AbortController
+) => {
+    await apiStream<RconResult>(
+        '/api/rcon/exec',
+        'POST',
+        { ...target, command },
+        (event, data) => {
+            if (event == 'done') {
+                return false;
+            }
+            if (event == 'result') {
+                onResult(data);
+            }
+        },
+        abortController
+    );
+};
+
+export const apiGetRconHistory = async (abortController?: AbortController) => {
+    return await apiCall<string[]>(
+        `/api/rcon/history`,
+        'GET',
+        undefined,
+        abortController
+    );
+};
+
+export const apiGetRconAudit = async (
+    opts: RconAuditQueryFilter,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<LazyResult<RconAudit>, RconAuditQueryFilter>(
+        `/api/rcon/audit`,
+        'POST',
+        opts,
+        abortController
+    );
+    resp.data = resp.data.map(transformCreatedOnDate);
+    return resp;
+};
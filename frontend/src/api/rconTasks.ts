@@ -0,0 +1,111 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import {
+    apiCall,
+    EmptyBody,
+    QueryFilter,
+    TimeStamped,
+    transformCreatedOnDate,
+    transformTimeStampedDates,
+    transformTimeStampedDatesList
+} from './common';
+
+export interface RconTask extends TimeStamped {
+    rcon_task_id: number;
+    name: string;
+    schedule: string;
+    server_ids: number[];
+    server_group_ids: number[];
+    region: string;
+    all_servers: boolean;
+    commands: string[];
+    players_below: number;
+    enabled: boolean;
+}
+
+export type SaveRconTaskOpts = Omit<
+    RconTask,
+    'rcon_task_id' | 'created_on' | 'updated_on'
+>;
+
+export interface RconTaskRun {
+    rcon_task_run_id: number;
+    rcon_task_id: number;
+    task_name: string;
+    server_id: number;
+    server_name: string;
+    skipped: boolean;
+    output: string;
+    error: string;
+    created_on: Date;
+}
+
+export interface RconTaskRunQueryFilter extends QueryFilter<RconTaskRun> {
+    rcon_task_id?: number;
+    server_id?: number;
+}
+
+export const apiGetRconTasks = async (abortController?: AbortController) => {
+    const resp = await apiCall<RconTask[]>(
+        `/api/rcon_tasks`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return transformTimeStampedDatesList(resp);
+};
+
+export const apiSaveRconTask = async (
+    opts: SaveRconTaskOpts,
+    rcon_task_id?: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<RconTask, SaveRconTaskOpts>(
+        rcon_task_id ? `/api/rcon_tasks/${rcon_task_id}` : `/api/rcon_tasks`,
+        'POST',
+        opts,
+        abortController
+    );
+    return transformTimeStampedDates(resp);
+};
+
+export const apiDeleteRconTask = async (
+    rcon_task_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/rcon_tasks/${rcon_task_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
+
+export const apiRunRconTask = async (
+    rcon_task_id: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<RconTaskRun[]>(
+        `/api/rcon_tasks/${rcon_task_id}/run`,
+        'POST',
+        undefined,
+        abortController
+    );
+    return resp.map(transformCreatedOnDate);
+};
+
+export const apiGetRconTaskRuns = async (
+    opts: RconTaskRunQueryFilter,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        LazyResult<RconTaskRun>,
+        RconTaskRunQueryFilter
+    >(
+        `/api/rcon_task_runs`,
+        'POST',
+        opts,
+        abortController
+    );
+    resp.data = resp.data.map(transformCreatedOnDate);
+    return resp;
+};
@@ -1,5 +1,6 @@
 import { LazyResult } from '../component/table/LazyTableSimple';
 import { apiCall, QueryFilter, TimeStamped } from './common';
+import { apiStream } from './stream';
 
 export interface BaseServer {
     server_id: number;
@@ -18,6 +19,12 @@ export interface BaseServer {
     latitude: number;
     longitude: number;
     distance: number; // calculated on load
+    player_list?: ServerPlayer[]; // only sent to logged-in users over the state stream
+}
+
+export interface ServerPlayer {
+    steam_id: string;
+    name: string;
 }
 
 export const cleanMapName = (name: string): string => {
@@ -61,6 +68,7 @@ export interface Server extends TimeStamped {
     colour: string;
     enable_stats: boolean;
     log_secret: number;
+    pending_approval: boolean;
 }
 
 export interface Location {
@@ -81,6 +89,76 @@ export const apiGetServerStates = async (abortController?: AbortController) =>
         abortController
     );
 
+export interface ServerStateSnapshot extends UserServers {
+    seq: number;
+}
+
+export interface ServerStateUpdate {
+    seq: number;
+    server_id: number;
+    removed: boolean;
+    server?: BaseServer;
+    joined?: ServerPlayer[];
+    left?: ServerPlayer[];
+}
+
+// applyServerStateUpdate returns a new server list with the update applied.
+export const applyServerStateUpdate = (
+    servers: BaseServer[],
+    update: ServerStateUpdate
+): BaseServer[] => {
+    const existing = servers.find((s) => s.server_id == update.server_id);
+    const others = servers.filter((s) => s.server_id != update.server_id);
+    if (update.removed || !update.server) {
+        return others;
+    }
+
+    let playerList = existing?.player_list;
+    if (update.joined || update.left) {
+        const left = (update.left ?? []).map((p) => p.steam_id);
+        playerList = [
+            ...(playerList ?? []).filter((p) => !left.includes(p.steam_id)),
+            ...(update.joined ?? [])
+        ];
+    }
+
+    return [...others, { ...update.server, player_list: playerList }].sort(
+        (a, b) => a.name.localeCompare(b.name)
+    );
+};
+
+// apiStreamServerStates receives the current server states followed by updates as they happen. The promise
+// resolves when the stream ends, or an update is missed, after which the caller should reconnect to resync.
+export const apiStreamServerStates = async (
+    onSnapshot: (snapshot: ServerStateSnapshot) => void,
+    onUpdate: (update: ServerStateUpdate) => void,
+    abortController?: AbortController
+) => {
+    let seq = 0;
+
+    await apiStream<ServerStateSnapshot | ServerStateUpdate>(
+        `/api/servers/state/stream`,
+        'GET',
+        undefined,
+        (event, data) => {
+            switch (event) {
+                case 'snapshot':
+                    seq = data.seq;
+                    onSnapshot(data as ServerStateSnapshot);
+                    break;
+                case 'update':
+                    if (data.seq != seq + 1) {
+                        return false;
+                    }
+                    seq = data.seq;
+                    onUpdate(data as ServerStateUpdate);
+                    break;
+            }
+        },
+        abortController
+    );
+};
+
 export interface SaveServerOpts {
     server_name_short: string;
     server_name: string;
@@ -133,6 +211,9 @@ export const apiGetServers = async (abortController: AbortController) =>
 export const apiDeleteServer = async (server_id: number) =>
     await apiCall(`/api/servers/${server_id}`, 'DELETE');
 
+export const apiApproveServer = async (server_id: number) =>
+    await apiCall<Server>(`/api/servers/${server_id}/approve`, 'POST');
+
 export interface SlimServer {
     addr: string;
     name: string;
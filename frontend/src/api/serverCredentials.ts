@@ -0,0 +1,125 @@
+import { parseDateTime } from '../util/text';
+import { apiCall, EmptyBody } from './common';
+
+export type ServerCredentialKind = 'key' | 'log_secret';
+
+export const serverScopes = [
+    'admins',
+    'ping',
+    'check',
+    'demo',
+    'ban',
+    'report',
+    'state'
+];
+
+export interface ServerCredential {
+    server_credential_id: number;
+    server_id: number;
+    kind: ServerCredentialKind;
+    scopes: string[];
+    revoked: boolean;
+    expires_on: Date | null;
+    last_used_on: Date | null;
+    created_on: Date;
+}
+
+export interface ServerCredentialRotated extends ServerCredential {
+    // Only returned once when the credential is created
+    secret: string;
+}
+
+export interface ServerToken {
+    server_token_id: string;
+    server_id: number;
+    server_credential_id: number;
+    scopes: string[];
+    revoked: boolean;
+    expires_on: Date;
+    last_used_on: Date | null;
+    created_on: Date;
+}
+
+const transformCredential = <T extends ServerCredential>(item: T): T => {
+    item.created_on = parseDateTime(item.created_on as unknown as string);
+    item.expires_on = item.expires_on
+        ? parseDateTime(item.expires_on as unknown as string)
+        : null;
+    item.last_used_on = item.last_used_on
+        ? parseDateTime(item.last_used_on as unknown as string)
+        : null;
+    return item;
+};
+
+const transformToken = (item: ServerToken): ServerToken => {
+    item.created_on = parseDateTime(item.created_on as unknown as string);
+    item.expires_on = parseDateTime(item.expires_on as unknown as string);
+    item.last_used_on = item.last_used_on
+        ? parseDateTime(item.last_used_on as unknown as string)
+        : null;
+    return item;
+};
+
+export const apiGetServerCredentials = async (
+    server_id: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerCredential[]>(
+        `/api/servers/${server_id}/credentials`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return resp.map(transformCredential);
+};
+
+export const apiRotateServerCredential = async (
+    server_id: number,
+    kind: ServerCredentialKind,
+    scopes: string[],
+    overlap?: string,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerCredentialRotated>(
+        `/api/servers/${server_id}/credentials/rotate`,
+        'POST',
+        { kind, scopes, overlap },
+        abortController
+    );
+    return transformCredential(resp);
+};
+
+export const apiRevokeServerCredential = async (
+    server_credential_id: number,
+    abortController?: AbortController
+) =>
+    await apiCall<EmptyBody>(
+        `/api/server_credentials/${server_credential_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+
+export const apiGetServerTokens = async (
+    server_id: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerToken[]>(
+        `/api/servers/${server_id}/tokens`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return resp.map(transformToken);
+};
+
+export const apiRevokeServerToken = async (
+    server_token_id: string,
+    abortController?: AbortController
+) =>
+    await apiCall<EmptyBody>(
+        `/api/server_tokens/${server_token_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
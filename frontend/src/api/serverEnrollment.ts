@@ -0,0 +1,77 @@
+import { parseDateTime } from '../util/text';
+import { apiCall, EmptyBody } from './common';
+
+export interface ServerEnrollmentToken {
+    server_enrollment_token_id: number;
+    token: string;
+    short_name: string;
+    region: string;
+    cc: string;
+    latitude: number;
+    longitude: number;
+    reserved_slots: number;
+    enable_stats: boolean;
+    note: string;
+    author_id: string;
+    server_id: number | null;
+    used_on: Date | null;
+    expires_on: Date;
+    created_on: Date;
+}
+
+export interface ServerEnrollmentTokenRequest {
+    short_name: string;
+    region: string;
+    cc: string;
+    lat: number;
+    lon: number;
+    reserved_slots: number;
+    enable_stats: boolean;
+    note: string;
+    valid_for: string;
+}
+
+const transformToken = (token: ServerEnrollmentToken) => {
+    token.created_on = parseDateTime(token.created_on as unknown as string);
+    token.expires_on = parseDateTime(token.expires_on as unknown as string);
+    token.used_on = token.used_on
+        ? parseDateTime(token.used_on as unknown as string)
+        : null;
+    return token;
+};
+
+export const apiGetServerEnrollmentTokens = async (
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerEnrollmentToken[]>(
+        `/api/server_enrollment_tokens`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return resp.map(transformToken);
+};
+
+export const apiCreateServerEnrollmentToken = async (
+    opts: ServerEnrollmentTokenRequest,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerEnrollmentToken>(
+        `/api/server_enrollment_tokens`,
+        'POST',
+        opts,
+        abortController
+    );
+    return transformToken(resp);
+};
+
+export const apiDeleteServerEnrollmentToken = async (
+    server_enrollment_token_id: number,
+    abortController?: AbortController
+) =>
+    await apiCall<EmptyBody>(
+        `/api/server_enrollment_tokens/${server_enrollment_token_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
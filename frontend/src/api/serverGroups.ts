@@ -0,0 +1,85 @@
+import {
+    apiCall,
+    EmptyBody,
+    TimeStamped,
+    transformTimeStampedDates,
+    transformTimeStampedDatesList
+} from './common';
+
+export interface ServerGroup extends TimeStamped {
+    server_group_id: number;
+    name: string;
+    description: string;
+    server_ids: number[];
+}
+
+export type SaveServerGroupOpts = Pick<
+    ServerGroup,
+    'name' | 'description' | 'server_ids'
+>;
+
+export const apiGetServerGroups = async (
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerGroup[]>(
+        `/api/server_groups`,
+        'GET',
+        undefined,
+        abortController
+    );
+    return transformTimeStampedDatesList(resp);
+};
+
+export const apiSaveServerGroup = async (
+    opts: SaveServerGroupOpts,
+    server_group_id?: number,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<ServerGroup, SaveServerGroupOpts>(
+        server_group_id
+            ? `/api/server_groups/${server_group_id}`
+            : `/api/server_groups`,
+        'POST',
+        opts,
+        abortController
+    );
+    return transformTimeStampedDates(resp);
+};
+
+export const apiDeleteServerGroup = async (
+    server_group_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/server_groups/${server_group_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
+
+export const apiAddServerGroupMember = async (
+    server_group_id: number,
+    server_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/server_groups/${server_group_id}/servers/${server_id}`,
+        'PUT',
+        undefined,
+        abortController
+    );
+};
+
+export const apiRemoveServerGroupMember = async (
+    server_group_id: number,
+    server_id: number,
+    abortController?: AbortController
+) => {
+    return await apiCall<EmptyBody>(
+        `/api/server_groups/${server_group_id}/servers/${server_id}`,
+        'DELETE',
+        undefined,
+        abortController
+    );
+};
@@ -0,0 +1,70 @@
+import { parseDateTime } from '../util/text';
+import { apiCall } from './common';
+
+export interface ServerPerformanceSample {
+    server_id: number;
+    created_on: Date;
+    map_name: string;
+    players: number;
+    cpu: number;
+    fps: number;
+    frame_ms: number;
+    var_ms: number;
+    tick_ms: number;
+    net_in_kbps: number;
+    net_out_kbps: number;
+    packets_in: number;
+    packets_out: number;
+    loss_in: number;
+    loss_out: number;
+    choke_in: number;
+    choke_out: number;
+    uptime_minutes: number;
+    tick_health: number;
+    lag_spike: boolean;
+}
+
+export interface ServerMapChange {
+    map_name: string;
+    changed_on: Date;
+}
+
+export interface ServerPerformanceSeries {
+    server_id: number;
+    short_name: string;
+    lag_spikes: number;
+    map_changes: ServerMapChange[];
+    samples: ServerPerformanceSample[];
+}
+
+export interface ServerPerformanceQuery {
+    server_ids?: number[];
+    from: Date;
+    to: Date;
+    lag_spikes_only?: boolean;
+}
+
+export const apiGetServerPerformance = async (
+    opts: ServerPerformanceQuery,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        ServerPerformanceSeries[],
+        ServerPerformanceQuery
+    >(`/api/server_performance`, 'POST', opts, abortController);
+    return resp.map((series) => {
+        series.samples = series.samples.map((sample) => {
+            sample.created_on = parseDateTime(
+                sample.created_on as unknown as string
+            );
+            return sample;
+        });
+        series.map_changes = series.map_changes.map((change) => {
+            change.changed_on = parseDateTime(
+                change.changed_on as unknown as string
+            );
+            return change;
+        });
+        return series;
+    });
+};
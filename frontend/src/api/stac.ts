@@ -0,0 +1,35 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import { apiCall, QueryFilter, transformCreatedOnDate } from './common';
+
+export interface StacDetection {
+    stac_detection_id: number;
+    steam_id: string;
+    personaname: string;
+    server_id: number;
+    server_name: string;
+    match_id: string;
+    demo_name: string;
+    demo_tick: number;
+    detection: string;
+    count: number;
+    created_on: Date;
+}
+
+export interface StacDetectionQueryFilter extends QueryFilter<StacDetection> {
+    steam_id?: string;
+    server_id?: number;
+    match_id?: string;
+    demo_name?: string;
+}
+
+export const apiGetStacDetections = async (
+    opts: StacDetectionQueryFilter,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        LazyResult<StacDetection>,
+        StacDetectionQueryFilter
+    >(`/api/stac`, 'POST', opts, abortController);
+    resp.data = resp.data.map(transformCreatedOnDate);
+    return resp;
+};
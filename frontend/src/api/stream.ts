@@ -0,0 +1,74 @@
+import { emptyOrNullString } from '../util/types';
+import { APIError, ErrorCode, getAccessToken } from './common';
+
+// apiStream performs a request to an endpoint which responds with server-sent events, authenticating
+// when logged in. Each received event is passed to onEvent with its parsed data. The promise resolves
+// when the stream ends, or onEvent returns false.
+export const apiStream = async <T>(
+    url: string,
+    method: string,
+    body: object | undefined,
+    onEvent: (event: string, data: T) => boolean | void,
+    abortController?: AbortController
+) => {
+    const headers: Record<string, string> = {
+        'Content-Type': 'application/json; charset=UTF-8'
+    };
+    const accessToken = await getAccessToken(false);
+    if (!emptyOrNullString(accessToken)) {
+        headers['Authorization'] = `Bearer ${accessToken}`;
+    }
+
+    const response = await fetch(
+        new URL(url, `${location.protocol}//${location.host}`),
+        {
+            mode: 'same-origin',
+            credentials: 'include',
+            method,
+            headers,
+            body: body ? JSON.stringify(body) : undefined,
+            signal: abortController?.signal
+        }
+    );
+
+    if (response.status == 403) {
+        throw new APIError(ErrorCode.PermissionDenied);
+    }
+
+    if (!response.ok || !response.body) {
+        throw new APIError(ErrorCode.Unknown);
+    }
+
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+
+    let buffer = '';
+    // eslint-disable-next-line no-constant-condition
+    while (true) {
+        const { value, done } = await reader.read();
+        if (done) {
+            return;
+        }
+        buffer += value;
+
+        let idx = buffer.indexOf('\n\n');
+        while (idx >= 0) {
+            const lines = buffer.slice(0, idx).split('\n');
+            buffer = buffer.slice(idx + 2);
+            idx = buffer.indexOf('\n\n');
+
+            const event =
+                lines
+                    .find((l) => l.startsWith('event:'))
+                    ?.slice('event:'.length) ?? '';
+            const data = lines
+                .filter((l) => l.startsWith('data:'))
+                .map((l) => l.slice('data:'.length))
+                .join('\n');
+
+            if (onEvent(event, data ? JSON.parse(data) : undefined) === false) {
+                await reader.cancel();
+                return;
+            }
+        }
+    }
+};
@@ -0,0 +1,92 @@
+import { LazyResult } from '../component/table/LazyTableSimple';
+import {
+    apiCall,
+    EmptyBody,
+    QueryFilter,
+    TimeStamped,
+    transformTimeStampedDates
+} from './common';
+
+export enum SuspicionMetric {
+    Accuracy = 1,
+    HeadshotRatio = 2,
+    KD = 3
+}
+
+export const suspicionMetricString = (m: SuspicionMetric) => {
+    switch (m) {
+        case SuspicionMetric.Accuracy:
+            return 'Accuracy';
+        case SuspicionMetric.HeadshotRatio:
+            return 'Headshot Ratio';
+        case SuspicionMetric.KD:
+            return 'K/D';
+        default:
+            return 'Unknown';
+    }
+};
+
+export enum SuspicionFlagState {
+    Any = -1,
+    Open = 0,
+    Confirmed = 1,
+    Dismissed = 2
+}
+
+export const suspicionFlagStateString = (s: SuspicionFlagState) => {
+    switch (s) {
+        case SuspicionFlagState.Open:
+            return 'Open';
+        case SuspicionFlagState.Confirmed:
+            return 'Confirmed';
+        case SuspicionFlagState.Dismissed:
+            return 'Dismissed';
+        default:
+            return 'Unknown';
+    }
+};
+
+export interface SuspicionFlag extends TimeStamped {
+    suspicion_flag_id: number;
+    steam_id: string;
+    personaname: string;
+    avatarhash: string;
+    match_id: string;
+    demo_id: number;
+    weapon_id: number;
+    weapon_name: string;
+    metric: SuspicionMetric;
+    value: number;
+    baseline_mean: number;
+    baseline_stddev: number;
+    score: number;
+    state: SuspicionFlagState;
+}
+
+export interface SuspicionFlagQueryFilter extends QueryFilter<SuspicionFlag> {
+    steam_id?: string;
+    state: SuspicionFlagState;
+    min_score?: number;
+}
+
+export const apiGetSuspicionFlags = async (
+    opts: SuspicionFlagQueryFilter,
+    abortController?: AbortController
+) => {
+    const resp = await apiCall<
+        LazyResult<SuspicionFlag>,
+        SuspicionFlagQueryFilter
+    >(`/api/suspicion`, 'POST', opts, abortController);
+    resp.data = resp.data.map(transformTimeStampedDates);
+    return resp;
+};
+
+export const apiSetSuspicionFlagState = async (
+    suspicion_flag_id: number,
+    state: SuspicionFlagState
+) =>
+    await apiCall<EmptyBody, { state: SuspicionFlagState }>(
+        `/api/suspicion/${suspicion_flag_id}/state`,
+        'POST',
+        { state }
+    );
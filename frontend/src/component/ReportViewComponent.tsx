@@ -34,6 +34,7 @@ import { MDBodyField } from './MDBodyField';
 import { MarkDownRenderer } from './MarkdownRenderer';
 import { PlayerMessageContext } from './PlayerMessageContext';
 import { SourceBansList } from './SourceBansList';
+import { StacDetectionList } from './StacDetectionList';
 import { TabPanel } from './TabPanel';
 import { UserMessageView } from './UserMessageView';
 import { ResetButton, SubmitButton } from './modal/Buttons';
@@ -254,6 +255,11 @@ export const ReportViewComponent = ({
                         />
                     )}
 
+                    {currentUser.permission_level >=
+                        PermissionLevel.Moderator && (
+                        <StacDetectionList steam_id={report.target_id} />
+                    )}
+
                     {messages.map((m) => (
                         <UserMessageView
                             onDelete={onDelete}
@@ -0,0 +1,203 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import DeleteIcon from '@mui/icons-material/Delete';
+import VpnKeyIcon from '@mui/icons-material/VpnKey';
+import Button from '@mui/material/Button';
+import IconButton from '@mui/material/IconButton';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import {
+    apiCreateServerEnrollmentToken,
+    apiDeleteServerEnrollmentToken,
+    apiGetServerEnrollmentTokens,
+    ServerEnrollmentToken
+} from '../api';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+import { ContainerWithHeader } from './ContainerWithHeader';
+import { LoadingIcon } from './LoadingIcon';
+import { LazyTable } from './table/LazyTable';
+
+export const ServerEnrollmentTokens = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [tokens, setTokens] = useState<ServerEnrollmentToken[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [shortName, setShortName] = useState('');
+    const [region, setRegion] = useState('');
+    const [cc, setCC] = useState('');
+    const [note, setNote] = useState('');
+    const [validFor, setValidFor] = useState('1d');
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerEnrollmentTokens(abortController)
+            .then(setTokens)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    const onCreate = useCallback(async () => {
+        try {
+            const token = await apiCreateServerEnrollmentToken({
+                short_name: shortName,
+                region: region,
+                cc: cc,
+                lat: 0,
+                lon: 0,
+                reserved_slots: 0,
+                enable_stats: true,
+                note: note,
+                valid_for: validFor
+            });
+            setTokens((prev) => [token, ...prev]);
+            setShortName('');
+            setNote('');
+            sendFlash('success', 'Enrollment token created successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to create enrollment token: ${e}`);
+        }
+    }, [cc, note, region, sendFlash, shortName, validFor]);
+
+    const onDelete = useCallback(
+        async (token: ServerEnrollmentToken) => {
+            try {
+                await apiDeleteServerEnrollmentToken(
+                    token.server_enrollment_token_id
+                );
+                setTokens((prev) =>
+                    prev.filter(
+                        (t) =>
+                            t.server_enrollment_token_id !=
+                            token.server_enrollment_token_id
+                    )
+                );
+                sendFlash('success', 'Enrollment token deleted successfully');
+            } catch (e) {
+                sendFlash('error', `Failed to delete enrollment token: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    return (
+        <ContainerWithHeader
+            title={'Enrollment Tokens'}
+            iconLeft={loading ? <LoadingIcon /> : <VpnKeyIcon />}
+        >
+            <Stack spacing={2}>
+                <Typography variant={'body2'}>
+                    Set gb_core_enroll_token on a new server to register it.
+                    Enrolled servers must be approved before they can be used.
+                </Typography>
+                <LazyTable<ServerEnrollmentToken>
+                    rows={tokens}
+                    sortOrder={'desc'}
+                    sortColumn={'created_on'}
+                    onSortColumnChanged={() => {}}
+                    onSortOrderChanged={() => {}}
+                    columns={[
+                        {
+                            label: 'Token',
+                            tooltip: 'Enrollment token',
+                            sortKey: 'token',
+                            align: 'left'
+                        },
+                        {
+                            label: 'Name',
+                            tooltip: 'Short name given to the server',
+                            sortKey: 'short_name',
+                            align: 'left'
+                        },
+                        {
+                            label: 'Region',
+                            tooltip: 'Region',
+                            sortKey: 'region',
+                            align: 'left'
+                        },
+                        {
+                            label: 'Note',
+                            tooltip: 'Note',
+                            sortKey: 'note',
+                            align: 'left'
+                        },
+                        {
+                            label: 'Expires',
+                            tooltip: 'When the token expires',
+                            sortKey: 'expires_on',
+                            align: 'left',
+                            renderer: (row) => (
+                                <Typography variant={'body1'}>
+                                    {renderDateTime(row.expires_on)}
+                                </Typography>
+                            )
+                        },
+                        {
+                            label: 'Used',
+                            tooltip: 'When the token was used',
+                            sortKey: 'used_on',
+                            align: 'left',
+                            renderer: (row) => (
+                                <Typography variant={'body1'}>
+                                    {row.used_on
+                                        ? renderDateTime(row.used_on)
+                                        : 'Unused'}
+                                </Typography>
+                            )
+                        },
+                        {
+                            label: 'Actions',
+                            tooltip: 'Actions',
+                            virtual: true,
+                            virtualKey: 'actions',
+                            align: 'right',
+                            renderer: (row) => (
+                                <IconButton
+                                    color={'error'}
+                                    onClick={() => onDelete(row)}
+                                >
+                                    <DeleteIcon />
+                                </IconButton>
+                            )
+                        }
+                    ]}
+                />
+                <Stack direction={'row'} spacing={1}>
+                    <TextField
+                        label={'Short Name'}
+                        placeholder={'Use server name'}
+                        value={shortName}
+                        onChange={(evt) => setShortName(evt.target.value)}
+                    />
+                    <TextField
+                        label={'Region'}
+                        value={region}
+                        onChange={(evt) => setRegion(evt.target.value)}
+                    />
+                    <TextField
+                        label={'CC'}
+                        value={cc}
+                        onChange={(evt) => setCC(evt.target.value)}
+                    />
+                    <TextField
+                        label={'Valid For'}
+                        value={validFor}
+                        onChange={(evt) => setValidFor(evt.target.value)}
+                    />
+                    <TextField
+                        fullWidth
+                        label={'Note'}
+                        value={note}
+                        onChange={(evt) => setNote(evt.target.value)}
+                    />
+                    <Button variant={'contained'} onClick={onCreate}>
+                        Create
+                    </Button>
+                </Stack>
+            </Stack>
+        </ContainerWithHeader>
+    );
+};
@@ -90,11 +90,23 @@ export const ServerList = () => {
                         sortKey: 'players',
                         sortable: false,
                         renderer: (obj, value) => {
-                            return (
+                            const count = (
                                 <Typography variant={'body2'}>
                                     {`${value}/${obj.max_players}`}
                                 </Typography>
                             );
+                            if (!obj.player_list?.length) {
+                                return count;
+                            }
+                            return (
+                                <Tooltip
+                                    title={obj.player_list
+                                        .map((p) => p.name)
+                                        .join(', ')}
+                                >
+                                    {count}
+                                </Tooltip>
+                            );
                         }
                     },
                     {
@@ -0,0 +1,81 @@
+import React, { useEffect, useState, JSX } from 'react';
+import GppMaybeIcon from '@mui/icons-material/GppMaybe';
+import Table from '@mui/material/Table';
+import TableBody from '@mui/material/TableBody';
+import TableCell from '@mui/material/TableCell';
+import TableContainer from '@mui/material/TableContainer';
+import TableHead from '@mui/material/TableHead';
+import TableRow from '@mui/material/TableRow';
+import { apiGetStacDetections, StacDetection } from '../api';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+import { ContainerWithHeader } from './ContainerWithHeader';
+
+interface StacDetectionListProps {
+    steam_id: string;
+}
+
+export const StacDetectionList = ({
+    steam_id
+}: StacDetectionListProps): JSX.Element => {
+    const [detections, setDetections] = useState<StacDetection[]>([]);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+
+        apiGetStacDetections({ steam_id, limit: 100 }, abortController)
+            .then((resp) => {
+                setDetections(resp.data);
+            })
+            .catch(logErr);
+
+        return () => abortController.abort();
+    }, [steam_id]);
+
+    if (!detections.length) {
+        return <></>;
+    }
+
+    return (
+        <ContainerWithHeader
+            title={'StAC Anti-Cheat Detections'}
+            iconLeft={<GppMaybeIcon />}
+        >
+            <TableContainer>
+                <Table size="small">
+                    <TableHead>
+                        <TableRow>
+                            <TableCell>Created</TableCell>
+                            <TableCell>Server</TableCell>
+                            <TableCell>Detection</TableCell>
+                            <TableCell>Count</TableCell>
+                            <TableCell>Demo</TableCell>
+                            <TableCell>Tick</TableCell>
+                        </TableRow>
+                    </TableHead>
+                    <TableBody>
+                        {detections.map((d) => {
+                            return (
+                                <TableRow
+                                    key={`stac-${d.stac_detection_id}`}
+                                    hover
+                                >
+                                    <TableCell>
+                                        {renderDateTime(d.created_on)}
+                                    </TableCell>
+                                    <TableCell>{d.server_name}</TableCell>
+                                    <TableCell>{d.detection}</TableCell>
+                                    <TableCell>{d.count}</TableCell>
+                                    <TableCell>{d.demo_name}</TableCell>
+                                    <TableCell>
+                                        {d.demo_tick >= 0 ? d.demo_tick : ''}
+                                    </TableCell>
+                                </TableRow>
+                            );
+                        })}
+                    </TableBody>
+                </Table>
+            </TableContainer>
+        </ContainerWithHeader>
+    );
+};
@@ -9,19 +9,32 @@ import DnsIcon from '@mui/icons-material/Dns';
 import EmojiEventsIcon from '@mui/icons-material/EmojiEvents';
 import ExitToAppIcon from '@mui/icons-material/ExitToApp';
 import ForumIcon from '@mui/icons-material/Forum';
+import GroupsIcon from '@mui/icons-material/Groups';
 import LightModeIcon from '@mui/icons-material/LightMode';
+import HowToRegIcon from '@mui/icons-material/HowToReg';
 import LiveHelpIcon from '@mui/icons-material/LiveHelp';
 import MailIcon from '@mui/icons-material/Mail';
+import MapIcon from '@mui/icons-material/Map';
 import MenuIcon from '@mui/icons-material/Menu';
+import MonitorHeartIcon from '@mui/icons-material/MonitorHeart';
 import NewspaperIcon from '@mui/icons-material/Newspaper';
 import PersonSearchIcon from '@mui/icons-material/PersonSearch';
 import ReportIcon from '@mui/icons-material/Report';
+import ScheduleIcon from '@mui/icons-material/Schedule';
 import SettingsIcon from '@mui/icons-material/Settings';
+import SmartToyIcon from '@mui/icons-material/SmartToy';
+import SpeedIcon from '@mui/icons-material/Speed';
 import StorageIcon from '@mui/icons-material/Storage';
+import StreamIcon from '@mui/icons-material/Stream';
 import SubjectIcon from '@mui/icons-material/Subject';
 import SupportIcon from '@mui/icons-material/Support';
+import TerminalIcon from '@mui/icons-material/Terminal';
 import TimelineIcon from '@mui/icons-material/Timeline';
+import TroubleshootIcon from '@mui/icons-material/Troubleshoot';
 import TravelExploreIcon from '@mui/icons-material/TravelExplore';
+import TuneIcon from '@mui/icons-material/Tune';
+import VolunteerActivismIcon from '@mui/icons-material/VolunteerActivism';
+import WorkspacesIcon from '@mui/icons-material/Workspaces';
 import AppBar from '@mui/material/AppBar';
 import Avatar from '@mui/material/Avatar';
 import Badge from '@mui/material/Badge';
@@ -209,6 +222,16 @@ export const TopBar = () => {
                 text: 'Ban Appeals',
                 icon: <LiveHelpIcon sx={colourOpts} />
             });
+            items.push({
+                to: '/admin/approvals',
+                text: 'Pending Approvals',
+                icon: <HowToRegIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/suspicion',
+                text: 'Suspicion Flags',
+                icon: <TroubleshootIcon sx={colourOpts} />
+            });
             items.push({
                 to: '/admin/news',
                 text: 'News',
@@ -219,6 +242,16 @@ export const TopBar = () => {
                 text: 'IP/Network Tools',
                 icon: <TravelExploreIcon sx={colourOpts} />
             });
+            items.push({
+                to: '/admin/rcon',
+                text: 'RCON Console',
+                icon: <TerminalIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/live',
+                text: 'Live Events',
+                icon: <StreamIcon sx={colourOpts} />
+            });
             items.push({
                 to: '/admin/contests',
                 text: 'Contests',
@@ -229,6 +262,16 @@ export const TopBar = () => {
                 text: 'People',
                 icon: <PersonSearchIcon sx={colourOpts} />
             });
+            items.push({
+                to: '/admin/player_counts',
+                text: 'Player Counts',
+                icon: <GroupsIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/server_performance',
+                text: 'Performance',
+                icon: <SpeedIcon sx={colourOpts} />
+            });
         }
         if (currentUser.permission_level >= PermissionLevel.Admin) {
             // items.push({
@@ -246,6 +289,41 @@ export const TopBar = () => {
                 text: 'Servers',
                 icon: <DnsIcon sx={colourOpts} />
             });
+            items.push({
+                to: '/admin/bot_defense',
+                text: 'Bot Defense',
+                icon: <SmartToyIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/health',
+                text: 'Server Health',
+                icon: <MonitorHeartIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/rcon_tasks',
+                text: 'Scheduled Tasks',
+                icon: <ScheduleIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/cvar_templates',
+                text: 'Cvar Templates',
+                icon: <TuneIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/map_rotation',
+                text: 'Map Rotation',
+                icon: <MapIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/server_groups',
+                text: 'Server Groups',
+                icon: <WorkspacesIcon sx={colourOpts} />
+            });
+            items.push({
+                to: '/admin/patreon',
+                text: 'Patreon',
+                icon: <VolunteerActivismIcon sx={colourOpts} />
+            });
         }
         return items;
     }, [colourOpts, currentUser.permission_level]);
@@ -0,0 +1,303 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import NiceModal, { muiDialogV5, useModal } from '@ebay/nice-modal-react';
+import BlockIcon from '@mui/icons-material/Block';
+import {
+    Dialog,
+    DialogActions,
+    DialogContent,
+    DialogTitle
+} from '@mui/material';
+import Alert from '@mui/material/Alert';
+import Button from '@mui/material/Button';
+import IconButton from '@mui/material/IconButton';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import {
+    apiGetServerCredentials,
+    apiGetServerTokens,
+    apiRevokeServerCredential,
+    apiRevokeServerToken,
+    apiRotateServerCredential,
+    Server,
+    ServerCredential,
+    ServerCredentialKind,
+    serverScopes,
+    ServerToken
+} from '../../api';
+import { useUserFlashCtx } from '../../contexts/UserFlashCtx';
+import { logErr } from '../../util/errors';
+import { renderDateTime } from '../../util/text';
+import { Heading } from '../Heading';
+import { LazyTable } from '../table/LazyTable';
+import { CloseButton } from './Buttons';
+
+export interface ServerCredentialsModalProps {
+    server: Server;
+}
+
+const renderOptionalDate = (date: Date | null) => (
+    <Typography variant={'body1'}>
+        {date ? renderDateTime(date) : 'Never'}
+    </Typography>
+);
+
+export const ServerCredentialsModal = NiceModal.create(
+    ({ server }: ServerCredentialsModalProps) => {
+        const modal = useModal();
+        const { sendFlash } = useUserFlashCtx();
+        const [credentials, setCredentials] = useState<ServerCredential[]>(
+            []
+        );
+        const [tokens, setTokens] = useState<ServerToken[]>([]);
+        const [kind, setKind] = useState<ServerCredentialKind>('key');
+        const [scopes, setScopes] = useState<string[]>([]);
+        const [overlap, setOverlap] = useState('');
+        const [secret, setSecret] = useState('');
+
+        const reload = useCallback(async () => {
+            setCredentials(await apiGetServerCredentials(server.server_id));
+            setTokens(await apiGetServerTokens(server.server_id));
+        }, [server.server_id]);
+
+        useEffect(() => {
+            reload().catch(logErr);
+        }, [reload]);
+
+        const onRotate = useCallback(async () => {
+            try {
+                const credential = await apiRotateServerCredential(
+                    server.server_id,
+                    kind,
+                    scopes,
+                    overlap
+                );
+                setSecret(credential.secret);
+                await reload();
+                sendFlash('success', 'Credential rotated successfully');
+            } catch (e) {
+                sendFlash('error', `Failed to rotate credential: ${e}`);
+            }
+        }, [kind, overlap, reload, scopes, sendFlash, server.server_id]);
+
+        const onRevokeCredential = useCallback(
+            async (credential: ServerCredential) => {
+                try {
+                    await apiRevokeServerCredential(
+                        credential.server_credential_id
+                    );
+                    await reload();
+                    sendFlash('success', 'Credential revoked successfully');
+                } catch (e) {
+                    sendFlash('error', `Failed to revoke credential: ${e}`);
+                }
+            },
+            [reload, sendFlash]
+        );
+
+        const onRevokeToken = useCallback(
+            async (token: ServerToken) => {
+                try {
+                    await apiRevokeServerToken(token.server_token_id);
+                    await reload();
+                    sendFlash('success', 'Token revoked successfully');
+                } catch (e) {
+                    sendFlash('error', `Failed to revoke token: ${e}`);
+                }
+            },
+            [reload, sendFlash]
+        );
+
+        return (
+            <Dialog fullWidth maxWidth={'lg'} {...muiDialogV5(modal)}>
+                <DialogTitle>Credentials: {server.short_name}</DialogTitle>
+                <DialogContent>
+                    <Stack spacing={2}>
+                        {secret != '' && (
+                            <Alert severity={'warning'}>
+                                New secret: <b>{secret}</b>. This will not be
+                                shown again.
+                            </Alert>
+                        )}
+                        <Stack direction={'row'} spacing={1}>
+                            <Select<ServerCredentialKind>
+                                value={kind}
+                                onChange={(evt) =>
+                                    setKind(
+                                        evt.target.value as ServerCredentialKind
+                                    )
+                                }
+                            >
+                                <MenuItem value={'key'}>Server Key</MenuItem>
+                                <MenuItem value={'log_secret'}>
+                                    Log Secret
+                                </MenuItem>
+                            </Select>
+                            <Select<string[]>
+                                multiple
+                                displayEmpty
+                                value={scopes}
+                                disabled={kind != 'key'}
+                                renderValue={(selected) =>
+                                    selected.length == 0
+                                        ? 'All scopes'
+                                        : selected.join(', ')
+                                }
+                                onChange={(evt) =>
+                                    setScopes(evt.target.value as string[])
+                                }
+                            >
+                                {serverScopes.map((scope) => (
+                                    <MenuItem key={scope} value={scope}>
+                                        {scope}
+                                    </MenuItem>
+                                ))}
+                            </Select>
+                            <TextField
+                                label={'Overlap'}
+                                placeholder={'Default'}
+                                value={overlap}
+                                onChange={(evt) => setOverlap(evt.target.value)}
+                            />
+                            <Button variant={'contained'} onClick={onRotate}>
+                                Rotate
+                            </Button>
+                        </Stack>
+                        <Heading>Credentials</Heading>
+                        <LazyTable<ServerCredential>
+                            rows={credentials}
+                            sortOrder={'desc'}
+                            sortColumn={'created_on'}
+                            onSortColumnChanged={() => {}}
+                            onSortOrderChanged={() => {}}
+                            columns={[
+                                {
+                                    label: 'Kind',
+                                    tooltip: 'Credential kind',
+                                    sortKey: 'kind',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Scopes',
+                                    tooltip: 'Scopes granted to tokens',
+                                    sortKey: 'scopes',
+                                    align: 'left',
+                                    renderer: (row) => (
+                                        <Typography variant={'body1'}>
+                                            {row.scopes.length == 0
+                                                ? 'All'
+                                                : row.scopes.join(', ')}
+                                        </Typography>
+                                    )
+                                },
+                                {
+                                    label: 'Created',
+                                    tooltip: 'When the credential was created',
+                                    sortKey: 'created_on',
+                                    align: 'left',
+                                    renderer: (row) =>
+                                        renderOptionalDate(row.created_on)
+                                },
+                                {
+                                    label: 'Expires',
+                                    tooltip: 'When the credential expires',
+                                    sortKey: 'expires_on',
+                                    align: 'left',
+                                    renderer: (row) =>
+                                        renderOptionalDate(row.expires_on)
+                                },
+                                {
+                                    label: 'Last Used',
+                                    tooltip: 'When the credential was last used',
+                                    sortKey: 'last_used_on',
+                                    align: 'left',
+                                    renderer: (row) =>
+                                        renderOptionalDate(row.last_used_on)
+                                },
+                                {
+                                    label: 'Revoke',
+                                    tooltip: 'Revoke the credential',
+                                    virtual: true,
+                                    virtualKey: 'actions',
+                                    align: 'right',
+                                    renderer: (row) => (
+                                        <IconButton
+                                            color={'error'}
+                                            disabled={row.revoked}
+                                            onClick={() =>
+                                                onRevokeCredential(row)
+                                            }
+                                        >
+                                            <BlockIcon />
+                                        </IconButton>
+                                    )
+                                }
+                            ]}
+                        />
+                        <Heading>Active Tokens</Heading>
+                        <LazyTable<ServerToken>
+                            rows={tokens}
+                            sortOrder={'desc'}
+                            sortColumn={'created_on'}
+                            onSortColumnChanged={() => {}}
+                            onSortOrderChanged={() => {}}
+                            columns={[
+                                {
+                                    label: 'Token',
+                                    tooltip: 'Token id',
+                                    sortKey: 'server_token_id',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Created',
+                                    tooltip: 'When the token was issued',
+                                    sortKey: 'created_on',
+                                    align: 'left',
+                                    renderer: (row) =>
+                                        renderOptionalDate(row.created_on)
+                                },
+                                {
+                                    label: 'Expires',
+                                    tooltip: 'When the token expires',
+                                    sortKey: 'expires_on',
+                                    align: 'left',
+                                    renderer: (row) =>
+                                        renderOptionalDate(row.expires_on)
+                                },
+                                {
+                                    label: 'Last Used',
+                                    tooltip: 'When the token was last used',
+                                    sortKey: 'last_used_on',
+                                    align: 'left',
+                                    renderer: (row) =>
+                                        renderOptionalDate(row.last_used_on)
+                                },
+                                {
+                                    label: 'Revoke',
+                                    tooltip: 'Revoke the token',
+                                    virtual: true,
+                                    virtualKey: 'actions',
+                                    align: 'right',
+                                    renderer: (row) => (
+                                        <IconButton
+                                            color={'error'}
+                                            disabled={row.revoked}
+                                            onClick={() => onRevokeToken(row)}
+                                        >
+                                            <BlockIcon />
+                                        </IconButton>
+                                    )
+                                }
+                            ]}
+                        />
+                    </Stack>
+                </DialogContent>
+                <DialogActions>
+                    <CloseButton onClick={async () => await modal.hide()} />
+                </DialogActions>
+            </Dialog>
+        );
+    }
+);
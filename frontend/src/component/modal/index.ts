@@ -19,6 +19,7 @@ import { ForumThreadCreatorModal } from './ForumThreadCreatorModal';
 import { ForumThreadEditorModal } from './ForumThreadEditorModal';
 import { MessageContextModal } from './MessageContextModal';
 import { PersonEditModal } from './PersonEditModal';
+import { ServerCredentialsModal } from './ServerCredentialsModal';
 import { ServerDeleteModal } from './ServerDeleteModal';
 import { ServerEditorModal } from './ServerEditorModal';
 import { UnbanASNModal } from './UnbanASNModal';
@@ -43,6 +44,7 @@ export const ModalUnbanCIDR = 'modal-unban-cidr';
 export const ModalUnbanGroup = 'modal-unban-group';
 export const ModalServerEditor = 'modal-server-editor';
 export const ModalServerDelete = 'modal-server-delete';
+export const ModalServerCredentials = 'modal-server-credentials';
 export const ModalMessageContext = 'modal-message-context';
 export const ModalFileUpload = 'modal-file-upload';
 export const ModalFilterDelete = 'modal-filter-delete';
@@ -79,3 +81,4 @@ NiceModal.register(ModalUnbanSteam, UnbanSteamModal);
 NiceModal.register(ModalUnbanASN, UnbanASNModal);
 NiceModal.register(ModalUnbanCIDR, UnbanCIDRModal);
 NiceModal.register(ModalUnbanGroup, UnbanGroupModal);
+NiceModal.register(ModalServerCredentials, ServerCredentialsModal);
@@ -0,0 +1,251 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import CheckIcon from '@mui/icons-material/Check';
+import CloseIcon from '@mui/icons-material/Close';
+import HowToRegIcon from '@mui/icons-material/HowToReg';
+import ButtonGroup from '@mui/material/ButtonGroup';
+import IconButton from '@mui/material/IconButton';
+import Tooltip from '@mui/material/Tooltip';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiGetPendingActions,
+    apiResolvePendingAction,
+    PendingAction,
+    PendingActionState,
+    pendingActionStateString,
+    pendingActionTypeString
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { TableCellLink } from '../component/table/TableCellLink';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+export const AdminApprovalsPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof PendingAction>('pending_action_id');
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+    const [page, setPage] = useState(0);
+    const [actions, setActions] = useState<PendingAction[]>([]);
+    const [count, setCount] = useState(0);
+    const [loading, setLoading] = useState(false);
+    const [updated, setUpdated] = useState(0);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetPendingActions(
+            {
+                desc: sortOrder == 'desc',
+                order_by: sortColumn,
+                offset: page * rowPerPageCount,
+                limit: rowPerPageCount,
+                state: PendingActionState.Any
+            },
+            abortController
+        )
+            .then((resp) => {
+                setActions(resp.data);
+                setCount(resp.count);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [page, rowPerPageCount, sortColumn, sortOrder, updated]);
+
+    const onResolve = useCallback(
+        async (action: PendingAction, approve: boolean) => {
+            try {
+                await apiResolvePendingAction(
+                    action.pending_action_id,
+                    approve
+                );
+                sendFlash(
+                    'success',
+                    `${approve ? 'Approved' : 'Rejected'} action #${
+                        action.pending_action_id
+                    }`
+                );
+            } catch (e) {
+                sendFlash('error', `Failed to update action: ${e}`);
+            } finally {
+                setUpdated((prev) => prev + 1);
+            }
+        },
+        [sendFlash]
+    );
+
+    return (
+        <Grid container spacing={3}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Pending Approvals'}
+                    iconLeft={loading ? <LoadingIcon /> : <HowToRegIcon />}
+                >
+                    <LazyTable<PendingAction>
+                        rows={actions}
+                        showPager
+                        page={page}
+                        rowsPerPage={rowPerPageCount}
+                        count={count}
+                        sortOrder={sortOrder}
+                        sortColumn={sortColumn}
+                        onSortColumnChanged={async (column) => {
+                            setSortColumn(column);
+                        }}
+                        onSortOrderChanged={async (direction) => {
+                            setSortOrder(direction);
+                        }}
+                        onRowsPerPageChange={(
+                            event: React.ChangeEvent<
+                                HTMLInputElement | HTMLTextAreaElement
+                            >
+                        ) => {
+                            setRowPerPageCount(
+                                parseInt(event.target.value, 10)
+                            );
+                            setPage(0);
+                        }}
+                        onPageChange={(_, newPage) => {
+                            setPage(newPage);
+                        }}
+                        columns={[
+                            {
+                                label: '#',
+                                tooltip: 'Action ID',
+                                sortKey: 'pending_action_id',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        #{row.pending_action_id}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Type',
+                                tooltip: 'Action Type',
+                                sortKey: 'action_type',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {pendingActionTypeString(
+                                            row.action_type
+                                        )}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'State',
+                                tooltip: 'State',
+                                sortKey: 'state',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {pendingActionStateString(row.state)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Author',
+                                tooltip: 'Author',
+                                sortKey: 'source_id',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <TableCellLink
+                                        label={row.source_id}
+                                        to={`/profile/${row.source_id}`}
+                                    />
+                                )
+                            },
+                            {
+                                label: 'Target',
+                                tooltip: 'Target',
+                                sortKey: 'target_id',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) =>
+                                    row.target_id != '0' ? (
+                                        <TableCellLink
+                                            label={row.target_id}
+                                            to={`/profile/${row.target_id}`}
+                                        />
+                                    ) : (
+                                        <></>
+                                    )
+                            },
+                            {
+                                label: 'Reason',
+                                tooltip: 'Reason',
+                                sortKey: 'reason',
+                                sortable: false,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Expires',
+                                tooltip: 'Expires On',
+                                sortKey: 'expires_on',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {renderDateTime(row.expires_on)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Act',
+                                tooltip: 'Approve or reject',
+                                sortable: false,
+                                align: 'right',
+                                renderer: (row) =>
+                                    row.state == PendingActionState.Open ? (
+                                        <ButtonGroup>
+                                            <Tooltip title={'Approve'}>
+                                                <IconButton
+                                                    color={'success'}
+                                                    onClick={async () =>
+                                                        await onResolve(
+                                                            row,
+                                                            true
+                                                        )
+                                                    }
+                                                >
+                                                    <CheckIcon />
+                                                </IconButton>
+                                            </Tooltip>
+                                            <Tooltip title={'Reject'}>
+                                                <IconButton
+                                                    color={'error'}
+                                                    onClick={async () =>
+                                                        await onResolve(
+                                                            row,
+                                                            false
+                                                        )
+                                                    }
+                                                >
+                                                    <CloseIcon />
+                                                </IconButton>
+                                            </Tooltip>
+                                        </ButtonGroup>
+                                    ) : (
+                                        <></>
+                                    )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
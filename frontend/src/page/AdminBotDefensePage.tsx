@@ -0,0 +1,291 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import DeleteIcon from '@mui/icons-material/Delete';
+import ImportExportIcon from '@mui/icons-material/ImportExport';
+import LibraryAddIcon from '@mui/icons-material/LibraryAdd';
+import SmartToyIcon from '@mui/icons-material/SmartToy';
+import SummarizeIcon from '@mui/icons-material/Summarize';
+import Button from '@mui/material/Button';
+import IconButton from '@mui/material/IconButton';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiCreateBotNamePattern,
+    apiDeleteBotNamePattern,
+    apiGetBotNamePatterns,
+    apiGetBotReport,
+    apiImportBotSteamIDs,
+    BotNamePattern,
+    botDetectionMethodString,
+    BotReportEntry
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+const BotNamePatterns = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [patterns, setPatterns] = useState<BotNamePattern[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [pattern, setPattern] = useState('');
+    const [note, setNote] = useState('');
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetBotNamePatterns(abortController)
+            .then(setPatterns)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    const onCreate = useCallback(async () => {
+        try {
+            const created = await apiCreateBotNamePattern(pattern, note);
+            setPatterns((prev) => [...prev, created]);
+            setPattern('');
+            setNote('');
+            sendFlash('success', 'Pattern created successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to create pattern: ${e}`);
+        }
+    }, [note, pattern, sendFlash]);
+
+    const onDelete = useCallback(
+        async (botNamePatternID: number) => {
+            try {
+                await apiDeleteBotNamePattern(botNamePatternID);
+                setPatterns((prev) =>
+                    prev.filter(
+                        (p) => p.bot_name_pattern_id != botNamePatternID
+                    )
+                );
+            } catch (e) {
+                sendFlash('error', `Failed to delete pattern: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    return (
+        <ContainerWithHeader
+            title={'Name Patterns'}
+            iconLeft={loading ? <LoadingIcon /> : <SmartToyIcon />}
+        >
+            <Stack spacing={1}>
+                <Stack direction={'row'} spacing={1}>
+                    <TextField
+                        fullWidth
+                        label={'Regex Pattern'}
+                        value={pattern}
+                        onChange={(evt) => setPattern(evt.target.value)}
+                    />
+                    <TextField
+                        fullWidth
+                        label={'Note'}
+                        value={note}
+                        onChange={(evt) => setNote(evt.target.value)}
+                    />
+                    <Button
+                        startIcon={<LibraryAddIcon />}
+                        variant={'contained'}
+                        color={'success'}
+                        disabled={pattern == ''}
+                        onClick={onCreate}
+                    >
+                        Add
+                    </Button>
+                </Stack>
+                <LazyTable<BotNamePattern>
+                    rows={patterns}
+                    sortOrder={'asc'}
+                    sortColumn={'bot_name_pattern_id'}
+                    onSortColumnChanged={() => {}}
+                    onSortOrderChanged={() => {}}
+                    columns={[
+                        {
+                            label: 'Pattern',
+                            tooltip: 'Pattern',
+                            sortKey: 'pattern',
+                            align: 'left',
+                            renderer: (row) => (
+                                <Typography
+                                    variant={'body1'}
+                                    fontFamily={'monospace'}
+                                >
+                                    {row.pattern}
+                                </Typography>
+                            )
+                        },
+                        {
+                            label: 'Note',
+                            tooltip: 'Note',
+                            sortKey: 'note',
+                            align: 'left'
+                        },
+                        {
+                            label: 'Created',
+                            tooltip: 'Created On',
+                            sortKey: 'created_on',
+                            align: 'left',
+                            renderer: (row) => (
+                                <Typography variant={'body1'}>
+                                    {renderDateTime(row.created_on)}
+                                </Typography>
+                            )
+                        },
+                        {
+                            label: '',
+                            tooltip: 'Delete',
+                            sortKey: 'bot_name_pattern_id',
+                            align: 'right',
+                            renderer: (row) => (
+                                <IconButton
+                                    color={'error'}
+                                    onClick={async () => {
+                                        await onDelete(row.bot_name_pattern_id);
+                                    }}
+                                >
+                                    <DeleteIcon />
+                                </IconButton>
+                            )
+                        }
+                    ]}
+                />
+            </Stack>
+        </ContainerWithHeader>
+    );
+};
+
+const BotSteamIDImport = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [steamIDs, setSteamIDs] = useState('');
+    const [source, setSource] = useState('');
+
+    const onImport = useCallback(async () => {
+        try {
+            const resp = await apiImportBotSteamIDs(steamIDs, source);
+            setSteamIDs('');
+            sendFlash('success', `Imported ${resp.count} steam ids`);
+        } catch (e) {
+            sendFlash('error', `Failed to import steam ids: ${e}`);
+        }
+    }, [sendFlash, source, steamIDs]);
+
+    return (
+        <ContainerWithHeader
+            title={'Import Bot SteamIDs'}
+            iconLeft={<ImportExportIcon />}
+        >
+            <Stack spacing={1}>
+                <TextField
+                    fullWidth
+                    multiline
+                    minRows={6}
+                    label={'SteamIDs'}
+                    helperText={'Steam ids in any format, one per line'}
+                    value={steamIDs}
+                    onChange={(evt) => setSteamIDs(evt.target.value)}
+                />
+                <TextField
+                    fullWidth
+                    label={'Source'}
+                    value={source}
+                    onChange={(evt) => setSource(evt.target.value)}
+                />
+                <Button
+                    variant={'contained'}
+                    color={'success'}
+                    disabled={steamIDs == ''}
+                    onClick={onImport}
+                >
+                    Import
+                </Button>
+            </Stack>
+        </ContainerWithHeader>
+    );
+};
+
+const BotReport = () => {
+    const [entries, setEntries] = useState<BotReportEntry[]>([]);
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetBotReport(abortController)
+            .then(setEntries)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    return (
+        <ContainerWithHeader
+            title={'Blocked Bots (Past 7 Days)'}
+            iconLeft={loading ? <LoadingIcon /> : <SummarizeIcon />}
+        >
+            <LazyTable<BotReportEntry>
+                rows={entries}
+                sortOrder={'asc'}
+                sortColumn={'server_name'}
+                onSortColumnChanged={() => {}}
+                onSortOrderChanged={() => {}}
+                columns={[
+                    {
+                        label: 'Server',
+                        tooltip: 'Server',
+                        sortKey: 'server_name',
+                        align: 'left'
+                    },
+                    {
+                        label: 'Method',
+                        tooltip: 'Detection Method',
+                        sortKey: 'method',
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography variant={'body1'}>
+                                {botDetectionMethodString(row.method)}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'Blocked',
+                        tooltip: 'Total connections blocked',
+                        sortKey: 'detections',
+                        align: 'left'
+                    },
+                    {
+                        label: 'Accounts',
+                        tooltip: 'Unique accounts blocked',
+                        sortKey: 'accounts',
+                        align: 'left'
+                    }
+                ]}
+            />
+        </ContainerWithHeader>
+    );
+};
+
+export const AdminBotDefensePage = () => {
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={8}>
+                <Stack spacing={2}>
+                    <BotNamePatterns />
+                    <BotReport />
+                </Stack>
+            </Grid>
+            <Grid xs={4}>
+                <BotSteamIDImport />
+            </Grid>
+        </Grid>
+    );
+};
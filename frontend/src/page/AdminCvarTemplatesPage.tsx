@@ -0,0 +1,498 @@
+import React, { useCallback, useEffect, useMemo, useState } from 'react';
+import DeleteIcon from '@mui/icons-material/Delete';
+import DifferenceIcon from '@mui/icons-material/Difference';
+import DnsIcon from '@mui/icons-material/Dns';
+import EditIcon from '@mui/icons-material/Edit';
+import PublishIcon from '@mui/icons-material/Publish';
+import RefreshIcon from '@mui/icons-material/Refresh';
+import TuneIcon from '@mui/icons-material/Tune';
+import Button from '@mui/material/Button';
+import IconButton from '@mui/material/IconButton';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Tooltip from '@mui/material/Tooltip';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiApplyServerCvarTemplate,
+    apiCheckCvarDrift,
+    apiDeleteCvarTemplate,
+    apiGetCvarDrift,
+    apiGetCvarTemplates,
+    apiGetServerStates,
+    apiSaveCvarTemplate,
+    apiSetServerCvarTemplate,
+    BaseServer,
+    CvarDrift,
+    CvarTemplate
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+// Values are edited as one "cvar value" pair per line
+const encodeValues = (values: Record<string, string>) =>
+    Object.keys(values)
+        .sort()
+        .map((cvar) => `${cvar} ${values[cvar]}`)
+        .join('\n');
+
+const decodeValues = (text: string) => {
+    const values: Record<string, string> = {};
+    text.split('\n')
+        .map((line) => line.trim())
+        .filter((line) => line != '')
+        .forEach((line) => {
+            const idx = line.search(/\s/);
+            const cvar = idx < 0 ? line : line.slice(0, idx);
+            const value = idx < 0 ? '' : line.slice(idx).trim();
+            values[cvar] = value.replace(/^"(.*)"$/, '$1');
+        });
+    return values;
+};
+
+export const AdminCvarTemplatesPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [templates, setTemplates] = useState<CvarTemplate[]>([]);
+    const [assigned, setAssigned] = useState<Record<number, number>>({});
+    const [servers, setServers] = useState<BaseServer[]>([]);
+    const [drift, setDrift] = useState<CvarDrift[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [checking, setChecking] = useState(false);
+    const [editID, setEditID] = useState<number>();
+    const [name, setName] = useState('');
+    const [note, setNote] = useState('');
+    const [values, setValues] = useState('');
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerStates(abortController)
+            .then((resp) => setServers(resp.servers))
+            .catch(logErr);
+        apiGetCvarDrift(abortController).then(setDrift).catch(logErr);
+        apiGetCvarTemplates(abortController)
+            .then((resp) => {
+                setTemplates(resp.templates);
+                setAssigned(resp.servers);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    const driftCount = useMemo(() => {
+        const counts: Record<number, number> = {};
+        drift.forEach((d) => {
+            counts[d.server_id] = (counts[d.server_id] ?? 0) + 1;
+        });
+        return counts;
+    }, [drift]);
+
+    const resetForm = useCallback(() => {
+        setEditID(undefined);
+        setName('');
+        setNote('');
+        setValues('');
+    }, []);
+
+    const onEdit = useCallback((template: CvarTemplate) => {
+        setEditID(template.cvar_template_id);
+        setName(template.name);
+        setNote(template.note);
+        setValues(encodeValues(template.values));
+    }, []);
+
+    const onSave = useCallback(async () => {
+        try {
+            const template = await apiSaveCvarTemplate(
+                { name, note, values: decodeValues(values) },
+                editID
+            );
+            setTemplates((prev) =>
+                [
+                    ...prev.filter(
+                        (t) => t.cvar_template_id != template.cvar_template_id
+                    ),
+                    template
+                ].sort((a, b) => a.name.localeCompare(b.name))
+            );
+            resetForm();
+            sendFlash('success', 'Template saved successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to save template: ${e}`);
+        }
+    }, [editID, name, note, resetForm, sendFlash, values]);
+
+    const onDelete = useCallback(
+        async (template: CvarTemplate) => {
+            try {
+                await apiDeleteCvarTemplate(template.cvar_template_id);
+                setTemplates((prev) =>
+                    prev.filter(
+                        (t) => t.cvar_template_id != template.cvar_template_id
+                    )
+                );
+                setAssigned((prev) =>
+                    Object.fromEntries(
+                        Object.entries(prev).filter(
+                            ([, id]) => id != template.cvar_template_id
+                        )
+                    )
+                );
+                sendFlash('success', 'Template deleted successfully');
+            } catch (e) {
+                sendFlash('error', `Failed to delete template: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    const onAssign = useCallback(
+        async (server_id: number, cvar_template_id: number) => {
+            try {
+                await apiSetServerCvarTemplate(server_id, cvar_template_id);
+                setAssigned((prev) => {
+                    const next = { ...prev };
+                    if (cvar_template_id > 0) {
+                        next[server_id] = cvar_template_id;
+                    } else {
+                        delete next[server_id];
+                    }
+                    return next;
+                });
+                if (cvar_template_id <= 0) {
+                    setDrift((prev) =>
+                        prev.filter((d) => d.server_id != server_id)
+                    );
+                }
+            } catch (e) {
+                sendFlash('error', `Failed to assign template: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    const onApply = useCallback(
+        async (server: BaseServer) => {
+            try {
+                const remaining = await apiApplyServerCvarTemplate(
+                    server.server_id
+                );
+                setDrift((prev) => [
+                    ...prev.filter((d) => d.server_id != server.server_id),
+                    ...remaining
+                ]);
+                sendFlash(
+                    remaining.length > 0 ? 'warning' : 'success',
+                    remaining.length > 0
+                        ? `Template applied, ${remaining.length} cvars still differ`
+                        : 'Template applied successfully'
+                );
+            } catch (e) {
+                sendFlash('error', `Failed to apply template: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    const onCheck = useCallback(async () => {
+        setChecking(true);
+        try {
+            setDrift(await apiCheckCvarDrift());
+        } catch (e) {
+            sendFlash('error', `Failed to check servers: ${e}`);
+        } finally {
+            setChecking(false);
+        }
+    }, [sendFlash]);
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Cvar Templates'}
+                    iconLeft={loading ? <LoadingIcon /> : <TuneIcon />}
+                >
+                    <LazyTable<CvarTemplate>
+                        rows={templates}
+                        sortOrder={'asc'}
+                        sortColumn={'name'}
+                        onSortColumnChanged={() => {}}
+                        onSortOrderChanged={() => {}}
+                        columns={[
+                            {
+                                label: 'Name',
+                                tooltip: 'Name',
+                                sortKey: 'name',
+                                align: 'left'
+                            },
+                            {
+                                label: 'Note',
+                                tooltip: 'Note',
+                                sortKey: 'note',
+                                align: 'left'
+                            },
+                            {
+                                label: 'Cvars',
+                                tooltip: 'Cvars defined by the template',
+                                sortKey: 'values',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Tooltip
+                                        title={
+                                            <pre>
+                                                {encodeValues(row.values)}
+                                            </pre>
+                                        }
+                                    >
+                                        <Typography variant={'body1'}>
+                                            {Object.keys(row.values).length}
+                                        </Typography>
+                                    </Tooltip>
+                                )
+                            },
+                            {
+                                label: 'Actions',
+                                tooltip: 'Actions',
+                                virtual: true,
+                                virtualKey: 'actions',
+                                align: 'right',
+                                renderer: (row) => (
+                                    <Stack direction={'row'}>
+                                        <IconButton
+                                            color={'warning'}
+                                            onClick={() => onEdit(row)}
+                                        >
+                                            <EditIcon />
+                                        </IconButton>
+                                        <IconButton
+                                            color={'error'}
+                                            onClick={() => onDelete(row)}
+                                        >
+                                            <DeleteIcon />
+                                        </IconButton>
+                                    </Stack>
+                                )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={editID ? 'Edit Template' : 'Create Template'}
+                    iconLeft={<EditIcon />}
+                >
+                    <Stack spacing={2}>
+                        <Stack direction={'row'} spacing={1}>
+                            <TextField
+                                fullWidth
+                                label={'Name'}
+                                value={name}
+                                onChange={(evt) => setName(evt.target.value)}
+                            />
+                            <TextField
+                                fullWidth
+                                label={'Note'}
+                                value={note}
+                                onChange={(evt) => setNote(evt.target.value)}
+                            />
+                        </Stack>
+                        <TextField
+                            fullWidth
+                            multiline
+                            minRows={5}
+                            label={'Values (one "cvar value" per line)'}
+                            value={values}
+                            onChange={(evt) => setValues(evt.target.value)}
+                            InputProps={{
+                                sx: { fontFamily: 'monospace' }
+                            }}
+                        />
+                        <Stack direction={'row'} spacing={1}>
+                            <Button variant={'contained'} onClick={onSave}>
+                                Save
+                            </Button>
+                            {editID && (
+                                <Button
+                                    variant={'contained'}
+                                    color={'warning'}
+                                    onClick={resetForm}
+                                >
+                                    Cancel
+                                </Button>
+                            )}
+                        </Stack>
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader title={'Servers'} iconLeft={<DnsIcon />}>
+                    <LazyTable<BaseServer>
+                        rows={servers}
+                        sortOrder={'asc'}
+                        sortColumn={'name_short'}
+                        onSortColumnChanged={() => {}}
+                        onSortOrderChanged={() => {}}
+                        columns={[
+                            {
+                                label: 'Server',
+                                tooltip: 'Server',
+                                sortKey: 'name_short',
+                                align: 'left'
+                            },
+                            {
+                                label: 'Template',
+                                tooltip: 'Assigned template',
+                                sortKey: 'server_id',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Select
+                                        size={'small'}
+                                        fullWidth
+                                        value={assigned[row.server_id] ?? 0}
+                                        onChange={(evt) =>
+                                            onAssign(
+                                                row.server_id,
+                                                evt.target.value as number
+                                            )
+                                        }
+                                    >
+                                        <MenuItem value={0}>None</MenuItem>
+                                        {templates.map((t) => (
+                                            <MenuItem
+                                                value={t.cvar_template_id}
+                                                key={t.cvar_template_id}
+                                            >
+                                                {t.name}
+                                            </MenuItem>
+                                        ))}
+                                    </Select>
+                                )
+                            },
+                            {
+                                label: 'Drift',
+                                tooltip: 'Cvars which differ from the template',
+                                virtual: true,
+                                virtualKey: 'drift',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography
+                                        variant={'body1'}
+                                        color={
+                                            driftCount[row.server_id]
+                                                ? 'warning.main'
+                                                : undefined
+                                        }
+                                    >
+                                        {driftCount[row.server_id] ?? 0}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Actions',
+                                tooltip: 'Actions',
+                                virtual: true,
+                                virtualKey: 'actions',
+                                align: 'right',
+                                renderer: (row) => (
+                                    <Tooltip title={'Apply template'}>
+                                        <span>
+                                            <IconButton
+                                                color={'success'}
+                                                disabled={
+                                                    !assigned[row.server_id]
+                                                }
+                                                onClick={() => onApply(row)}
+                                            >
+                                                <PublishIcon />
+                                            </IconButton>
+                                        </span>
+                                    </Tooltip>
+                                )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Drift'}
+                    iconLeft={checking ? <LoadingIcon /> : <DifferenceIcon />}
+                >
+                    <Stack spacing={1}>
+                        <Stack direction={'row'}>
+                            <Button
+                                variant={'contained'}
+                                startIcon={<RefreshIcon />}
+                                disabled={checking}
+                                onClick={onCheck}
+                            >
+                                Check Now
+                            </Button>
+                        </Stack>
+                        <LazyTable<CvarDrift>
+                            rows={drift}
+                            sortOrder={'asc'}
+                            sortColumn={'server_name'}
+                            onSortColumnChanged={() => {}}
+                            onSortOrderChanged={() => {}}
+                            columns={[
+                                {
+                                    label: 'Server',
+                                    tooltip: 'Server',
+                                    sortKey: 'server_name',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Cvar',
+                                    tooltip: 'Cvar',
+                                    sortKey: 'cvar',
+                                    align: 'left',
+                                    renderer: (row) => (
+                                        <Typography
+                                            variant={'body1'}
+                                            fontFamily={'monospace'}
+                                        >
+                                            {row.cvar}
+                                        </Typography>
+                                    )
+                                },
+                                {
+                                    label: 'Expected',
+                                    tooltip: 'Template value',
+                                    sortKey: 'expected',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Actual',
+                                    tooltip: 'Value on the server',
+                                    sortKey: 'actual',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Checked',
+                                    tooltip: 'Time of the last check',
+                                    sortKey: 'checked_on',
+                                    align: 'left',
+                                    renderer: (row) => (
+                                        <Typography variant={'body1'}>
+                                            {renderDateTime(row.checked_on)}
+                                        </Typography>
+                                    )
+                                }
+                            ]}
+                        />
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
@@ -0,0 +1,491 @@
+import React, { useCallback, useEffect, useMemo, useState } from 'react';
+import CasinoIcon from '@mui/icons-material/Casino';
+import HistoryIcon from '@mui/icons-material/History';
+import MapIcon from '@mui/icons-material/Map';
+import Button from '@mui/material/Button';
+import Checkbox from '@mui/material/Checkbox';
+import FormControl from '@mui/material/FormControl';
+import FormControlLabel from '@mui/material/FormControlLabel';
+import InputLabel from '@mui/material/InputLabel';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiDeleteMapRotation,
+    apiGetMapRotationCandidates,
+    apiGetMapRotationChanges,
+    apiGetMapRotations,
+    apiGetServerStates,
+    apiRotateMap,
+    apiSaveMapRotation,
+    BaseServer,
+    MapCandidate,
+    MapRotation,
+    MapRotationChange,
+    MapRotationEntry
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+// Maps are edited as one "map_name weight min_players max_players" entry per line
+const encodeMaps = (maps: MapRotationEntry[]) =>
+    maps
+        .map(
+            (m) => `${m.map_name} ${m.weight} ${m.min_players} ${m.max_players}`
+        )
+        .join('\n');
+
+const decodeMaps = (text: string): MapRotationEntry[] =>
+    text
+        .split('\n')
+        .map((line) => line.trim().split(/\s+/))
+        .filter((fields) => fields[0] != '')
+        .map((fields) => ({
+            map_name: fields[0],
+            weight: fields.length > 1 ? parseInt(fields[1]) || 0 : 1,
+            min_players: parseInt(fields[2]) || 0,
+            max_players: parseInt(fields[3]) || 0
+        }));
+
+const MapRotationChanges = ({
+    serverID,
+    updated
+}: {
+    serverID: number;
+    updated: number;
+}) => {
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof MapRotationChange>('created_on');
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+    const [page, setPage] = useState(0);
+    const [rows, setRows] = useState<MapRotationChange[]>([]);
+    const [count, setCount] = useState(0);
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetMapRotationChanges(
+            {
+                server_id: serverID,
+                desc: sortOrder == 'desc',
+                order_by: sortColumn,
+                offset: page * rowPerPageCount,
+                limit: rowPerPageCount
+            },
+            abortController
+        )
+            .then((resp) => {
+                setRows(resp.data);
+                setCount(resp.count);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [page, rowPerPageCount, serverID, sortColumn, sortOrder, updated]);
+
+    return (
+        <ContainerWithHeader
+            title={'Rotation History'}
+            iconLeft={loading ? <LoadingIcon /> : <HistoryIcon />}
+        >
+            <LazyTable<MapRotationChange>
+                rows={rows}
+                showPager
+                page={page}
+                rowsPerPage={rowPerPageCount}
+                count={count}
+                sortOrder={sortOrder}
+                sortColumn={sortColumn}
+                onSortColumnChanged={async (column) => {
+                    setSortColumn(column);
+                }}
+                onSortOrderChanged={async (direction) => {
+                    setSortOrder(direction);
+                }}
+                onRowsPerPageChange={(
+                    event: React.ChangeEvent<
+                        HTMLInputElement | HTMLTextAreaElement
+                    >
+                ) => {
+                    setRowPerPageCount(parseInt(event.target.value, 10));
+                    setPage(0);
+                }}
+                onPageChange={(_, newPage) => {
+                    setPage(newPage);
+                }}
+                columns={[
+                    {
+                        label: 'Time',
+                        tooltip: 'Time the next map was set',
+                        sortKey: 'created_on',
+                        sortable: true,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography variant={'body1'}>
+                                {renderDateTime(row.created_on)}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'Server',
+                        tooltip: 'Server',
+                        sortKey: 'server_name',
+                        sortable: false,
+                        align: 'left'
+                    },
+                    {
+                        label: 'Previous',
+                        tooltip: 'Map being played',
+                        sortKey: 'previous_map',
+                        sortable: true,
+                        align: 'left'
+                    },
+                    {
+                        label: 'Next',
+                        tooltip: 'Map selected',
+                        sortKey: 'map_name',
+                        sortable: true,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography
+                                variant={'body1'}
+                                color={row.error ? 'error' : undefined}
+                            >
+                                {row.error
+                                    ? `${row.map_name} (${row.error})`
+                                    : row.map_name}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'Players',
+                        tooltip: 'Players connected at the time',
+                        sortKey: 'players',
+                        sortable: true,
+                        align: 'left'
+                    }
+                ]}
+            />
+        </ContainerWithHeader>
+    );
+};
+
+export const AdminMapRotationPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [servers, setServers] = useState<BaseServer[]>([]);
+    const [rotations, setRotations] = useState<MapRotation[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [serverID, setServerID] = useState(0);
+    const [enabled, setEnabled] = useState(false);
+    const [excludeRecent, setExcludeRecent] = useState(3);
+    const [useStats, setUseStats] = useState(false);
+    const [maps, setMaps] = useState('');
+    const [players, setPlayers] = useState<number>();
+    const [candidates, setCandidates] = useState<MapCandidate[]>([]);
+    const [updated, setUpdated] = useState(0);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerStates(abortController)
+            .then((resp) => setServers(resp.servers))
+            .catch(logErr);
+        apiGetMapRotations(abortController)
+            .then(setRotations)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    const rotation = useMemo(
+        () => rotations.find((r) => r.server_id == serverID),
+        [rotations, serverID]
+    );
+
+    useEffect(() => {
+        setEnabled(rotation?.enabled ?? false);
+        setExcludeRecent(rotation?.exclude_recent ?? 3);
+        setUseStats(rotation?.use_stats ?? false);
+        setMaps(encodeMaps(rotation?.maps ?? []));
+    }, [rotation]);
+
+    useEffect(() => {
+        if (!rotation) {
+            setCandidates([]);
+            return;
+        }
+        const abortController = new AbortController();
+        apiGetMapRotationCandidates(
+            rotation.server_id,
+            players,
+            abortController
+        )
+            .then(setCandidates)
+            .catch(logErr);
+
+        return () => abortController.abort();
+    }, [players, rotation]);
+
+    const totalWeight = useMemo(
+        () => candidates.reduce((total, c) => total + c.weight, 0),
+        [candidates]
+    );
+
+    const onSave = useCallback(async () => {
+        try {
+            const saved = await apiSaveMapRotation(serverID, {
+                enabled,
+                exclude_recent: excludeRecent,
+                use_stats: useStats,
+                maps: decodeMaps(maps)
+            });
+            setRotations((prev) => [
+                ...prev.filter((r) => r.server_id != saved.server_id),
+                saved
+            ]);
+            sendFlash('success', 'Rotation saved successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to save rotation: ${e}`);
+        }
+    }, [enabled, excludeRecent, maps, sendFlash, serverID, useStats]);
+
+    const onDelete = useCallback(async () => {
+        try {
+            await apiDeleteMapRotation(serverID);
+            setRotations((prev) =>
+                prev.filter((r) => r.server_id != serverID)
+            );
+            sendFlash('success', 'Rotation deleted successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to delete rotation: ${e}`);
+        }
+    }, [sendFlash, serverID]);
+
+    const onRotate = useCallback(async () => {
+        try {
+            const change = await apiRotateMap(serverID);
+            if (change.error) {
+                sendFlash('error', `Failed to set next map: ${change.error}`);
+            } else {
+                sendFlash('success', `Next map set to ${change.map_name}`);
+            }
+        } catch (e) {
+            sendFlash('error', `Failed to rotate map: ${e}`);
+        } finally {
+            setUpdated((prev) => prev + 1);
+        }
+    }, [sendFlash, serverID]);
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Map Rotation'}
+                    iconLeft={loading ? <LoadingIcon /> : <MapIcon />}
+                >
+                    <Stack spacing={2}>
+                        <FormControl fullWidth>
+                            <InputLabel id="map-rotation-server-label">
+                                Server
+                            </InputLabel>
+                            <Select
+                                labelId="map-rotation-server-label"
+                                label={'Server'}
+                                value={serverID}
+                                onChange={(evt) =>
+                                    setServerID(evt.target.value as number)
+                                }
+                            >
+                                <MenuItem value={0}>Select a server</MenuItem>
+                                {servers.map((server) => (
+                                    <MenuItem
+                                        value={server.server_id}
+                                        key={server.server_id}
+                                    >
+                                        {server.name_short}
+                                        {rotations.find(
+                                            (r) =>
+                                                r.server_id ==
+                                                    server.server_id &&
+                                                r.enabled
+                                        )
+                                            ? ' (enabled)'
+                                            : ''}
+                                    </MenuItem>
+                                ))}
+                            </Select>
+                        </FormControl>
+                        {serverID > 0 && (
+                            <>
+                                <Stack direction={'row'} spacing={1}>
+                                    <FormControlLabel
+                                        label={'Enabled'}
+                                        control={
+                                            <Checkbox
+                                                checked={enabled}
+                                                onChange={(_, checked) =>
+                                                    setEnabled(checked)
+                                                }
+                                            />
+                                        }
+                                    />
+                                    <FormControlLabel
+                                        label={'Weight by playtime stats'}
+                                        control={
+                                            <Checkbox
+                                                checked={useStats}
+                                                onChange={(_, checked) =>
+                                                    setUseStats(checked)
+                                                }
+                                            />
+                                        }
+                                    />
+                                    <TextField
+                                        type={'number'}
+                                        label={'Exclude recent maps'}
+                                        helperText={
+                                            'Number of previous maps which cannot be selected'
+                                        }
+                                        value={excludeRecent}
+                                        onChange={(evt) =>
+                                            setExcludeRecent(
+                                                parseInt(evt.target.value) || 0
+                                            )
+                                        }
+                                    />
+                                </Stack>
+                                <TextField
+                                    fullWidth
+                                    multiline
+                                    minRows={6}
+                                    label={
+                                        'Maps (one "map weight min_players max_players" per line)'
+                                    }
+                                    helperText={
+                                        'A max_players of 0 is unlimited. The default maps are used when empty.'
+                                    }
+                                    value={maps}
+                                    onChange={(evt) => setMaps(evt.target.value)}
+                                    InputProps={{
+                                        sx: { fontFamily: 'monospace' }
+                                    }}
+                                />
+                                <Stack direction={'row'} spacing={1}>
+                                    <Button
+                                        variant={'contained'}
+                                        onClick={onSave}
+                                    >
+                                        Save
+                                    </Button>
+                                    <Button
+                                        variant={'contained'}
+                                        color={'success'}
+                                        disabled={!rotation}
+                                        onClick={onRotate}
+                                    >
+                                        Set Next Map Now
+                                    </Button>
+                                    <Button
+                                        variant={'contained'}
+                                        color={'error'}
+                                        disabled={!rotation}
+                                        onClick={onDelete}
+                                    >
+                                        Delete
+                                    </Button>
+                                </Stack>
+                            </>
+                        )}
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            {rotation && (
+                <Grid xs={12}>
+                    <ContainerWithHeader
+                        title={'Next Map Candidates'}
+                        iconLeft={<CasinoIcon />}
+                    >
+                        <Stack spacing={1}>
+                            <TextField
+                                type={'number'}
+                                label={'Player count'}
+                                helperText={
+                                    'Leave empty to use the current player count'
+                                }
+                                value={players ?? ''}
+                                onChange={(evt) =>
+                                    setPlayers(
+                                        evt.target.value == ''
+                                            ? undefined
+                                            : parseInt(evt.target.value) || 0
+                                    )
+                                }
+                            />
+                            <LazyTable<MapCandidate>
+                                rows={candidates}
+                                sortOrder={'desc'}
+                                sortColumn={'weight'}
+                                onSortColumnChanged={() => {}}
+                                onSortOrderChanged={() => {}}
+                                columns={[
+                                    {
+                                        label: 'Map',
+                                        tooltip: 'Map',
+                                        sortKey: 'map_name',
+                                        align: 'left'
+                                    },
+                                    {
+                                        label: 'Weight',
+                                        tooltip:
+                                            'Effective weight including stats',
+                                        sortKey: 'weight',
+                                        align: 'left',
+                                        renderer: (row) => (
+                                            <Typography variant={'body1'}>
+                                                {row.weight.toFixed(2)}
+                                            </Typography>
+                                        )
+                                    },
+                                    {
+                                        label: 'Chance',
+                                        tooltip: 'Chance of being selected',
+                                        virtual: true,
+                                        virtualKey: 'chance',
+                                        align: 'left',
+                                        renderer: (row) => (
+                                            <Typography variant={'body1'}>
+                                                {totalWeight > 0
+                                                    ? `${(
+                                                          (row.weight /
+                                                              totalWeight) *
+                                                          100
+                                                      ).toFixed(1)}%`
+                                                    : ''}
+                                            </Typography>
+                                        )
+                                    }
+                                ]}
+                            />
+                        </Stack>
+                    </ContainerWithHeader>
+                </Grid>
+            )}
+            <Grid xs={12}>
+                <MapRotationChanges serverID={serverID} updated={updated} />
+            </Grid>
+        </Grid>
+    );
+};
@@ -0,0 +1,285 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import DeleteIcon from '@mui/icons-material/Delete';
+import HistoryIcon from '@mui/icons-material/History';
+import LinkIcon from '@mui/icons-material/Link';
+import Button from '@mui/material/Button';
+import IconButton from '@mui/material/IconButton';
+import Stack from '@mui/material/Stack';
+import TablePagination from '@mui/material/TablePagination';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiDeletePatreonLink,
+    apiGetPatreonLinks,
+    apiGetPatreonPerkLogs,
+    apiSavePatreonLink,
+    PatreonLink,
+    PatreonPerkLog
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+export const AdminPatreonPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [links, setLinks] = useState<PatreonLink[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [patreonID, setPatreonID] = useState('');
+    const [steamID, setSteamID] = useState('');
+    const [logs, setLogs] = useState<PatreonPerkLog[]>([]);
+    const [logsCount, setLogsCount] = useState(0);
+    const [logsLoading, setLogsLoading] = useState(false);
+    const [logsSteamID, setLogsSteamID] = useState('');
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof PatreonPerkLog>('patreon_perk_log_id');
+    const [page, setPage] = useState(0);
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetPatreonLinks(abortController)
+            .then(setLinks)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLogsLoading(true);
+        apiGetPatreonPerkLogs(
+            {
+                steam_id: logsSteamID,
+                limit: rowPerPageCount,
+                offset: page * rowPerPageCount,
+                order_by: sortColumn,
+                desc: sortOrder == 'desc'
+            },
+            abortController
+        )
+            .then((resp) => {
+                setLogs(resp.data);
+                setLogsCount(resp.count);
+            })
+            .catch(logErr)
+            .finally(() => setLogsLoading(false));
+
+        return () => abortController.abort();
+    }, [logsSteamID, page, rowPerPageCount, sortColumn, sortOrder]);
+
+    const onSave = useCallback(async () => {
+        try {
+            const link = await apiSavePatreonLink(patreonID, steamID);
+            setLinks((prev) => [
+                ...prev.filter((l) => l.patreon_id != link.patreon_id),
+                link
+            ]);
+            setPatreonID('');
+            setSteamID('');
+            sendFlash('success', 'Patreon user linked successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to link patreon user: ${e}`);
+        }
+    }, [patreonID, sendFlash, steamID]);
+
+    const onDelete = useCallback(
+        async (link: PatreonLink) => {
+            try {
+                await apiDeletePatreonLink(link.patreon_id);
+                setLinks((prev) =>
+                    prev.filter((l) => l.patreon_id != link.patreon_id)
+                );
+                sendFlash('success', 'Patreon link removed successfully');
+            } catch (e) {
+                sendFlash('error', `Failed to remove patreon link: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Patreon Links'}
+                    iconLeft={loading ? <LoadingIcon /> : <LinkIcon />}
+                >
+                    <Stack spacing={2}>
+                        <LazyTable<PatreonLink>
+                            rows={links}
+                            sortOrder={'asc'}
+                            sortColumn={'created_on'}
+                            onSortColumnChanged={() => {}}
+                            onSortOrderChanged={() => {}}
+                            columns={[
+                                {
+                                    label: 'Patreon ID',
+                                    tooltip: 'Patreon user id',
+                                    sortKey: 'patreon_id',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Steam ID',
+                                    tooltip: 'Linked steam account',
+                                    sortKey: 'steam_id',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Linked',
+                                    tooltip: 'When the accounts were linked',
+                                    sortKey: 'created_on',
+                                    align: 'left',
+                                    renderer: (row) => (
+                                        <Typography variant={'body1'}>
+                                            {renderDateTime(row.created_on)}
+                                        </Typography>
+                                    )
+                                },
+                                {
+                                    label: 'Actions',
+                                    tooltip: 'Actions',
+                                    virtual: true,
+                                    virtualKey: 'actions',
+                                    align: 'right',
+                                    renderer: (row) => (
+                                        <IconButton
+                                            color={'error'}
+                                            onClick={() => onDelete(row)}
+                                        >
+                                            <DeleteIcon />
+                                        </IconButton>
+                                    )
+                                }
+                            ]}
+                        />
+                        <Stack direction={'row'} spacing={1}>
+                            <TextField
+                                fullWidth
+                                label={'Patreon ID'}
+                                value={patreonID}
+                                onChange={(evt) =>
+                                    setPatreonID(evt.target.value)
+                                }
+                            />
+                            <TextField
+                                fullWidth
+                                label={'Steam ID'}
+                                value={steamID}
+                                onChange={(evt) => setSteamID(evt.target.value)}
+                            />
+                            <Button variant={'contained'} onClick={onSave}>
+                                Link
+                            </Button>
+                        </Stack>
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Perk History'}
+                    iconLeft={logsLoading ? <LoadingIcon /> : <HistoryIcon />}
+                >
+                    <Stack spacing={1}>
+                        <TextField
+                            size={'small'}
+                            label={'Steam ID'}
+                            value={logsSteamID}
+                            onChange={(evt) => {
+                                setLogsSteamID(evt.target.value);
+                                setPage(0);
+                            }}
+                        />
+                        <TablePagination
+                            component="div"
+                            variant={'head'}
+                            page={page}
+                            count={logsCount}
+                            showFirstButton
+                            showLastButton
+                            rowsPerPage={rowPerPageCount}
+                            onRowsPerPageChange={(
+                                event: React.ChangeEvent<
+                                    HTMLInputElement | HTMLTextAreaElement
+                                >
+                            ) => {
+                                setRowPerPageCount(
+                                    parseInt(event.target.value, 10)
+                                );
+                                setPage(0);
+                            }}
+                            onPageChange={(_, newPage) => {
+                                setPage(newPage);
+                            }}
+                        />
+                        <LazyTable<PatreonPerkLog>
+                            rows={logs}
+                            sortOrder={sortOrder}
+                            sortColumn={sortColumn}
+                            onSortColumnChanged={async (column) => {
+                                setSortColumn(column);
+                            }}
+                            onSortOrderChanged={async (direction) => {
+                                setSortOrder(direction);
+                            }}
+                            columns={[
+                                {
+                                    label: 'Date',
+                                    tooltip: 'When the change was applied',
+                                    sortKey: 'created_on',
+                                    sortable: true,
+                                    align: 'left',
+                                    renderer: (row) => (
+                                        <Typography variant={'body1'}>
+                                            {renderDateTime(row.created_on)}
+                                        </Typography>
+                                    )
+                                },
+                                {
+                                    label: 'Steam ID',
+                                    tooltip: 'Patron steam id',
+                                    sortKey: 'steam_id',
+                                    sortable: true,
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Tier',
+                                    tooltip: 'Patreon tier id',
+                                    sortKey: 'tier_id',
+                                    sortable: true,
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Action',
+                                    tooltip: 'Action taken',
+                                    sortKey: 'action',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Perk',
+                                    tooltip: 'Perk changed',
+                                    sortKey: 'perk',
+                                    align: 'left'
+                                },
+                                {
+                                    label: 'Value',
+                                    tooltip: 'Perk value',
+                                    sortKey: 'value',
+                                    align: 'left'
+                                }
+                            ]}
+                        />
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
@@ -0,0 +1,237 @@
+import React, { useEffect, useMemo, useState } from 'react';
+import GroupsIcon from '@mui/icons-material/Groups';
+import InsightsIcon from '@mui/icons-material/Insights';
+import FormControl from '@mui/material/FormControl';
+import InputLabel from '@mui/material/InputLabel';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Grid from '@mui/material/Unstable_Grid2';
+import { LineChart } from '@mui/x-charts';
+import { apiGetPlayerCounts, PlayerCountResult } from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order } from '../component/table/LazyTable';
+import { compare, stableSort } from '../component/table/LazyTableSimple';
+import { logErr } from '../util/errors';
+
+interface SummaryRow {
+    name: string;
+    region: string;
+    samples: number;
+    peak: number;
+    average: number;
+    p50: number;
+    p90: number;
+    p95: number;
+    p99: number;
+}
+
+const ranges: Record<string, number> = {
+    '24 Hours': 1,
+    '7 Days': 7,
+    '30 Days': 30,
+    '90 Days': 90,
+    '1 Year': 365
+};
+
+const dayMs = 24 * 60 * 60 * 1000;
+
+export const AdminPlayerCountsPage = () => {
+    const [days, setDays] = useState(1);
+    const [region, setRegion] = useState('');
+    const [result, setResult] = useState<PlayerCountResult>();
+    const [loading, setLoading] = useState(false);
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] = useState<keyof SummaryRow>('average');
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        const to = new Date();
+        setLoading(true);
+        apiGetPlayerCounts(
+            {
+                region: region,
+                from: new Date(to.getTime() - days * dayMs),
+                to: to
+            },
+            abortController
+        )
+            .then(setResult)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [days, region]);
+
+    const chart = useMemo(() => {
+        const buckets = Array.from(
+            new Set(
+                (result?.servers ?? []).flatMap((s) =>
+                    s.points.map((p) => p.bucket.getTime())
+                )
+            )
+        ).sort((a, b) => a - b);
+
+        return {
+            xAxis: buckets.map((b) => new Date(b)),
+            series: (result?.servers ?? []).map((s) => {
+                const averages = new Map(
+                    s.points.map((p) => [p.bucket.getTime(), p.average])
+                );
+                return {
+                    label: s.short_name,
+                    showMark: false,
+                    connectNulls: false,
+                    data: buckets.map((b) => averages.get(b) ?? null)
+                };
+            })
+        };
+    }, [result]);
+
+    const rows = useMemo(() => {
+        const summaries: SummaryRow[] = (result?.servers ?? []).map((s) => ({
+            name: s.short_name,
+            region: s.region,
+            ...s.summary
+        }));
+        Object.entries(result?.regions ?? {}).forEach(([name, summary]) => {
+            summaries.push({
+                name: `Region: ${name || 'Unknown'}`,
+                region: name,
+                ...summary
+            });
+        });
+        if (result && result.summary.samples > 0) {
+            summaries.push({ name: 'All', region: '', ...result.summary });
+        }
+        return stableSort(summaries, compare(sortOrder, sortColumn));
+    }, [result, sortColumn, sortOrder]);
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={`Player Counts ${
+                        result ? `(${result.resolution})` : ''
+                    }`}
+                    iconLeft={loading ? <LoadingIcon /> : <InsightsIcon />}
+                >
+                    <Stack spacing={2}>
+                        <Stack direction={'row'} spacing={1}>
+                            <FormControl>
+                                <InputLabel id="range-label">Range</InputLabel>
+                                <Select<number>
+                                    labelId="range-label"
+                                    label={'Range'}
+                                    value={days}
+                                    onChange={(evt) =>
+                                        setDays(Number(evt.target.value))
+                                    }
+                                >
+                                    {Object.entries(ranges).map(
+                                        ([label, value]) => (
+                                            <MenuItem key={label} value={value}>
+                                                {label}
+                                            </MenuItem>
+                                        )
+                                    )}
+                                </Select>
+                            </FormControl>
+                            <TextField
+                                label={'Region'}
+                                value={region}
+                                onChange={(evt) => setRegion(evt.target.value)}
+                            />
+                        </Stack>
+                        {chart.xAxis.length > 0 && (
+                            <LineChart
+                                height={400}
+                                xAxis={[{ data: chart.xAxis, scaleType: 'time' }]}
+                                series={chart.series}
+                            />
+                        )}
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Capacity Summary'}
+                    iconLeft={<GroupsIcon />}
+                >
+                    <LazyTable<SummaryRow>
+                        rows={rows}
+                        sortOrder={sortOrder}
+                        sortColumn={sortColumn}
+                        onSortColumnChanged={async (column) => {
+                            setSortColumn(column);
+                        }}
+                        onSortOrderChanged={async (direction) => {
+                            setSortOrder(direction);
+                        }}
+                        columns={[
+                            {
+                                label: 'Name',
+                                tooltip: 'Server or region',
+                                sortKey: 'name',
+                                sortable: true,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Region',
+                                tooltip: 'Region',
+                                sortKey: 'region',
+                                sortable: true,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Peak',
+                                tooltip: 'Highest player count seen',
+                                sortKey: 'peak',
+                                sortable: true,
+                                align: 'right'
+                            },
+                            {
+                                label: 'Average',
+                                tooltip: 'Average player count',
+                                sortKey: 'average',
+                                sortable: true,
+                                align: 'right',
+                                renderer: (row) => row.average.toFixed(1)
+                            },
+                            {
+                                label: 'P50',
+                                tooltip: 'Median player count',
+                                sortKey: 'p50',
+                                sortable: true,
+                                align: 'right'
+                            },
+                            {
+                                label: 'P90',
+                                tooltip: '90th percentile player count',
+                                sortKey: 'p90',
+                                sortable: true,
+                                align: 'right'
+                            },
+                            {
+                                label: 'P95',
+                                tooltip: '95th percentile player count',
+                                sortKey: 'p95',
+                                sortable: true,
+                                align: 'right'
+                            },
+                            {
+                                label: 'P99',
+                                tooltip: '99th percentile player count',
+                                sortKey: 'p99',
+                                sortable: true,
+                                align: 'right'
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
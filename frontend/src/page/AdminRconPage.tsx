@@ -0,0 +1,364 @@
+import React, { useCallback, useEffect, useMemo, useState } from 'react';
+import HistoryIcon from '@mui/icons-material/History';
+import SendIcon from '@mui/icons-material/Send';
+import TerminalIcon from '@mui/icons-material/Terminal';
+import Button from '@mui/material/Button';
+import FormControl from '@mui/material/FormControl';
+import InputLabel from '@mui/material/InputLabel';
+import MenuItem from '@mui/material/MenuItem';
+import Paper from '@mui/material/Paper';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiGetRconAudit,
+    apiGetRconHistory,
+    apiGetServerGroups,
+    apiGetServerStates,
+    apiRconExec,
+    BaseServer,
+    PermissionLevel,
+    RconAudit,
+    RconResult,
+    RconTarget,
+    ServerGroup
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { TableCellLink } from '../component/table/TableCellLink';
+import { useCurrentUserCtx } from '../contexts/CurrentUserCtx';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+// Targets are encoded as strings for use in the select input: all, region:<region>, group:<server_group_id>
+// or server:<server_id>
+const parseTarget = (value: string): RconTarget => {
+    if (value.startsWith('region:')) {
+        return { region: value.slice('region:'.length) };
+    }
+    if (value.startsWith('group:')) {
+        return {
+            server_group_ids: [parseInt(value.slice('group:'.length))]
+        };
+    }
+    if (value.startsWith('server:')) {
+        return { server_ids: [parseInt(value.slice('server:'.length))] };
+    }
+    return { all: true };
+};
+
+const RconAuditLog = ({ updated }: { updated: number }) => {
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof RconAudit>('created_on');
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+    const [page, setPage] = useState(0);
+    const [rows, setRows] = useState<RconAudit[]>([]);
+    const [count, setCount] = useState(0);
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetRconAudit(
+            {
+                desc: sortOrder == 'desc',
+                order_by: sortColumn,
+                offset: page * rowPerPageCount,
+                limit: rowPerPageCount
+            },
+            abortController
+        )
+            .then((resp) => {
+                setRows(resp.data);
+                setCount(resp.count);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [page, rowPerPageCount, sortColumn, sortOrder, updated]);
+
+    return (
+        <ContainerWithHeader
+            title={'Audit Log'}
+            iconLeft={loading ? <LoadingIcon /> : <HistoryIcon />}
+        >
+            <LazyTable<RconAudit>
+                rows={rows}
+                showPager
+                page={page}
+                rowsPerPage={rowPerPageCount}
+                count={count}
+                sortOrder={sortOrder}
+                sortColumn={sortColumn}
+                onSortColumnChanged={async (column) => {
+                    setSortColumn(column);
+                }}
+                onSortOrderChanged={async (direction) => {
+                    setSortOrder(direction);
+                }}
+                onRowsPerPageChange={(
+                    event: React.ChangeEvent<
+                        HTMLInputElement | HTMLTextAreaElement
+                    >
+                ) => {
+                    setRowPerPageCount(parseInt(event.target.value, 10));
+                    setPage(0);
+                }}
+                onPageChange={(_, newPage) => {
+                    setPage(newPage);
+                }}
+                columns={[
+                    {
+                        label: 'Created',
+                        tooltip: 'Created On',
+                        sortKey: 'created_on',
+                        sortable: true,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography variant={'body1'}>
+                                {renderDateTime(row.created_on)}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'Actor',
+                        tooltip: 'Actor',
+                        sortKey: 'steam_id',
+                        sortable: true,
+                        align: 'left',
+                        renderer: (row) => (
+                            <TableCellLink
+                                label={row.personaname || row.steam_id}
+                                to={`/profile/${row.steam_id}`}
+                            />
+                        )
+                    },
+                    {
+                        label: 'Server',
+                        tooltip: 'Server',
+                        sortKey: 'server_name',
+                        sortable: false,
+                        align: 'left'
+                    },
+                    {
+                        label: 'Command',
+                        tooltip: 'Command',
+                        sortKey: 'command',
+                        sortable: true,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography
+                                variant={'body1'}
+                                fontFamily={'monospace'}
+                            >
+                                {row.command}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'Response',
+                        tooltip: 'Response',
+                        sortKey: 'response',
+                        sortable: false,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography
+                                variant={'body2'}
+                                fontFamily={'monospace'}
+                                noWrap
+                                maxWidth={400}
+                            >
+                                {row.error || row.response}
+                            </Typography>
+                        )
+                    }
+                ]}
+            />
+        </ContainerWithHeader>
+    );
+};
+
+export const AdminRconPage = () => {
+    const { currentUser } = useCurrentUserCtx();
+    const { sendFlash } = useUserFlashCtx();
+    const [servers, setServers] = useState<BaseServer[]>([]);
+    const [groups, setGroups] = useState<ServerGroup[]>([]);
+    const [target, setTarget] = useState('all');
+    const [command, setCommand] = useState('');
+    const [history, setHistory] = useState<string[]>([]);
+    const [historyIdx, setHistoryIdx] = useState(-1);
+    const [results, setResults] = useState<RconResult[]>([]);
+    const [running, setRunning] = useState(false);
+    const [updated, setUpdated] = useState(0);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        apiGetServerStates(abortController)
+            .then((resp) => setServers(resp.servers))
+            .catch(logErr);
+        apiGetRconHistory(abortController).then(setHistory).catch(logErr);
+        apiGetServerGroups(abortController).then(setGroups).catch(logErr);
+
+        return () => abortController.abort();
+    }, []);
+
+    const regions = useMemo(() => {
+        return [...new Set(servers.map((s) => s.region))]
+            .filter((r) => r != '')
+            .sort();
+    }, [servers]);
+
+    const onExec = useCallback(async () => {
+        if (command == '' || running) {
+            return;
+        }
+        setRunning(true);
+        setResults([]);
+        try {
+            await apiRconExec(command, parseTarget(target), (result) => {
+                setResults((prev) => [...prev, result]);
+            });
+            setHistory((prev) => [command, ...prev.filter((c) => c != command)]);
+            setHistoryIdx(-1);
+            setCommand('');
+        } catch (e) {
+            sendFlash('error', `Failed to execute command: ${e}`);
+        } finally {
+            setRunning(false);
+            setUpdated((prev) => prev + 1);
+        }
+    }, [command, running, sendFlash, target]);
+
+    const onKeyDown = useCallback(
+        async (evt: React.KeyboardEvent) => {
+            switch (evt.key) {
+                case 'Enter':
+                    await onExec();
+                    break;
+                case 'ArrowUp': {
+                    const idx = Math.min(historyIdx + 1, history.length - 1);
+                    if (idx >= 0) {
+                        setHistoryIdx(idx);
+                        setCommand(history[idx]);
+                    }
+                    evt.preventDefault();
+                    break;
+                }
+                case 'ArrowDown': {
+                    const idx = historyIdx - 1;
+                    setHistoryIdx(Math.max(idx, -1));
+                    setCommand(idx >= 0 ? history[idx] : '');
+                    evt.preventDefault();
+                    break;
+                }
+            }
+        },
+        [history, historyIdx, onExec]
+    );
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'RCON Console'}
+                    iconLeft={running ? <LoadingIcon /> : <TerminalIcon />}
+                >
+                    <Stack spacing={2}>
+                        <Stack direction={'row'} spacing={1}>
+                            <FormControl sx={{ minWidth: 200 }}>
+                                <InputLabel id="rcon-target-label">
+                                    Target
+                                </InputLabel>
+                                <Select
+                                    labelId="rcon-target-label"
+                                    label={'Target'}
+                                    value={target}
+                                    onChange={(evt) =>
+                                        setTarget(evt.target.value)
+                                    }
+                                >
+                                    <MenuItem value={'all'}>All Servers</MenuItem>
+                                    {regions.map((region) => (
+                                        <MenuItem
+                                            value={`region:${region}`}
+                                            key={`region-${region}`}
+                                        >
+                                            Region: {region}
+                                        </MenuItem>
+                                    ))}
+                                    {groups.map((group) => (
+                                        <MenuItem
+                                            value={`group:${group.server_group_id}`}
+                                            key={`group-${group.server_group_id}`}
+                                        >
+                                            Group: @{group.name}
+                                        </MenuItem>
+                                    ))}
+                                    {servers.map((server) => (
+                                        <MenuItem
+                                            value={`server:${server.server_id}`}
+                                            key={`server-${server.server_id}`}
+                                        >
+                                            {server.name_short}
+                                        </MenuItem>
+                                    ))}
+                                </Select>
+                            </FormControl>
+                            <TextField
+                                fullWidth
+                                label={'Command'}
+                                value={command}
+                                disabled={running}
+                                onChange={(evt) => setCommand(evt.target.value)}
+                                onKeyDown={onKeyDown}
+                                InputProps={{
+                                    sx: { fontFamily: 'monospace' }
+                                }}
+                            />
+                            <Button
+                                variant={'contained'}
+                                endIcon={<SendIcon />}
+                                disabled={running || command == ''}
+                                onClick={onExec}
+                            >
+                                Send
+                            </Button>
+                        </Stack>
+                        {results.map((result) => (
+                            <Paper
+                                key={`rcon-result-${result.server_id}`}
+                                sx={{ padding: 1 }}
+                            >
+                                <Typography variant={'h6'}>
+                                    {result.server_name}
+                                </Typography>
+                                <Typography
+                                    variant={'body2'}
+                                    component={'pre'}
+                                    fontFamily={'monospace'}
+                                    color={result.error ? 'error' : undefined}
+                                    sx={{ whiteSpace: 'pre-wrap' }}
+                                >
+                                    {result.error || result.response}
+                                </Typography>
+                            </Paper>
+                        ))}
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            {currentUser.permission_level >= PermissionLevel.Admin && (
+                <Grid xs={12}>
+                    <RconAuditLog updated={updated} />
+                </Grid>
+            )}
+        </Grid>
+    );
+};
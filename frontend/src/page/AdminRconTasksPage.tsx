@@ -0,0 +1,596 @@
+import React, { useCallback, useEffect, useMemo, useState } from 'react';
+import DeleteIcon from '@mui/icons-material/Delete';
+import EditIcon from '@mui/icons-material/Edit';
+import HistoryIcon from '@mui/icons-material/History';
+import PlayArrowIcon from '@mui/icons-material/PlayArrow';
+import ScheduleIcon from '@mui/icons-material/Schedule';
+import Button from '@mui/material/Button';
+import Checkbox from '@mui/material/Checkbox';
+import FormControl from '@mui/material/FormControl';
+import FormControlLabel from '@mui/material/FormControlLabel';
+import IconButton from '@mui/material/IconButton';
+import InputLabel from '@mui/material/InputLabel';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Tooltip from '@mui/material/Tooltip';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiDeleteRconTask,
+    apiGetRconTaskRuns,
+    apiGetRconTasks,
+    apiGetServerGroups,
+    apiGetServerStates,
+    apiRunRconTask,
+    apiSaveRconTask,
+    BaseServer,
+    RconTask,
+    RconTaskRun,
+    ServerGroup
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+// Targets are encoded as strings for use in the select input: all, region:<region>, group:<server_group_id>
+// or server:<server_id>
+const encodeTarget = (task: RconTask) => {
+    if (task.all_servers) {
+        return 'all';
+    }
+    if (task.region != '') {
+        return `region:${task.region}`;
+    }
+    if (task.server_group_ids.length > 0) {
+        return `group:${task.server_group_ids[0]}`;
+    }
+    return `server:${task.server_ids[0]}`;
+};
+
+const decodeTarget = (value: string) => {
+    if (value.startsWith('region:')) {
+        return {
+            all_servers: false,
+            region: value.slice('region:'.length),
+            server_ids: [],
+            server_group_ids: []
+        };
+    }
+    if (value.startsWith('group:')) {
+        return {
+            all_servers: false,
+            region: '',
+            server_ids: [],
+            server_group_ids: [parseInt(value.slice('group:'.length))]
+        };
+    }
+    if (value.startsWith('server:')) {
+        return {
+            all_servers: false,
+            region: '',
+            server_ids: [parseInt(value.slice('server:'.length))],
+            server_group_ids: []
+        };
+    }
+    return {
+        all_servers: true,
+        region: '',
+        server_ids: [],
+        server_group_ids: []
+    };
+};
+
+const RconTaskRuns = ({ updated }: { updated: number }) => {
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof RconTaskRun>('created_on');
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+    const [page, setPage] = useState(0);
+    const [rows, setRows] = useState<RconTaskRun[]>([]);
+    const [count, setCount] = useState(0);
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetRconTaskRuns(
+            {
+                desc: sortOrder == 'desc',
+                order_by: sortColumn,
+                offset: page * rowPerPageCount,
+                limit: rowPerPageCount
+            },
+            abortController
+        )
+            .then((resp) => {
+                setRows(resp.data);
+                setCount(resp.count);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [page, rowPerPageCount, sortColumn, sortOrder, updated]);
+
+    return (
+        <ContainerWithHeader
+            title={'Run History'}
+            iconLeft={loading ? <LoadingIcon /> : <HistoryIcon />}
+        >
+            <LazyTable<RconTaskRun>
+                rows={rows}
+                showPager
+                page={page}
+                rowsPerPage={rowPerPageCount}
+                count={count}
+                sortOrder={sortOrder}
+                sortColumn={sortColumn}
+                onSortColumnChanged={async (column) => {
+                    setSortColumn(column);
+                }}
+                onSortOrderChanged={async (direction) => {
+                    setSortOrder(direction);
+                }}
+                onRowsPerPageChange={(
+                    event: React.ChangeEvent<
+                        HTMLInputElement | HTMLTextAreaElement
+                    >
+                ) => {
+                    setRowPerPageCount(parseInt(event.target.value, 10));
+                    setPage(0);
+                }}
+                onPageChange={(_, newPage) => {
+                    setPage(newPage);
+                }}
+                columns={[
+                    {
+                        label: 'Time',
+                        tooltip: 'Time the task ran',
+                        sortKey: 'created_on',
+                        sortable: true,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography variant={'body1'}>
+                                {renderDateTime(row.created_on)}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'Task',
+                        tooltip: 'Task',
+                        sortKey: 'task_name',
+                        sortable: false,
+                        align: 'left'
+                    },
+                    {
+                        label: 'Server',
+                        tooltip: 'Server',
+                        sortKey: 'server_name',
+                        sortable: false,
+                        align: 'left'
+                    },
+                    {
+                        label: 'Output',
+                        tooltip: 'Command output',
+                        sortKey: 'output',
+                        sortable: false,
+                        align: 'left',
+                        renderer: (row) => (
+                            <Tooltip
+                                title={
+                                    <pre style={{ whiteSpace: 'pre-wrap' }}>
+                                        {row.error || row.output}
+                                    </pre>
+                                }
+                            >
+                                <Typography
+                                    variant={'body2'}
+                                    fontFamily={'monospace'}
+                                    color={row.error ? 'error' : undefined}
+                                    noWrap
+                                    maxWidth={400}
+                                >
+                                    {row.error || row.output}
+                                </Typography>
+                            </Tooltip>
+                        )
+                    }
+                ]}
+            />
+        </ContainerWithHeader>
+    );
+};
+
+export const AdminRconTasksPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [tasks, setTasks] = useState<RconTask[]>([]);
+    const [servers, setServers] = useState<BaseServer[]>([]);
+    const [groups, setGroups] = useState<ServerGroup[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [updated, setUpdated] = useState(0);
+    const [editID, setEditID] = useState<number>();
+    const [name, setName] = useState('');
+    const [schedule, setSchedule] = useState('0 4 * * *');
+    const [target, setTarget] = useState('all');
+    const [commands, setCommands] = useState('');
+    const [playersBelow, setPlayersBelow] = useState(0);
+    const [enabled, setEnabled] = useState(true);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerStates(abortController)
+            .then((resp) => setServers(resp.servers))
+            .catch(logErr);
+        apiGetServerGroups(abortController).then(setGroups).catch(logErr);
+        apiGetRconTasks(abortController)
+            .then(setTasks)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    const regions = useMemo(() => {
+        return [...new Set(servers.map((s) => s.region))]
+            .filter((r) => r != '')
+            .sort();
+    }, [servers]);
+
+    const resetForm = useCallback(() => {
+        setEditID(undefined);
+        setName('');
+        setSchedule('0 4 * * *');
+        setTarget('all');
+        setCommands('');
+        setPlayersBelow(0);
+        setEnabled(true);
+    }, []);
+
+    const onEdit = useCallback((task: RconTask) => {
+        setEditID(task.rcon_task_id);
+        setName(task.name);
+        setSchedule(task.schedule);
+        setTarget(encodeTarget(task));
+        setCommands(task.commands.join('\n'));
+        setPlayersBelow(task.players_below);
+        setEnabled(task.enabled);
+    }, []);
+
+    const onSave = useCallback(async () => {
+        try {
+            const task = await apiSaveRconTask(
+                {
+                    ...decodeTarget(target),
+                    name,
+                    schedule,
+                    commands: commands.split('\n'),
+                    players_below: playersBelow,
+                    enabled
+                },
+                editID
+            );
+            setTasks((prev) => [
+                ...prev.filter((t) => t.rcon_task_id != task.rcon_task_id),
+                task
+            ]);
+            resetForm();
+            sendFlash('success', 'Task saved successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to save task: ${e}`);
+        }
+    }, [
+        commands,
+        editID,
+        enabled,
+        name,
+        playersBelow,
+        resetForm,
+        schedule,
+        sendFlash,
+        target
+    ]);
+
+    const onDelete = useCallback(
+        async (task: RconTask) => {
+            try {
+                await apiDeleteRconTask(task.rcon_task_id);
+                setTasks((prev) =>
+                    prev.filter((t) => t.rcon_task_id != task.rcon_task_id)
+                );
+                sendFlash('success', 'Task deleted successfully');
+            } catch (e) {
+                sendFlash('error', `Failed to delete task: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    const onRun = useCallback(
+        async (task: RconTask) => {
+            try {
+                const runs = await apiRunRconTask(task.rcon_task_id);
+                const failed = runs.filter((r) => r.error != '').length;
+                sendFlash(
+                    failed > 0 ? 'warning' : 'success',
+                    `Task ran on ${runs.length} servers, ${failed} failed`
+                );
+            } catch (e) {
+                sendFlash('error', `Failed to run task: ${e}`);
+            } finally {
+                setUpdated((prev) => prev + 1);
+            }
+        },
+        [sendFlash]
+    );
+
+    const renderTarget = (task: RconTask) => {
+        if (task.all_servers) {
+            return 'All Servers';
+        }
+        if (task.region != '') {
+            return `Region: ${task.region}`;
+        }
+        if (task.server_group_ids.length > 0) {
+            return task.server_group_ids
+                .map(
+                    (id) =>
+                        `@${groups.find((g) => g.server_group_id == id)?.name ?? id}`
+                )
+                .join(', ');
+        }
+        return task.server_ids
+            .map(
+                (id) =>
+                    servers.find((s) => s.server_id == id)?.name_short ?? id
+            )
+            .join(', ');
+    };
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Scheduled Tasks'}
+                    iconLeft={loading ? <LoadingIcon /> : <ScheduleIcon />}
+                >
+                    <LazyTable<RconTask>
+                        rows={tasks}
+                        sortOrder={'asc'}
+                        sortColumn={'name'}
+                        onSortColumnChanged={() => {}}
+                        onSortOrderChanged={() => {}}
+                        columns={[
+                            {
+                                label: 'Name',
+                                tooltip: 'Name',
+                                sortKey: 'name',
+                                align: 'left'
+                            },
+                            {
+                                label: 'Schedule',
+                                tooltip: 'Cron schedule',
+                                sortKey: 'schedule',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography
+                                        variant={'body1'}
+                                        fontFamily={'monospace'}
+                                    >
+                                        {row.schedule}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Target',
+                                tooltip: 'Target servers',
+                                sortKey: 'server_ids',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {renderTarget(row)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Commands',
+                                tooltip: 'Commands',
+                                sortKey: 'commands',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography
+                                        variant={'body2'}
+                                        fontFamily={'monospace'}
+                                    >
+                                        {row.commands.join('; ')}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Condition',
+                                tooltip: 'Only run when the player count is below',
+                                sortKey: 'players_below',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.players_below > 0
+                                            ? `< ${row.players_below} players`
+                                            : ''}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Enabled',
+                                tooltip: 'Enabled',
+                                sortKey: 'enabled',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.enabled ? 'Yes' : 'No'}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Actions',
+                                tooltip: 'Actions',
+                                virtual: true,
+                                virtualKey: 'actions',
+                                align: 'right',
+                                renderer: (row) => (
+                                    <Stack direction={'row'}>
+                                        <IconButton
+                                            color={'success'}
+                                            onClick={() => onRun(row)}
+                                        >
+                                            <PlayArrowIcon />
+                                        </IconButton>
+                                        <IconButton
+                                            color={'warning'}
+                                            onClick={() => onEdit(row)}
+                                        >
+                                            <EditIcon />
+                                        </IconButton>
+                                        <IconButton
+                                            color={'error'}
+                                            onClick={() => onDelete(row)}
+                                        >
+                                            <DeleteIcon />
+                                        </IconButton>
+                                    </Stack>
+                                )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={editID ? 'Edit Task' : 'Create Task'}
+                    iconLeft={<EditIcon />}
+                >
+                    <Stack spacing={2}>
+                        <Stack direction={'row'} spacing={1}>
+                            <TextField
+                                fullWidth
+                                label={'Name'}
+                                value={name}
+                                onChange={(evt) => setName(evt.target.value)}
+                            />
+                            <TextField
+                                fullWidth
+                                label={'Schedule'}
+                                helperText={
+                                    'Cron expression: minute hour day month weekday'
+                                }
+                                value={schedule}
+                                onChange={(evt) =>
+                                    setSchedule(evt.target.value)
+                                }
+                            />
+                            <FormControl fullWidth>
+                                <InputLabel id="rcon-task-target-label">
+                                    Target
+                                </InputLabel>
+                                <Select
+                                    labelId="rcon-task-target-label"
+                                    label={'Target'}
+                                    value={target}
+                                    onChange={(evt) =>
+                                        setTarget(evt.target.value)
+                                    }
+                                >
+                                    <MenuItem value={'all'}>
+                                        All Servers
+                                    </MenuItem>
+                                    {regions.map((region) => (
+                                        <MenuItem
+                                            value={`region:${region}`}
+                                            key={`region-${region}`}
+                                        >
+                                            Region: {region}
+                                        </MenuItem>
+                                    ))}
+                                    {groups.map((group) => (
+                                        <MenuItem
+                                            value={`group:${group.server_group_id}`}
+                                            key={`group-${group.server_group_id}`}
+                                        >
+                                            Group: @{group.name}
+                                        </MenuItem>
+                                    ))}
+                                    {servers.map((server) => (
+                                        <MenuItem
+                                            value={`server:${server.server_id}`}
+                                            key={`server-${server.server_id}`}
+                                        >
+                                            {server.name_short}
+                                        </MenuItem>
+                                    ))}
+                                </Select>
+                            </FormControl>
+                        </Stack>
+                        <TextField
+                            fullWidth
+                            multiline
+                            minRows={3}
+                            label={'Commands (one per line)'}
+                            value={commands}
+                            onChange={(evt) => setCommands(evt.target.value)}
+                            InputProps={{
+                                sx: { fontFamily: 'monospace' }
+                            }}
+                        />
+                        <Stack direction={'row'} spacing={1}>
+                            <TextField
+                                type={'number'}
+                                label={'Only run when players below'}
+                                helperText={'0 to always run'}
+                                value={playersBelow}
+                                onChange={(evt) =>
+                                    setPlayersBelow(
+                                        parseInt(evt.target.value) || 0
+                                    )
+                                }
+                            />
+                            <FormControlLabel
+                                label={'Enabled'}
+                                control={
+                                    <Checkbox
+                                        checked={enabled}
+                                        onChange={(_, checked) =>
+                                            setEnabled(checked)
+                                        }
+                                    />
+                                }
+                            />
+                        </Stack>
+                        <Stack direction={'row'} spacing={1}>
+                            <Button variant={'contained'} onClick={onSave}>
+                                Save
+                            </Button>
+                            {editID && (
+                                <Button
+                                    variant={'contained'}
+                                    color={'warning'}
+                                    onClick={resetForm}
+                                >
+                                    Cancel
+                                </Button>
+                            )}
+                        </Stack>
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <RconTaskRuns updated={updated} />
+            </Grid>
+        </Grid>
+    );
+};
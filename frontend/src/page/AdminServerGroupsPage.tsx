@@ -0,0 +1,255 @@
+import React, { useCallback, useEffect, useMemo, useState } from 'react';
+import DeleteIcon from '@mui/icons-material/Delete';
+import EditIcon from '@mui/icons-material/Edit';
+import WorkspacesIcon from '@mui/icons-material/Workspaces';
+import Button from '@mui/material/Button';
+import Checkbox from '@mui/material/Checkbox';
+import IconButton from '@mui/material/IconButton';
+import ListItemText from '@mui/material/ListItemText';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiDeleteServerGroup,
+    apiGetServerGroups,
+    apiGetServerStates,
+    apiSaveServerGroup,
+    BaseServer,
+    ServerGroup
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+
+export const AdminServerGroupsPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [groups, setGroups] = useState<ServerGroup[]>([]);
+    const [servers, setServers] = useState<BaseServer[]>([]);
+    const [loading, setLoading] = useState(false);
+    const [editID, setEditID] = useState<number>();
+    const [name, setName] = useState('');
+    const [description, setDescription] = useState('');
+    const [serverIDs, setServerIDs] = useState<number[]>([]);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerStates(abortController)
+            .then((resp) => setServers(resp.servers))
+            .catch(logErr);
+        apiGetServerGroups(abortController)
+            .then(setGroups)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    const serverNames = useMemo(() => {
+        const names: Record<number, string> = {};
+        servers.forEach((s) => {
+            names[s.server_id] = s.name_short;
+        });
+        return names;
+    }, [servers]);
+
+    const resetForm = useCallback(() => {
+        setEditID(undefined);
+        setName('');
+        setDescription('');
+        setServerIDs([]);
+    }, []);
+
+    const onEdit = useCallback((group: ServerGroup) => {
+        setEditID(group.server_group_id);
+        setName(group.name);
+        setDescription(group.description);
+        setServerIDs(group.server_ids);
+    }, []);
+
+    const onSave = useCallback(async () => {
+        try {
+            const group = await apiSaveServerGroup(
+                { name, description, server_ids: serverIDs },
+                editID
+            );
+            setGroups((prev) =>
+                [
+                    ...prev.filter(
+                        (g) => g.server_group_id != group.server_group_id
+                    ),
+                    group
+                ].sort((a, b) => a.name.localeCompare(b.name))
+            );
+            resetForm();
+            sendFlash('success', 'Group saved successfully');
+        } catch (e) {
+            sendFlash('error', `Failed to save group: ${e}`);
+        }
+    }, [description, editID, name, resetForm, sendFlash, serverIDs]);
+
+    const onDelete = useCallback(
+        async (group: ServerGroup) => {
+            try {
+                await apiDeleteServerGroup(group.server_group_id);
+                setGroups((prev) =>
+                    prev.filter(
+                        (g) => g.server_group_id != group.server_group_id
+                    )
+                );
+                sendFlash('success', 'Group deleted successfully');
+            } catch (e) {
+                sendFlash('error', `Failed to delete group: ${e}`);
+            }
+        },
+        [sendFlash]
+    );
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Server Groups'}
+                    iconLeft={loading ? <LoadingIcon /> : <WorkspacesIcon />}
+                >
+                    <LazyTable<ServerGroup>
+                        rows={groups}
+                        sortOrder={'asc'}
+                        sortColumn={'name'}
+                        onSortColumnChanged={() => {}}
+                        onSortOrderChanged={() => {}}
+                        columns={[
+                            {
+                                label: 'Name',
+                                tooltip: 'Name used to target the group, eg: @mge',
+                                sortKey: 'name',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography
+                                        variant={'body1'}
+                                        fontFamily={'monospace'}
+                                    >
+                                        @{row.name}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Description',
+                                tooltip: 'Description',
+                                sortKey: 'description',
+                                align: 'left'
+                            },
+                            {
+                                label: 'Servers',
+                                tooltip: 'Member servers',
+                                sortKey: 'server_ids',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.server_ids
+                                            .map(
+                                                (id) =>
+                                                    serverNames[id] ?? `#${id}`
+                                            )
+                                            .join(', ')}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Actions',
+                                tooltip: 'Actions',
+                                virtual: true,
+                                virtualKey: 'actions',
+                                align: 'right',
+                                renderer: (row) => (
+                                    <Stack direction={'row'}>
+                                        <IconButton
+                                            color={'warning'}
+                                            onClick={() => onEdit(row)}
+                                        >
+                                            <EditIcon />
+                                        </IconButton>
+                                        <IconButton
+                                            color={'error'}
+                                            onClick={() => onDelete(row)}
+                                        >
+                                            <DeleteIcon />
+                                        </IconButton>
+                                    </Stack>
+                                )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={editID ? 'Edit Group' : 'Create Group'}
+                    iconLeft={<EditIcon />}
+                >
+                    <Stack spacing={2}>
+                        <Stack direction={'row'} spacing={1}>
+                            <TextField
+                                fullWidth
+                                label={'Name'}
+                                value={name}
+                                onChange={(evt) => setName(evt.target.value)}
+                            />
+                            <TextField
+                                fullWidth
+                                label={'Description'}
+                                value={description}
+                                onChange={(evt) =>
+                                    setDescription(evt.target.value)
+                                }
+                            />
+                        </Stack>
+                        <Select<number[]>
+                            fullWidth
+                            multiple
+                            value={serverIDs}
+                            onChange={(evt) =>
+                                setServerIDs(evt.target.value as number[])
+                            }
+                            renderValue={(selected) =>
+                                selected
+                                    .map((id) => serverNames[id] ?? `#${id}`)
+                                    .join(', ')
+                            }
+                        >
+                            {servers.map((s) => (
+                                <MenuItem value={s.server_id} key={s.server_id}>
+                                    <Checkbox
+                                        checked={serverIDs.includes(
+                                            s.server_id
+                                        )}
+                                    />
+                                    <ListItemText primary={s.name_short} />
+                                </MenuItem>
+                            ))}
+                        </Select>
+                        <Stack direction={'row'} spacing={1}>
+                            <Button variant={'contained'} onClick={onSave}>
+                                Save
+                            </Button>
+                            {editID && (
+                                <Button
+                                    variant={'contained'}
+                                    color={'warning'}
+                                    onClick={resetForm}
+                                >
+                                    Cancel
+                                </Button>
+                            )}
+                        </Stack>
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
@@ -0,0 +1,192 @@
+import React, { useEffect, useState } from 'react';
+import HistoryIcon from '@mui/icons-material/History';
+import MonitorHeartIcon from '@mui/icons-material/MonitorHeart';
+import Chip from '@mui/material/Chip';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiGetServerHealth,
+    apiGetServerHealthHistory,
+    HealthState,
+    healthStateString,
+    ServerHealthEvent,
+    ServerHealthStatus
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable } from '../component/table/LazyTable';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+const HealthChip = ({ state }: { state: HealthState }) => {
+    switch (state) {
+        case HealthState.Up:
+            return <Chip label={healthStateString(state)} color={'success'} />;
+        case HealthState.Degraded:
+            return <Chip label={healthStateString(state)} color={'warning'} />;
+        default:
+            return <Chip label={healthStateString(state)} color={'error'} />;
+    }
+};
+
+const ServerHealthHistory = ({ server }: { server: ServerHealthStatus }) => {
+    const [events, setEvents] = useState<ServerHealthEvent[]>([]);
+    const [uptime, setUptime] = useState(100);
+    const [loading, setLoading] = useState(false);
+    const days = 30;
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerHealthHistory(server.server_id, days, abortController)
+            .then((resp) => {
+                setEvents(resp.events.reverse());
+                setUptime(resp.uptime);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [server.server_id]);
+
+    return (
+        <ContainerWithHeader
+            title={`${server.server_name} History (${days} Days, ${uptime.toFixed(2)}% Uptime)`}
+            iconLeft={loading ? <LoadingIcon /> : <HistoryIcon />}
+        >
+            <LazyTable<ServerHealthEvent>
+                rows={events}
+                sortOrder={'desc'}
+                sortColumn={'created_on'}
+                onSortColumnChanged={() => {}}
+                onSortOrderChanged={() => {}}
+                columns={[
+                    {
+                        label: 'Time',
+                        tooltip: 'Time of the state change',
+                        sortKey: 'created_on',
+                        align: 'left',
+                        renderer: (row) => (
+                            <Typography variant={'body1'}>
+                                {renderDateTime(row.created_on)}
+                            </Typography>
+                        )
+                    },
+                    {
+                        label: 'State',
+                        tooltip: 'Health State',
+                        sortKey: 'state',
+                        align: 'left',
+                        renderer: (row) => <HealthChip state={row.state} />
+                    },
+                    {
+                        label: 'Reason',
+                        tooltip: 'Reason',
+                        sortKey: 'reason',
+                        align: 'left'
+                    }
+                ]}
+            />
+        </ContainerWithHeader>
+    );
+};
+
+export const AdminServerHealthPage = () => {
+    const [servers, setServers] = useState<ServerHealthStatus[]>([]);
+    const [selected, setSelected] = useState<ServerHealthStatus>();
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetServerHealth(abortController)
+            .then(setServers)
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, []);
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Server Health (Past 7 Days)'}
+                    iconLeft={loading ? <LoadingIcon /> : <MonitorHeartIcon />}
+                >
+                    <LazyTable<ServerHealthStatus>
+                        rows={servers}
+                        sortOrder={'asc'}
+                        sortColumn={'server_name'}
+                        onSortColumnChanged={() => {}}
+                        onSortOrderChanged={() => {}}
+                        columns={[
+                            {
+                                label: 'Server',
+                                tooltip: 'Server',
+                                sortKey: 'server_name',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography
+                                        variant={'body1'}
+                                        sx={{ cursor: 'pointer' }}
+                                        onClick={() => setSelected(row)}
+                                    >
+                                        {row.server_name}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'State',
+                                tooltip: 'Current Health State',
+                                sortKey: 'state',
+                                align: 'left',
+                                renderer: (row) =>
+                                    row.known ? (
+                                        <HealthChip state={row.state} />
+                                    ) : (
+                                        <Chip label={'Unknown'} />
+                                    )
+                            },
+                            {
+                                label: 'Since',
+                                tooltip: 'Time of the last state change',
+                                sortKey: 'since',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.known
+                                            ? renderDateTime(row.since)
+                                            : ''}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Reason',
+                                tooltip: 'Reason',
+                                sortKey: 'reason',
+                                align: 'left'
+                            },
+                            {
+                                label: 'Uptime',
+                                tooltip: 'Percentage of time the server was not down',
+                                sortKey: 'uptime',
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.uptime.toFixed(2)}%
+                                    </Typography>
+                                )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+            {selected && (
+                <Grid xs={12}>
+                    <ServerHealthHistory server={selected} />
+                </Grid>
+            )}
+        </Grid>
+    );
+};
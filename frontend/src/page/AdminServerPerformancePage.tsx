@@ -0,0 +1,245 @@
+import React, { useEffect, useMemo, useState } from 'react';
+import EventNoteIcon from '@mui/icons-material/EventNote';
+import SpeedIcon from '@mui/icons-material/Speed';
+import FormControl from '@mui/material/FormControl';
+import InputLabel from '@mui/material/InputLabel';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
+import Stack from '@mui/material/Stack';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import { LineChart } from '@mui/x-charts';
+import { apiGetServerPerformance, ServerPerformanceSeries } from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order } from '../component/table/LazyTable';
+import { compare, stableSort } from '../component/table/LazyTableSimple';
+import { useServers } from '../hooks/useServers';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+interface PerformanceEvent {
+    created_on: Date;
+    event: string;
+    detail: string;
+}
+
+const ranges: Record<string, number> = {
+    '6 Hours': 6,
+    '24 Hours': 24,
+    '3 Days': 72,
+    '7 Days': 168
+};
+
+const hourMs = 60 * 60 * 1000;
+
+export const AdminServerPerformancePage = () => {
+    const { data: servers } = useServers();
+    const [serverId, setServerId] = useState<number>(0);
+    const [hours, setHours] = useState(24);
+    const [series, setSeries] = useState<ServerPerformanceSeries>();
+    const [loading, setLoading] = useState(false);
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof PerformanceEvent>('created_on');
+
+    useEffect(() => {
+        if (serverId <= 0 && servers.length > 0) {
+            setServerId(servers[0].server_id);
+        }
+    }, [serverId, servers]);
+
+    useEffect(() => {
+        if (serverId <= 0) {
+            return;
+        }
+        const abortController = new AbortController();
+        const to = new Date();
+        setLoading(true);
+        apiGetServerPerformance(
+            {
+                server_ids: [serverId],
+                from: new Date(to.getTime() - hours * hourMs),
+                to: to
+            },
+            abortController
+        )
+            .then((resp) => setSeries(resp.length > 0 ? resp[0] : undefined))
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [hours, serverId]);
+
+    const events = useMemo(() => {
+        const rows: PerformanceEvent[] = [
+            ...(series?.map_changes ?? []).map((change) => ({
+                created_on: change.changed_on,
+                event: 'Map Change',
+                detail: change.map_name
+            })),
+            ...(series?.samples ?? [])
+                .filter((sample) => sample.lag_spike)
+                .map((sample) => ({
+                    created_on: sample.created_on,
+                    event: 'Lag Spike',
+                    detail: `${sample.fps.toFixed(1)} fps, var ${sample.var_ms.toFixed(2)}ms, ${sample.players} players`
+                }))
+        ];
+        return stableSort(rows, compare(sortOrder, sortColumn));
+    }, [series, sortColumn, sortOrder]);
+
+    const samples = series?.samples ?? [];
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Server Performance'}
+                    iconLeft={loading ? <LoadingIcon /> : <SpeedIcon />}
+                >
+                    <Stack spacing={2}>
+                        <Stack direction={'row'} spacing={1}>
+                            <FormControl sx={{ minWidth: 200 }}>
+                                <InputLabel id="server-label">Server</InputLabel>
+                                <Select<number>
+                                    labelId="server-label"
+                                    label={'Server'}
+                                    value={serverId > 0 ? serverId : ''}
+                                    onChange={(evt) =>
+                                        setServerId(Number(evt.target.value))
+                                    }
+                                >
+                                    {servers.map((server) => (
+                                        <MenuItem
+                                            key={server.server_id}
+                                            value={server.server_id}
+                                        >
+                                            {server.server_name}
+                                        </MenuItem>
+                                    ))}
+                                </Select>
+                            </FormControl>
+                            <FormControl>
+                                <InputLabel id="range-label">Range</InputLabel>
+                                <Select<number>
+                                    labelId="range-label"
+                                    label={'Range'}
+                                    value={hours}
+                                    onChange={(evt) =>
+                                        setHours(Number(evt.target.value))
+                                    }
+                                >
+                                    {Object.entries(ranges).map(
+                                        ([label, value]) => (
+                                            <MenuItem key={label} value={value}>
+                                                {label}
+                                            </MenuItem>
+                                        )
+                                    )}
+                                </Select>
+                            </FormControl>
+                        </Stack>
+                        {samples.length > 0 ? (
+                            <>
+                                <LineChart
+                                    height={300}
+                                    xAxis={[
+                                        {
+                                            data: samples.map(
+                                                (s) => s.created_on
+                                            ),
+                                            scaleType: 'time'
+                                        }
+                                    ]}
+                                    series={[
+                                        {
+                                            label: 'FPS',
+                                            showMark: false,
+                                            data: samples.map((s) => s.fps)
+                                        },
+                                        {
+                                            label: 'Players',
+                                            showMark: false,
+                                            data: samples.map((s) => s.players)
+                                        }
+                                    ]}
+                                />
+                                <LineChart
+                                    height={300}
+                                    xAxis={[
+                                        {
+                                            data: samples.map(
+                                                (s) => s.created_on
+                                            ),
+                                            scaleType: 'time'
+                                        }
+                                    ]}
+                                    series={[
+                                        {
+                                            label: 'Var (ms)',
+                                            showMark: false,
+                                            data: samples.map((s) => s.var_ms)
+                                        },
+                                        {
+                                            label: 'Frame (ms)',
+                                            showMark: false,
+                                            data: samples.map((s) => s.frame_ms)
+                                        }
+                                    ]}
+                                />
+                            </>
+                        ) : (
+                            <Typography variant={'body1'}>
+                                No performance data recorded for this period
+                            </Typography>
+                        )}
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={`Events (${series?.lag_spikes ?? 0} lag spikes)`}
+                    iconLeft={<EventNoteIcon />}
+                >
+                    <LazyTable<PerformanceEvent>
+                        rows={events}
+                        sortOrder={sortOrder}
+                        sortColumn={sortColumn}
+                        onSortColumnChanged={async (column) => {
+                            setSortColumn(column);
+                        }}
+                        onSortOrderChanged={async (direction) => {
+                            setSortOrder(direction);
+                        }}
+                        columns={[
+                            {
+                                label: 'Time',
+                                tooltip: 'When the event was recorded',
+                                sortKey: 'created_on',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) =>
+                                    renderDateTime(row.created_on)
+                            },
+                            {
+                                label: 'Event',
+                                tooltip: 'Event type',
+                                sortKey: 'event',
+                                sortable: true,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Detail',
+                                tooltip: 'Event detail',
+                                sortKey: 'detail',
+                                sortable: false,
+                                align: 'left'
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
@@ -1,8 +1,10 @@
 import React, { useState } from 'react';
 import NiceModal from '@ebay/nice-modal-react';
+import CheckCircleIcon from '@mui/icons-material/CheckCircle';
 import CreateIcon from '@mui/icons-material/Create';
 import DeleteIcon from '@mui/icons-material/Delete';
 import EditIcon from '@mui/icons-material/Edit';
+import KeyIcon from '@mui/icons-material/Key';
 import StorageIcon from '@mui/icons-material/Storage';
 import Button from '@mui/material/Button';
 import ButtonGroup from '@mui/material/ButtonGroup';
@@ -10,12 +12,18 @@ import IconButton from '@mui/material/IconButton';
 import Stack from '@mui/material/Stack';
 import Tooltip from '@mui/material/Tooltip';
 import Grid from '@mui/material/Unstable_Grid2';
-import { Server } from '../api';
+import { apiApproveServer, Server } from '../api';
 import { ContainerWithHeader } from '../component/ContainerWithHeader';
-import { ModalServerDelete, ModalServerEditor } from '../component/modal';
+import { ServerEnrollmentTokens } from '../component/ServerEnrollmentTokens';
+import {
+    ModalServerCredentials,
+    ModalServerDelete,
+    ModalServerEditor
+} from '../component/modal';
 import { ServerEditorModal } from '../component/modal/ServerEditorModal';
 import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
 import { TableCellBool } from '../component/table/TableCellBool';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
 import { useServersAdmin } from '../hooks/useServersAdmin';
 
 export const AdminServersPage = () => {
@@ -26,6 +34,7 @@ export const AdminServersPage = () => {
     );
     const [page, setPage] = useState(0);
     const [deleted] = useState(false);
+    const { sendFlash } = useUserFlashCtx();
 
     const { data, count } = useServersAdmin({
         limit: rowPerPageCount,
@@ -166,6 +175,35 @@ export const AdminServersPage = () => {
                                     align: 'center',
                                     renderer: (row) => (
                                         <ButtonGroup fullWidth>
+                                            {row.pending_approval && (
+                                                <IconButton
+                                                    color={'success'}
+                                                    onClick={async () => {
+                                                        try {
+                                                            await apiApproveServer(
+                                                                row.server_id
+                                                            );
+                                                            sendFlash(
+                                                                'success',
+                                                                'Server approved'
+                                                            );
+                                                        } catch (e) {
+                                                            sendFlash(
+                                                                'error',
+                                                                `Failed to approve server: ${e}`
+                                                            );
+                                                        }
+                                                    }}
+                                                >
+                                                    <Tooltip
+                                                        title={
+                                                            'Approve Enrolled Server'
+                                                        }
+                                                    >
+                                                        <CheckCircleIcon />
+                                                    </Tooltip>
+                                                </IconButton>
+                                            )}
                                             <IconButton
                                                 color={'warning'}
                                                 onClick={async () => {
@@ -181,6 +219,21 @@ export const AdminServersPage = () => {
                                                     <EditIcon />
                                                 </Tooltip>
                                             </IconButton>
+                                            <IconButton
+                                                color={'warning'}
+                                                onClick={async () => {
+                                                    await NiceModal.show(
+                                                        ModalServerCredentials,
+                                                        { server: row }
+                                                    );
+                                                }}
+                                            >
+                                                <Tooltip
+                                                    title={'Server Credentials'}
+                                                >
+                                                    <KeyIcon />
+                                                </Tooltip>
+                                            </IconButton>
                                             <IconButton
                                                 color={'warning'}
                                                 onClick={async () => {
@@ -204,6 +257,7 @@ export const AdminServersPage = () => {
                             ]}
                         />
                     </ContainerWithHeader>
+                    <ServerEnrollmentTokens />
                 </Stack>
             </Grid>
         </Grid>
@@ -0,0 +1,265 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import CheckIcon from '@mui/icons-material/Check';
+import CloseIcon from '@mui/icons-material/Close';
+import TroubleshootIcon from '@mui/icons-material/Troubleshoot';
+import ButtonGroup from '@mui/material/ButtonGroup';
+import IconButton from '@mui/material/IconButton';
+import Tooltip from '@mui/material/Tooltip';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiGetSuspicionFlags,
+    apiSetSuspicionFlagState,
+    SuspicionFlag,
+    SuspicionFlagState,
+    suspicionFlagStateString,
+    suspicionMetricString
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { TableCellLink } from '../component/table/TableCellLink';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+import { logErr } from '../util/errors';
+import { renderDateTime } from '../util/text';
+
+export const AdminSuspicionPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof SuspicionFlag>('score');
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+    const [page, setPage] = useState(0);
+    const [actions, setActions] = useState<SuspicionFlag[]>([]);
+    const [count, setCount] = useState(0);
+    const [loading, setLoading] = useState(false);
+    const [updated, setUpdated] = useState(0);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        setLoading(true);
+        apiGetSuspicionFlags(
+            {
+                desc: sortOrder == 'desc',
+                order_by: sortColumn,
+                offset: page * rowPerPageCount,
+                limit: rowPerPageCount,
+                state: SuspicionFlagState.Open
+            },
+            abortController
+        )
+            .then((resp) => {
+                setActions(resp.data);
+                setCount(resp.count);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [page, rowPerPageCount, sortColumn, sortOrder, updated]);
+
+    const onResolve = useCallback(
+        async (flag: SuspicionFlag, confirm: boolean) => {
+            try {
+                await apiSetSuspicionFlagState(
+                    flag.suspicion_flag_id,
+                    confirm
+                        ? SuspicionFlagState.Confirmed
+                        : SuspicionFlagState.Dismissed
+                );
+                sendFlash(
+                    'success',
+                    `${confirm ? 'Confirmed' : 'Dismissed'} flag #${
+                        flag.suspicion_flag_id
+                    }`
+                );
+            } catch (e) {
+                sendFlash('error', `Failed to update flag: ${e}`);
+            } finally {
+                setUpdated((prev) => prev + 1);
+            }
+        },
+        [sendFlash]
+    );
+
+    return (
+        <Grid container spacing={3}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Open Suspicion Flags'}
+                    iconLeft={loading ? <LoadingIcon /> : <TroubleshootIcon />}
+                >
+                    <LazyTable<SuspicionFlag>
+                        rows={actions}
+                        showPager
+                        page={page}
+                        rowsPerPage={rowPerPageCount}
+                        count={count}
+                        sortOrder={sortOrder}
+                        sortColumn={sortColumn}
+                        onSortColumnChanged={async (column) => {
+                            setSortColumn(column);
+                        }}
+                        onSortOrderChanged={async (direction) => {
+                            setSortOrder(direction);
+                        }}
+                        onRowsPerPageChange={(
+                            event: React.ChangeEvent<
+                                HTMLInputElement | HTMLTextAreaElement
+                            >
+                        ) => {
+                            setRowPerPageCount(
+                                parseInt(event.target.value, 10)
+                            );
+                            setPage(0);
+                        }}
+                        onPageChange={(_, newPage) => {
+                            setPage(newPage);
+                        }}
+                        columns={[
+                            {
+                                label: 'Score',
+                                tooltip: 'Standard deviations above the mean',
+                                sortKey: 'score',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.score.toFixed(2)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Player',
+                                tooltip: 'Player',
+                                sortKey: 'steam_id',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <TableCellLink
+                                        label={row.personaname || row.steam_id}
+                                        to={`/profile/${row.steam_id}`}
+                                    />
+                                )
+                            },
+                            {
+                                label: 'Metric',
+                                tooltip: 'Metric',
+                                sortKey: 'metric',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {suspicionMetricString(row.metric)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Weapon',
+                                tooltip: 'Weapon',
+                                sortKey: 'weapon_name',
+                                sortable: false,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Value',
+                                tooltip: 'Value (Baseline Mean)',
+                                sortKey: 'value',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.value.toFixed(3)} (
+                                        {row.baseline_mean.toFixed(3)})
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Match',
+                                tooltip: 'Match',
+                                sortKey: 'match_id',
+                                sortable: false,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <TableCellLink
+                                        label={
+                                            row.demo_id > 0
+                                                ? `Demo #${row.demo_id}`
+                                                : 'Logs'
+                                        }
+                                        to={`/log/${row.match_id}`}
+                                    />
+                                )
+                            },
+                            {
+                                label: 'State',
+                                tooltip: 'State',
+                                sortKey: 'state',
+                                sortable: false,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {suspicionFlagStateString(row.state)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Created',
+                                tooltip: 'Created On',
+                                sortKey: 'created_on',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {renderDateTime(row.created_on)}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Act',
+                                tooltip: 'Confirm or dismiss',
+                                sortable: false,
+                                align: 'right',
+                                renderer: (row) =>
+                                    row.state == SuspicionFlagState.Open ? (
+                                        <ButtonGroup>
+                                            <Tooltip title={'Confirm'}>
+                                                <IconButton
+                                                    color={'error'}
+                                                    onClick={async () =>
+                                                        await onResolve(
+                                                            row,
+                                                            true
+                                                        )
+                                                    }
+                                                >
+                                                    <CheckIcon />
+                                                </IconButton>
+                                            </Tooltip>
+                                            <Tooltip title={'Dismiss'}>
+                                                <IconButton
+                                                    color={'success'}
+                                                    onClick={async () =>
+                                                        await onResolve(
+                                                            row,
+                                                            false
+                                                        )
+                                                    }
+                                                >
+                                                    <CloseIcon />
+                                                </IconButton>
+                                            </Tooltip>
+                                        </ButtonGroup>
+                                    ) : (
+                                        <></>
+                                    )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
@@ -38,6 +38,7 @@ import { ContainerWithHeader } from '../component/ContainerWithHeader';
 import { MDBodyField } from '../component/MDBodyField';
 import { ProfileInfoBox } from '../component/ProfileInfoBox';
 import { SourceBansList } from '../component/SourceBansList';
+import { StacDetectionList } from '../component/StacDetectionList';
 import { SteamIDList } from '../component/SteamIDList';
 import { UserMessageView } from '../component/UserMessageView';
 import { ModalBanSteam, ModalUnbanSteam } from '../component/modal';
@@ -205,6 +206,12 @@ export const BanPage = (): JSX.Element => {
                             />
                         )}
 
+                    {ban &&
+                        currentUser.permission_level >=
+                            PermissionLevel.Moderator && (
+                            <StacDetectionList steam_id={ban.target_id} />
+                        )}
+
                     {messages.map((m) => (
                         <UserMessageView
                             onDelete={onDelete}
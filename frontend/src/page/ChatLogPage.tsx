@@ -65,6 +65,7 @@ const anyServer: Server = {
     colour: '',
     enable_stats: true,
     log_secret: 0,
+    pending_approval: false,
     updated_on: new Date(),
     created_on: new Date()
 };
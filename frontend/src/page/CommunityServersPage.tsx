@@ -0,0 +1,220 @@
+import React, { useEffect, useState } from 'react';
+import PublicIcon from '@mui/icons-material/Public';
+import Button from '@mui/material/Button';
+import Checkbox from '@mui/material/Checkbox';
+import FormControlLabel from '@mui/material/FormControlLabel';
+import Stack from '@mui/material/Stack';
+import TablePagination from '@mui/material/TablePagination';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiGetCommunityServers,
+    CommunityServer,
+    CommunityServerQuery
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
+import { logErr } from '../util/errors';
+
+export const CommunityServersPage = () => {
+    const [sortOrder, setSortOrder] = useState<Order>('desc');
+    const [sortColumn, setSortColumn] =
+        useState<keyof CommunityServer>('players');
+    const [rows, setRows] = useState<CommunityServer[]>([]);
+    const [totalRows, setTotalRows] = useState<number>(0);
+    const [page, setPage] = useState(0);
+    const [rowPerPageCount, setRowPerPageCount] = useState<number>(
+        RowsPerPage.TwentyFive
+    );
+    const [query, setQuery] = useState('');
+    const [mapName, setMapName] = useState('');
+    const [notEmpty, setNotEmpty] = useState(true);
+    const [notFull, setNotFull] = useState(false);
+    const [noPassword, setNoPassword] = useState(true);
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        const opts: CommunityServerQuery = {
+            query: query,
+            map: mapName,
+            not_empty: notEmpty,
+            not_full: notFull,
+            no_password: noPassword,
+            limit: rowPerPageCount,
+            offset: page * rowPerPageCount,
+            order_by: sortColumn,
+            desc: sortOrder == 'desc'
+        };
+        setLoading(true);
+        apiGetCommunityServers(opts, abortController)
+            .then((resp) => {
+                setTotalRows(resp.count);
+                setRows(resp.data);
+            })
+            .catch(logErr)
+            .finally(() => setLoading(false));
+
+        return () => abortController.abort();
+    }, [
+        mapName,
+        noPassword,
+        notEmpty,
+        notFull,
+        page,
+        query,
+        rowPerPageCount,
+        sortColumn,
+        sortOrder
+    ]);
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <Stack direction={'row'} spacing={1}>
+                    <TextField
+                        size={'small'}
+                        label={'Name or Address'}
+                        value={query}
+                        onChange={(evt) => {
+                            setQuery(evt.target.value);
+                            setPage(0);
+                        }}
+                    />
+                    <TextField
+                        size={'small'}
+                        label={'Map'}
+                        value={mapName}
+                        onChange={(evt) => {
+                            setMapName(evt.target.value);
+                            setPage(0);
+                        }}
+                    />
+                    <FormControlLabel
+                        label={'Not Empty'}
+                        control={
+                            <Checkbox
+                                checked={notEmpty}
+                                onChange={(_, checked) => {
+                                    setNotEmpty(checked);
+                                    setPage(0);
+                                }}
+                            />
+                        }
+                    />
+                    <FormControlLabel
+                        label={'Not Full'}
+                        control={
+                            <Checkbox
+                                checked={notFull}
+                                onChange={(_, checked) => {
+                                    setNotFull(checked);
+                                    setPage(0);
+                                }}
+                            />
+                        }
+                    />
+                    <FormControlLabel
+                        label={'No Password'}
+                        control={
+                            <Checkbox
+                                checked={noPassword}
+                                onChange={(_, checked) => {
+                                    setNoPassword(checked);
+                                    setPage(0);
+                                }}
+                            />
+                        }
+                    />
+                </Stack>
+            </Grid>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Community Servers'}
+                    iconLeft={loading ? <LoadingIcon /> : <PublicIcon />}
+                >
+                    <TablePagination
+                        component="div"
+                        variant={'head'}
+                        page={page}
+                        count={totalRows}
+                        showFirstButton
+                        showLastButton
+                        rowsPerPage={rowPerPageCount}
+                        onRowsPerPageChange={(
+                            event: React.ChangeEvent<
+                                HTMLInputElement | HTMLTextAreaElement
+                            >
+                        ) => {
+                            setRowPerPageCount(
+                                parseInt(event.target.value, 10)
+                            );
+                            setPage(0);
+                        }}
+                        onPageChange={(_, newPage) => {
+                            setPage(newPage);
+                        }}
+                    />
+                    <LazyTable<CommunityServer>
+                        rows={rows}
+                        sortOrder={sortOrder}
+                        sortColumn={sortColumn}
+                        onSortColumnChanged={async (column) => {
+                            setSortColumn(column);
+                        }}
+                        onSortOrderChanged={async (direction) => {
+                            setSortOrder(direction);
+                        }}
+                        columns={[
+                            {
+                                label: 'Name',
+                                tooltip: 'Server name',
+                                sortKey: 'name',
+                                sortable: true,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Map',
+                                tooltip: 'Current map',
+                                sortKey: 'map',
+                                sortable: true,
+                                align: 'left'
+                            },
+                            {
+                                label: 'Players',
+                                tooltip: 'Players (bots)',
+                                sortKey: 'players',
+                                sortable: true,
+                                align: 'left',
+                                renderer: (row) => (
+                                    <Typography variant={'body1'}>
+                                        {row.players}/{row.max_players}
+                                        {row.bots > 0 ? ` (${row.bots})` : ''}
+                                    </Typography>
+                                )
+                            },
+                            {
+                                label: 'Connect',
+                                tooltip: 'Join the server',
+                                virtual: true,
+                                virtualKey: 'connect',
+                                align: 'right',
+                                renderer: (row) => (
+                                    <Button
+                                        variant={'contained'}
+                                        color={'success'}
+                                        href={`steam://connect/${row.addr}`}
+                                    >
+                                        Connect
+                                    </Button>
+                                )
+                            }
+                        ]}
+                    />
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
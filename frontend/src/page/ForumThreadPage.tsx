@@ -13,6 +13,7 @@ import Badge from '@mui/material/Badge';
 import Box from '@mui/material/Box';
 import Button from '@mui/material/Button';
 import ButtonGroup from '@mui/material/ButtonGroup';
+import Chip from '@mui/material/Chip';
 import Pagination from '@mui/material/Pagination';
 import Paper from '@mui/material/Paper';
 import Stack from '@mui/material/Stack';
@@ -212,6 +213,13 @@ const ThreadMessageContainer = ({
                                 activeMessage.permission_level
                             )}
                         </Typography>
+                        {activeMessage.forum_badge != '' && (
+                            <Chip
+                                label={activeMessage.forum_badge}
+                                color={'secondary'}
+                                size={'small'}
+                            />
+                        )}
                     </Stack>
                 </Grid>
                 <Grid xs={10}>
@@ -0,0 +1,174 @@
+import React, { useEffect, useMemo, useState } from 'react';
+import StreamIcon from '@mui/icons-material/Stream';
+import Checkbox from '@mui/material/Checkbox';
+import FormControlLabel from '@mui/material/FormControlLabel';
+import FormGroup from '@mui/material/FormGroup';
+import List from '@mui/material/List';
+import ListItem from '@mui/material/ListItem';
+import ListItemText from '@mui/material/ListItemText';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import {
+    apiGetServerStates,
+    apiStreamEvents,
+    BaseServer,
+    PermissionLevel,
+    StreamEvent,
+    StreamEventCategory
+} from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { useCurrentUserCtx } from '../contexts/CurrentUserCtx';
+import { logErr } from '../util/errors';
+
+const maxEvents = 200;
+
+const teamName = (team: number) => {
+    switch (team) {
+        case 2:
+            return 'RED';
+        case 3:
+            return 'BLU';
+        default:
+            return '';
+    }
+};
+
+const renderEvent = (evt: StreamEvent) => {
+    const e = evt.event;
+    switch (evt.category) {
+        case 'chat':
+            return `${e.name}${e.team ? ' (team)' : ''}: ${e.msg}`;
+        case 'kill':
+            return `${e.name} killed ${e.name2} with ${e.weapon}`;
+        case 'connect':
+            if (e.reason != undefined) {
+                return `${e.name} disconnected (${e.reason})`;
+            }
+            return `${e.name} ${e.address != undefined ? 'connected' : 'entered the game'}`;
+        case 'round':
+            return `Round won by ${teamName(e.winner)}`;
+        default:
+            return '';
+    }
+};
+
+export const LiveEventsPage = () => {
+    const { currentUser } = useCurrentUserCtx();
+    const [servers, setServers] = useState<BaseServer[]>([]);
+    const [serverIDs, setServerIDs] = useState<number[]>([]);
+    const [categories, setCategories] = useState<StreamEventCategory[]>([
+        'kill',
+        'connect',
+        'round'
+    ]);
+    const [events, setEvents] = useState<StreamEvent[]>([]);
+
+    const availableCategories = useMemo(() => {
+        const available: StreamEventCategory[] = ['kill', 'connect', 'round'];
+        if (currentUser.permission_level >= PermissionLevel.Moderator) {
+            available.unshift('chat');
+        }
+        return available;
+    }, [currentUser.permission_level]);
+
+    useEffect(() => {
+        const abortController = new AbortController();
+        apiGetServerStates(abortController)
+            .then((resp) => setServers(resp.servers))
+            .catch(logErr);
+
+        return () => abortController.abort();
+    }, []);
+
+    useEffect(() => {
+        if (categories.length == 0) {
+            return;
+        }
+        const abortController = new AbortController();
+        apiStreamEvents(
+            { server_ids: serverIDs, categories },
+            (evt) => {
+                setEvents((prev) => [evt, ...prev].slice(0, maxEvents));
+            },
+            abortController
+        ).catch((e) => {
+            if (!abortController.signal.aborted) {
+                logErr(e);
+            }
+        });
+
+        return () => abortController.abort();
+    }, [categories, serverIDs]);
+
+    const toggle = <T,>(values: T[], value: T) =>
+        values.includes(value)
+            ? values.filter((v) => v != value)
+            : [...values, value];
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={3}>
+                <ContainerWithHeader title={'Filters'}>
+                    <FormGroup>
+                        {availableCategories.map((category) => (
+                            <FormControlLabel
+                                key={`category-${category}`}
+                                label={category}
+                                control={
+                                    <Checkbox
+                                        checked={categories.includes(category)}
+                                        onChange={() =>
+                                            setCategories((prev) =>
+                                                toggle(prev, category)
+                                            )
+                                        }
+                                    />
+                                }
+                            />
+                        ))}
+                    </FormGroup>
+                    <Typography variant={'subtitle1'}>
+                        Servers (none selected = all)
+                    </Typography>
+                    <FormGroup>
+                        {servers.map((server) => (
+                            <FormControlLabel
+                                key={`server-${server.server_id}`}
+                                label={server.name_short}
+                                control={
+                                    <Checkbox
+                                        checked={serverIDs.includes(
+                                            server.server_id
+                                        )}
+                                        onChange={() =>
+                                            setServerIDs((prev) =>
+                                                toggle(prev, server.server_id)
+                                            )
+                                        }
+                                    />
+                                }
+                            />
+                        ))}
+                    </FormGroup>
+                </ContainerWithHeader>
+            </Grid>
+            <Grid xs={9}>
+                <ContainerWithHeader
+                    title={'Live Events'}
+                    iconLeft={<StreamIcon />}
+                >
+                    <List dense>
+                        {events.map((evt, idx) => (
+                            <ListItem key={`event-${events.length - idx}`}>
+                                <ListItemText
+                                    primary={renderEvent(evt)}
+                                    secondary={`${evt.server_name} - ${evt.category}`}
+                                />
+                            </ListItem>
+                        ))}
+                    </List>
+                </ContainerWithHeader>
+            </Grid>
+        </Grid>
+    );
+};
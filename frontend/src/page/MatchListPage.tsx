@@ -5,10 +5,18 @@ import CloseIcon from '@mui/icons-material/Close';
 import TimelineIcon from '@mui/icons-material/Timeline';
 import VisibilityIcon from '@mui/icons-material/Visibility';
 import { IconButton, TablePagination } from '@mui/material';
+import MenuItem from '@mui/material/MenuItem';
+import Select from '@mui/material/Select';
 import Tooltip from '@mui/material/Tooltip';
 import Typography from '@mui/material/Typography';
 import Grid from '@mui/material/Unstable_Grid2';
-import { apiGetMatches, MatchesQueryOpts, MatchSummary } from '../api';
+import {
+    apiGetMatches,
+    apiGetServerGroups,
+    MatchesQueryOpts,
+    MatchSummary,
+    ServerGroup
+} from '../api';
 import { ContainerWithHeader } from '../component/ContainerWithHeader';
 import { LazyTable, Order, RowsPerPage } from '../component/table/LazyTable';
 import { useCurrentUserCtx } from '../contexts/CurrentUserCtx';
@@ -30,14 +38,24 @@ const MatchSummaryTable = ({ steam_id }: MatchSummaryTableProps) => {
     const [rowPerPageCount, setRowPerPageCount] = useState<number>(
         RowsPerPage.TwentyFive
     );
+    const [groups, setGroups] = useState<ServerGroup[]>([]);
+    const [serverGroupID, setServerGroupID] = useState(0);
     const { sendFlash } = useUserFlashCtx();
     const { currentUser } = useCurrentUserCtx();
     const navigate = useNavigate();
 
+    useEffect(() => {
+        const abortController = new AbortController();
+        apiGetServerGroups(abortController).then(setGroups).catch(logErr);
+
+        return () => abortController.abort();
+    }, []);
+
     useEffect(() => {
         const abortController = new AbortController();
         const opts: MatchesQueryOpts = {
             steam_id: steam_id,
+            server_group_id: serverGroupID,
             limit: rowPerPageCount,
             offset: page * rowPerPageCount,
             order_by: sortColumn,
@@ -52,7 +70,7 @@ const MatchSummaryTable = ({ steam_id }: MatchSummaryTableProps) => {
                 logErr(e);
             });
         return () => abortController.abort();
-    }, [page, rowPerPageCount, sortColumn, sortOrder, steam_id]);
+    }, [page, rowPerPageCount, serverGroupID, sortColumn, sortOrder, steam_id]);
 
     if (currentUser.steam_id != steam_id) {
         sendFlash(
@@ -87,6 +105,28 @@ const MatchSummaryTable = ({ steam_id }: MatchSummaryTableProps) => {
                     }}
                 />
             </Grid>
+            {groups.length > 0 && (
+                <Grid xs={'auto'}>
+                    <Select
+                        size={'small'}
+                        value={serverGroupID}
+                        onChange={(evt) => {
+                            setServerGroupID(evt.target.value as number);
+                            setPage(0);
+                        }}
+                    >
+                        <MenuItem value={0}>All Servers</MenuItem>
+                        {groups.map((group) => (
+                            <MenuItem
+                                value={group.server_group_id}
+                                key={group.server_group_id}
+                            >
+                                @{group.name}
+                            </MenuItem>
+                        ))}
+                    </Select>
+                </Grid>
+            )}
             <Grid xs={12}>
                 <ContainerWithHeader
                     title={'Match History'}
@@ -10,12 +10,13 @@ import Link from '@mui/material/Link';
 import Stack from '@mui/material/Stack';
 import Typography from '@mui/material/Typography';
 import Grid from '@mui/material/Unstable_Grid2';
-import { apiGetPlayerWeaponsOverall } from '../api';
+import { apiGetPlayerWeaponsOverall, PermissionLevel } from '../api';
 import { ContainerWithHeader } from '../component/ContainerWithHeader';
 import { LoadingPlaceholder } from '../component/LoadingPlaceholder';
 import { PlayerClassStatsContainer } from '../component/PlayerClassStatsContainer';
 import { PlayerStatsOverallContainer } from '../component/PlayerStatsOverallContainer';
 import { ProfileInfoBox } from '../component/ProfileInfoBox';
+import { StacDetectionList } from '../component/StacDetectionList';
 import { SteamIDList } from '../component/SteamIDList';
 import { WeaponsStatListContainer } from '../component/WeaponsStatListContainer';
 import { useCurrentUserCtx } from '../contexts/CurrentUserCtx';
@@ -100,6 +101,11 @@ export const ProfilePage = () => {
             <Grid xs={6} md={2}>
                 <SteamIDList steam_id={data.player.steam_id} />
             </Grid>
+            {currentUser.permission_level >= PermissionLevel.Moderator && (
+                <Grid xs={12}>
+                    <StacDetectionList steam_id={data.player.steam_id} />
+                </Grid>
+            )}
             {!data.settings.stats_hidden && (
                 <>
                     <Grid xs={12}>
@@ -0,0 +1,178 @@
+import React, { useCallback, useState } from 'react';
+import PlayArrowIcon from '@mui/icons-material/PlayArrow';
+import SportsEsportsIcon from '@mui/icons-material/SportsEsports';
+import Button from '@mui/material/Button';
+import Checkbox from '@mui/material/Checkbox';
+import FormControlLabel from '@mui/material/FormControlLabel';
+import FormGroup from '@mui/material/FormGroup';
+import Stack from '@mui/material/Stack';
+import TextField from '@mui/material/TextField';
+import Typography from '@mui/material/Typography';
+import Grid from '@mui/material/Unstable_Grid2';
+import { apiQuickPlay, QuickPlayResult } from '../api';
+import { ContainerWithHeader } from '../component/ContainerWithHeader';
+import { LoadingIcon } from '../component/LoadingIcon';
+import { LazyTable } from '../component/table/LazyTable';
+import { useUserFlashCtx } from '../contexts/UserFlashCtx';
+
+const gameModes = [
+    { mode: 'pl', label: 'Payload' },
+    { mode: 'cp', label: 'Control Points' },
+    { mode: 'koth', label: 'King of the Hill' },
+    { mode: 'ctf', label: 'Capture the Flag' },
+    { mode: 'plr', label: 'Payload Race' },
+    { mode: 'pd', label: 'Player Destruction' }
+];
+
+export const QuickPlayPage = () => {
+    const { sendFlash } = useUserFlashCtx();
+    const [modes, setModes] = useState<string[]>([]);
+    const [maps, setMaps] = useState('');
+    const [results, setResults] = useState<QuickPlayResult[]>();
+    const [loading, setLoading] = useState(false);
+
+    const onSearch = useCallback(async () => {
+        setLoading(true);
+        try {
+            setResults(
+                await apiQuickPlay({
+                    game_modes: modes,
+                    maps: maps
+                        .split(',')
+                        .map((m) => m.trim())
+                        .filter((m) => m != '')
+                })
+            );
+        } catch (e) {
+            sendFlash('error', `Failed to find a server: ${e}`);
+        } finally {
+            setLoading(false);
+        }
+    }, [maps, modes, sendFlash]);
+
+    return (
+        <Grid container spacing={2}>
+            <Grid xs={12}>
+                <ContainerWithHeader
+                    title={'Quickplay'}
+                    iconLeft={loading ? <LoadingIcon /> : <SportsEsportsIcon />}
+                >
+                    <Stack spacing={2}>
+                        <FormGroup row>
+                            {gameModes.map((gm) => (
+                                <FormControlLabel
+                                    key={gm.mode}
+                                    label={gm.label}
+                                    control={
+                                        <Checkbox
+                                            checked={modes.includes(gm.mode)}
+                                            onChange={(_, checked) =>
+                                                setModes((prev) =>
+                                                    checked
+                                                        ? [...prev, gm.mode]
+                                                        : prev.filter(
+                                                              (m) =>
+                                                                  m != gm.mode
+                                                          )
+                                                )
+                                            }
+                                        />
+                                    }
+                                />
+                            ))}
+                        </FormGroup>
+                        <TextField
+                            fullWidth
+                            label={'Preferred maps (comma separated)'}
+                            value={maps}
+                            onChange={(evt) => setMaps(evt.target.value)}
+                        />
+                        <Stack direction={'row'}>
+                            <Button
+                                variant={'contained'}
+                                disabled={loading}
+                                onClick={onSearch}
+                            >
+                                Find Server
+                            </Button>
+                        </Stack>
+                    </Stack>
+                </ContainerWithHeader>
+            </Grid>
+            {results && (
+                <Grid xs={12}>
+                    <ContainerWithHeader
+                        title={'Best Matches'}
+                        iconLeft={<PlayArrowIcon />}
+                    >
+                        {results.length == 0 ? (
+                            <Typography variant={'body1'}>
+                                No servers match your preferences
+                            </Typography>
+                        ) : (
+                            <LazyTable<QuickPlayResult>
+                                rows={results}
+                                sortOrder={'desc'}
+                                sortColumn={'score'}
+                                onSortColumnChanged={() => {}}
+                                onSortOrderChanged={() => {}}
+                                columns={[
+                                    {
+                                        label: 'Server',
+                                        tooltip: 'Server',
+                                        sortKey: 'name',
+                                        align: 'left'
+                                    },
+                                    {
+                                        label: 'Map',
+                                        tooltip: 'Current map',
+                                        sortKey: 'map',
+                                        align: 'left'
+                                    },
+                                    {
+                                        label: 'Players',
+                                        tooltip: 'Players',
+                                        sortKey: 'players',
+                                        align: 'left',
+                                        renderer: (row) => (
+                                            <Typography variant={'body1'}>
+                                                {row.players}/{row.max_players}
+                                            </Typography>
+                                        )
+                                    },
+                                    {
+                                        label: 'Distance',
+                                        tooltip: 'Approximate distance',
+                                        sortKey: 'distance',
+                                        align: 'left',
+                                        renderer: (row) => (
+                                            <Typography variant={'body1'}>
+                                                {row.distance.toFixed(0)}km
+                                            </Typography>
+                                        )
+                                    },
+                                    {
+                                        label: 'Connect',
+                                        tooltip: 'Join the server',
+                                        virtual: true,
+                                        virtualKey: 'connect',
+                                        align: 'right',
+                                        renderer: (row) => (
+                                            <Button
+                                                variant={'contained'}
+                                                color={'success'}
+                                                href={row.connect_url}
+                                            >
+                                                Connect
+                                            </Button>
+                                        )
+                                    }
+                                ]}
+                            />
+                        )}
+                    </ContainerWithHeader>
+                </Grid>
+            )}
+        </Grid>
+    );
+};
@@ -1,5 +1,4 @@
-import React, { useState } from 'react';
-import { useTimer } from 'react-timer-hook';
+import React, { useEffect, useState } from 'react';
 import Box from '@mui/material/Box';
 import Container from '@mui/material/Container';
 import LinearProgress, {
@@ -11,11 +10,16 @@ import Typography from '@mui/material/Typography';
 import Grid from '@mui/material/Unstable_Grid2';
 import { LatLngLiteral } from 'leaflet';
 import { sum } from 'lodash-es';
-import { apiGetServerStates, BaseServer } from '../api';
+import {
+    apiStreamServerStates,
+    applyServerStateUpdate,
+    BaseServer
+} from '../api';
 import { ServerFilters } from '../component/ServerFilters';
 import { ServerList } from '../component/ServerList';
 import { ServerMap } from '../component/ServerMap';
 import { MapStateCtx, useMapStateCtx } from '../contexts/MapStateCtx';
+import { logErr } from '../util/errors';
 
 function LinearProgressWithLabel(
     props: LinearProgressProps & { value: number }
@@ -126,39 +130,49 @@ export const ServersPage = () => {
     const [showOpenOnly, setShowOpenOnly] = useState<boolean>(false);
     const [selectedRegion, setSelectedRegion] = useState<string>('any');
 
-    const interval = 5;
+    useEffect(() => {
+        const abortController = new AbortController();
+        const retryDelay = 5000;
 
-    const nextExpiry = () => {
-        const t0 = new Date();
-        t0.setSeconds(t0.getSeconds() + interval);
-        return t0;
-    };
-
-    const { restart } = useTimer({
-        autoStart: true,
-        expiryTimestamp: new Date(),
-        onExpire: () => {
-            apiGetServerStates()
-                .then((response) => {
-                    if (!response) {
-                        restart(nextExpiry());
+        const connect = async () => {
+            while (!abortController.signal.aborted) {
+                try {
+                    await apiStreamServerStates(
+                        (snapshot) => {
+                            setServers(snapshot.servers || []);
+                            setPos((prev) =>
+                                prev.lat == 0
+                                    ? {
+                                          lat: snapshot.lat_long.latitude,
+                                          lng: snapshot.lat_long.longitude
+                                      }
+                                    : prev
+                            );
+                        },
+                        (update) => {
+                            setServers((prev) =>
+                                applyServerStateUpdate(prev, update)
+                            );
+                        },
+                        abortController
+                    );
+                } catch (e) {
+                    if (abortController.signal.aborted) {
                         return;
                     }
-                    setServers(response.servers || []);
-                    if (pos.lat == 0) {
-                        setPos({
-                            lat: response.lat_long.latitude,
-                            lng: response.lat_long.longitude
-                        });
-                    }
+                    logErr(e);
+                    await new Promise((resolve) =>
+                        setTimeout(resolve, retryDelay)
+                    );
+                }
+            }
+        };
+
+        connect().catch(logErr);
+
+        return () => abortController.abort();
+    }, []);
 
-                    restart(nextExpiry());
-                })
-                .catch(() => {
-                    restart(nextExpiry());
-                });
-        }
-    });
     return (
         <MapStateCtx.Provider
             value={{
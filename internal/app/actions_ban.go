@@ -18,11 +18,24 @@ import (
 
 // BanSteam will ban the steam id from all servers. Players are immediately kicked from servers
 // once executed. If duration is 0, the value of config.DefaultExpiration() will be used.
+//
+// When ban approval is enabled, permanent bans are queued as a pending action and consts.ErrPendingApproval
+// is returned instead.
 func (app *App) BanSteam(ctx context.Context, banSteam *store.BanSteam) error {
 	if !banSteam.TargetID.Valid() {
 		return errors.Wrap(consts.ErrInvalidSID, "Invalid target steam id")
 	}
 
+	if app.requiresApproval(banSteam.Origin) && isPermanent(banSteam.ValidUntil) {
+		return app.queuePendingAction(ctx, store.PendingBanSteam, banSteam.SourceID, banSteam.TargetID,
+			banSteam.Reason.String(), banSteam)
+	}
+
+	return app.banSteam(ctx, banSteam)
+}
+
+func (app *App) banSteam(ctx context.Context, banSteam *store.BanSteam) error {
+
 	existing := store.NewBannedPerson()
 
 	errGetExistingBan := app.db.GetBanBySteamID(ctx, banSteam.TargetID, &existing, false)
@@ -121,6 +134,15 @@ func (app *App) BanSteam(ctx context.Context, banSteam *store.BanSteam) error {
 
 // BanASN will ban all network ranges associated with the requested ASN.
 func (app *App) BanASN(ctx context.Context, banASN *store.BanASN) error {
+	if app.requiresApproval(banASN.Origin) {
+		return app.queuePendingAction(ctx, store.PendingBanASN, banASN.SourceID, banASN.TargetID,
+			fmt.Sprintf("AS%d: %s", banASN.ASNum, banASN.Reason.String()), banASN)
+	}
+
+	return app.banASN(ctx, banASN)
+}
+
+func (app *App) banASN(ctx context.Context, banASN *store.BanASN) error {
 	var existing store.BanASN
 	if errGetExistingBan := app.db.GetBanASN(ctx, banASN.ASNum, &existing); errGetExistingBan != nil {
 		if !errors.Is(errGetExistingBan, store.ErrNoResult) {
@@ -140,6 +162,19 @@ func (app *App) BanASN(ctx context.Context, banASN *store.BanASN) error {
 // that fall within the range will be kicked immediately.
 // If duration is 0, the value of config.DefaultExpiration() will be used.
 func (app *App) BanCIDR(ctx context.Context, banNet *store.BanCIDR) error {
+	if app.requiresApproval(banNet.Origin) {
+		if banNet.CIDR == "" {
+			return errors.New("IP unset")
+		}
+
+		return app.queuePendingAction(ctx, store.PendingBanCIDR, banNet.SourceID, banNet.TargetID,
+			fmt.Sprintf("%s: %s", banNet.CIDR, banNet.Reason.String()), banNet)
+	}
+
+	return app.banCIDR(ctx, banNet)
+}
+
+func (app *App) banCIDR(ctx context.Context, banNet *store.BanCIDR) error {
 	// TODO
 	// _, err2 := store.GetBanNetByAddress(ctx, net.ParseIP(cidrStr))
 	// if err2 != nil && err2 != store.ErrNoResult {
@@ -213,7 +248,8 @@ func (app *App) BanSteamGroup(ctx context.Context, banGroup *store.BanGroup) err
 // Unban will set the current ban to now, making it expired.
 // Returns true, nil if the ban exists, and was successfully banned.
 // Returns false, nil if the ban does not exist.
-func (app *App) Unban(ctx context.Context, target steamid.SID64, reason string) (bool, error) {
+// Returns true, consts.ErrPendingApproval if a permanent ban removal was queued for approval.
+func (app *App) Unban(ctx context.Context, target steamid.SID64, author steamid.SID64, reason string) (bool, error) {
 	bannedPerson := store.NewBannedPerson()
 	errGetBan := app.db.GetBanBySteamID(ctx, target, &bannedPerson, false)
 
@@ -225,6 +261,17 @@ func (app *App) Unban(ctx context.Context, target steamid.SID64, reason string)
 		return false, errors.Wrapf(errGetBan, "Failed to get ban")
 	}
 
+	if app.requiresApproval(store.Web) && isPermanent(bannedPerson.ValidUntil) {
+		return true, app.queuePendingAction(ctx, store.PendingUnbanSteam, author, target, reason,
+			pendingUnban{TargetID: target, Reason: reason})
+	}
+
+	return app.unban(ctx, bannedPerson, reason)
+}
+
+func (app *App) unban(ctx context.Context, bannedPerson store.BannedSteamPerson, reason string) (bool, error) {
+	target := bannedPerson.TargetID
+
 	bannedPerson.Deleted = true
 	bannedPerson.UnbanReasonText = reason
 
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -54,6 +55,14 @@ type App struct {
 	activityMu           *sync.RWMutex
 	activity             []forumActivity
 	netBlock             *NetworkBlocker
+	suspicionChan        chan uuid.UUID
+	connTracker          *ConnectionTracker
+	botDetector          *BotDetector
+	eventHub             *EventHub
+	communityBrowser     *CommunityBrowser
+	reconnects           *ReconnectReservations
+	cvarCommands         fp.MutexMap[cvarCommandKey, bool]
+	latestPerformance    fp.MutexMap[int, store.ServerPerformanceSample]
 }
 
 func New(conf *Config, database *store.Store, bot *discord.Bot, logger *zap.Logger, assetStore AssetStore) App {
@@ -70,12 +79,20 @@ func New(conf *Config, database *store.Store, bot *discord.Bot, logger *zap.Logg
 		bannedGroupMembers:   map[int64]steamid.Collection{},
 		bannedGroupMembersMu: &sync.RWMutex{},
 		matchUUIDMap:         fp.NewMutexMap[int, uuid.UUID](),
-		patreon:              newPatreonManager(logger, conf, database),
+		patreon:              newPatreonManager(logger, conf, database, bot),
 		wordFilters:          newWordFilters(),
 		mc:                   newMetricCollector(),
 		state:                newServerStateCollector(logger),
 		activityMu:           &sync.RWMutex{},
 		netBlock:             NewNetworkBlocker(),
+		suspicionChan:        make(chan uuid.UUID, 10),
+		connTracker:          newMassConnectTracker(conf, logger),
+		botDetector:          newBotDetector(conf),
+		eventHub:             NewEventHub(),
+		communityBrowser:     NewCommunityBrowser(),
+		reconnects:           NewReconnectReservations(),
+		cvarCommands:         fp.NewMutexMap[cvarCommandKey, bool](),
+		latestPerformance:    fp.NewMutexMap[int, store.ServerPerformanceSample](),
 	}
 
 	if conf.Discord.Enabled {
@@ -206,10 +223,18 @@ func (app *App) Init(ctx context.Context) error {
 		app.log.Info("Loaded filter list", zap.Int("count", len(app.wordFilters.wordFilters)))
 	}
 
+	app.warnServerCredentialConflicts(ctx)
+
 	if errBlocklist := app.loadNetBlocks(ctx); errBlocklist != nil {
 		app.log.Error("Could not load CIDR block list", zap.Error(errBlocklist))
 	}
 
+	if app.conf.BotDefense.Enabled {
+		if errBotDefense := app.loadBotDefense(ctx); errBotDefense != nil {
+			app.log.Error("Could not load bot defense lists", zap.Error(errBotDefense))
+		}
+	}
+
 	return nil
 }
 
@@ -757,6 +782,19 @@ func (app *App) startWorkers(ctx context.Context) {
 	go app.demoCleaner(ctx)
 	go app.stateUpdater(ctx)
 	go app.forumActivityUpdater(ctx)
+	go app.suspicionAnalyzer(ctx)
+	go app.stacRecorder(ctx)
+	go app.botChatMonitor(ctx)
+	go app.botReporter(ctx)
+	go app.eventStreamer(ctx)
+	go app.serverHealthMonitor(ctx)
+	go app.rconTaskScheduler(ctx)
+	go app.cvarDriftChecker(ctx)
+	go app.mapRotator(ctx)
+	go app.communityBrowserUpdater(ctx)
+	go app.reconnectTracker(ctx)
+	go app.playerCountRecorder(ctx)
+	go app.serverPerformanceCollector(ctx)
 }
 
 // UDP log sink.
@@ -771,8 +809,7 @@ func (app *App) initLogSrc(ctx context.Context) {
 
 	app.logListener = logSrc
 
-	// TODO run on server config changes
-	go app.updateSrcdsLogSecrets(ctx)
+	go app.logSecretUpdater(ctx)
 
 	app.logListener.Start(ctx)
 }
@@ -793,13 +830,39 @@ func (app *App) updateSrcdsLogSecrets(ctx context.Context) {
 		return
 	}
 
+	serversByID := map[int]store.Server{}
+
 	for _, server := range servers {
+		serversByID[server.ServerID] = server
 		newSecrets[server.LogSecret] = logparse.ServerIDMap{
 			ServerID:   server.ServerID,
 			ServerName: server.ShortName,
 		}
 	}
 
+	// Previous log secrets are accepted until their overlap window ends, so the server can be updated after rotation.
+	credentials, errCredentials := app.db.GetValidServerCredentials(serversCtx, store.ServerCredentialLogSecret, time.Now())
+	if errCredentials != nil {
+		app.log.Error("Failed to load log secret credentials", zap.Error(errCredentials))
+	}
+
+	for _, credential := range credentials {
+		server, found := serversByID[credential.ServerID]
+		if !found {
+			continue
+		}
+
+		logSecret, errLogSecret := strconv.Atoi(credential.Secret)
+		if errLogSecret != nil {
+			continue
+		}
+
+		newSecrets[logSecret] = logparse.ServerIDMap{
+			ServerID:   server.ServerID,
+			ServerName: server.ShortName,
+		}
+	}
+
 	app.logListener.SetSecrets(newSecrets)
 }
 
@@ -186,10 +186,13 @@ func (app *App) Silence(ctx context.Context, _ store.Origin, target steamid.SID6
 	return nil
 }
 
-// Say is used to send a message to the server via sm_say.
+// Say is used to send a message to the server via sm_say. The server name may also be * for all
+// servers or an @group.
 func (app *App) Say(ctx context.Context, author steamid.SID64, serverName string, message string) error {
-	state := app.state.current()
-	servers := state.serverIDsByName(serverName, true)
+	servers, errServers := app.serverIDsByTarget(ctx, serverName)
+	if errServers != nil {
+		return errServers
+	}
 
 	if len(servers) == 0 {
 		return errUnknownServer
@@ -211,10 +214,13 @@ func (app *App) Say(ctx context.Context, author steamid.SID64, serverName string
 	return nil
 }
 
-// CSay is used to send a centered message to the server via sm_csay.
+// CSay is used to send a centered message to the server via sm_csay. The server name may also be * for
+// all servers or an @group.
 func (app *App) CSay(ctx context.Context, author steamid.SID64, serverName string, message string) error {
-	state := app.state.current()
-	servers := state.serverIDsByName(serverName, true)
+	servers, errServers := app.serverIDsByTarget(ctx, serverName)
+	if errServers != nil {
+		return errServers
+	}
 
 	if len(servers) == 0 {
 		return errUnknownServer
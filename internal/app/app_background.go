@@ -42,6 +42,10 @@ type activeMatchContext struct {
 	incomingEvents chan logparse.ServerEvent
 	log            *zap.Logger
 	finalScores    int
+	// lines holds the raw log lines of the match so they can be archived once it completes.
+	lines []string
+	// truncated is set once the match exceeds the configured line limit, the log is not archived in that case.
+	truncated bool
 }
 
 func (am *activeMatchContext) start(ctx context.Context) {
@@ -95,6 +99,15 @@ func (app *App) matchSummarizer(ctx context.Context) {
 				matches[evt.ServerID] = matchContext
 			}
 
+			if app.matchLogsEnabled() && !matchContext.truncated {
+				if len(matchContext.lines)+len(evt.Lines) > app.conf.MatchLogs.MaxLines {
+					matchContext.truncated = true
+					matchContext.lines = nil
+				} else {
+					matchContext.lines = append(matchContext.lines, evt.Lines...)
+				}
+			}
+
 			matchContext.incomingEvents <- evt
 
 			switch evt.EventType {
@@ -139,7 +152,12 @@ func (app *App) matchSummarizer(ctx context.Context) {
 					continue
 				}
 
+				if app.matchLogsEnabled() && !matchContext.truncated {
+					go app.archiveMatchLog(ctx, matchContext.match.MatchID, matchContext.lines)
+				}
+
 				app.onMatchComplete(ctx, matchContext.match.MatchID)
+				app.queueSuspicionAnalysis(matchContext.match.MatchID)
 
 				delete(matches, evt.ServerID)
 			}
@@ -494,6 +512,10 @@ func cleanupTasks(ctx context.Context, database *store.Store, logger *zap.Logger
 			if err := database.PrunePersonAuth(ctx); err != nil && !errors.Is(err, store.ErrNoResult) {
 				log.Error("Error pruning expired refresh tokens", zap.Error(err))
 			}
+
+			if err := database.DeleteExpiredServerTokens(ctx, time.Now()); err != nil && !errors.Is(err, store.ErrNoResult) {
+				log.Error("Error pruning expired server tokens", zap.Error(err))
+			}
 		case <-ctx.Done():
 			log.Debug("cleanupTasks shutting down")
 
@@ -700,7 +722,7 @@ func (app *App) banSweeper(ctx context.Context) {
 		select {
 		case <-ticker.C:
 			waitGroup := &sync.WaitGroup{}
-			waitGroup.Add(3)
+			waitGroup.Add(4)
 
 			go func() {
 				defer waitGroup.Done()
@@ -794,6 +816,14 @@ func (app *App) banSweeper(ctx context.Context) {
 				}
 			}()
 
+			go func() {
+				defer waitGroup.Done()
+
+				if errExpire := app.expirePendingActions(ctx); errExpire != nil && !errors.Is(errExpire, store.ErrNoResult) {
+					log.Error("Failed to expire pending actions", zap.Error(errExpire))
+				}
+			}()
+
 			waitGroup.Wait()
 		case <-ctx.Done():
 			log.Debug("banSweeper shutting down")
@@ -862,3 +892,73 @@ func (app *App) forumActivityUpdater(ctx context.Context) {
 		}
 	}
 }
+
+// stacRecorder persists StAC anti-cheat detections, linking them to the currently active match. Demos
+// which contain detections are archived so that they are not removed by demoCleaner.
+func (app *App) stacRecorder(ctx context.Context) {
+	var (
+		log             = app.log.Named("stacRecorder")
+		serverEventChan = make(chan logparse.ServerEvent)
+	)
+
+	if errRegister := app.eb.Consume(serverEventChan, logparse.StacDetection); errRegister != nil {
+		log.Warn("stacRecorder tried to register duplicate reader channel", zap.Error(errRegister))
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-serverEventChan:
+			newServerEvent, ok := evt.Event.(logparse.StacDetectionEvt)
+			if !ok || !newServerEvent.SID.Valid() {
+				continue
+			}
+
+			var person store.Person
+			if errPerson := app.db.GetOrCreatePersonBySteamID(ctx, newServerEvent.SID, &person); errPerson != nil {
+				log.Error("Failed to load stac detection person", zap.Error(errPerson))
+
+				continue
+			}
+
+			matchID, _ := app.matchUUIDMap.Get(evt.ServerID)
+
+			detection := store.StacDetection{
+				SteamID:   newServerEvent.SID,
+				ServerID:  evt.ServerID,
+				MatchID:   matchID,
+				DemoName:  newServerEvent.Demo,
+				DemoTick:  newServerEvent.Tick,
+				Detection: newServerEvent.Detection,
+				Count:     newServerEvent.Count,
+				CreatedOn: newServerEvent.CreatedOn,
+			}
+
+			if detection.DemoName == "" {
+				detection.DemoTick = -1
+			}
+
+			if errSave := app.db.SaveStacDetection(ctx, &detection); errSave != nil {
+				log.Error("Failed to save stac detection", zap.Error(errSave))
+
+				continue
+			}
+
+			// The demo is usually still being recorded and will be archived when uploaded, but handle
+			// the case where it already exists.
+			if detection.DemoName != "" {
+				if errArchive := app.db.ArchiveDemoByName(ctx, detection.DemoName); errArchive != nil {
+					log.Error("Failed to archive stac detection demo", zap.Error(errArchive))
+				}
+			}
+
+			log.Info("StAC detection recorded",
+				zap.Int64("steam_id", detection.SteamID.Int64()),
+				zap.String("detection", detection.Detection),
+				zap.String("server", evt.ServerName))
+		}
+	}
+}
@@ -54,6 +54,16 @@ func (app *App) registerDiscordHandlers() error {
 		}
 	}
 
+	componentMap := map[string]discord.ComponentHandler{
+		componentApprove: makeOnPendingAction(app, true),
+		componentReject:  makeOnPendingAction(app, false),
+	}
+	for k, v := range componentMap {
+		if errRegister := app.bot.RegisterComponentHandler(k, v); errRegister != nil {
+			return errors.Wrap(errRegister, "Failed to register discord component handler")
+		}
+	}
+
 	return nil
 }
 
@@ -512,8 +522,17 @@ func onUnbanSteam(ctx context.Context, app *App, _ *discordgo.Session, interacti
 		return nil, consts.ErrInvalidSID
 	}
 
-	found, errUnban := app.Unban(ctx, steamID, reason)
+	author, errAuthor := getDiscordAuthor(ctx, app.db, interaction)
+	if errAuthor != nil {
+		return nil, errAuthor
+	}
+
+	found, errUnban := app.Unban(ctx, steamID, author.SteamID, reason)
 	if errUnban != nil {
+		if errors.Is(errUnban, consts.ErrPendingApproval) {
+			return pendingApprovalEmbed(app), nil
+		}
+
 		return nil, errUnban
 	}
 
@@ -1548,6 +1567,10 @@ func makeOnMute(app *App) discord.CommandHandler {
 		}
 
 		if errBan := app.BanSteam(ctx, &banSteam); errBan != nil {
+			if errors.Is(errBan, consts.ErrPendingApproval) {
+				return pendingApprovalEmbed(app), nil
+			}
+
 			return nil, errBan
 		}
 
@@ -1614,6 +1637,10 @@ func onBanASN(ctx context.Context, app *App, _ *discordgo.Session,
 	}
 
 	if errBanASN := app.BanASN(ctx, &banASN); errBanASN != nil {
+		if errors.Is(errBanASN, consts.ErrPendingApproval) {
+			return pendingApprovalEmbed(app), nil
+		}
+
 		if errors.Is(errBanASN, store.ErrDuplicate) {
 			return nil, errors.New("Duplicate ASN ban")
 		}
@@ -1677,6 +1704,10 @@ func onBanIP(ctx context.Context, app *App, _ *discordgo.Session,
 	}
 
 	if errBanNet := app.BanCIDR(ctx, &banCIDR); errBanNet != nil {
+		if errors.Is(errBanNet, consts.ErrPendingApproval) {
+			return pendingApprovalEmbed(app), nil
+		}
+
 		return nil, errBanNet
 	}
 
@@ -1740,6 +1771,10 @@ func onBanSteam(ctx context.Context, app *App, _ *discordgo.Session,
 	}
 
 	if errBan := app.BanSteam(ctx, &banSteam); errBan != nil {
+		if errors.Is(errBan, consts.ErrPendingApproval) {
+			return pendingApprovalEmbed(app), nil
+		}
+
 		if errors.Is(errBan, store.ErrDuplicate) {
 			return nil, errors.New("Duplicate ban")
 		}
@@ -15,10 +15,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/gbans/internal/discord"
 	"github.com/leighmacdonald/gbans/internal/store"
 	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/pkg/errors"
@@ -44,6 +48,16 @@ func (s *MockAssetStore) Remove(_ context.Context, _ string, _ string) error {
 	return nil
 }
 
+func (s *MockAssetStore) Get(_ context.Context, bucket string, name string) (io.ReadCloser, error) {
+	for _, asset := range s.buckets[bucket] {
+		if asset.name == name {
+			return io.NopCloser(bytes.NewReader(asset.body)), nil
+		}
+	}
+
+	return nil, errors.New("Asset not found")
+}
+
 func (s *MockAssetStore) Put(_ context.Context, bucket string, name string, body io.Reader, size int64, contentType string) error {
 	_, ok := s.buckets[bucket]
 	if !ok {
@@ -113,7 +127,11 @@ func TestApp(t *testing.T) {
 		}
 	})
 
-	app := New(&config, database, nil, zap.NewNop(), &MockAssetStore{
+	// The bot is never started, so it drops any payloads sent to it.
+	bot, errBot := discord.New(zap.NewNop(), "", "", false, "")
+	require.NoError(t, errBot)
+
+	app := New(&config, database, bot, zap.NewNop(), &MockAssetStore{
 		map[string][]mockAsset{},
 	})
 
@@ -122,6 +140,7 @@ func TestApp(t *testing.T) {
 	t.Run("api_server", testServerAPI(&app))
 	t.Run("api_frontend", testFrontendAPI(&app))
 	t.Run("match_sum", testMatchSum(&app))
+	t.Run("approval", testApproval(&app))
 }
 
 func newTestReq(method string, route string, body any, token string) *http.Request {
@@ -148,7 +167,17 @@ func testServerAPI(app *App) func(t *testing.T) {
 		testServer := store.NewServer("test-1", "127.0.0.1", 27015)
 		testServer.Name = "Test Instance"
 		require.NoError(t, app.db.SaveServer(ctx, &testServer))
-		token, errToken := newServerToken(testServer.ServerID, app.conf.HTTP.CookieKey)
+		var credential store.ServerCredential
+		require.NoError(t, app.db.GetServerCredentialBySecret(ctx, store.ServerCredentialKey, testServer.Password, &credential))
+		serverToken := store.ServerToken{
+			ServerTokenID:      uuid.Must(uuid.NewV4()),
+			ServerID:           testServer.ServerID,
+			ServerCredentialID: credential.ServerCredentialID,
+			ExpiresOn:          time.Now().Add(authTokenDuration),
+			CreatedOn:          time.Now(),
+		}
+		require.NoError(t, app.db.SaveServerToken(ctx, &serverToken))
+		token, errToken := newServerToken(serverToken, credential)
 		require.NoError(t, errToken)
 		testBaddie := "76561197961279983"
 
@@ -368,6 +397,111 @@ func testMatchSum(_ *App) func(t *testing.T) {
 	}
 }
 
+func testApproval(app *App) func(t *testing.T) {
+	return func(t *testing.T) {
+		ctx := context.Background()
+
+		app.conf.BanApproval.Enabled = false
+		require.False(t, app.requiresApproval(store.Web))
+
+		app.conf.BanApproval.Enabled = true
+		defer func() {
+			app.conf.BanApproval.Enabled = false
+		}()
+
+		require.True(t, app.requiresApproval(store.Web))
+		require.True(t, app.requiresApproval(store.InGame))
+		require.False(t, app.requiresApproval(store.System))
+
+		var source, approver store.Person
+		require.NoError(t, app.db.GetOrCreatePersonBySteamID(ctx, app.conf.General.Owner, &source))
+		require.NoError(t, app.db.GetOrCreatePersonBySteamID(ctx, steamid.New(76561198003911389), &approver))
+
+		queueBan := func(cidr string) int64 {
+			var banCIDR store.BanCIDR
+			require.NoError(t, store.NewBanCIDR(ctx, store.StringSID(source.SteamID.String()),
+				"76561198044052046", time.Hour, store.Custom, "custom reason", "", store.Web,
+				cidr, store.Banned, &banCIDR))
+			require.ErrorIs(t, app.BanCIDR(ctx, &banCIDR), consts.ErrPendingApproval)
+
+			pending, _, errPending := app.db.GetPendingActions(ctx, store.PendingActionQueryFilter{
+				QueryFilter: store.QueryFilter{Desc: true, OrderBy: "pending_action_id"},
+				State:       store.PendingStateOpen,
+			})
+			require.NoError(t, errPending)
+			require.NotEmpty(t, pending)
+			require.Equal(t, store.PendingBanCIDR, pending[0].ActionType)
+			require.Equal(t, source.SteamID, pending[0].SourceID)
+
+			return pending[0].PendingActionID
+		}
+
+		t.Run("self_approval", func(t *testing.T) {
+			actionID := queueBan("10.10.10.0/24")
+
+			_, errApprove := app.ApprovePendingAction(ctx, actionID, source.SteamID)
+			require.ErrorIs(t, errApprove, errSelfApproval)
+
+			_, errReject := app.RejectPendingAction(ctx, actionID, source.SteamID, "")
+			require.ErrorIs(t, errReject, errSelfApproval)
+
+			_, errCleanup := app.RejectPendingAction(ctx, actionID, approver.SteamID, "")
+			require.NoError(t, errCleanup)
+		})
+
+		t.Run("approve", func(t *testing.T) {
+			actionID := queueBan("10.10.11.0/24")
+
+			action, errApprove := app.ApprovePendingAction(ctx, actionID, approver.SteamID)
+			require.NoError(t, errApprove)
+			require.Equal(t, store.PendingStateApproved, action.State)
+			require.Equal(t, approver.SteamID, action.ApproverID)
+
+			_, errAgain := app.ApprovePendingAction(ctx, actionID, approver.SteamID)
+			require.ErrorIs(t, errAgain, errActionNotPending)
+
+			_, errReject := app.RejectPendingAction(ctx, actionID, approver.SteamID, "")
+			require.ErrorIs(t, errReject, errActionNotPending)
+		})
+
+		t.Run("concurrent_approve", func(t *testing.T) {
+			actionID := queueBan("10.10.12.0/24")
+
+			var (
+				waitGroup sync.WaitGroup
+				results   = make(chan error, 2)
+			)
+
+			for i := 0; i < 2; i++ {
+				waitGroup.Add(1)
+
+				go func() {
+					defer waitGroup.Done()
+
+					_, errApprove := app.ApprovePendingAction(ctx, actionID, approver.SteamID)
+					results <- errApprove
+				}()
+			}
+
+			waitGroup.Wait()
+			close(results)
+
+			var succeeded, rejected int
+
+			for result := range results {
+				if result == nil {
+					succeeded++
+				} else if errors.Is(result, errActionNotPending) {
+					rejected++
+				}
+			}
+
+			require.Equal(t, 1, succeeded)
+			require.Equal(t, 1, rejected)
+		})
+	}
+}
+
 func testFrontendAPI(_ *App) func(t *testing.T) {
 	return func(t *testing.T) {
 	}
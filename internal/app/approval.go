@@ -0,0 +1,363 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	componentApprove = "approval_approve"
+	componentReject  = "approval_reject"
+)
+
+var (
+	errSelfApproval     = errors.New("Cannot approve or reject your own action")
+	errActionNotPending = errors.New("Action is no longer pending")
+)
+
+// pendingUnban is the payload stored for a queued unban request.
+type pendingUnban struct {
+	TargetID steamid.SID64 `json:"target_id"`
+	Reason   string        `json:"reason"`
+}
+
+// isPermanent uses the same heuristic as the discord embeds, anything further out than 5 years is
+// considered to be permanent.
+func isPermanent(validUntil time.Time) bool {
+	return validUntil.Year()-time.Now().Year() >= 5
+}
+
+// requiresApproval checks if actions from the origin should be routed through the approval queue. Automatic
+// system actions are never held for approval.
+func (app *App) requiresApproval(origin store.Origin) bool {
+	return app.conf.BanApproval.Enabled && origin != store.System
+}
+
+// queuePendingAction stores the action for later approval and notifies moderators. It always
+// returns consts.ErrPendingApproval on success so callers can inform the user.
+func (app *App) queuePendingAction(ctx context.Context, actionType store.PendingActionType,
+	sourceID steamid.SID64, targetID steamid.SID64, reason string, payload any,
+) error {
+	body, errMarshal := json.Marshal(payload)
+	if errMarshal != nil {
+		return errors.Wrap(errMarshal, "Failed to encode pending action")
+	}
+
+	if !targetID.Valid() {
+		targetID = steamid.New(0)
+	}
+
+	action := store.PendingAction{
+		ActionType: actionType,
+		State:      store.PendingStateOpen,
+		SourceID:   sourceID,
+		TargetID:   targetID,
+		Payload:    body,
+		Reason:     reason,
+		ExpiresOn:  time.Now().Add(app.conf.BanApproval.ExpiryValue),
+	}
+
+	if errSave := app.db.SavePendingAction(ctx, &action); errSave != nil {
+		return errors.Wrap(errSave, "Failed to save pending action")
+	}
+
+	app.addPendingActionEvent(ctx, action.PendingActionID, sourceID, store.PendingEventCreated, reason)
+
+	app.log.Info("Action queued for approval",
+		zap.Int64("pending_action_id", action.PendingActionID),
+		zap.String("type", action.ActionType.String()),
+		zap.Int64("source_id", sourceID.Int64()))
+
+	msgEmbed := discord.
+		NewEmbed(fmt.Sprintf("Approval Required: %s (#%d)", action.ActionType, action.PendingActionID)).
+		SetColor(app.bot.Colour.Warn).
+		SetURL(app.ExtURL(action)).
+		AddField("Reason", reason).
+		AddField("Expires At", FmtTimeShort(action.ExpiresOn))
+
+	app.addAuthor(ctx, msgEmbed, sourceID)
+
+	if targetID.Valid() {
+		app.addTarget(ctx, msgEmbed, targetID)
+	}
+
+	idStr := strconv.FormatInt(action.PendingActionID, 10)
+
+	app.bot.SendPayload(discord.Payload{
+		ChannelID: app.conf.Discord.LogChannelID,
+		Embed:     msgEmbed.Truncate().MessageEmbed,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Approve",
+					Style:    discordgo.SuccessButton,
+					CustomID: discord.ComponentID(componentApprove, idStr),
+				},
+				discordgo.Button{
+					Label:    "Reject",
+					Style:    discordgo.DangerButton,
+					CustomID: discord.ComponentID(componentReject, idStr),
+				},
+			}},
+		},
+	})
+
+	return consts.ErrPendingApproval
+}
+
+func (app *App) addPendingActionEvent(ctx context.Context, pendingActionID int64, steamID steamid.SID64,
+	eventType store.PendingActionEventType, note string,
+) {
+	event := store.PendingActionEvent{
+		PendingActionID: pendingActionID,
+		SteamID:         steamID,
+		Event:           eventType,
+		Note:            note,
+	}
+
+	if errEvent := app.db.AddPendingActionEvent(ctx, &event); errEvent != nil {
+		app.log.Error("Failed to record pending action event",
+			zap.Int64("pending_action_id", pendingActionID), zap.Error(errEvent))
+	}
+}
+
+// loadOpenPendingAction fetches the action and validates that it can still be acted upon by the actor.
+func (app *App) loadOpenPendingAction(ctx context.Context, pendingActionID int64, actor steamid.SID64,
+	action *store.PendingAction,
+) error {
+	if errGet := app.db.GetPendingAction(ctx, pendingActionID, action); errGet != nil {
+		return errors.Wrap(errGet, "Failed to load pending action")
+	}
+
+	if action.State != store.PendingStateOpen {
+		return errActionNotPending
+	}
+
+	if action.Expired() {
+		app.expirePendingAction(ctx, action)
+
+		return errActionNotPending
+	}
+
+	if action.SourceID == actor {
+		return errSelfApproval
+	}
+
+	return nil
+}
+
+// transitionPendingAction moves the action out of the from state. Only a single caller can succeed for each
+// state, so concurrent approvals from the web and discord cannot both execute the action.
+func (app *App) transitionPendingAction(ctx context.Context, action *store.PendingAction,
+	from store.PendingActionState,
+) error {
+	if errUpdate := app.db.TransitionPendingAction(ctx, action, from); errUpdate != nil {
+		if errors.Is(errUpdate, store.ErrNoResult) {
+			return errActionNotPending
+		}
+
+		return errors.Wrap(errUpdate, "Failed to update pending action")
+	}
+
+	return nil
+}
+
+// ApprovePendingAction marks the action as approved by the approver and executes the original action.
+func (app *App) ApprovePendingAction(ctx context.Context, pendingActionID int64, approver steamid.SID64) (store.PendingAction, error) {
+	var action store.PendingAction
+	if errLoad := app.loadOpenPendingAction(ctx, pendingActionID, approver, &action); errLoad != nil {
+		return action, errLoad
+	}
+
+	action.State = store.PendingStateApproved
+	action.ApproverID = approver
+
+	if errSave := app.transitionPendingAction(ctx, &action, store.PendingStateOpen); errSave != nil {
+		return action, errSave
+	}
+
+	app.addPendingActionEvent(ctx, action.PendingActionID, approver, store.PendingEventApproved, "")
+
+	if errExec := app.executePendingAction(ctx, action); errExec != nil {
+		action.State = store.PendingStateFailed
+		if errSave := app.transitionPendingAction(ctx, &action, store.PendingStateApproved); errSave != nil {
+			app.log.Error("Failed to update failed pending action", zap.Error(errSave))
+		}
+
+		app.addPendingActionEvent(ctx, action.PendingActionID, approver, store.PendingEventFailed, errExec.Error())
+
+		return action, errExec
+	}
+
+	app.addPendingActionEvent(ctx, action.PendingActionID, approver, store.PendingEventApplied, "")
+
+	app.log.Info("Pending action approved",
+		zap.Int64("pending_action_id", action.PendingActionID),
+		zap.String("type", action.ActionType.String()),
+		zap.Int64("approver_id", approver.Int64()))
+
+	return action, nil
+}
+
+// RejectPendingAction closes the action without executing it.
+func (app *App) RejectPendingAction(ctx context.Context, pendingActionID int64, actor steamid.SID64, note string) (store.PendingAction, error) {
+	var action store.PendingAction
+	if errLoad := app.loadOpenPendingAction(ctx, pendingActionID, actor, &action); errLoad != nil {
+		return action, errLoad
+	}
+
+	action.State = store.PendingStateRejected
+	action.ApproverID = actor
+
+	if errSave := app.transitionPendingAction(ctx, &action, store.PendingStateOpen); errSave != nil {
+		return action, errSave
+	}
+
+	app.addPendingActionEvent(ctx, action.PendingActionID, actor, store.PendingEventRejected, note)
+
+	app.log.Info("Pending action rejected",
+		zap.Int64("pending_action_id", action.PendingActionID),
+		zap.Int64("actor_id", actor.Int64()))
+
+	return action, nil
+}
+
+func (app *App) executePendingAction(ctx context.Context, action store.PendingAction) error {
+	switch action.ActionType {
+	case store.PendingBanSteam:
+		var banSteam store.BanSteam
+		if errDecode := json.Unmarshal(action.Payload, &banSteam); errDecode != nil {
+			return errors.Wrap(errDecode, "Failed to decode steam ban")
+		}
+
+		return app.banSteam(ctx, &banSteam)
+	case store.PendingBanASN:
+		var banASN store.BanASN
+		if errDecode := json.Unmarshal(action.Payload, &banASN); errDecode != nil {
+			return errors.Wrap(errDecode, "Failed to decode asn ban")
+		}
+
+		return app.banASN(ctx, &banASN)
+	case store.PendingBanCIDR:
+		var banCIDR store.BanCIDR
+		if errDecode := json.Unmarshal(action.Payload, &banCIDR); errDecode != nil {
+			return errors.Wrap(errDecode, "Failed to decode cidr ban")
+		}
+
+		return app.banCIDR(ctx, &banCIDR)
+	case store.PendingUnbanSteam:
+		var req pendingUnban
+		if errDecode := json.Unmarshal(action.Payload, &req); errDecode != nil {
+			return errors.Wrap(errDecode, "Failed to decode unban")
+		}
+
+		bannedPerson := store.NewBannedPerson()
+		if errGetBan := app.db.GetBanBySteamID(ctx, req.TargetID, &bannedPerson, false); errGetBan != nil {
+			return errors.Wrap(errGetBan, "Failed to load ban")
+		}
+
+		_, errUnban := app.unban(ctx, bannedPerson, req.Reason)
+
+		return errUnban
+	default:
+		return errors.Errorf("Unknown pending action type: %d", action.ActionType)
+	}
+}
+
+func (app *App) expirePendingAction(ctx context.Context, action *store.PendingAction) {
+	action.State = store.PendingStateExpired
+
+	if errSave := app.transitionPendingAction(ctx, action, store.PendingStateOpen); errSave != nil {
+		// Another moderator acted on it first, so there is nothing left to expire.
+		if !errors.Is(errSave, errActionNotPending) {
+			app.log.Error("Failed to expire pending action", zap.Error(errSave))
+		}
+
+		return
+	}
+
+	app.addPendingActionEvent(ctx, action.PendingActionID, app.conf.General.Owner, store.PendingEventExpired, "")
+
+	app.log.Info("Pending action expired", zap.Int64("pending_action_id", action.PendingActionID))
+}
+
+// expirePendingActions closes out any open pending actions that have exceeded their expiry.
+func (app *App) expirePendingActions(ctx context.Context) error {
+	expired, errExpired := app.db.GetExpiredPendingActions(ctx)
+	if errExpired != nil {
+		return errExpired
+	}
+
+	for _, expiredAction := range expired {
+		action := expiredAction
+		app.expirePendingAction(ctx, &action)
+	}
+
+	return nil
+}
+
+// pendingApprovalEmbed is returned to discord users when their action was queued instead of being applied.
+func pendingApprovalEmbed(app *App) *discordgo.MessageEmbed {
+	return discord.
+		NewEmbed("Pending Approval", "This action requires approval from another moderator before taking effect").
+		SetColor(app.bot.Colour.Warn).
+		SetURL(app.ExtURLRaw("/admin/approvals")).
+		MessageEmbed
+}
+
+func makeOnPendingAction(app *App, approve bool) discord.ComponentHandler {
+	return func(ctx context.Context, _ *discordgo.Session, interaction *discordgo.InteractionCreate, arg string) (*discordgo.MessageEmbed, error) {
+		pendingActionID, errID := strconv.ParseInt(arg, 10, 64)
+		if errID != nil {
+			return nil, consts.ErrInvalidParameter
+		}
+
+		author, errAuthor := getDiscordAuthor(ctx, app.db, interaction)
+		if errAuthor != nil {
+			return nil, errAuthor
+		}
+
+		if author.PermissionLevel < consts.PModerator {
+			return nil, consts.ErrPermissionDenied
+		}
+
+		var (
+			action    store.PendingAction
+			errAction error
+			title     = "Action Approved"
+			colour    = app.bot.Colour.Success
+		)
+
+		if approve {
+			action, errAction = app.ApprovePendingAction(ctx, pendingActionID, author.SteamID)
+		} else {
+			action, errAction = app.RejectPendingAction(ctx, pendingActionID, author.SteamID, "")
+			title = "Action Rejected"
+			colour = app.bot.Colour.Warn
+		}
+
+		if errAction != nil {
+			return nil, errAction
+		}
+
+		msgEmbed := discord.
+			NewEmbed(fmt.Sprintf("%s: %s (#%d)", title, action.ActionType, action.PendingActionID)).
+			SetColor(colour).
+			SetURL(app.ExtURL(action))
+
+		app.addAuthorPerson(msgEmbed, author)
+
+		return msgEmbed.Truncate().MessageEmbed, nil
+	}
+}
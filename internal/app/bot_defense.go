@@ -0,0 +1,265 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const botReportPeriod = time.Hour * 24 * 7
+
+func newBotDetector(conf *Config) *BotDetector {
+	detector := NewBotDetector(conf.BotDefense.WaveThreshold, conf.BotDefense.WaveWindowValue,
+		conf.BotDefense.ChatThreshold, conf.BotDefense.ChatWindowValue)
+
+	detector.SetChatFilter(conf.BotDefense.ChatMinLength, conf.BotDefense.ChatIgnore)
+
+	return detector
+}
+
+// loadBotDefense loads the known bot name patterns and steam ids into memory.
+func (app *App) loadBotDefense(ctx context.Context) error {
+	patterns, errPatterns := app.db.GetBotNamePatterns(ctx)
+	if errPatterns != nil {
+		return errors.Wrap(errPatterns, "Failed to load bot name patterns")
+	}
+
+	for _, invalid := range app.botDetector.SetNamePatterns(patterns) {
+		app.log.Warn("Invalid bot name pattern", zap.String("pattern", invalid))
+	}
+
+	steamIDs, errSteamIDs := app.db.GetBotSteamIDs(ctx)
+	if errSteamIDs != nil {
+		return errors.Wrap(errSteamIDs, "Failed to load bot steam ids")
+	}
+
+	app.botDetector.AddSteamIDs(steamIDs)
+
+	app.log.Info("Loaded bot defense lists",
+		zap.Int("patterns", len(patterns)), zap.Int("steam_ids", len(steamIDs)))
+
+	return nil
+}
+
+// checkBot determines if a connecting player is a bot. When a wave of suspicious accounts is detected, the
+// other accounts in the wave which are already connected are kicked.
+func (app *App) checkBot(ctx context.Context, person store.Person, name string, serverID int) (store.BotDetectionMethod, string, bool) {
+	if !app.conf.BotDefense.Enabled || person.PermissionLevel > consts.PUser {
+		return 0, "", false
+	}
+
+	if app.botDetector.IsListed(person.SteamID) {
+		return store.BotMethodSteamList, "", true
+	}
+
+	if pattern, matched := app.botDetector.MatchName(name); matched {
+		return store.BotMethodName, pattern, true
+	}
+
+	if !app.isFreshAccount(ctx, person) {
+		return 0, "", false
+	}
+
+	wave := app.botDetector.AddSuspect(serverID, person.SteamID, time.Now())
+	if len(wave) == 0 {
+		return 0, "", false
+	}
+
+	detail := fmt.Sprintf("%d fresh accounts within %s", len(wave), app.conf.BotDefense.WaveWindow)
+
+	var others steamid.Collection
+
+	for _, steamID := range wave {
+		if steamID != person.SteamID {
+			others = append(others, steamID)
+		}
+	}
+
+	go app.kickBots(context.Background(), serverID, others, store.BotMethodWave, detail)
+
+	return store.BotMethodWave, detail, true
+}
+
+// isFreshAccount checks if the account is either newly created, or has a private creation date, and has
+// never played a match on our servers.
+func (app *App) isFreshAccount(ctx context.Context, person store.Person) bool {
+	if person.PlayerSummary != nil && person.TimeCreated > 0 &&
+		time.Since(time.Unix(int64(person.TimeCreated), 0)) > app.conf.BotDefense.FreshAccountAgeValue {
+		return false
+	}
+
+	matches, errMatches := app.db.GetPlayerMatchCount(ctx, person.SteamID)
+	if errMatches != nil {
+		app.log.Error("Failed to get player match count", zap.Error(errMatches))
+
+		return false
+	}
+
+	return matches == 0
+}
+
+func (app *App) recordBotDetection(ctx context.Context, detection store.BotDetection) {
+	if errSave := app.db.SaveBotDetection(ctx, &detection); errSave != nil {
+		app.log.Error("Failed to save bot detection", zap.Error(errSave))
+
+		return
+	}
+
+	app.log.Info("Bot blocked", zap.Int64("steam_id", detection.SteamID.Int64()),
+		zap.Int("server_id", detection.ServerID), zap.String("method", detection.Method.String()),
+		zap.String("detail", detection.Detail))
+}
+
+// kickBots records and kicks any of the accounts that are currently connected.
+func (app *App) kickBots(ctx context.Context, serverID int, steamIDs steamid.Collection, method store.BotDetectionMethod, detail string) {
+	for _, steamID := range steamIDs {
+		var person store.Person
+		if errPerson := app.PersonBySID(ctx, steamID, &person); errPerson != nil {
+			app.log.Error("Failed to load bot person", zap.Error(errPerson))
+
+			continue
+		}
+
+		if person.PermissionLevel > consts.PUser {
+			continue
+		}
+
+		app.recordBotDetection(ctx, store.BotDetection{
+			SteamID:  steamID,
+			ServerID: serverID,
+			Name:     person.PersonaName,
+			Method:   method,
+			Detail:   detail,
+		})
+
+		if errKick := app.Kick(ctx, store.System, steamID, app.conf.General.Owner, store.BotHost); errKick != nil &&
+			!errors.Is(errKick, consts.ErrPlayerNotFound) {
+			app.log.Error("Failed to kick bot", zap.Error(errKick))
+		}
+	}
+}
+
+// botChatMonitor watches for multiple accounts sending identical chat messages.
+func (app *App) botChatMonitor(ctx context.Context) {
+	if !app.conf.BotDefense.Enabled {
+		return
+	}
+
+	var (
+		log             = app.log.Named("botChatMonitor")
+		serverEventChan = make(chan logparse.ServerEvent)
+	)
+
+	if errRegister := app.eb.Consume(serverEventChan, logparse.Say, logparse.SayTeam); errRegister != nil {
+		log.Warn("botChatMonitor tried to register duplicate reader channel", zap.Error(errRegister))
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-serverEventChan:
+			newServerEvent, ok := evt.Event.(logparse.SayEvt)
+			if !ok || !newServerEvent.SID.Valid() || !app.botDetector.ChatCandidate(newServerEvent.Msg) {
+				continue
+			}
+
+			var person store.Person
+			if errPerson := app.PersonBySID(ctx, newServerEvent.SID, &person); errPerson != nil {
+				log.Error("Failed to load chat person", zap.Error(errPerson))
+
+				continue
+			}
+
+			fresh := person.PermissionLevel <= consts.PUser && app.isFreshAccount(ctx, person)
+
+			spammers := app.botDetector.AddChat(evt.ServerID, newServerEvent.SID, fresh, newServerEvent.Msg, newServerEvent.CreatedOn)
+			if len(spammers) == 0 {
+				continue
+			}
+
+			app.kickBots(ctx, evt.ServerID, spammers, store.BotMethodChatSpam, newServerEvent.Msg)
+		}
+	}
+}
+
+// botReporter sends a summary of the blocked bots for each server at the start of each week.
+func (app *App) botReporter(ctx context.Context) {
+	if !app.conf.BotDefense.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			if now.Weekday() != time.Monday || now.Hour() != 0 {
+				continue
+			}
+
+			if errReport := app.sendBotReport(ctx, now.Add(-botReportPeriod)); errReport != nil {
+				app.log.Error("Failed to send bot report", zap.Error(errReport))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (app *App) sendBotReport(ctx context.Context, since time.Time) error {
+	entries, errReport := app.db.GetBotDetectionReport(ctx, since)
+	if errReport != nil {
+		return errors.Wrap(errReport, "Failed to load bot report")
+	}
+
+	channelID := app.conf.BotDefense.ReportChannelID
+	if channelID == "" {
+		channelID = app.conf.Discord.LogChannelID
+	}
+
+	var (
+		servers = map[string][]string{}
+		names   []string
+		total   int64
+	)
+
+	for _, entry := range entries {
+		if _, found := servers[entry.ServerName]; !found {
+			names = append(names, entry.ServerName)
+		}
+
+		servers[entry.ServerName] = append(servers[entry.ServerName],
+			fmt.Sprintf("%s: %d (%d accounts)", entry.Method.String(), entry.Detections, entry.Accounts))
+		total += entry.Detections
+	}
+
+	msgEmbed := discord.
+		NewEmbed("Weekly Bot Report").
+		SetColor(app.bot.Colour.Info).
+		SetDescription(fmt.Sprintf("%d bots blocked since %s", total, since.Format(time.DateOnly)))
+
+	for _, name := range names {
+		msgEmbed.AddField(name, strings.Join(servers[name], "\n"))
+	}
+
+	app.bot.SendPayload(discord.Payload{
+		ChannelID: channelID,
+		Embed:     msgEmbed.Truncate().MessageEmbed,
+	})
+
+	return nil
+}
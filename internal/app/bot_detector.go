@@ -0,0 +1,247 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+)
+
+type botActivity struct {
+	steamID   steamid.SID64
+	createdOn time.Time
+}
+
+type botNameMatcher struct {
+	pattern string
+	rx      *regexp.Regexp
+}
+
+// BotDetector matches connecting players against known bot name patterns and steam ids, and tracks
+// behaviour commonly seen from bot hosts: fresh accounts joining a server in waves and multiple
+// accounts spamming identical chat messages.
+type BotDetector struct {
+	names         []botNameMatcher
+	steamIDs      map[steamid.SID64]bool
+	waveThreshold int
+	waveWindow    time.Duration
+	waves         map[int][]botActivity
+	chatThreshold int
+	chatWindow    time.Duration
+	chat          map[int]map[string][]botActivity
+	chatMinLength int
+	chatIgnored   map[string]bool
+	sync.RWMutex
+}
+
+func NewBotDetector(waveThreshold int, waveWindow time.Duration, chatThreshold int, chatWindow time.Duration) *BotDetector {
+	return &BotDetector{
+		steamIDs:      map[steamid.SID64]bool{},
+		waveThreshold: waveThreshold,
+		waveWindow:    waveWindow,
+		waves:         map[int][]botActivity{},
+		chatThreshold: chatThreshold,
+		chatWindow:    chatWindow,
+		chat:          map[int]map[string][]botActivity{},
+		chatIgnored:   map[string]bool{},
+	}
+}
+
+// SetChatFilter sets which messages are considered by the chat spam detection. Messages shorter than minLength,
+// chat commands and any of the ignored messages are common between real players and are never counted.
+func (d *BotDetector) SetChatFilter(minLength int, ignored []string) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.chatMinLength = minLength
+	d.chatIgnored = map[string]bool{}
+
+	for _, message := range ignored {
+		d.chatIgnored[normalizeBotChat(message)] = true
+	}
+}
+
+func normalizeBotChat(message string) string {
+	return strings.ToLower(strings.TrimSpace(message))
+}
+
+// ChatCandidate checks if the message should be considered by the chat spam detection.
+func (d *BotDetector) ChatCandidate(message string) bool {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.chatCandidate(normalizeBotChat(message))
+}
+
+func (d *BotDetector) chatCandidate(message string) bool {
+	if d.chatThreshold <= 0 || message == "" || len([]rune(message)) < d.chatMinLength {
+		return false
+	}
+
+	if strings.HasPrefix(message, "!") || strings.HasPrefix(message, "/") {
+		return false
+	}
+
+	return !d.chatIgnored[message]
+}
+
+// SetNamePatterns replaces the current set of name patterns. Patterns which fail to compile are returned
+// and otherwise ignored.
+func (d *BotDetector) SetNamePatterns(patterns []store.BotNamePattern) []string {
+	var (
+		invalid []string
+		names   = make([]botNameMatcher, 0, len(patterns))
+	)
+
+	for _, pattern := range patterns {
+		rx, errCompile := regexp.Compile(pattern.Pattern)
+		if errCompile != nil {
+			invalid = append(invalid, pattern.Pattern)
+
+			continue
+		}
+
+		names = append(names, botNameMatcher{pattern: pattern.Pattern, rx: rx})
+	}
+
+	d.Lock()
+	d.names = names
+	d.Unlock()
+
+	return invalid
+}
+
+// AddSteamIDs adds known bot accounts to the block list.
+func (d *BotDetector) AddSteamIDs(steamIDs steamid.Collection) {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, steamID := range steamIDs {
+		d.steamIDs[steamID] = true
+	}
+}
+
+func (d *BotDetector) RemoveSteamID(steamID steamid.SID64) {
+	d.Lock()
+	defer d.Unlock()
+
+	delete(d.steamIDs, steamID)
+}
+
+// MatchName checks the name against the known bot name patterns, returning the matched pattern.
+func (d *BotDetector) MatchName(name string) (string, bool) {
+	d.RLock()
+	defer d.RUnlock()
+
+	for _, matcher := range d.names {
+		if matcher.rx.MatchString(name) {
+			return matcher.pattern, true
+		}
+	}
+
+	return "", false
+}
+
+func (d *BotDetector) IsListed(steamID steamid.SID64) bool {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.steamIDs[steamID]
+}
+
+// AddSuspect records a connection from a suspicious, fresh, account. Once the threshold of distinct
+// suspects connect to the same server within the window, all the accounts in the wave are returned.
+func (d *BotDetector) AddSuspect(serverID int, steamID steamid.SID64, now time.Time) steamid.Collection {
+	if d.waveThreshold <= 0 {
+		return nil
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	wave := append(expireBotActivity(d.waves[serverID], now, d.waveWindow), botActivity{steamID: steamID, createdOn: now})
+
+	members := uniqueBotAccounts(wave)
+	if len(members) < d.waveThreshold {
+		d.waves[serverID] = wave
+
+		return nil
+	}
+
+	// Members have been handled, so start a new wave.
+	delete(d.waves, serverID)
+
+	return members
+}
+
+// AddChat records a chat message, returning the accounts involved once the same message has been sent
+// by the threshold of distinct accounts on a server within the window. Only messages from fresh accounts are
+// counted, established players repeating each other is normal.
+func (d *BotDetector) AddChat(serverID int, steamID steamid.SID64, fresh bool, message string, now time.Time) steamid.Collection {
+	if !fresh {
+		return nil
+	}
+
+	message = normalizeBotChat(message)
+
+	d.Lock()
+	defer d.Unlock()
+
+	if !d.chatCandidate(message) {
+		return nil
+	}
+
+	serverChat, found := d.chat[serverID]
+	if !found {
+		serverChat = map[string][]botActivity{}
+		d.chat[serverID] = serverChat
+	}
+
+	for msg, activity := range serverChat {
+		if valid := expireBotActivity(activity, now, d.chatWindow); len(valid) > 0 {
+			serverChat[msg] = valid
+		} else {
+			delete(serverChat, msg)
+		}
+	}
+
+	activity := append(serverChat[message], botActivity{steamID: steamID, createdOn: now})
+
+	members := uniqueBotAccounts(activity)
+	if len(members) < d.chatThreshold {
+		serverChat[message] = activity
+
+		return nil
+	}
+
+	delete(serverChat, message)
+
+	return members
+}
+
+func expireBotActivity(activity []botActivity, now time.Time, window time.Duration) []botActivity {
+	var valid []botActivity
+
+	for _, act := range activity {
+		if now.Sub(act.createdOn) < window {
+			valid = append(valid, act)
+		}
+	}
+
+	return valid
+}
+
+func uniqueBotAccounts(activity []botActivity) steamid.Collection {
+	var accounts steamid.Collection
+
+	for _, act := range activity {
+		if !accounts.Contains(act.steamID) {
+			accounts = append(accounts, act.steamID)
+		}
+	}
+
+	return accounts
+}
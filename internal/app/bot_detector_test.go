@@ -0,0 +1,90 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotDetector(t *testing.T) {
+	var (
+		detector = app.NewBotDetector(3, time.Minute, 2, time.Second*30)
+		now      = time.Now()
+		sid1     = steamid.New(76561198084134025)
+		sid2     = steamid.New(76561197961279983)
+		sid3     = steamid.New(76561197960265828)
+	)
+
+	invalid := detector.SetNamePatterns([]store.BotNamePattern{
+		{Pattern: `^(?i)mega\s?bot\d*$`},
+		{Pattern: `[invalid`},
+	})
+	require.Equal(t, []string{"[invalid"}, invalid)
+
+	pattern, matched := detector.MatchName("MegaBot42")
+	require.True(t, matched)
+	require.Equal(t, `^(?i)mega\s?bot\d*$`, pattern)
+
+	_, notMatched := detector.MatchName("a real player")
+	require.False(t, notMatched)
+
+	detector.AddSteamIDs(steamid.Collection{sid1})
+	require.True(t, detector.IsListed(sid1))
+	require.False(t, detector.IsListed(sid2))
+	detector.RemoveSteamID(sid1)
+	require.False(t, detector.IsListed(sid1))
+
+	require.Empty(t, detector.AddSuspect(1, sid1, now))
+	require.Empty(t, detector.AddSuspect(1, sid1, now.Add(time.Second)))
+	// Suspects on other servers are tracked separately
+	require.Empty(t, detector.AddSuspect(2, sid2, now.Add(time.Second)))
+	require.Empty(t, detector.AddSuspect(1, sid2, now.Add(time.Second*2)))
+	require.ElementsMatch(t, steamid.Collection{sid1, sid2, sid3}, detector.AddSuspect(1, sid3, now.Add(time.Second*3)))
+	// Expired suspects no longer count towards a wave
+	require.Empty(t, detector.AddSuspect(2, sid1, now.Add(time.Minute*2)))
+
+	require.Empty(t, detector.AddChat(1, sid1, true, "Join our discord!", now))
+	require.Empty(t, detector.AddChat(1, sid1, true, "join our discord!", now))
+	require.Empty(t, detector.AddChat(1, sid2, true, "something else", now))
+	require.ElementsMatch(t, steamid.Collection{sid1, sid2}, detector.AddChat(1, sid2, true, " JOIN OUR DISCORD! ", now))
+	require.Empty(t, detector.AddChat(1, sid3, true, "join our discord!", now.Add(time.Minute)))
+}
+
+func TestBotDetectorChatFilter(t *testing.T) {
+	var (
+		detector = app.NewBotDetector(3, time.Minute, 2, time.Second*30)
+		now      = time.Now()
+		sid1     = steamid.New(76561198084134025)
+		sid2     = steamid.New(76561197961279983)
+		sid3     = steamid.New(76561197960265828)
+	)
+
+	detector.SetChatFilter(4, []string{"rtv", "Nice Shot"})
+
+	// Established accounts agreeing with each other is normal
+	for _, sid := range []steamid.SID64{sid1, sid2, sid3} {
+		require.Empty(t, detector.AddChat(1, sid, false, "gg", now))
+		require.Empty(t, detector.AddChat(1, sid, false, "join our discord!", now))
+	}
+
+	// Short, ignored and command messages are not counted even for fresh accounts
+	for _, sid := range []steamid.SID64{sid1, sid2, sid3} {
+		require.Empty(t, detector.AddChat(1, sid, true, "gg", now))
+		require.Empty(t, detector.AddChat(1, sid, true, "NICE SHOT", now))
+		require.Empty(t, detector.AddChat(1, sid, true, "!rtv please", now))
+		require.Empty(t, detector.AddChat(1, sid, true, "/nominate pl_upward", now))
+	}
+
+	require.False(t, detector.ChatCandidate("gg"))
+	require.False(t, detector.ChatCandidate(" rtv "))
+	require.True(t, detector.ChatCandidate("join our discord!"))
+
+	// Established accounts do not contribute towards fresh accounts being flagged either
+	require.Empty(t, detector.AddChat(1, sid1, false, "join our discord!", now))
+	require.Empty(t, detector.AddChat(1, sid2, true, "join our discord!", now))
+	require.ElementsMatch(t, steamid.Collection{sid2, sid3}, detector.AddChat(1, sid3, true, "join our discord!", now))
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/a2s"
+	"github.com/leighmacdonald/gbans/pkg/msqp"
+	"go.uber.org/zap"
+)
+
+// communityQueryTimeout is how long to wait for each server to respond to the A2S query.
+const communityQueryTimeout = time.Second * 2
+
+// CommunityServer is a server discovered from the master server list.
+type CommunityServer struct {
+	Addr       string    `json:"addr"`
+	Name       string    `json:"name"`
+	Map        string    `json:"map"`
+	Game       string    `json:"game"`
+	Version    string    `json:"version"`
+	AppID      uint16    `json:"app_id"`
+	Players    int       `json:"players"`
+	MaxPlayers int       `json:"max_players"`
+	Bots       int       `json:"bots"`
+	Password   bool      `json:"password"`
+	VAC        bool      `json:"vac"`
+	Keywords   []string  `json:"keywords"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// CommunityServerQuery filters the community servers. Query matches against the name and address.
+type CommunityServerQuery struct {
+	store.QueryFilter
+	Map        string   `json:"map"`
+	Tags       []string `json:"tags"`
+	NotEmpty   bool     `json:"not_empty"`
+	NotFull    bool     `json:"not_full"`
+	NoPassword bool     `json:"no_password"`
+}
+
+func (q CommunityServerQuery) matches(server CommunityServer) bool {
+	if q.Query != "" {
+		query := strings.ToLower(q.Query)
+		if !strings.Contains(strings.ToLower(server.Name), query) && !strings.Contains(server.Addr, query) {
+			return false
+		}
+	}
+
+	if q.Map != "" && !strings.Contains(strings.ToLower(server.Map), strings.ToLower(q.Map)) {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !containsString(server.Keywords, strings.ToLower(tag)) {
+			return false
+		}
+	}
+
+	humans := server.Players - server.Bots
+
+	if q.NotEmpty && humans <= 0 {
+		return false
+	}
+
+	if q.NotFull && server.Players >= server.MaxPlayers {
+		return false
+	}
+
+	return !q.NoPassword || !server.Password
+}
+
+// CommunityBrowser is an index of the most recently seen community servers.
+type CommunityBrowser struct {
+	mu      *sync.RWMutex
+	servers map[string]CommunityServer
+}
+
+func NewCommunityBrowser() *CommunityBrowser {
+	return &CommunityBrowser{
+		mu:      &sync.RWMutex{},
+		servers: map[string]CommunityServer{},
+	}
+}
+
+// Update merges the servers into the index, removing any which have not been seen since expireAfter.
+func (b *CommunityBrowser) Update(servers []CommunityServer, now time.Time, expireAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, server := range servers {
+		server.LastSeen = now
+		b.servers[server.Addr] = server
+	}
+
+	for addr, server := range b.servers {
+		if now.Sub(server.LastSeen) > expireAfter {
+			delete(b.servers, addr)
+		}
+	}
+}
+
+// Search returns a page of the servers matching the query along with the total number of matches. Results are
+// sorted by player count unless ordered by name or map.
+func (b *CommunityBrowser) Search(query CommunityServerQuery) ([]CommunityServer, int64) {
+	b.mu.RLock()
+
+	var results []CommunityServer
+
+	for _, server := range b.servers {
+		if query.matches(server) {
+			results = append(results, server)
+		}
+	}
+
+	b.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		var less bool
+
+		switch query.OrderBy {
+		case "name":
+			less = strings.ToLower(results[i].Name) < strings.ToLower(results[j].Name)
+		case "map":
+			less = results[i].Map < results[j].Map
+		default:
+			if results[i].Players == results[j].Players {
+				return results[i].Addr < results[j].Addr
+			}
+
+			less = results[i].Players < results[j].Players
+		}
+
+		if query.Desc {
+			return !less
+		}
+
+		return less
+	})
+
+	total := int64(len(results))
+
+	if query.Offset >= uint64(len(results)) {
+		return []CommunityServer{}, total
+	}
+
+	results = results[query.Offset:]
+
+	if query.Limit > 0 && query.Limit < uint64(len(results)) {
+		results = results[:query.Limit]
+	}
+
+	return results, total
+}
+
+// queryCommunityServer fetches the current details of the server over A2S.
+func queryCommunityServer(addr string) (CommunityServer, error) {
+	client, errDial := a2s.Dial(addr, communityQueryTimeout)
+	if errDial != nil {
+		return CommunityServer{}, errDial
+	}
+
+	defer func() {
+		_ = client.Close()
+	}()
+
+	info, errInfo := client.Info()
+	if errInfo != nil {
+		return CommunityServer{}, errInfo
+	}
+
+	keywords := make([]string, len(info.Keywords))
+	for idx, keyword := range info.Keywords {
+		keywords[idx] = strings.ToLower(keyword)
+	}
+
+	return CommunityServer{
+		Addr:       addr,
+		Name:       info.Name,
+		Map:        info.Map,
+		Game:       info.Game,
+		Version:    info.Version,
+		AppID:      info.AppID,
+		Players:    info.Players,
+		MaxPlayers: info.MaxPlayers,
+		Bots:       info.Bots,
+		Password:   info.Password,
+		VAC:        info.VAC,
+		Keywords:   keywords,
+	}, nil
+}
+
+// updateCommunityServers lists the servers from the master server and queries each of them, updating the index
+// with the servers which responded.
+func (app *App) updateCommunityServers(ctx context.Context) (int, int, error) {
+	conf := app.conf.CommunityBrowser
+	client := msqp.New(conf.MasterHost)
+	filters := []msqp.Filter{msqp.AppID(conf.AppID)}
+
+	if !conf.IncludeEmpty {
+		filters = append(filters, msqp.NotEmpty())
+	}
+
+	endpoints, errList := client.List(ctx, []msqp.Region{msqp.AllRegions}, filters...)
+	if errList != nil {
+		return 0, 0, errList
+	}
+
+	var (
+		waitGroup = &sync.WaitGroup{}
+		limiter   = make(chan struct{}, max(conf.QueryConcurrency, 1))
+		resultsMu = &sync.Mutex{}
+		servers   []CommunityServer
+	)
+
+	for _, endpoint := range endpoints {
+		waitGroup.Add(1)
+
+		limiter <- struct{}{}
+
+		go func(addr string) {
+			defer func() {
+				<-limiter
+
+				waitGroup.Done()
+			}()
+
+			server, errQuery := queryCommunityServer(addr)
+			if errQuery != nil {
+				return
+			}
+
+			resultsMu.Lock()
+			servers = append(servers, server)
+			resultsMu.Unlock()
+		}(endpoint.String())
+	}
+
+	waitGroup.Wait()
+
+	// Servers which miss a couple of updates are kept to avoid them disappearing due to packet loss.
+	app.communityBrowser.Update(servers, time.Now(), conf.UpdateIntervalValue*3)
+
+	return len(endpoints), len(servers), nil
+}
+
+// communityBrowserUpdater periodically refreshes the community server index.
+func (app *App) communityBrowserUpdater(ctx context.Context) {
+	if !app.conf.CommunityBrowser.Enabled || app.conf.CommunityBrowser.UpdateIntervalValue <= 0 {
+		return
+	}
+
+	var (
+		log    = app.log.Named("communityBrowser")
+		ticker = time.NewTicker(app.conf.CommunityBrowser.UpdateIntervalValue)
+	)
+
+	defer ticker.Stop()
+
+	update := func() {
+		startTime := time.Now()
+
+		listed, responded, errUpdate := app.updateCommunityServers(ctx)
+		if errUpdate != nil {
+			log.Error("Failed to update community servers", zap.Error(errUpdate))
+
+			return
+		}
+
+		log.Info("Updated community servers", zap.Int("listed", listed),
+			zap.Int("responded", responded), zap.Duration("duration", time.Since(startTime)))
+	}
+
+	update()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			update()
+		}
+	}
+}
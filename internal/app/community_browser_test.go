@@ -0,0 +1,57 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func communityAddrs(servers []app.CommunityServer) []string {
+	addrs := make([]string, len(servers))
+	for idx, server := range servers {
+		addrs[idx] = server.Addr
+	}
+
+	return addrs
+}
+
+func TestCommunityBrowser(t *testing.T) {
+	var (
+		browser = app.NewCommunityBrowser()
+		now     = time.Now()
+	)
+
+	browser.Update([]app.CommunityServer{
+		{Addr: "1.1.1.1:27015", Name: "Uncletopia | Seattle", Map: "pl_upward", Players: 20, MaxPlayers: 24, Keywords: []string{"alltalk", "nocrits"}},
+		{Addr: "2.2.2.2:27015", Name: "Some Trade Server", Map: "trade_plaza", Players: 10, MaxPlayers: 32, Bots: 10},
+		{Addr: "3.3.3.3:27015", Name: "Full Server", Map: "koth_harvest_final", Players: 24, MaxPlayers: 24, Password: true},
+	}, now.Add(-time.Hour), time.Hour*2)
+	browser.Update([]app.CommunityServer{
+		{Addr: "4.4.4.4:27015", Name: "Uncletopia | Frankfurt", Map: "pl_badwater", Players: 5, MaxPlayers: 24},
+	}, now, time.Hour*2)
+
+	servers, count := browser.Search(app.CommunityServerQuery{QueryFilter: store.QueryFilter{Desc: true}})
+	require.EqualValues(t, 4, count)
+	require.Equal(t, []string{"3.3.3.3:27015", "1.1.1.1:27015", "2.2.2.2:27015", "4.4.4.4:27015"}, communityAddrs(servers))
+
+	servers, _ = browser.Search(app.CommunityServerQuery{QueryFilter: store.QueryFilter{Query: "uncletopia", OrderBy: "name"}})
+	require.Equal(t, []string{"4.4.4.4:27015", "1.1.1.1:27015"}, communityAddrs(servers))
+
+	servers, _ = browser.Search(app.CommunityServerQuery{NotEmpty: true, NotFull: true, NoPassword: true})
+	require.Equal(t, []string{"4.4.4.4:27015", "1.1.1.1:27015"}, communityAddrs(servers))
+
+	servers, _ = browser.Search(app.CommunityServerQuery{Tags: []string{"NoCrits"}, Map: "pl_"})
+	require.Equal(t, []string{"1.1.1.1:27015"}, communityAddrs(servers))
+
+	servers, count = browser.Search(app.CommunityServerQuery{QueryFilter: store.QueryFilter{Offset: 1, Limit: 2}})
+	require.EqualValues(t, 4, count)
+	require.Len(t, servers, 2)
+
+	// Servers which have not been seen recently are removed
+	browser.Update(nil, now.Add(time.Hour*2), time.Hour*2)
+	servers, _ = browser.Search(app.CommunityServerQuery{})
+	require.Equal(t, []string{"4.4.4.4:27015"}, communityAddrs(servers))
+}
@@ -30,16 +30,177 @@ type filterConfig struct {
 //	export general.steam_key=STEAM_KEY_STEAM_KEY_STEAM_KEY
 //	./gbans serve
 type Config struct {
-	General     generalConfig   `mapstructure:"general"`
-	HTTP        httpConfig      `mapstructure:"http"`
-	Filter      filterConfig    `mapstructure:"word_filter"`
-	DB          dbConfig        `mapstructure:"database"`
-	Discord     discordConfig   `mapstructure:"discord"`
-	Log         LogConfig       `mapstructure:"logging"`
-	IP2Location ip2locationConf `mapstructure:"ip2location"`
-	Debug       debugConfig     `mapstructure:"debug"`
-	Patreon     patreonConfig   `mapstructure:"patreon"`
-	S3          s3Config        `mapstructure:"s3"`
+	General           generalConfig           `mapstructure:"general"`
+	HTTP              httpConfig              `mapstructure:"http"`
+	Filter            filterConfig            `mapstructure:"word_filter"`
+	DB                dbConfig                `mapstructure:"database"`
+	Discord           discordConfig           `mapstructure:"discord"`
+	Log               LogConfig               `mapstructure:"logging"`
+	IP2Location       ip2locationConf         `mapstructure:"ip2location"`
+	Debug             debugConfig             `mapstructure:"debug"`
+	Patreon           patreonConfig           `mapstructure:"patreon"`
+	S3                s3Config                `mapstructure:"s3"`
+	BanApproval       approvalConfig          `mapstructure:"ban_approval"`
+	Suspicion         suspicionConfig         `mapstructure:"suspicion"`
+	MassConnect       massConnectConfig       `mapstructure:"mass_connect"`
+	BotDefense        botDefenseConfig        `mapstructure:"bot_defense"`
+	RconConsole       rconConsoleConfig       `mapstructure:"rcon_console"`
+	ServerHealth      serverHealthConfig      `mapstructure:"server_health"`
+	CvarDrift         cvarDriftConfig         `mapstructure:"cvar_drift"`
+	CommunityBrowser  communityBrowserConfig  `mapstructure:"community_browser"`
+	ReconnectGrace    reconnectGraceConfig    `mapstructure:"reconnect_grace"`
+	ServerCredentials serverCredentialsConfig `mapstructure:"server_credentials"`
+	PlayerCounts      playerCountsConfig      `mapstructure:"player_counts"`
+	ServerPerformance serverPerformanceConfig `mapstructure:"server_performance"`
+	ServiceDiscovery  serviceDiscoveryConfig  `mapstructure:"service_discovery"`
+	MatchLogs         matchLogsConfig         `mapstructure:"match_logs"`
+}
+
+// serverHealthConfig controls the monitoring of game server health and the alerts sent when it changes.
+type serverHealthConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	DownAfter          string        `mapstructure:"down_after"`
+	DownAfterValue     time.Duration `mapstructure:"-"`
+	MapStuckAfter      string        `mapstructure:"map_stuck_after"`
+	MapStuckAfterValue time.Duration `mapstructure:"-"`
+	PeakHoursStart     int           `mapstructure:"peak_hours_start"`
+	PeakHoursEnd       int           `mapstructure:"peak_hours_end"`
+	RestartGap         string        `mapstructure:"restart_gap"`
+	RestartGapValue    time.Duration `mapstructure:"-"`
+	AlertChannelID     string        `mapstructure:"alert_channel_id"`
+}
+
+// cvarDriftConfig controls the periodic comparison of server cvars against their assigned template.
+type cvarDriftConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	CheckInterval      string        `mapstructure:"check_interval"`
+	CheckIntervalValue time.Duration `mapstructure:"-"`
+	ReportChannelID    string        `mapstructure:"report_channel_id"`
+}
+
+// communityBrowserConfig controls the discovery of community servers through the master server.
+type communityBrowserConfig struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	MasterHost          string        `mapstructure:"master_host"`
+	UpdateInterval      string        `mapstructure:"update_interval"`
+	UpdateIntervalValue time.Duration `mapstructure:"-"`
+	AppID               int           `mapstructure:"app_id"`
+	IncludeEmpty        bool          `mapstructure:"include_empty"`
+	QueryConcurrency    int           `mapstructure:"query_concurrency"`
+}
+
+// reconnectGraceConfig controls how long a slot is held for players who disconnect unexpectedly.
+type reconnectGraceConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Duration      string        `mapstructure:"duration"`
+	DurationValue time.Duration `mapstructure:"-"`
+	Reasons       []string      `mapstructure:"reasons"`
+}
+
+// serverCredentialsConfig controls the rotation of server keys and log secrets.
+type serverCredentialsConfig struct {
+	// RotationOverlap is how long the previous credential remains valid after being rotated.
+	RotationOverlap      string        `mapstructure:"rotation_overlap"`
+	RotationOverlapValue time.Duration `mapstructure:"-"`
+}
+
+// playerCountsConfig controls the recording of per-server player count history. Samples are taken each minute
+// and downsampled into hourly and then daily buckets as they age.
+type playerCountsConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	MinuteRetention      string        `mapstructure:"minute_retention"`
+	MinuteRetentionValue time.Duration `mapstructure:"-"`
+	HourRetention        string        `mapstructure:"hour_retention"`
+	HourRetentionValue   time.Duration `mapstructure:"-"`
+}
+
+// serverPerformanceConfig controls the polling of server performance over rcon.
+type serverPerformanceConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Interval       string        `mapstructure:"interval"`
+	IntervalValue  time.Duration `mapstructure:"-"`
+	Retention      string        `mapstructure:"retention"`
+	RetentionValue time.Duration `mapstructure:"-"`
+	// TickRate is the expected tick rate of the servers, used to judge if they are keeping up.
+	TickRate float64 `mapstructure:"tick_rate"`
+	// MinTickHealth is the lowest ratio of fps to tick rate before a sample is considered a lag spike.
+	MinTickHealth float64 `mapstructure:"min_tick_health"`
+	// LagSpikeVar is the frame time variance, in milliseconds, at which a sample is considered a lag spike.
+	LagSpikeVar float64 `mapstructure:"lag_spike_var"`
+}
+
+// serviceDiscoveryConfig controls the service discovery outputs generated from the servers.
+type serviceDiscoveryConfig struct {
+	// Datacenter is reported in the consul catalog output.
+	Datacenter string                     `mapstructure:"datacenter"`
+	Exporters  []ServiceDiscoveryExporter `mapstructure:"exporters"`
+}
+
+// ServiceDiscoveryExporter is a prometheus exporter which runs on every game server host.
+type ServiceDiscoveryExporter struct {
+	Name string `mapstructure:"name"`
+	Port int    `mapstructure:"port"`
+}
+
+// matchLogsConfig controls the archival of the raw log lines of each match.
+type matchLogsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxLines limits how many lines are buffered for a single match, longer matches are not archived.
+	MaxLines int `mapstructure:"max_lines"`
+}
+
+// rconConsoleConfig controls access to the web rcon console.
+type rconConsoleConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	ModeratorCommands []string `mapstructure:"moderator_commands"`
+	AdminCommands     []string `mapstructure:"admin_commands"`
+	// ModeratorServerGroups restricts moderators to servers within these groups, empty allows all servers.
+	ModeratorServerGroups []string `mapstructure:"moderator_server_groups"`
+}
+
+// botDefenseConfig controls the automatic detection and kicking of bots.
+type botDefenseConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	WaveThreshold        int           `mapstructure:"wave_threshold"`
+	WaveWindow           string        `mapstructure:"wave_window"`
+	WaveWindowValue      time.Duration `mapstructure:"-"`
+	FreshAccountAge      string        `mapstructure:"fresh_account_age"`
+	FreshAccountAgeValue time.Duration `mapstructure:"-"`
+	ChatThreshold        int           `mapstructure:"chat_threshold"`
+	ChatWindow           string        `mapstructure:"chat_window"`
+	ChatWindowValue      time.Duration `mapstructure:"-"`
+	ChatMinLength        int           `mapstructure:"chat_min_length"`
+	ChatIgnore           []string      `mapstructure:"chat_ignore"`
+	ReportChannelID      string        `mapstructure:"report_channel_id"`
+}
+
+// massConnectConfig controls detection of many accounts connecting from a single ip or network.
+type massConnectConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	Threshold            int           `mapstructure:"threshold"`
+	Window               string        `mapstructure:"window"`
+	WindowValue          time.Duration `mapstructure:"-"`
+	Subnet               bool          `mapstructure:"subnet"`
+	Whitelist            []string      `mapstructure:"whitelist"`
+	AutoBanBotHosts      bool          `mapstructure:"auto_ban_bot_hosts"`
+	AutoBanDuration      string        `mapstructure:"auto_ban_duration"`
+	AutoBanDurationValue time.Duration `mapstructure:"-"`
+}
+
+// suspicionConfig controls the statistical analysis of match results used to surface potential cheaters.
+type suspicionConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`
+	MinShots       int     `mapstructure:"min_shots"`
+	FlagScore      float64 `mapstructure:"flag_score"`
+	AlertScore     float64 `mapstructure:"alert_score"`
+	AlertChannelID string  `mapstructure:"alert_channel_id"`
+}
+
+// approvalConfig controls the two-person approval policy for high impact moderation actions.
+type approvalConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Expiry      string        `mapstructure:"expiry"`
+	ExpiryValue time.Duration `mapstructure:"-"`
 }
 
 type s3Config struct {
@@ -52,6 +213,8 @@ type s3Config struct {
 	SSL         bool   `mapstructure:"ssl"`
 	BucketMedia string `mapstructure:"bucket_media"`
 	BucketDemo  string `mapstructure:"bucket_demo"`
+	// BucketMatchLog stores the archived raw logs of matches.
+	BucketMatchLog string `mapstructure:"bucket_match_log"`
 }
 
 type dbConfig struct {
@@ -66,6 +229,20 @@ type patreonConfig struct {
 	ClientSecret        string `mapstructure:"client_secret"`
 	CreatorAccessToken  string `mapstructure:"creator_access_token"`
 	CreatorRefreshToken string `mapstructure:"creator_refresh_token"`
+	// GracePeriod is how long perks are kept after a pledge lapses before being revoked.
+	GracePeriod      string             `mapstructure:"grace_period"`
+	GracePeriodValue time.Duration      `mapstructure:"-"`
+	Tiers            []patreonTierPerks `mapstructure:"tiers"`
+}
+
+// patreonTierPerks are the perks granted to patrons pledged to the tier.
+type patreonTierPerks struct {
+	TierID          string   `mapstructure:"tier_id"`
+	Reserved        bool     `mapstructure:"reserved"`
+	DiscordRoleID   string   `mapstructure:"discord_role_id"`
+	ForumBadge      string   `mapstructure:"forum_badge"`
+	ChatTag         string   `mapstructure:"chat_tag"`
+	ReservedServers []string `mapstructure:"reserved_servers"`
 }
 
 type httpConfig struct {
@@ -215,6 +392,141 @@ func ReadConfig(conf *Config, noFileOk bool) error {
 
 	conf.HTTP.ClientTimeoutValue = clientTimeoutDuration
 
+	approvalExpiry, errApprovalExpiry := ParseUserStringDuration(conf.BanApproval.Expiry)
+	if errApprovalExpiry != nil {
+		return errors.Wrap(errApprovalExpiry, "Failed to parse ban approval expiry duration")
+	}
+
+	conf.BanApproval.ExpiryValue = approvalExpiry
+
+	massConnectWindow, errMassConnectWindow := ParseUserStringDuration(conf.MassConnect.Window)
+	if errMassConnectWindow != nil {
+		return errors.Wrap(errMassConnectWindow, "Failed to parse mass connect window duration")
+	}
+
+	conf.MassConnect.WindowValue = massConnectWindow
+
+	massConnectBanDuration, errMassConnectBanDuration := ParseUserStringDuration(conf.MassConnect.AutoBanDuration)
+	if errMassConnectBanDuration != nil {
+		return errors.Wrap(errMassConnectBanDuration, "Failed to parse mass connect auto ban duration")
+	}
+
+	conf.MassConnect.AutoBanDurationValue = massConnectBanDuration
+
+	botWaveWindow, errBotWaveWindow := ParseUserStringDuration(conf.BotDefense.WaveWindow)
+	if errBotWaveWindow != nil {
+		return errors.Wrap(errBotWaveWindow, "Failed to parse bot defense wave window duration")
+	}
+
+	conf.BotDefense.WaveWindowValue = botWaveWindow
+
+	botAccountAge, errBotAccountAge := ParseUserStringDuration(conf.BotDefense.FreshAccountAge)
+	if errBotAccountAge != nil {
+		return errors.Wrap(errBotAccountAge, "Failed to parse bot defense fresh account age duration")
+	}
+
+	conf.BotDefense.FreshAccountAgeValue = botAccountAge
+
+	botChatWindow, errBotChatWindow := ParseUserStringDuration(conf.BotDefense.ChatWindow)
+	if errBotChatWindow != nil {
+		return errors.Wrap(errBotChatWindow, "Failed to parse bot defense chat window duration")
+	}
+
+	conf.BotDefense.ChatWindowValue = botChatWindow
+
+	healthDownAfter, errHealthDownAfter := ParseUserStringDuration(conf.ServerHealth.DownAfter)
+	if errHealthDownAfter != nil {
+		return errors.Wrap(errHealthDownAfter, "Failed to parse server health down after duration")
+	}
+
+	conf.ServerHealth.DownAfterValue = healthDownAfter
+
+	healthMapStuck, errHealthMapStuck := ParseUserStringDuration(conf.ServerHealth.MapStuckAfter)
+	if errHealthMapStuck != nil {
+		return errors.Wrap(errHealthMapStuck, "Failed to parse server health map stuck after duration")
+	}
+
+	conf.ServerHealth.MapStuckAfterValue = healthMapStuck
+
+	healthRestartGap, errHealthRestartGap := ParseUserStringDuration(conf.ServerHealth.RestartGap)
+	if errHealthRestartGap != nil {
+		return errors.Wrap(errHealthRestartGap, "Failed to parse server health restart gap duration")
+	}
+
+	conf.ServerHealth.RestartGapValue = healthRestartGap
+
+	cvarCheckInterval, errCvarCheckInterval := ParseUserStringDuration(conf.CvarDrift.CheckInterval)
+	if errCvarCheckInterval != nil {
+		return errors.Wrap(errCvarCheckInterval, "Failed to parse cvar drift check interval duration")
+	}
+
+	conf.CvarDrift.CheckIntervalValue = cvarCheckInterval
+
+	communityUpdateInterval, errCommunityUpdateInterval := ParseUserStringDuration(conf.CommunityBrowser.UpdateInterval)
+	if errCommunityUpdateInterval != nil {
+		return errors.Wrap(errCommunityUpdateInterval, "Failed to parse community browser update interval duration")
+	}
+
+	conf.CommunityBrowser.UpdateIntervalValue = communityUpdateInterval
+
+	reconnectDuration, errReconnectDuration := ParseUserStringDuration(conf.ReconnectGrace.Duration)
+	if errReconnectDuration != nil {
+		return errors.Wrap(errReconnectDuration, "Failed to parse reconnect grace duration")
+	}
+
+	conf.ReconnectGrace.DurationValue = reconnectDuration
+
+	patreonGracePeriod, errPatreonGracePeriod := ParseUserStringDuration(conf.Patreon.GracePeriod)
+	if errPatreonGracePeriod != nil {
+		return errors.Wrap(errPatreonGracePeriod, "Failed to parse patreon grace period duration")
+	}
+
+	conf.Patreon.GracePeriodValue = patreonGracePeriod
+
+	rotationOverlap, errRotationOverlap := ParseUserStringDuration(conf.ServerCredentials.RotationOverlap)
+	if errRotationOverlap != nil {
+		return errors.Wrap(errRotationOverlap, "Failed to parse server credential rotation overlap duration")
+	}
+
+	conf.ServerCredentials.RotationOverlapValue = rotationOverlap
+
+	minuteRetention, errMinuteRetention := ParseUserStringDuration(conf.PlayerCounts.MinuteRetention)
+	if errMinuteRetention != nil {
+		return errors.Wrap(errMinuteRetention, "Failed to parse player count minute retention duration")
+	}
+
+	conf.PlayerCounts.MinuteRetentionValue = minuteRetention
+
+	hourRetention, errHourRetention := ParseUserStringDuration(conf.PlayerCounts.HourRetention)
+	if errHourRetention != nil {
+		return errors.Wrap(errHourRetention, "Failed to parse player count hour retention duration")
+	}
+
+	// Daily buckets are built from the hourly buckets, so they must outlive the minute samples by at least a day.
+	if hourRetention < minuteRetention+time.Hour*24 {
+		return errors.New("Player count hour retention must be at least a day longer than minute retention")
+	}
+
+	conf.PlayerCounts.HourRetentionValue = hourRetention
+
+	performanceInterval, errPerformanceInterval := ParseUserStringDuration(conf.ServerPerformance.Interval)
+	if errPerformanceInterval != nil {
+		return errors.Wrap(errPerformanceInterval, "Failed to parse server performance interval duration")
+	}
+
+	if performanceInterval < time.Second*10 {
+		return errors.New("Server performance interval must be at least 10 seconds")
+	}
+
+	conf.ServerPerformance.IntervalValue = performanceInterval
+
+	performanceRetention, errPerformanceRetention := ParseUserStringDuration(conf.ServerPerformance.Retention)
+	if errPerformanceRetention != nil {
+		return errors.Wrap(errPerformanceRetention, "Failed to parse server performance retention duration")
+	}
+
+	conf.ServerPerformance.RetentionValue = performanceRetention
+
 	return nil
 }
 
@@ -252,6 +564,8 @@ func setDefaultConfigValues() {
 		"patreon.client_secret":                    "",
 		"patreon.creator_access_token":             "",
 		"patreon.creator_refresh_token":            "",
+		"patreon.grace_period":                     "3d",
+		"patreon.tiers":                            []patreonTierPerks{},
 		"http.host":                                "127.0.0.1",
 		"http.port":                                6006,
 		"http.tls":                                 false,
@@ -298,6 +612,69 @@ func setDefaultConfigValues() {
 		"s3.region":                                "",
 		"s3.bucket_media":                          "media",
 		"s3.bucket_demo":                           "demos",
+		"s3.bucket_match_log":                      "matchlogs",
+		"ban_approval.enabled":                     false,
+		"ban_approval.expiry":                      "2d",
+		"suspicion.enabled":                        false,
+		"suspicion.min_shots":                      50,
+		"suspicion.flag_score":                     3.0,
+		"suspicion.alert_score":                    4.5,
+		"suspicion.alert_channel_id":               "",
+		"mass_connect.enabled":                     false,
+		"mass_connect.threshold":                   4,
+		"mass_connect.window":                      "10m",
+		"mass_connect.subnet":                      true,
+		"mass_connect.whitelist":                   []string{},
+		"mass_connect.auto_ban_bot_hosts":          false,
+		"mass_connect.auto_ban_duration":           "0",
+		"bot_defense.enabled":                      false,
+		"bot_defense.wave_threshold":               5,
+		"bot_defense.wave_window":                  "2m",
+		"bot_defense.fresh_account_age":            "30d",
+		"bot_defense.chat_threshold":               3,
+		"bot_defense.chat_window":                  "30s",
+		"bot_defense.chat_min_length":              6,
+		"bot_defense.chat_ignore":                  []string{"gg", "ggwp", "gg wp", "rtv", "nominate", "lol", "nice shot", "thanks"},
+		"bot_defense.report_channel_id":            "",
+		"rcon_console.enabled":                     false,
+		"rcon_console.moderator_commands": []string{
+			"status", "sm_say", "sm_csay", "sm_psay", "sm_kick", "sm_mute", "sm_gag", "sm_silence",
+		},
+		"rcon_console.admin_commands":          []string{"*"},
+		"rcon_console.moderator_server_groups": []string{},
+		"server_health.enabled":                false,
+		"server_health.down_after":             "5m",
+		"server_health.map_stuck_after":        "3h",
+		"server_health.peak_hours_start":       18,
+		"server_health.peak_hours_end":         23,
+		"server_health.restart_gap":            "3m",
+		"server_health.alert_channel_id":       "",
+		"cvar_drift.enabled":                   false,
+		"cvar_drift.check_interval":            "1h",
+		"cvar_drift.report_channel_id":         "",
+		"community_browser.enabled":            false,
+		"community_browser.master_host":        "hl2master.steampowered.com:27011",
+		"community_browser.update_interval":    "15m",
+		"community_browser.app_id":             440,
+		"community_browser.include_empty":      false,
+		"community_browser.query_concurrency":  50,
+		"reconnect_grace.enabled":              false,
+		"reconnect_grace.duration":             "5m",
+		"reconnect_grace.reasons":              []string{"timed out", "crashed"},
+		"server_credentials.rotation_overlap":  "1d",
+		"player_counts.enabled":                true,
+		"player_counts.minute_retention":       "2d",
+		"player_counts.hour_retention":         "90d",
+		"server_performance.enabled":           true,
+		"server_performance.interval":          "1m",
+		"server_performance.retention":         "7d",
+		"server_performance.tick_rate":         66.67,
+		"server_performance.min_tick_health":   0.9,
+		"server_performance.lag_spike_var":     5.0,
+		"service_discovery.datacenter":         "dc1",
+		"service_discovery.exporters":          []map[string]any{{"name": "node", "port": 9100}},
+		"match_logs.enabled":                   false,
+		"match_logs.max_lines":                 250000,
 	}
 
 	for configKey, value := range defaultConfig {
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v3/steamid"
+)
+
+// subnetMaskBits is the size of the network used when grouping connections by subnet.
+const subnetMaskBits = 24
+
+// connectionKey identifies an account connecting from a single address, repeat connections update the same entry.
+type connectionKey struct {
+	steamID steamid.SID64
+	addr    string
+}
+
+type trackedConnection struct {
+	// servers holds the last connection time to each server.
+	servers   map[int]time.Time
+	createdOn time.Time
+}
+
+// ConnectionAlert is produced when the number of distinct accounts connecting from a single address or
+// subnet exceeds the configured threshold within the tracking window.
+type ConnectionAlert struct {
+	// Key is the IP or CIDR network that was matched
+	Key       string
+	Subnet    bool
+	Accounts  steamid.Collection
+	ServerIDs []int
+}
+
+// ConnectionTracker provides a sliding window over recent connections, grouped by their source ip and /24 network,
+// which is used to detect large numbers of accounts originating from the same location. This is most commonly
+// seen from bot hosts cycling through accounts.
+type ConnectionTracker struct {
+	window      time.Duration
+	threshold   int
+	subnet      bool
+	whitelisted []*net.IPNet
+	connections map[string]map[connectionKey]*trackedConnection
+	alerted     map[string]time.Time
+	sync.Mutex
+}
+
+func NewConnectionTracker(window time.Duration, threshold int, subnet bool, whitelisted []*net.IPNet) *ConnectionTracker {
+	return &ConnectionTracker{
+		window:      window,
+		threshold:   threshold,
+		subnet:      subnet,
+		whitelisted: whitelisted,
+		connections: map[string]map[connectionKey]*trackedConnection{},
+		alerted:     map[string]time.Time{},
+	}
+}
+
+// Add records a new connection and returns any alerts that were triggered by it. Each address or network
+// will only alert once per window.
+func (t *ConnectionTracker) Add(addr net.IP, steamID steamid.SID64, serverID int, now time.Time) []ConnectionAlert {
+	if addr == nil || !steamID.Valid() || t.threshold <= 0 {
+		return nil
+	}
+
+	for _, network := range t.whitelisted {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.expire(now)
+
+	keys := []string{addr.String()}
+
+	if t.subnet && addr.To4() != nil {
+		subnet := net.IPNet{IP: addr.Mask(net.CIDRMask(subnetMaskBits, 32)), Mask: net.CIDRMask(subnetMaskBits, 32)}
+		keys = append(keys, subnet.String())
+	}
+
+	var (
+		alerts  []ConnectionAlert
+		connKey = connectionKey{steamID: steamID, addr: addr.String()}
+	)
+
+	for idx, key := range keys {
+		conns, found := t.connections[key]
+		if !found {
+			conns = map[connectionKey]*trackedConnection{}
+			t.connections[key] = conns
+		}
+
+		conn, found := conns[connKey]
+		if !found {
+			conn = &trackedConnection{servers: map[int]time.Time{}}
+			conns[connKey] = conn
+		}
+
+		conn.createdOn = now
+		conn.servers[serverID] = now
+
+		if _, alreadyAlerted := t.alerted[key]; alreadyAlerted {
+			continue
+		}
+
+		alert := ConnectionAlert{Key: key, Subnet: idx > 0}
+
+		for trackedKey, tracked := range conns {
+			if !alert.Accounts.Contains(trackedKey.steamID) {
+				alert.Accounts = append(alert.Accounts, trackedKey.steamID)
+			}
+
+			for trackedServerID := range tracked.servers {
+				if !containsInt(alert.ServerIDs, trackedServerID) {
+					alert.ServerIDs = append(alert.ServerIDs, trackedServerID)
+				}
+			}
+		}
+
+		sort.Ints(alert.ServerIDs)
+
+		if len(alert.Accounts) < t.threshold {
+			continue
+		}
+
+		t.alerted[key] = now
+
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// Len returns the number of connections currently tracked, counting each account and address once per group.
+func (t *ConnectionTracker) Len() int {
+	t.Lock()
+	defer t.Unlock()
+
+	var count int
+
+	for _, conns := range t.connections {
+		count += len(conns)
+	}
+
+	return count
+}
+
+// expire removes any connections and alert cool-downs which are outside the current window.
+func (t *ConnectionTracker) expire(now time.Time) {
+	for key, conns := range t.connections {
+		for connKey, conn := range conns {
+			if now.Sub(conn.createdOn) >= t.window {
+				delete(conns, connKey)
+
+				continue
+			}
+
+			for serverID, connectedOn := range conn.servers {
+				if now.Sub(connectedOn) >= t.window {
+					delete(conn.servers, serverID)
+				}
+			}
+		}
+
+		if len(conns) == 0 {
+			delete(t.connections, key)
+		}
+	}
+
+	for key, alertedOn := range t.alerted {
+		if now.Sub(alertedOn) >= t.window {
+			delete(t.alerted, key)
+		}
+	}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,58 @@
+package app_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionTracker(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("10.0.0.0/8")
+
+	var (
+		tracker = app.NewConnectionTracker(time.Minute*10, 3, true, []*net.IPNet{lan})
+		now     = time.Now()
+		addr    = net.ParseIP("1.2.3.4")
+		sid1    = steamid.New(76561198084134025)
+		sid2    = steamid.New(76561197961279983)
+		sid3    = steamid.New(76561197960265728 + 100)
+	)
+
+	require.Empty(t, tracker.Add(addr, sid1, 1, now))
+	// Repeated connections from the same account are only counted once
+	require.Empty(t, tracker.Add(addr, sid1, 1, now.Add(time.Minute)))
+	require.Empty(t, tracker.Add(net.ParseIP("1.2.3.5"), sid2, 2, now.Add(time.Minute*2)))
+
+	alerts := tracker.Add(addr, sid3, 1, now.Add(time.Minute*3))
+	require.Len(t, alerts, 1)
+	require.Equal(t, "1.2.3.0/24", alerts[0].Key)
+	require.True(t, alerts[0].Subnet)
+	require.Len(t, alerts[0].Accounts, 3)
+	require.ElementsMatch(t, []int{1, 2}, alerts[0].ServerIDs)
+
+	// The subnet has already alerted within the window, so only the address alerts
+	addrAlerts := tracker.Add(addr, sid2, 1, now.Add(time.Minute*4))
+	require.Len(t, addrAlerts, 1)
+	require.Equal(t, addr.String(), addrAlerts[0].Key)
+	require.False(t, addrAlerts[0].Subnet)
+
+	// Reconnecting refreshes the existing entry instead of adding another
+	tracked := tracker.Len()
+
+	for i := 0; i < 10; i++ {
+		tracker.Add(addr, sid1, 1, now.Add(time.Minute*5))
+	}
+
+	require.Equal(t, tracked, tracker.Len())
+
+	// Connections outside the window are expired
+	require.Empty(t, tracker.Add(addr, sid1, 1, now.Add(time.Hour)))
+
+	for _, sid := range []steamid.SID64{sid1, sid2, sid3} {
+		require.Empty(t, tracker.Add(net.ParseIP("10.1.1.1"), sid, 1, now))
+	}
+}
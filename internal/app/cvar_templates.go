@@ -0,0 +1,427 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// cvarBatchSize is the number of cvars queried or set in a single rcon command.
+const cvarBatchSize = 10
+
+var (
+	errCvarTemplateName  = errors.New("Template name cannot be empty")
+	errCvarTemplateEmpty = errors.New("Template must have at least one cvar")
+	errCvarName          = errors.New("Invalid cvar name")
+	errCvarValue         = errors.New("Cvar values cannot contain quotes, semicolons or newlines")
+	errCvarNoTemplate    = errors.New("Server has no cvar template assigned")
+	errCvarCommand       = errors.New("Commands and protected cvars cannot be used in a template")
+
+	cvarNameRx  = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	cvarValueRx = regexp.MustCompile(`(?m)^"([^"]+)" = "([^"]*)"`)
+	cvarListRx  = regexp.MustCompile(`(?m)^(\S+)\s+:\s+(\S*)\s*:`)
+
+	// cvarBlocked are commands, along with cvars gbans itself depends on, which are rejected in templates
+	// without needing to ask a server. Any other command is caught when the template is checked against the
+	// server, see rejectCvarCommands.
+	cvarBlocked = map[string]bool{ //nolint:gochecknoglobals
+		"quit": true, "exit": true, "restart": true, "_restart": true, "exec": true, "alias": true, "bind": true,
+		"rcon": true, "rcon_password": true, "sv_rcon_banpenalty": true, "sv_logsecret": true, "log": true,
+		"logaddress_add": true, "logaddress_del": true, "logaddress_delall": true, "changelevel": true, "map": true,
+		"kick": true, "kickid": true, "banid": true, "banip": true, "addip": true, "removeid": true, "removeip": true,
+		"writeid": true, "writeip": true, "sm": true, "meta": true, "sm_rcon": true, "plugin_load": true,
+		"plugin_unload": true, "host_writeconfig": true, "cvarlist": true,
+	}
+)
+
+// ValidateCvarTemplate checks that the template can be safely sent to a server. Cvar names are normalised
+// to lower case. Known commands, and any of the additional blocked names, are rejected since setting them
+// would execute them instead.
+func ValidateCvarTemplate(template *store.CvarTemplate, blocked []string) error {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" {
+		return errCvarTemplateName
+	}
+
+	if len(template.Values) == 0 {
+		return errCvarTemplateEmpty
+	}
+
+	values := make(map[string]string, len(template.Values))
+
+	for cvar, value := range template.Values {
+		cvar = strings.ToLower(strings.TrimSpace(cvar))
+		if !cvarNameRx.MatchString(cvar) {
+			return errors.Wrapf(errCvarName, "%s", cvar)
+		}
+
+		if cvarBlocked[cvar] || containsFold(blocked, cvar) {
+			return errors.Wrapf(errCvarCommand, "%s", cvar)
+		}
+
+		if strings.ContainsAny(value, "\";\r\n") {
+			return errors.Wrapf(errCvarValue, "%s", cvar)
+		}
+
+		values[cvar] = value
+	}
+
+	template.Values = values
+
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, existing := range values {
+		if strings.EqualFold(strings.TrimSpace(existing), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseCvarList reads the response of the cvarlist command, returning whether each of the listed names is
+// a command rather than a cvar.
+//
+//	quit                                     : cmd      :                  : Exit the engine.
+//	sv_gravity                               : 800      : , "sv", "nf", "rep" : World gravity.
+func ParseCvarList(response string) map[string]bool {
+	commands := map[string]bool{}
+
+	for _, match := range cvarListRx.FindAllStringSubmatch(response, -1) {
+		commands[strings.ToLower(match[1])] = match[2] == "cmd"
+	}
+
+	return commands
+}
+
+// ParseCvarValues extracts the current values from the response to querying one or more cvars, keyed by
+// the lower case cvar name. Unknown cvars are not included.
+func ParseCvarValues(response string) map[string]string {
+	values := map[string]string{}
+
+	for _, match := range cvarValueRx.FindAllStringSubmatch(response, -1) {
+		values[strings.ToLower(match[1])] = match[2]
+	}
+
+	return values
+}
+
+// cvarValuesEqual compares numeric values numerically as the game reports floats with trailing zeros.
+func cvarValuesEqual(expected string, actual string) bool {
+	if expected == actual {
+		return true
+	}
+
+	expectedNum, errExpected := strconv.ParseFloat(expected, 64)
+	actualNum, errActual := strconv.ParseFloat(actual, 64)
+
+	return errExpected == nil && errActual == nil && expectedNum == actualNum
+}
+
+// FindCvarDrift compares the actual values against the template, returning each cvar that does not match
+// sorted by name. Cvars missing from actual are reported with an empty actual value.
+func FindCvarDrift(expected map[string]string, actual map[string]string) []store.CvarDrift {
+	drift := make([]store.CvarDrift, 0)
+
+	for cvar, value := range expected {
+		current, found := actual[cvar]
+		if found && cvarValuesEqual(value, current) {
+			continue
+		}
+
+		drift = append(drift, store.CvarDrift{Cvar: cvar, Expected: value, Actual: current})
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].Cvar < drift[j].Cvar
+	})
+
+	return drift
+}
+
+func sortedCvars(values map[string]string) []string {
+	cvars := make([]string, 0, len(values))
+	for cvar := range values {
+		cvars = append(cvars, cvar)
+	}
+
+	sort.Strings(cvars)
+
+	return cvars
+}
+
+// cvarCommandKey identifies a name on a single server, as plugins register their own commands on each server.
+type cvarCommandKey struct {
+	serverID int
+	name     string
+}
+
+// rejectCvarCommands confirms with the server that none of the names are commands before they are sent to it,
+// since both querying and setting a command would execute it. Only names known to the server are cached, as a
+// plugin loaded later can register a command using a name which is currently unknown.
+func (app *App) rejectCvarCommands(serverID int, cvars []string) error {
+	for _, cvar := range cvars {
+		if cvarBlocked[cvar] {
+			return errors.Wrapf(errCvarCommand, "%s", cvar)
+		}
+
+		key := cvarCommandKey{serverID: serverID, name: cvar}
+
+		isCommand, known := app.cvarCommands.Get(key)
+		if !known {
+			resp, errExec := app.state.rcon(serverID, "cvarlist "+cvar)
+			if errExec != nil {
+				return errExec
+			}
+
+			// Names missing from the list are unknown to the server and are harmless to send.
+			isCommand, known = ParseCvarList(resp)[cvar]
+			if known {
+				app.cvarCommands.Set(key, isCommand)
+			}
+		}
+
+		if isCommand {
+			return errors.Wrapf(errCvarCommand, "%s", cvar)
+		}
+	}
+
+	return nil
+}
+
+// queryCvars reads the current value of each of the cvars from the server.
+func (app *App) queryCvars(serverID int, cvars []string) (map[string]string, error) {
+	if errCommands := app.rejectCvarCommands(serverID, cvars); errCommands != nil {
+		return nil, errCommands
+	}
+
+	values := map[string]string{}
+
+	for start := 0; start < len(cvars); start += cvarBatchSize {
+		end := min(start+cvarBatchSize, len(cvars))
+
+		resp, errExec := app.state.rcon(serverID, strings.Join(cvars[start:end], ";"))
+		if errExec != nil {
+			return nil, errExec
+		}
+
+		for cvar, value := range ParseCvarValues(resp) {
+			values[cvar] = value
+		}
+	}
+
+	return values, nil
+}
+
+// checkServerCvars compares the server against the template and records the result.
+func (app *App) checkServerCvars(ctx context.Context, serverID int, template store.CvarTemplate) ([]store.CvarDrift, error) {
+	actual, errQuery := app.queryCvars(serverID, sortedCvars(template.Values))
+	if errQuery != nil {
+		return nil, errQuery
+	}
+
+	var (
+		now   = time.Now()
+		drift = FindCvarDrift(template.Values, actual)
+	)
+
+	for idx := range drift {
+		drift[idx].ServerID = serverID
+		drift[idx].CheckedOn = now
+	}
+
+	if errSave := app.db.SaveCvarDrift(ctx, serverID, drift); errSave != nil {
+		return nil, errors.Wrap(errSave, "Failed to save cvar drift")
+	}
+
+	return drift, nil
+}
+
+// applyCvarTemplate sets each of the template values on the server and then checks it again so the
+// recorded drift reflects the result.
+func (app *App) applyCvarTemplate(ctx context.Context, serverID int) ([]store.CvarDrift, error) {
+	assigned, errAssigned := app.db.GetServerCvarTemplates(ctx)
+	if errAssigned != nil {
+		return nil, errors.Wrap(errAssigned, "Failed to load server cvar templates")
+	}
+
+	templateID, found := assigned[serverID]
+	if !found {
+		return nil, errCvarNoTemplate
+	}
+
+	var template store.CvarTemplate
+	if errTemplate := app.db.GetCvarTemplate(ctx, templateID, &template); errTemplate != nil {
+		return nil, errors.Wrap(errTemplate, "Failed to load cvar template")
+	}
+
+	cvars := sortedCvars(template.Values)
+
+	if errCommands := app.rejectCvarCommands(serverID, cvars); errCommands != nil {
+		return nil, errCommands
+	}
+
+	for start := 0; start < len(cvars); start += cvarBatchSize {
+		var commands []string
+		for _, cvar := range cvars[start:min(start+cvarBatchSize, len(cvars))] {
+			commands = append(commands, fmt.Sprintf(`%s "%s"`, cvar, template.Values[cvar]))
+		}
+
+		if _, errExec := app.state.rcon(serverID, strings.Join(commands, ";")); errExec != nil {
+			return nil, errExec
+		}
+	}
+
+	return app.checkServerCvars(ctx, serverID, template)
+}
+
+// checkCvarDrift checks every server with an assigned template. Servers which cannot currently be reached
+// are skipped and retain the result of their last check.
+func (app *App) checkCvarDrift(ctx context.Context) error {
+	assigned, errAssigned := app.db.GetServerCvarTemplates(ctx)
+	if errAssigned != nil {
+		return errors.Wrap(errAssigned, "Failed to load server cvar templates")
+	}
+
+	if len(assigned) == 0 {
+		return nil
+	}
+
+	templates, errTemplates := app.db.GetCvarTemplates(ctx)
+	if errTemplates != nil {
+		return errors.Wrap(errTemplates, "Failed to load cvar templates")
+	}
+
+	templatesByID := map[int]store.CvarTemplate{}
+	for _, template := range templates {
+		templatesByID[template.CvarTemplateID] = template
+	}
+
+	waitGroup := &sync.WaitGroup{}
+
+	for serverID, templateID := range assigned {
+		template, found := templatesByID[templateID]
+		if !found {
+			continue
+		}
+
+		waitGroup.Add(1)
+
+		go func(sid int, tmpl store.CvarTemplate) {
+			defer waitGroup.Done()
+
+			if _, errCheck := app.checkServerCvars(ctx, sid, tmpl); errCheck != nil {
+				app.log.Debug("Failed to check server cvars", zap.Int("server_id", sid), zap.Error(errCheck))
+			}
+		}(serverID, template)
+	}
+
+	waitGroup.Wait()
+
+	return nil
+}
+
+// cvarDriftChecker periodically checks all servers for cvar drift, sending a summary to discord whenever
+// the set of drifted cvars changes.
+func (app *App) cvarDriftChecker(ctx context.Context) {
+	if !app.conf.CvarDrift.Enabled || app.conf.CvarDrift.CheckIntervalValue <= 0 {
+		return
+	}
+
+	var (
+		log          = app.log.Named("cvarDrift")
+		ticker       = time.NewTicker(app.conf.CvarDrift.CheckIntervalValue)
+		lastReported string
+	)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if errCheck := app.checkCvarDrift(ctx); errCheck != nil {
+				log.Error("Failed to check cvar drift", zap.Error(errCheck))
+
+				continue
+			}
+
+			drift, errDrift := app.db.GetCvarDrift(ctx, 0)
+			if errDrift != nil {
+				log.Error("Failed to load cvar drift", zap.Error(errDrift))
+
+				continue
+			}
+
+			var keys []string
+			for _, item := range drift {
+				keys = append(keys, fmt.Sprintf("%d:%s=%s", item.ServerID, item.Cvar, item.Actual))
+			}
+
+			if summary := strings.Join(keys, ","); summary != lastReported {
+				lastReported = summary
+
+				app.onCvarDriftChanged(drift)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (app *App) onCvarDriftChanged(drift []store.CvarDrift) {
+	channelID := app.conf.CvarDrift.ReportChannelID
+	if channelID == "" {
+		channelID = app.conf.Discord.LogChannelID
+	}
+
+	if len(drift) == 0 {
+		app.bot.SendPayload(discord.Payload{
+			ChannelID: channelID,
+			Embed: discord.
+				NewEmbed("All servers match their cvar templates").
+				SetColor(app.bot.Colour.Success).
+				Truncate().MessageEmbed,
+		})
+
+		return
+	}
+
+	var (
+		servers []string
+		byName  = map[string][]string{}
+	)
+
+	for _, item := range drift {
+		if _, found := byName[item.ServerName]; !found {
+			servers = append(servers, item.ServerName)
+		}
+
+		byName[item.ServerName] = append(byName[item.ServerName],
+			fmt.Sprintf("`%s` %q (expected %q)", item.Cvar, item.Actual, item.Expected))
+	}
+
+	msgEmbed := discord.
+		NewEmbed(fmt.Sprintf("Cvar drift detected on %d server(s)", len(servers))).
+		SetColor(app.bot.Colour.Warn)
+
+	for _, server := range servers {
+		msgEmbed.AddField(server, strings.Join(byName[server], "\n"))
+	}
+
+	app.bot.SendPayload(discord.Payload{
+		ChannelID: channelID,
+		Embed:     msgEmbed.Truncate().MessageEmbed,
+	})
+}
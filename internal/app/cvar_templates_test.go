@@ -0,0 +1,80 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCvarValues(t *testing.T) {
+	resp := `"sv_gravity" = "800" ( def. "800" ) min. 0.000000
+ notify replicated
+ - World gravity.
+"mp_timelimit" = "30.000000" ( def. "0" ) min. 0.000000 game notify replicated
+ - game time per map in minutes
+Unknown command "sv_nope"
+"Hostname" = "Uncletopia | Test"
+ - Hostname for server.
+`
+	require.Equal(t, map[string]string{
+		"sv_gravity":   "800",
+		"mp_timelimit": "30.000000",
+		"hostname":     "Uncletopia | Test",
+	}, app.ParseCvarValues(resp))
+}
+
+func TestFindCvarDrift(t *testing.T) {
+	expected := map[string]string{
+		"mp_timelimit": "30",
+		"sv_gravity":   "800",
+		"sv_nope":      "1",
+		"hostname":     "Test",
+	}
+	actual := map[string]string{
+		"mp_timelimit": "30.000000",
+		"sv_gravity":   "600",
+		"hostname":     "Test",
+	}
+
+	require.Equal(t, []store.CvarDrift{
+		{Cvar: "sv_gravity", Expected: "800", Actual: "600"},
+		{Cvar: "sv_nope", Expected: "1", Actual: ""},
+	}, app.FindCvarDrift(expected, actual))
+}
+
+func TestValidateCvarTemplate(t *testing.T) {
+	template := store.CvarTemplate{Name: " comp ", Values: map[string]string{"MP_Tournament": "1"}}
+	require.NoError(t, app.ValidateCvarTemplate(&template, nil))
+	require.Equal(t, "comp", template.Name)
+	require.Equal(t, map[string]string{"mp_tournament": "1"}, template.Values)
+
+	require.Error(t, app.ValidateCvarTemplate(&store.CvarTemplate{Name: "x"}, nil))
+	require.Error(t, app.ValidateCvarTemplate(&store.CvarTemplate{Name: "x", Values: map[string]string{"sv cheats": "1"}}, nil))
+	require.Error(t, app.ValidateCvarTemplate(&store.CvarTemplate{Name: "x", Values: map[string]string{"hostname": `a";rcon_password "b`}}, nil))
+
+	// Commands would be executed rather than set
+	for _, name := range []string{"quit", "EXEC", "rcon_password", "logaddress_delall"} {
+		require.Error(t, app.ValidateCvarTemplate(&store.CvarTemplate{Name: "x", Values: map[string]string{name: "1"}}, nil))
+	}
+
+	require.Error(t, app.ValidateCvarTemplate(&store.CvarTemplate{Name: "x", Values: map[string]string{"sm_kick": "1"}},
+		[]string{"status", "sm_kick", "*"}))
+}
+
+func TestParseCvarList(t *testing.T) {
+	resp := `cvar list
+--------------
+quit                                     : cmd      :                  : Exit the engine.
+sv_gravity                               : 800      : , "sv", "nf", "rep" : World gravity.
+sv_password                              :          : , "nf", "prot"   : Server password for entry into multiplayer games
+--------------
+  3 total convars/concommands
+`
+	require.Equal(t, map[string]bool{
+		"quit":        true,
+		"sv_gravity":  false,
+		"sv_password": false,
+	}, app.ParseCvarList(resp))
+}
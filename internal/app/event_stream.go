@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"go.uber.org/zap"
+)
+
+// eventSubscriberBuffer is the number of events buffered for each subscriber. Slow clients
+// which fill their buffer will miss events rather than block the log pipeline.
+const eventSubscriberBuffer = 100
+
+// streamEventCategories maps the public category names used by clients to the underlying event types.
+var streamEventCategories = map[string][]logparse.EventType{ //nolint:gochecknoglobals
+	"chat":    {logparse.Say, logparse.SayTeam},
+	"kill":    {logparse.Killed},
+	"connect": {logparse.Connected, logparse.Entered, logparse.Disconnected},
+	"round":   {logparse.WRoundWin},
+}
+
+// streamEvent is the payload sent to web clients.
+type streamEvent struct {
+	ServerID   int                `json:"server_id"`
+	ServerName string             `json:"server_name"`
+	Category   string             `json:"category"`
+	EventType  logparse.EventType `json:"event_type"`
+	Event      any                `json:"event"`
+}
+
+// EventSubscription receives the events matching its filters. An empty filter matches everything.
+type EventSubscription struct {
+	serverIDs  []int
+	eventTypes []logparse.EventType
+	Events     chan logparse.ServerEvent
+}
+
+func (s *EventSubscription) matches(evt logparse.ServerEvent) bool {
+	if len(s.serverIDs) > 0 && !containsInt(s.serverIDs, evt.ServerID) {
+		return false
+	}
+
+	if len(s.eventTypes) == 0 {
+		return true
+	}
+
+	for _, eventType := range s.eventTypes {
+		if eventType == evt.EventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventHub fans out game events to any number of web clients. Unlike fp.Broadcaster, publishing
+// never blocks on slow subscribers.
+type EventHub struct {
+	subscribers map[*EventSubscription]struct{}
+	sync.RWMutex
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: map[*EventSubscription]struct{}{}}
+}
+
+func (h *EventHub) Subscribe(serverIDs []int, eventTypes []logparse.EventType) *EventSubscription {
+	sub := &EventSubscription{
+		serverIDs:  serverIDs,
+		eventTypes: eventTypes,
+		Events:     make(chan logparse.ServerEvent, eventSubscriberBuffer),
+	}
+
+	h.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.Unlock()
+
+	return sub
+}
+
+func (h *EventHub) Unsubscribe(sub *EventSubscription) {
+	h.Lock()
+	delete(h.subscribers, sub)
+	h.Unlock()
+}
+
+// Publish sends the event to all matching subscribers, returning the number of subscribers the
+// event was dropped for due to a full buffer.
+func (h *EventHub) Publish(evt logparse.ServerEvent) int {
+	h.RLock()
+	defer h.RUnlock()
+
+	dropped := 0
+
+	for sub := range h.subscribers {
+		if !sub.matches(evt) {
+			continue
+		}
+
+		select {
+		case sub.Events <- evt:
+		default:
+			dropped++
+		}
+	}
+
+	return dropped
+}
+
+// eventStreamer forwards the streamable event types from the log pipeline to the web client hub.
+func (app *App) eventStreamer(ctx context.Context) {
+	var (
+		log             = app.log.Named("eventStreamer")
+		serverEventChan = make(chan logparse.ServerEvent)
+		eventTypes      []logparse.EventType
+	)
+
+	for _, categoryTypes := range streamEventCategories {
+		eventTypes = append(eventTypes, categoryTypes...)
+	}
+
+	if errRegister := app.eb.Consume(serverEventChan, eventTypes...); errRegister != nil {
+		log.Warn("eventStreamer tried to register duplicate reader channel", zap.Error(errRegister))
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-serverEventChan:
+			if dropped := app.eventHub.Publish(evt); dropped > 0 {
+				log.Debug("Dropped events for slow clients", zap.Int("count", dropped))
+			}
+		}
+	}
+}
+
+func eventCategory(eventType logparse.EventType) string {
+	for category, eventTypes := range streamEventCategories {
+		for _, categoryType := range eventTypes {
+			if categoryType == eventType {
+				return category
+			}
+		}
+	}
+
+	return ""
+}
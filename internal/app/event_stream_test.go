@@ -0,0 +1,37 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHub(t *testing.T) {
+	var (
+		hub     = app.NewEventHub()
+		all     = hub.Subscribe(nil, nil)
+		chat    = hub.Subscribe([]int{1}, []logparse.EventType{logparse.Say})
+		chatEvt = logparse.ServerEvent{ServerID: 1, Results: &logparse.Results{EventType: logparse.Say}}
+		killEvt = logparse.ServerEvent{ServerID: 1, Results: &logparse.Results{EventType: logparse.Killed}}
+	)
+
+	require.Equal(t, 0, hub.Publish(chatEvt))
+	require.Equal(t, 0, hub.Publish(killEvt))
+	require.Equal(t, 0, hub.Publish(logparse.ServerEvent{ServerID: 2, Results: &logparse.Results{EventType: logparse.Say}}))
+
+	require.Len(t, all.Events, 3)
+	require.Len(t, chat.Events, 1)
+	require.Equal(t, chatEvt, <-chat.Events)
+
+	hub.Unsubscribe(chat)
+
+	for len(all.Events) < cap(all.Events) {
+		hub.Publish(chatEvt)
+	}
+
+	// Full subscribers drop events instead of blocking
+	require.Equal(t, 1, hub.Publish(chatEvt))
+	require.Empty(t, chat.Events)
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+)
+
+// HealthRules are the conditions under which a server is considered degraded or down. A zero duration
+// disables the respective rule, as does setting PeakStart equal to PeakEnd.
+type HealthRules struct {
+	// DownAfter is how long rcon may be unreachable before the server is considered down.
+	DownAfter time.Duration
+	// MapStuckAfter is how long a populated server may stay on the same map before it is considered degraded.
+	MapStuckAfter time.Duration
+	// PeakStart and PeakEnd are the hours of the day, in local time, during which a server emptying out is
+	// considered degraded. The range may wrap past midnight.
+	PeakStart int
+	PeakEnd   int
+	// RestartGap is how long rcon may go without responding before it coming back is treated as the server
+	// having crashed or restarted. It should be longer than the interval between samples.
+	RestartGap time.Duration
+}
+
+// HealthSample is a snapshot of the state of a server used to evaluate its health.
+type HealthSample struct {
+	ServerID int
+	// LastSeen is the last time the server responded to a rcon status request.
+	LastSeen time.Time
+	Players  int
+	Map      string
+	// Uptime of the server process, zero when unknown. The uptime going backwards means the server restarted.
+	Uptime time.Duration
+}
+
+// HealthResult is the outcome of evaluating a sample. Initial is set when the server had no previously
+// known state, in which case Changed is always true.
+type HealthResult struct {
+	ServerID int
+	State    store.HealthState
+	Previous store.HealthState
+	Reason   string
+	Changed  bool
+	Initial  bool
+	// Restarted is set when the server crashed or restarted since the previous sample. Changed is always set
+	// along with it, even if the state itself is unchanged.
+	Restarted bool
+}
+
+type serverHealth struct {
+	state        store.HealthState
+	known        bool
+	players      int
+	mapName      string
+	mapSince     time.Time
+	droppedEmpty bool
+	lastSeen     time.Time
+	uptime       time.Duration
+}
+
+// HealthMonitor tracks the health state of each server, evaluating each new sample against the configured rules.
+type HealthMonitor struct {
+	rules   HealthRules
+	started time.Time
+	servers map[int]*serverHealth
+	mu      sync.Mutex
+}
+
+// NewHealthMonitor creates a new monitor. Servers are not considered unreachable until DownAfter has passed since
+// started, giving them a chance to be polled after a restart.
+func NewHealthMonitor(rules HealthRules, started time.Time) *HealthMonitor {
+	return &HealthMonitor{
+		rules:   rules,
+		started: started,
+		servers: map[int]*serverHealth{},
+	}
+}
+
+// SetState sets the last known state of a server, such as one loaded from the database.
+func (m *HealthMonitor) SetState(serverID int, state store.HealthState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health := m.server(serverID)
+	health.state = state
+	health.known = true
+}
+
+func (m *HealthMonitor) server(serverID int) *serverHealth {
+	health, found := m.servers[serverID]
+	if !found {
+		health = &serverHealth{}
+		m.servers[serverID] = health
+	}
+
+	return health
+}
+
+func (m *HealthMonitor) inPeakHours(now time.Time) bool {
+	if m.rules.PeakStart == m.rules.PeakEnd {
+		return false
+	}
+
+	hour := now.Hour()
+
+	if m.rules.PeakStart < m.rules.PeakEnd {
+		return hour >= m.rules.PeakStart && hour < m.rules.PeakEnd
+	}
+
+	return hour >= m.rules.PeakStart || hour < m.rules.PeakEnd
+}
+
+// Update evaluates a new sample for the server, returning its current state.
+func (m *HealthMonitor) Update(sample HealthSample, now time.Time) HealthResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health := m.server(sample.ServerID)
+
+	if sample.Map != health.mapName {
+		health.mapName = sample.Map
+		health.mapSince = now
+	}
+
+	inPeak := m.inPeakHours(now)
+
+	switch {
+	case sample.Players > 0 || !inPeak:
+		health.droppedEmpty = false
+	case health.players > 0:
+		health.droppedEmpty = true
+	}
+
+	health.players = sample.Players
+
+	restarted, restartReason := m.detectRestart(health, sample)
+
+	lastSeen := sample.LastSeen
+	if lastSeen.Before(m.started) {
+		lastSeen = m.started
+	}
+
+	var (
+		state  = store.HealthUp
+		reason string
+	)
+
+	switch {
+	case m.rules.DownAfter > 0 && now.Sub(lastSeen) > m.rules.DownAfter:
+		state = store.HealthDown
+		reason = fmt.Sprintf("RCON unreachable for %s", now.Sub(lastSeen).Round(time.Minute))
+	case m.rules.MapStuckAfter > 0 && sample.Players > 0 && sample.Map != "" &&
+		now.Sub(health.mapSince) > m.rules.MapStuckAfter:
+		state = store.HealthDegraded
+		reason = fmt.Sprintf("Map %s running for %s", sample.Map, now.Sub(health.mapSince).Round(time.Minute))
+	case health.droppedEmpty:
+		state = store.HealthDegraded
+		reason = "Player count dropped to zero during peak hours"
+	}
+
+	if restarted && reason == "" {
+		reason = restartReason
+	}
+
+	result := HealthResult{
+		ServerID:  sample.ServerID,
+		State:     state,
+		Previous:  health.state,
+		Reason:    reason,
+		Changed:   !health.known || health.state != state || restarted,
+		Initial:   !health.known,
+		Restarted: restarted,
+	}
+
+	health.state = state
+	health.known = true
+
+	return result
+}
+
+// detectRestart compares the sample against the previous one. A server is considered to have restarted when its
+// uptime goes backwards, or when rcon responds again after being unreachable for longer than RestartGap.
+func (m *HealthMonitor) detectRestart(health *serverHealth, sample HealthSample) (bool, string) {
+	var (
+		restarted bool
+		reason    string
+	)
+
+	switch {
+	case sample.Uptime > 0 && health.uptime > 0 && sample.Uptime < health.uptime:
+		restarted = true
+		reason = fmt.Sprintf("Server restarted, up for %s", sample.Uptime.Round(time.Minute))
+	case m.rules.RestartGap > 0 && !health.lastSeen.IsZero() && sample.LastSeen.Sub(health.lastSeen) > m.rules.RestartGap:
+		restarted = true
+		reason = fmt.Sprintf("Server responding again after %s", sample.LastSeen.Sub(health.lastSeen).Round(time.Minute))
+	}
+
+	if sample.Uptime > 0 {
+		health.uptime = sample.Uptime
+	}
+
+	if sample.LastSeen.After(health.lastSeen) {
+		health.lastSeen = sample.LastSeen
+	}
+
+	return restarted, reason
+}
+
+// Uptime returns the percentage of time between since and now that the server was not down, according to the
+// health events provided in chronological order. Time before the first event is not counted. When there is no
+// recorded history, 100 is returned.
+func Uptime(events []store.ServerHealthEvent, since time.Time, now time.Time) float64 {
+	var total, down time.Duration
+
+	for idx, event := range events {
+		start := event.CreatedOn
+		if start.Before(since) {
+			start = since
+		}
+
+		end := now
+		if idx+1 < len(events) {
+			end = events[idx+1].CreatedOn
+		}
+
+		if !end.After(start) {
+			continue
+		}
+
+		total += end.Sub(start)
+
+		if event.State == store.HealthDown {
+			down += end.Sub(start)
+		}
+	}
+
+	if total == 0 {
+		return 100
+	}
+
+	return float64(total-down) / float64(total) * 100
+}
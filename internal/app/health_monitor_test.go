@@ -0,0 +1,132 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	var (
+		start   = time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+		monitor = app.NewHealthMonitor(app.HealthRules{
+			DownAfter:     time.Minute * 5,
+			MapStuckAfter: time.Hour,
+			PeakStart:     18,
+			PeakEnd:       2,
+		}, start)
+		sample = app.HealthSample{ServerID: 1, LastSeen: start, Players: 10, Map: "pl_upward"}
+	)
+
+	initial := monitor.Update(sample, start)
+	require.True(t, initial.Initial)
+	require.True(t, initial.Changed)
+	require.Equal(t, store.HealthUp, initial.State)
+
+	require.False(t, monitor.Update(sample, start.Add(time.Minute)).Changed)
+
+	// Same map with players for too long
+	sample.LastSeen = start.Add(time.Minute * 61)
+	stuck := monitor.Update(sample, start.Add(time.Minute*61))
+	require.True(t, stuck.Changed)
+	require.Equal(t, store.HealthDegraded, stuck.State)
+
+	sample.Map = "pl_badwater"
+	require.Equal(t, store.HealthUp, monitor.Update(sample, start.Add(time.Minute*62)).State)
+
+	// Rcon unreachable, LastSeen is no longer updated
+	down := monitor.Update(sample, start.Add(time.Minute*70))
+	require.Equal(t, store.HealthDown, down.State)
+	require.Equal(t, store.HealthUp, down.Previous)
+
+	// Emptying out during peak hours
+	peak := start.Add(time.Hour * 7)
+	sample.LastSeen = peak
+	sample.Map = "cp_process"
+	require.Equal(t, store.HealthUp, monitor.Update(sample, peak).State)
+
+	sample.Players = 0
+	require.Equal(t, store.HealthDegraded, monitor.Update(sample, peak.Add(time.Minute)).State)
+	// Peak hours end at 02:00
+	sample.LastSeen = start.Add(time.Hour * 14)
+	require.Equal(t, store.HealthUp, monitor.Update(sample, start.Add(time.Hour*14)).State)
+
+	known := app.NewHealthMonitor(app.HealthRules{}, start)
+	known.SetState(2, store.HealthDown)
+	recovered := known.Update(app.HealthSample{ServerID: 2, LastSeen: start}, start)
+	require.False(t, recovered.Initial)
+	require.True(t, recovered.Changed)
+	require.Equal(t, store.HealthDown, recovered.Previous)
+}
+
+func TestHealthMonitorRestart(t *testing.T) {
+	var (
+		start   = time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+		monitor = app.NewHealthMonitor(app.HealthRules{
+			DownAfter:  time.Minute * 5,
+			RestartGap: time.Minute * 3,
+		}, start)
+		sample = app.HealthSample{ServerID: 1, LastSeen: start, Players: 10, Map: "pl_upward", Uptime: time.Hour}
+	)
+
+	require.True(t, monitor.Update(sample, start).Initial)
+
+	sample.LastSeen = start.Add(time.Minute)
+	sample.Uptime = time.Hour + time.Minute
+	require.False(t, monitor.Update(sample, start.Add(time.Minute)).Changed)
+
+	// Uptime going backwards
+	sample.LastSeen = start.Add(time.Minute * 2)
+	sample.Uptime = time.Minute
+	restarted := monitor.Update(sample, start.Add(time.Minute*2))
+	require.True(t, restarted.Restarted)
+	require.True(t, restarted.Changed)
+	require.Equal(t, store.HealthUp, restarted.State)
+	require.Equal(t, store.HealthUp, restarted.Previous)
+	require.NotEmpty(t, restarted.Reason)
+
+	// Unknown uptime is ignored
+	sample.LastSeen = start.Add(time.Minute * 3)
+	sample.Uptime = 0
+	require.False(t, monitor.Update(sample, start.Add(time.Minute*3)).Changed)
+
+	// Reconnecting after a gap shorter than the down threshold
+	sample.LastSeen = start.Add(time.Minute * 7)
+	reconnected := monitor.Update(sample, start.Add(time.Minute*7))
+	require.True(t, reconnected.Restarted)
+	require.Equal(t, store.HealthUp, reconnected.State)
+
+	// Reconnecting after being down
+	down := monitor.Update(sample, start.Add(time.Minute*20))
+	require.Equal(t, store.HealthDown, down.State)
+	require.False(t, down.Restarted)
+
+	sample.LastSeen = start.Add(time.Minute * 21)
+	recovered := monitor.Update(sample, start.Add(time.Minute*21))
+	require.True(t, recovered.Restarted)
+	require.Equal(t, store.HealthUp, recovered.State)
+	require.Equal(t, store.HealthDown, recovered.Previous)
+
+	sample.LastSeen = start.Add(time.Minute * 22)
+	require.False(t, monitor.Update(sample, start.Add(time.Minute*22)).Changed)
+}
+
+func TestUptime(t *testing.T) {
+	var (
+		since = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		now   = since.Add(time.Hour * 10)
+	)
+
+	require.InDelta(t, 100.0, app.Uptime(nil, since, now), 0.001)
+
+	events := []store.ServerHealthEvent{
+		{State: store.HealthUp, CreatedOn: since.Add(-time.Hour)},
+		{State: store.HealthDown, CreatedOn: since.Add(time.Hour * 2)},
+		{State: store.HealthDegraded, CreatedOn: since.Add(time.Hour * 3)},
+	}
+
+	require.InDelta(t, 90.0, app.Uptime(events, since, now), 0.001)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"runtime"
 	"sort"
@@ -21,6 +22,7 @@ import (
 	"github.com/leighmacdonald/gbans/pkg/ip2location"
 	"github.com/leighmacdonald/gbans/pkg/util"
 	"github.com/leighmacdonald/gbans/pkg/wiki"
+	"github.com/leighmacdonald/steamid/v3/extra"
 	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/leighmacdonald/steamweb/v2"
 	"github.com/pkg/errors"
@@ -50,21 +52,9 @@ func onAPIPostDemosQuery(app *App) gin.HandlerFunc {
 
 // https://prometheus.io/docs/prometheus/latest/configuration/configuration/#http_sd_config
 func onAPIGetPrometheusHosts(app *App) gin.HandlerFunc {
-	type promStaticConfig struct {
-		Targets []string          `json:"targets"`
-		Labels  map[string]string `json:"labels"`
-	}
-
-	type portMap struct {
-		Type string
-		Port int
-	}
-
 	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
 
 	return func(ctx *gin.Context) {
-		var staticConfigs []promStaticConfig
-
 		servers, _, errGetServers := app.db.GetServers(ctx, store.ServerQueryFilter{})
 		if errGetServers != nil {
 			log.Error("Failed to fetch servers", zap.Error(errGetServers))
@@ -73,32 +63,87 @@ func onAPIGetPrometheusHosts(app *App) gin.HandlerFunc {
 			return
 		}
 
-		for _, nodePortConfig := range []portMap{{"node", 9100}} {
-			staticConfig := promStaticConfig{Targets: nil, Labels: map[string]string{}}
-			staticConfig.Labels["__meta_prometheus_job"] = nodePortConfig.Type
+		// Don't wrap in our custom response format
+		ctx.JSON(http.StatusOK, PrometheusTargets(servers, app.conf.ServiceDiscovery.Exporters))
+	}
+}
 
-			for _, server := range servers {
-				host := fmt.Sprintf("%s:%d", server.Address, nodePortConfig.Port)
-				found := false
+// https://docs.ansible.com/ansible/latest/dev_guide/developing_inventory.html
+func onAPIGetAnsibleHosts(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
 
-				for _, hostName := range staticConfig.Targets {
-					if hostName == host {
-						found = true
+	return func(ctx *gin.Context) {
+		servers, serverGroups, errData := app.serviceDiscoveryData(ctx)
+		if errData != nil {
+			log.Error("Failed to load service discovery data", zap.Error(errData))
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
 
-						break
-					}
-				}
+			return
+		}
 
-				if !found {
-					staticConfig.Targets = append(staticConfig.Targets, host)
-				}
-			}
+		ctx.JSON(http.StatusOK, NewAnsibleInventory(servers, serverGroups))
+	}
+}
+
+// consulCatalog loads the current catalog and sets the index header expected by consul clients. The index changes
+// whenever a server is updated.
+func consulCatalog(ctx *gin.Context, app *App) (map[string][]ConsulCatalogService, error) {
+	servers, serverGroups, errData := app.serviceDiscoveryData(ctx)
+	if errData != nil {
+		return nil, errData
+	}
 
-			staticConfigs = append(staticConfigs, staticConfig)
+	var index int64 = 1
+
+	for _, server := range servers {
+		if updated := server.UpdatedOn.Unix(); updated > index {
+			index = updated
 		}
+	}
 
-		// Don't wrap in our custom response format
-		ctx.JSON(200, staticConfigs)
+	ctx.Header("X-Consul-Index", strconv.FormatInt(index, 10))
+
+	return ConsulCatalog(app.conf.ServiceDiscovery.Datacenter, servers, serverGroups,
+		app.conf.ServiceDiscovery.Exporters), nil
+}
+
+// https://developer.hashicorp.com/consul/api-docs/catalog#list-services
+func onAPIGetConsulServices(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		catalog, errCatalog := consulCatalog(ctx, app)
+		if errCatalog != nil {
+			log.Error("Failed to load service discovery data", zap.Error(errCatalog))
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, ConsulServices(catalog))
+	}
+}
+
+// https://developer.hashicorp.com/consul/api-docs/catalog#list-nodes-for-service
+func onAPIGetConsulService(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		catalog, errCatalog := consulCatalog(ctx, app)
+		if errCatalog != nil {
+			log.Error("Failed to load service discovery data", zap.Error(errCatalog))
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			return
+		}
+
+		entries, found := catalog[ctx.Param("service_name")]
+		if !found {
+			// Consul returns an empty list for unknown services
+			entries = []ConsulCatalogService{}
+		}
+
+		ctx.JSON(http.StatusOK, entries)
 	}
 }
 
@@ -115,6 +160,27 @@ func getDefaultFloat64(s string, def float64) float64 {
 	return def
 }
 
+func newBaseServer(srv serverDetails, lat float64, lon float64) baseServer {
+	return baseServer{
+		Host:       srv.Host,
+		Port:       srv.Port,
+		IP:         srv.IP,
+		Name:       srv.Name,
+		NameShort:  srv.NameShort,
+		Region:     srv.Region,
+		CC:         srv.CC,
+		ServerID:   srv.ServerID,
+		Players:    srv.PlayerCount,
+		MaxPlayers: srv.MaxPlayers,
+		Bots:       srv.Bots,
+		Map:        srv.Map,
+		GameTypes:  []string{},
+		Latitude:   srv.Latitude,
+		Longitude:  srv.Longitude,
+		Distance:   distance(srv.Latitude, srv.Longitude, lat, lon),
+	}
+}
+
 func onAPIGetServerStates(app *App) gin.HandlerFunc {
 	type UserServers struct {
 		Servers []baseServer        `json:"servers"`
@@ -131,24 +197,7 @@ func onAPIGetServerStates(app *App) gin.HandlerFunc {
 		)
 
 		for _, srv := range curState {
-			servers = append(servers, baseServer{
-				Host:       srv.Host,
-				Port:       srv.Port,
-				IP:         srv.IP,
-				Name:       srv.Name,
-				NameShort:  srv.NameShort,
-				Region:     srv.Region,
-				CC:         srv.CC,
-				ServerID:   srv.ServerID,
-				Players:    srv.PlayerCount,
-				MaxPlayers: srv.MaxPlayers,
-				Bots:       srv.Bots,
-				Map:        srv.Map,
-				GameTypes:  []string{},
-				Latitude:   srv.Latitude,
-				Longitude:  srv.Longitude,
-				Distance:   distance(srv.Latitude, srv.Longitude, lat, lon),
-			})
+			servers = append(servers, newBaseServer(srv, lat, lon))
 		}
 
 		sort.SliceStable(servers, func(i, j int) bool {
@@ -165,6 +214,103 @@ func onAPIGetServerStates(app *App) gin.HandlerFunc {
 	}
 }
 
+// statePlayer is the public view of a player shown in the server browser.
+type statePlayer struct {
+	SteamID steamid.SID64 `json:"steam_id"`
+	Name    string        `json:"name"`
+}
+
+func newStatePlayers(players []extra.Player) []statePlayer {
+	out := make([]statePlayer, len(players))
+	for i, player := range players {
+		out[i] = statePlayer{SteamID: player.SID, Name: player.Name}
+	}
+
+	return out
+}
+
+// streamServer is a server as sent over the state stream. PlayerList is only set for authenticated users.
+type streamServer struct {
+	baseServer
+	PlayerList []statePlayer `json:"player_list,omitempty"`
+}
+
+// onAPIGetServerStateStream sends the current server state followed by a diff each time a server changes. Clients
+// should reconnect to receive a fresh snapshot if they see a gap in the sequence numbers.
+func onAPIGetServerStateStream(app *App) gin.HandlerFunc {
+	const keepAlive = time.Second * 30
+
+	type stateSnapshot struct {
+		Seq     uint64              `json:"seq"`
+		Servers []streamServer      `json:"servers"`
+		LatLong ip2location.LatLong `json:"lat_long"`
+	}
+
+	type stateDiff struct {
+		Seq      uint64        `json:"seq"`
+		ServerID int           `json:"server_id"`
+		Removed  bool          `json:"removed"`
+		Server   *streamServer `json:"server,omitempty"`
+		Joined   []statePlayer `json:"joined,omitempty"`
+		Left     []statePlayer `json:"left,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var (
+			lat           = getDefaultFloat64(ctx.GetHeader("cf-iplatitude"), 41.7774)
+			lon           = getDefaultFloat64(ctx.GetHeader("cf-iplongitude"), -87.6160)
+			authenticated = currentUserProfile(ctx).PermissionLevel >= consts.PUser
+		)
+
+		sub, curState, seq := app.state.subscribe()
+		defer app.state.unsubscribe(sub)
+
+		snapshot := stateSnapshot{
+			Seq:     seq,
+			Servers: []streamServer{},
+			LatLong: ip2location.LatLong{Latitude: lat, Longitude: lon},
+		}
+
+		for _, srv := range curState {
+			server := streamServer{baseServer: newBaseServer(srv, lat, lon)}
+			if authenticated {
+				server.PlayerList = newStatePlayers(srv.Players)
+			}
+
+			snapshot.Servers = append(snapshot.Servers, server)
+		}
+
+		ctx.SSEvent("snapshot", snapshot)
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+
+		ctx.Stream(func(_ io.Writer) bool {
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case <-ticker.C:
+				ctx.SSEvent("ping", gin.H{})
+			case update := <-sub.Updates:
+				diff := stateDiff{Seq: update.Seq, ServerID: update.ServerID, Removed: update.Removed}
+
+				if !update.Removed {
+					diff.Server = &streamServer{baseServer: newBaseServer(update.Server, lat, lon)}
+				}
+
+				if authenticated {
+					diff.Joined = newStatePlayers(update.Joined)
+					diff.Left = newStatePlayers(update.Left)
+				}
+
+				ctx.SSEvent("update", diff)
+			}
+
+			return true
+		})
+	}
+}
+
 func onAPIExportBansValveSteamID(app *App) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		bans, _, errBans := app.db.GetBansSteam(ctx, store.SteamBansQueryFilter{
@@ -932,3 +1078,71 @@ func onAPIForumMessagesRecent(app *App) gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, messages)
 	}
 }
+
+func onAPIGetServerGroups(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		groups, errGroups := app.db.GetServerGroups(ctx)
+		if errGroups != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server groups", zap.Error(errGroups))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, groups)
+	}
+}
+
+// onAPIPostQuickPlay returns the servers best suited to the user, ranked from best to worst.
+func onAPIPostQuickPlay(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var prefs QuickPlayPrefs
+		if !bind(ctx, log, &prefs) {
+			return
+		}
+
+		var (
+			user     = currentUserProfile(ctx)
+			fallback = ip2location.LatLong{
+				Latitude:  getDefaultFloat64(ctx.GetHeader("cf-iplatitude"), 41.7774),
+				Longitude: getDefaultFloat64(ctx.GetHeader("cf-iplongitude"), -87.6160),
+			}
+			location = app.quickPlayLocation(ctx, net.ParseIP(ctx.ClientIP()), fallback)
+		)
+
+		results, errResults := app.quickPlay(ctx, user.SteamID, user.PermissionLevel, location, prefs)
+		if errResults != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to select quickplay server", zap.Error(errResults))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, results)
+	}
+}
+
+func onAPIPostCommunityServers(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var query CommunityServerQuery
+		if !bind(ctx, log, &query) {
+			return
+		}
+
+		if query.Limit == 0 || query.Limit > 100 {
+			query.Limit = 100
+		}
+
+		servers, count := app.communityBrowser.Search(query)
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, servers))
+	}
+}
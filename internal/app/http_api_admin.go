@@ -4,12 +4,16 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/leighmacdonald/gbans/internal/consts"
 	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -34,6 +38,12 @@ func onAPIPostServer(app *App) gin.HandlerFunc {
 		server.IsEnabled = req.IsEnabled
 
 		if errSave := app.db.SaveServer(ctx, &server); errSave != nil {
+			if errors.Is(errSave, store.ErrDuplicate) {
+				responseErr(ctx, http.StatusConflict, store.ErrDuplicate)
+
+				return
+			}
+
 			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
 			log.Error("Failed to save new server", zap.Error(errSave))
 
@@ -103,6 +113,12 @@ func onAPIPostServerUpdate(app *App) gin.HandlerFunc {
 		server.EnableStats = req.EnableStats
 
 		if errSave := app.db.SaveServer(ctx, &server); errSave != nil {
+			if errors.Is(errSave, store.ErrDuplicate) {
+				responseErr(ctx, http.StatusConflict, store.ErrDuplicate)
+
+				return
+			}
+
 			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
 			log.Error("Failed to update server", zap.Error(errSave))
 
@@ -554,3 +570,1491 @@ func onAPIPostBlocklistCheck(app *App) gin.HandlerFunc {
 		})
 	}
 }
+
+func onAPIGetBotNamePatterns(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		patterns, errPatterns := app.db.GetBotNamePatterns(ctx)
+		if errPatterns != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load bot name patterns", zap.Error(errPatterns))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, patterns)
+	}
+}
+
+func onAPIPostBotNamePattern(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type createRequest struct {
+		Pattern string `json:"pattern"`
+		Note    string `json:"note"`
+	}
+
+	return func(ctx *gin.Context) {
+		var req createRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if _, errCompile := regexp.Compile(req.Pattern); req.Pattern == "" || errCompile != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		pattern := store.BotNamePattern{
+			Pattern: req.Pattern,
+			Note:    req.Note,
+		}
+
+		if errSave := app.db.SaveBotNamePattern(ctx, &pattern); errSave != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save bot name pattern", zap.Error(errSave))
+
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, pattern)
+
+		if errLoad := app.loadBotDefense(ctx); errLoad != nil {
+			log.Error("Failed to reload bot defense", zap.Error(errLoad))
+		}
+	}
+}
+
+func onAPIDeleteBotNamePattern(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		patternID, errPatternID := getIntParam(ctx, "bot_name_pattern_id")
+		if errPatternID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteBotNamePattern(ctx, patternID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete bot name pattern", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+
+		if errLoad := app.loadBotDefense(ctx); errLoad != nil {
+			log.Error("Failed to reload bot defense", zap.Error(errLoad))
+		}
+	}
+}
+
+func onAPIPostBotSteamIDs(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type importRequest struct {
+		// SteamIDs is a blob of text containing steam ids in any format
+		SteamIDs string `json:"steam_ids"`
+		Source   string `json:"source"`
+	}
+
+	type importResponse struct {
+		Count int `json:"count"`
+	}
+
+	return func(ctx *gin.Context) {
+		var req importRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		var steamIDs steamid.Collection
+
+		for _, steamID := range steamid.ParseString(req.SteamIDs) {
+			if steamID.Valid() && !steamIDs.Contains(steamID) {
+				steamIDs = append(steamIDs, steamID)
+			}
+		}
+
+		if len(steamIDs) == 0 {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errSave := app.db.SaveBotSteamIDs(ctx, steamIDs, req.Source); errSave != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to import bot steam ids", zap.Error(errSave))
+
+			return
+		}
+
+		app.botDetector.AddSteamIDs(steamIDs)
+
+		ctx.JSON(http.StatusOK, importResponse{Count: len(steamIDs)})
+	}
+}
+
+func onAPIDeleteBotSteamID(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		steamID, errSteamID := getSID64Param(ctx, "steam_id")
+		if errSteamID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteBotSteamID(ctx, steamID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete bot steam id", zap.Error(errDelete))
+
+			return
+		}
+
+		app.botDetector.RemoveSteamID(steamID)
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIGetBotReport(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		entries, errReport := app.db.GetBotDetectionReport(ctx, time.Now().Add(-botReportPeriod))
+		if errReport != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load bot report", zap.Error(errReport))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, entries)
+	}
+}
+
+func onAPIGetServerHealth(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type serverHealthStatus struct {
+		ServerID   int               `json:"server_id"`
+		ServerName string            `json:"server_name"`
+		Known      bool              `json:"known"`
+		State      store.HealthState `json:"state"`
+		Reason     string            `json:"reason"`
+		Since      time.Time         `json:"since"`
+		Uptime     float64           `json:"uptime"`
+	}
+
+	return func(ctx *gin.Context) {
+		now := time.Now()
+
+		events, errEvents := app.db.GetServerHealthEvents(ctx, 0, now.Add(-serverUptimePeriod))
+		if errEvents != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server health events", zap.Error(errEvents))
+
+			return
+		}
+
+		serverEvents := map[int][]store.ServerHealthEvent{}
+		for _, event := range events {
+			serverEvents[event.ServerID] = append(serverEvents[event.ServerID], event)
+		}
+
+		statuses := make([]serverHealthStatus, 0)
+
+		for _, server := range app.state.current() {
+			status := serverHealthStatus{
+				ServerID:   server.ServerID,
+				ServerName: server.NameShort,
+				Uptime:     Uptime(serverEvents[server.ServerID], now.Add(-serverUptimePeriod), now),
+			}
+
+			if history := serverEvents[server.ServerID]; len(history) > 0 {
+				last := history[len(history)-1]
+				status.Known = true
+				status.State = last.State
+				status.Reason = last.Reason
+				status.Since = last.CreatedOn
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		ctx.JSON(http.StatusOK, statuses)
+	}
+}
+
+func onAPIGetServerHealthHistory(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type serverHealthHistory struct {
+		Uptime float64                   `json:"uptime"`
+		Events []store.ServerHealthEvent `json:"events"`
+	}
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		days, errDays := strconv.Atoi(ctx.DefaultQuery("days", "7"))
+		if errDays != nil || days <= 0 {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var (
+			now   = time.Now()
+			since = now.AddDate(0, 0, -days)
+		)
+
+		events, errEvents := app.db.GetServerHealthEvents(ctx, serverID, since)
+		if errEvents != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server health events", zap.Error(errEvents))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, serverHealthHistory{
+			Uptime: Uptime(events, since, now),
+			Events: events,
+		})
+	}
+}
+
+func onAPIGetRconTasks(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		tasks, errTasks := app.db.GetRconTasks(ctx)
+		if errTasks != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load rcon tasks", zap.Error(errTasks))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, tasks)
+	}
+}
+
+func onAPIPostRconTask(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.RconTask
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		task := store.RconTask{}
+
+		if taskIDParam := ctx.Param("rcon_task_id"); taskIDParam != "" {
+			taskID, errTaskID := getIntParam(ctx, "rcon_task_id")
+			if errTaskID != nil {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+				return
+			}
+
+			if errTask := app.db.GetRconTask(ctx, taskID, &task); errTask != nil {
+				if errors.Is(errTask, store.ErrNoResult) {
+					responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+					return
+				}
+
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+				log.Error("Failed to load rcon task", zap.Error(errTask))
+
+				return
+			}
+		}
+
+		task.Name = strings.TrimSpace(req.Name)
+		task.Schedule = strings.TrimSpace(req.Schedule)
+		task.ServerIDs = req.ServerIDs
+		task.ServerGroupIDs = req.ServerGroupIDs
+		task.Region = req.Region
+		task.AllServers = req.AllServers
+		task.PlayersBelow = req.PlayersBelow
+		task.Enabled = req.Enabled
+		task.Commands = nil
+
+		for _, command := range req.Commands {
+			if command = strings.TrimSpace(command); command != "" {
+				task.Commands = append(task.Commands, command)
+			}
+		}
+
+		if errValidate := app.validateRconTask(task); errValidate != nil {
+			responseErr(ctx, http.StatusBadRequest, errValidate)
+
+			return
+		}
+
+		if errSave := app.db.SaveRconTask(ctx, &task); errSave != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save rcon task", zap.Error(errSave))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, task)
+	}
+}
+
+func onAPIDeleteRconTask(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		taskID, errTaskID := getIntParam(ctx, "rcon_task_id")
+		if errTaskID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteRconTask(ctx, taskID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete rcon task", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+// onAPIPostRconTaskRun runs a task immediately, regardless of its schedule or enabled state.
+func onAPIPostRconTaskRun(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		taskID, errTaskID := getIntParam(ctx, "rcon_task_id")
+		if errTaskID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var task store.RconTask
+		if errTask := app.db.GetRconTask(ctx, taskID, &task); errTask != nil {
+			if errors.Is(errTask, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load rcon task", zap.Error(errTask))
+
+			return
+		}
+
+		runs, errRun := app.runRconTask(ctx.Request.Context(), task, currentUserProfile(ctx).SteamID)
+		if errRun != nil {
+			if errors.Is(errRun, errRconNoTargets) {
+				responseErr(ctx, http.StatusBadRequest, errRun)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to run rcon task", zap.Error(errRun))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, runs)
+	}
+}
+
+func onAPIGetRconTaskRuns(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.RconTaskRunQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		runs, count, errRuns := app.db.GetRconTaskRuns(ctx, req)
+		if errRuns != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load rcon task runs", zap.Error(errRuns))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, runs))
+	}
+}
+
+func onAPIGetCvarTemplates(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		templates, errTemplates := app.db.GetCvarTemplates(ctx)
+		if errTemplates != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load cvar templates", zap.Error(errTemplates))
+
+			return
+		}
+
+		assigned, errAssigned := app.db.GetServerCvarTemplates(ctx)
+		if errAssigned != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server cvar templates", zap.Error(errAssigned))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"templates": templates,
+			"servers":   assigned,
+		})
+	}
+}
+
+func onAPIPostCvarTemplate(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.CvarTemplate
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		template := store.CvarTemplate{}
+
+		if templateIDParam := ctx.Param("cvar_template_id"); templateIDParam != "" {
+			templateID, errTemplateID := getIntParam(ctx, "cvar_template_id")
+			if errTemplateID != nil {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+				return
+			}
+
+			if errTemplate := app.db.GetCvarTemplate(ctx, templateID, &template); errTemplate != nil {
+				if errors.Is(errTemplate, store.ErrNoResult) {
+					responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+					return
+				}
+
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+				log.Error("Failed to load cvar template", zap.Error(errTemplate))
+
+				return
+			}
+		}
+
+		template.Name = req.Name
+		template.Note = req.Note
+		template.Values = req.Values
+
+		blocked := append(append([]string{}, app.conf.RconConsole.ModeratorCommands...), app.conf.RconConsole.AdminCommands...)
+
+		if errValidate := ValidateCvarTemplate(&template, blocked); errValidate != nil {
+			responseErr(ctx, http.StatusBadRequest, errValidate)
+
+			return
+		}
+
+		if errSave := app.db.SaveCvarTemplate(ctx, &template); errSave != nil {
+			if errors.Is(errSave, store.ErrDuplicate) {
+				responseErr(ctx, http.StatusConflict, store.ErrDuplicate)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save cvar template", zap.Error(errSave))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, template)
+	}
+}
+
+func onAPIDeleteCvarTemplate(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		templateID, errTemplateID := getIntParam(ctx, "cvar_template_id")
+		if errTemplateID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteCvarTemplate(ctx, templateID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete cvar template", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIPutServerCvarTemplate(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type assignRequest struct {
+		CvarTemplateID int `json:"cvar_template_id"`
+	}
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var req assignRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if errAssign := app.db.SetServerCvarTemplate(ctx, serverID, req.CvarTemplateID); errAssign != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to assign cvar template", zap.Error(errAssign))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+// onAPIPostServerCvarTemplateApply pushes the values of the assigned template to the server, returning any
+// drift which remains afterwards.
+func onAPIPostServerCvarTemplateApply(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		drift, errApply := app.applyCvarTemplate(ctx, serverID)
+		if errApply != nil {
+			switch {
+			case errors.Is(errApply, errCvarNoTemplate):
+				responseErr(ctx, http.StatusBadRequest, errApply)
+			case errors.Is(errApply, errUnknownServer):
+				responseErr(ctx, http.StatusServiceUnavailable, errApply)
+			default:
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+				log.Error("Failed to apply cvar template", zap.Error(errApply))
+			}
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, drift)
+	}
+}
+
+func onAPIGetCvarDrift(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		drift, errDrift := app.db.GetCvarDrift(ctx, 0)
+		if errDrift != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load cvar drift", zap.Error(errDrift))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, drift)
+	}
+}
+
+// onAPIPostCvarDriftCheck checks all servers immediately instead of waiting for the next scheduled check.
+func onAPIPostCvarDriftCheck(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		if errCheck := app.checkCvarDrift(ctx); errCheck != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to check cvar drift", zap.Error(errCheck))
+
+			return
+		}
+
+		drift, errDrift := app.db.GetCvarDrift(ctx, 0)
+		if errDrift != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load cvar drift", zap.Error(errDrift))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, drift)
+	}
+}
+
+func onAPIGetMapRotations(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		rotations, errRotations := app.db.GetMapRotations(ctx)
+		if errRotations != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load map rotations", zap.Error(errRotations))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rotations)
+	}
+}
+
+func onAPIPostMapRotation(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var req store.MapRotation
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		var server store.Server
+		if errServer := app.db.GetServer(ctx, serverID, &server); errServer != nil {
+			if errors.Is(errServer, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server", zap.Error(errServer))
+
+			return
+		}
+
+		rotation := store.MapRotation{ServerID: server.ServerID, ServerName: server.ShortName}
+
+		var existing store.MapRotation
+		if errExisting := app.db.GetMapRotation(ctx, serverID, &existing); errExisting == nil {
+			rotation.CreatedOn = existing.CreatedOn
+		}
+
+		rotation.Enabled = req.Enabled
+		rotation.ExcludeRecent = req.ExcludeRecent
+		rotation.UseStats = req.UseStats
+		rotation.Maps = req.Maps
+
+		if errValidate := validateMapRotation(&rotation); errValidate != nil {
+			responseErr(ctx, http.StatusBadRequest, errValidate)
+
+			return
+		}
+
+		if errSave := app.db.SaveMapRotation(ctx, &rotation); errSave != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save map rotation", zap.Error(errSave))
+
+			return
+		}
+
+		if rotation.Maps == nil {
+			rotation.Maps = []store.MapRotationEntry{}
+		}
+
+		ctx.JSON(http.StatusOK, rotation)
+	}
+}
+
+func onAPIDeleteMapRotation(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteMapRotation(ctx, serverID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete map rotation", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+// onAPIGetMapRotationCandidates shows the maps which would currently be considered for the next map, along
+// with their effective weights. The current player count is used unless the players query value is given.
+func onAPIGetMapRotationCandidates(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var rotation store.MapRotation
+		if errRotation := app.db.GetMapRotation(ctx, serverID, &rotation); errRotation != nil {
+			if errors.Is(errRotation, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load map rotation", zap.Error(errRotation))
+
+			return
+		}
+
+		var (
+			players    int
+			currentMap string
+			state      = app.state.current()
+		)
+
+		if server, found := state.byServerID(serverID); found {
+			players = server.PlayerCount
+			currentMap = server.Map
+		}
+
+		if playersQuery := ctx.Query("players"); playersQuery != "" {
+			parsedPlayers, errPlayers := strconv.Atoi(playersQuery)
+			if errPlayers != nil || parsedPlayers < 0 {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+				return
+			}
+
+			players = parsedPlayers
+		}
+
+		candidates, errCandidates := app.mapRotationCandidates(ctx, rotation, players, currentMap)
+		if errCandidates != nil {
+			if errors.Is(errCandidates, errMapRotationEmpty) {
+				ctx.JSON(http.StatusOK, []MapCandidate{})
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to calculate map candidates", zap.Error(errCandidates))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, candidates)
+	}
+}
+
+// onAPIPostMapRotationRotate chooses and sets the next map immediately rather than waiting for the end of
+// the current map.
+func onAPIPostMapRotationRotate(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var rotation store.MapRotation
+		if errRotation := app.db.GetMapRotation(ctx, serverID, &rotation); errRotation != nil {
+			if errors.Is(errRotation, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load map rotation", zap.Error(errRotation))
+
+			return
+		}
+
+		change, errRotate := app.rotateMap(ctx, rotation)
+		if errRotate != nil {
+			if errors.Is(errRotate, errMapRotationEmpty) {
+				responseErr(ctx, http.StatusBadRequest, errRotate)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to rotate map", zap.Error(errRotate))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, change)
+	}
+}
+
+func onAPIGetMapRotationChanges(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.MapRotationChangeQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		changes, count, errChanges := app.db.GetMapRotationChanges(ctx, req)
+		if errChanges != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load map rotation changes", zap.Error(errChanges))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, changes))
+	}
+}
+
+func onAPIPostServerGroup(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.ServerGroup
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		group := store.ServerGroup{}
+
+		if groupIDParam := ctx.Param("server_group_id"); groupIDParam != "" {
+			groupID, errGroupID := getIntParam(ctx, "server_group_id")
+			if errGroupID != nil {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+				return
+			}
+
+			if errGroup := app.db.GetServerGroup(ctx, groupID, &group); errGroup != nil {
+				if errors.Is(errGroup, store.ErrNoResult) {
+					responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+					return
+				}
+
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+				log.Error("Failed to load server group", zap.Error(errGroup))
+
+				return
+			}
+		}
+
+		group.Name = req.Name
+		group.Description = req.Description
+		group.ServerIDs = req.ServerIDs
+
+		if errValidate := validateServerGroup(&group); errValidate != nil {
+			responseErr(ctx, http.StatusBadRequest, errValidate)
+
+			return
+		}
+
+		if errSave := app.db.SaveServerGroup(ctx, &group); errSave != nil {
+			if errors.Is(errSave, store.ErrDuplicate) {
+				responseErr(ctx, http.StatusConflict, store.ErrDuplicate)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save server group", zap.Error(errSave))
+
+			return
+		}
+
+		if group.ServerIDs == nil {
+			group.ServerIDs = []int{}
+		}
+
+		ctx.JSON(http.StatusOK, group)
+	}
+}
+
+func onAPIDeleteServerGroup(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		groupID, errGroupID := getIntParam(ctx, "server_group_id")
+		if errGroupID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteServerGroup(ctx, groupID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete server group", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIPutServerGroupMember(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		groupID, errGroupID := getIntParam(ctx, "server_group_id")
+		if errGroupID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errAdd := app.db.AddServerGroupMember(ctx, groupID, serverID); errAdd != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to add server group member", zap.Error(errAdd))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIDeleteServerGroupMember(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		groupID, errGroupID := getIntParam(ctx, "server_group_id")
+		if errGroupID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errRemove := app.db.RemoveServerGroupMember(ctx, groupID, serverID); errRemove != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to remove server group member", zap.Error(errRemove))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIGetPatreonLinks(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		links, errLinks := app.db.GetPatreonLinks(ctx)
+		if errLinks != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load patreon links", zap.Error(errLinks))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, links)
+	}
+}
+
+type patreonLinkRequest struct {
+	PatreonID string          `json:"patreon_id"`
+	SteamID   store.StringSID `json:"steam_id"`
+}
+
+// onAPIPostPatreonLink associates a patreon user with a steam account so their pledge is able to grant perks.
+func onAPIPostPatreonLink(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req patreonLinkRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		steamID, errSteamID := req.SteamID.SID64(ctx)
+		if errSteamID != nil || req.PatreonID == "" {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var person store.Person
+		if errPerson := app.db.GetOrCreatePersonBySteamID(ctx, steamID, &person); errPerson != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load patreon link person", zap.Error(errPerson))
+
+			return
+		}
+
+		link := store.PatreonLink{PatreonID: req.PatreonID, SteamID: steamID}
+		if errSave := app.db.SavePatreonLink(ctx, &link); errSave != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save patreon link", zap.Error(errSave))
+
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, link)
+
+		log.Info("Patreon user linked", zap.String("patreon_id", link.PatreonID),
+			zap.Int64("steam_id", link.SteamID.Int64()))
+	}
+}
+
+func onAPIDeletePatreonLink(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		patreonID := ctx.Param("patreon_id")
+		if patreonID == "" {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeletePatreonLink(ctx, patreonID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete patreon link", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIQueryPatreonPerkLogs(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.PatreonPerkLogQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		entries, count, errEntries := app.db.GetPatreonPerkLogs(ctx, req)
+		if errEntries != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to query patreon perk logs", zap.Error(errEntries))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, entries))
+	}
+}
+
+func onAPIGetServerCredentials(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		credentials, errCredentials := app.db.GetServerCredentials(ctx, serverID)
+		if errCredentials != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server credentials", zap.Error(errCredentials))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, credentials)
+	}
+}
+
+type serverCredentialRotateRequest struct {
+	Kind store.ServerCredentialKind `json:"kind"`
+	// Overlap overrides the configured rotation overlap, eg: "2h". Use "0" to invalidate the previous
+	// credential immediately.
+	Overlap string   `json:"overlap"`
+	Scopes  []string `json:"scopes"`
+}
+
+// serverCredentialRotateResponse is the only time the new secret is returned.
+type serverCredentialRotateResponse struct {
+	store.ServerCredential
+	Secret string `json:"secret"`
+}
+
+func onAPIPostServerCredentialRotate(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var req serverCredentialRotateRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if req.Kind != store.ServerCredentialKey && req.Kind != store.ServerCredentialLogSecret {
+			responseErr(ctx, http.StatusBadRequest, errUnknownCredentialKind)
+
+			return
+		}
+
+		if errScopes := validateServerScopes(req.Scopes); errScopes != nil {
+			responseErr(ctx, http.StatusBadRequest, errScopes)
+
+			return
+		}
+
+		overlap := app.conf.ServerCredentials.RotationOverlapValue
+
+		if req.Overlap != "" {
+			reqOverlap, errOverlap := ParseUserStringDuration(req.Overlap)
+			if errOverlap != nil || reqOverlap < 0 {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+				return
+			}
+
+			overlap = reqOverlap
+		}
+
+		credential, errRotate := app.rotateServerCredential(ctx, serverID, req.Kind, req.Scopes, overlap)
+		if errRotate != nil {
+			if errors.Is(errRotate, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to rotate server credential", zap.Error(errRotate))
+
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, serverCredentialRotateResponse{ServerCredential: credential, Secret: credential.Secret})
+	}
+}
+
+func onAPIDeleteServerCredential(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		credentialID, errCredentialID := getInt64Param(ctx, "server_credential_id")
+		if errCredentialID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errRevoke := app.db.RevokeServerCredential(ctx, credentialID); errRevoke != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to revoke server credential", zap.Error(errRevoke))
+
+			return
+		}
+
+		if app.logListener != nil {
+			app.updateSrcdsLogSecrets(ctx)
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+
+		log.Info("Server credential revoked", zap.Int64("server_credential_id", credentialID))
+	}
+}
+
+func onAPIGetServerTokens(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		tokens, errTokens := app.db.GetServerTokens(ctx, serverID, time.Now())
+		if errTokens != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server tokens", zap.Error(errTokens))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, tokens)
+	}
+}
+
+func onAPIDeleteServerToken(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		tokenID, errTokenID := getUUIDParam(ctx, "server_token_id")
+		if errTokenID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errRevoke := app.db.RevokeServerToken(ctx, tokenID); errRevoke != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to revoke server token", zap.Error(errRevoke))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
+
+func onAPIPostServerApprove(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		serverID, errServerID := getIntParam(ctx, "server_id")
+		if errServerID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		var server store.Server
+		if errServer := app.db.GetServer(ctx, serverID, &server); errServer != nil {
+			responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+			return
+		}
+
+		if !server.PendingApproval {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errApprove := app.approveServer(ctx, &server); errApprove != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to approve server", zap.Error(errApprove))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, server)
+
+		log.Info("Server approved", zap.Int("server_id", server.ServerID), zap.String("name", server.ShortName))
+	}
+}
+
+func onAPIGetServerEnrollmentTokens(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		tokens, errTokens := app.db.GetServerEnrollmentTokens(ctx)
+		if errTokens != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load server enrollment tokens", zap.Error(errTokens))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, tokens)
+	}
+}
+
+type serverEnrollmentTokenRequest struct {
+	ShortName     string  `json:"short_name"`
+	Region        string  `json:"region"`
+	CC            string  `json:"cc"`
+	Lat           float64 `json:"lat"`
+	Lon           float64 `json:"lon"`
+	ReservedSlots int     `json:"reserved_slots"`
+	EnableStats   bool    `json:"enable_stats"`
+	Note          string  `json:"note"`
+	// ValidFor is how long the token can be used for, eg: "1d". Defaults to 1 day.
+	ValidFor string `json:"valid_for"`
+}
+
+func onAPIPostServerEnrollmentToken(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req serverEnrollmentTokenRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if req.ValidFor == "" {
+			req.ValidFor = "1d"
+		}
+
+		validFor, errValidFor := ParseUserStringDuration(req.ValidFor)
+		if errValidFor != nil || validFor <= 0 {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if len(req.ShortName) > maxShortNameLength {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		token := store.NewServerEnrollmentToken(currentUserProfile(ctx).SteamID, validFor)
+		token.ShortName = req.ShortName
+		token.Region = req.Region
+		token.CC = req.CC
+		token.Latitude = req.Lat
+		token.Longitude = req.Lon
+		token.ReservedSlots = req.ReservedSlots
+		token.EnableStats = req.EnableStats
+		token.Note = req.Note
+
+		if errSave := app.db.SaveServerEnrollmentToken(ctx, &token); errSave != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to save server enrollment token", zap.Error(errSave))
+
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, token)
+
+		log.Info("Server enrollment token created", zap.Int64("server_enrollment_token_id", token.ServerEnrollmentTokenID))
+	}
+}
+
+func onAPIDeleteServerEnrollmentToken(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		tokenID, errTokenID := getInt64Param(ctx, "server_enrollment_token_id")
+		if errTokenID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if errDelete := app.db.DeleteServerEnrollmentToken(ctx, tokenID); errDelete != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to delete server enrollment token", zap.Error(errDelete))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, nil)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/leighmacdonald/gbans/internal/discord"
 	"github.com/leighmacdonald/gbans/internal/store"
 	"github.com/leighmacdonald/gbans/pkg/fp"
+	"github.com/leighmacdonald/gbans/pkg/logparse"
 	"github.com/leighmacdonald/gbans/pkg/util"
 	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/pkg/errors"
@@ -1299,6 +1300,65 @@ func onAPIGetMatch(app *App) gin.HandlerFunc {
 	}
 }
 
+// onAPIGetMatchLogDownload serves the archived raw log of a match. The plain log is returned by default, or a
+// zip in the same format as logs.tf when format=zip is given.
+func onAPIGetMatchLogDownload(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		matchID, errID := getUUIDParam(ctx, "match_id")
+		if errID != nil {
+			log.Error("Invalid match_id value", zap.Error(errID))
+			responseErr(ctx, http.StatusBadRequest, consts.ErrInvalidParameter)
+
+			return
+		}
+
+		var matchLog store.MatchLog
+		if errMatchLog := app.db.GetMatchLog(ctx, matchID, &matchLog); errMatchLog != nil {
+			if errors.Is(errMatchLog, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load match log", zap.Error(errMatchLog))
+
+			return
+		}
+
+		lines, errLines := loadMatchLog(ctx, app.assetStore, matchLog)
+		if errLines != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to read match log", zap.Error(errLines))
+
+			return
+		}
+
+		if ctx.Query("format") == "zip" {
+			content, errZip := MatchLogZip(matchID, lines)
+			if errZip != nil {
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+				log.Error("Failed to create match log zip", zap.Error(errZip))
+
+				return
+			}
+
+			ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", matchLogContentName(matchID, true)))
+			ctx.Data(http.StatusOK, "application/zip", content)
+
+			return
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", matchLogContentName(matchID, false)))
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(strings.Join(lines, "\n")+"\n"))
+	}
+}
+
 func onAPIGetMatches(app *App) gin.HandlerFunc {
 	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
 
@@ -2347,3 +2407,91 @@ func onAPIPostPersonSettings(app *App) gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, settings)
 	}
 }
+
+// onAPIGetEventStream streams live game events to the client as server-sent events. Events can be filtered
+// with the comma separated server_ids and categories query parameters. Chat events are restricted to moderators.
+func onAPIGetEventStream(app *App) gin.HandlerFunc {
+	const keepAlive = time.Second * 30
+
+	return func(ctx *gin.Context) {
+		var (
+			currentUser = currentUserProfile(ctx)
+			serverIDs   []int
+			eventTypes  []logparse.EventType
+			categories  []string
+		)
+
+		if serverIDsQuery := ctx.Query("server_ids"); serverIDsQuery != "" {
+			for _, idStr := range strings.Split(serverIDsQuery, ",") {
+				serverID, errServerID := strconv.Atoi(strings.TrimSpace(idStr))
+				if errServerID != nil {
+					responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+					return
+				}
+
+				serverIDs = append(serverIDs, serverID)
+			}
+		}
+
+		if categoriesQuery := ctx.Query("categories"); categoriesQuery != "" {
+			categories = strings.Split(categoriesQuery, ",")
+		} else {
+			for category := range streamEventCategories {
+				if category != "chat" || currentUser.PermissionLevel >= consts.PModerator {
+					categories = append(categories, category)
+				}
+			}
+		}
+
+		for _, category := range categories {
+			categoryTypes, found := streamEventCategories[category]
+			if !found {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+				return
+			}
+
+			if category == "chat" && currentUser.PermissionLevel < consts.PModerator {
+				responseErr(ctx, http.StatusForbidden, consts.ErrPermissionDenied)
+
+				return
+			}
+
+			eventTypes = append(eventTypes, categoryTypes...)
+		}
+
+		sub := app.eventHub.Subscribe(serverIDs, eventTypes)
+		defer app.eventHub.Unsubscribe(sub)
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+
+		ctx.Stream(func(_ io.Writer) bool {
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case <-ticker.C:
+				ctx.SSEvent("ping", gin.H{})
+			case evt := <-sub.Events:
+				event := evt.Event
+				// Player addresses are only visible to moderators
+				if connected, ok := event.(logparse.ConnectedEvt); ok && currentUser.PermissionLevel < consts.PModerator {
+					connected.Address = ""
+					connected.Port = 0
+					event = connected
+				}
+
+				ctx.SSEvent("event", streamEvent{
+					ServerID:   evt.ServerID,
+					ServerName: evt.ServerName,
+					Category:   eventCategory(evt.EventType),
+					EventType:  evt.EventType,
+					Event:      event,
+				})
+			}
+
+			return true
+		})
+	}
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"regexp"
@@ -520,8 +521,14 @@ func onAPIPostBanDelete(app *App) gin.HandlerFunc {
 			return
 		}
 
-		changed, errSave := app.Unban(ctx, bannedPerson.TargetID, req.UnbanReasonText)
+		changed, errSave := app.Unban(ctx, bannedPerson.TargetID, currentUserProfile(ctx).SteamID, req.UnbanReasonText)
 		if errSave != nil {
+			if errors.Is(errSave, consts.ErrPendingApproval) {
+				responseErr(ctx, http.StatusAccepted, consts.ErrPendingApproval)
+
+				return
+			}
+
 			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
 
 			return
@@ -705,6 +712,12 @@ func onAPIPostBansCIDRCreate(app *App) gin.HandlerFunc {
 		}
 
 		if errBan := app.BanCIDR(ctx, &banCIDR); errBan != nil {
+			if errors.Is(errBan, consts.ErrPendingApproval) {
+				responseErr(ctx, http.StatusAccepted, consts.ErrPendingApproval)
+
+				return
+			}
+
 			if errors.Is(errBan, store.ErrDuplicate) {
 				responseErr(ctx, http.StatusConflict, consts.ErrDuplicate)
 
@@ -904,6 +917,12 @@ func onAPIPostBansASNCreate(app *App) gin.HandlerFunc {
 		}
 
 		if errBan := app.BanASN(ctx, &banASN); errBan != nil {
+			if errors.Is(errBan, consts.ErrPendingApproval) {
+				responseErr(ctx, http.StatusAccepted, consts.ErrPendingApproval)
+
+				return
+			}
+
 			if errors.Is(errBan, store.ErrDuplicate) {
 				responseErr(ctx, http.StatusConflict, consts.ErrDuplicate)
 
@@ -1554,3 +1573,420 @@ func onAPIUpdateForumForum(app *App) gin.HandlerFunc {
 		log.Info("Forum updated", zap.String("title", forum.Title))
 	}
 }
+
+func onAPIGetPendingActions(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.PendingActionQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		actions, count, errActions := app.db.GetPendingActions(ctx, req)
+		if errActions != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch pending actions", zap.Error(errActions))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, actions))
+	}
+}
+
+func onAPIGetPendingAction(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type pendingActionResponse struct {
+		Action store.PendingAction        `json:"action"`
+		Events []store.PendingActionEvent `json:"events"`
+	}
+
+	return func(ctx *gin.Context) {
+		pendingActionID, errID := getInt64Param(ctx, "pending_action_id")
+		if errID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrInvalidParameter)
+
+			return
+		}
+
+		var action store.PendingAction
+		if errAction := app.db.GetPendingAction(ctx, pendingActionID, &action); errAction != nil {
+			if errors.Is(errAction, store.ErrNoResult) {
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+
+				return
+			}
+
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch pending action", zap.Error(errAction))
+
+			return
+		}
+
+		events, errEvents := app.db.GetPendingActionEvents(ctx, pendingActionID)
+		if errEvents != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch pending action events", zap.Error(errEvents))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, pendingActionResponse{Action: action, Events: events})
+	}
+}
+
+func onAPIPostPendingActionResolve(app *App, approve bool) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type resolveRequest struct {
+		Note string `json:"note"`
+	}
+
+	return func(ctx *gin.Context) {
+		pendingActionID, errID := getInt64Param(ctx, "pending_action_id")
+		if errID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrInvalidParameter)
+
+			return
+		}
+
+		var req resolveRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		var (
+			action    store.PendingAction
+			errAction error
+			actor     = currentUserProfile(ctx).SteamID
+		)
+
+		if approve {
+			action, errAction = app.ApprovePendingAction(ctx, pendingActionID, actor)
+		} else {
+			action, errAction = app.RejectPendingAction(ctx, pendingActionID, actor, req.Note)
+		}
+
+		if errAction != nil {
+			switch {
+			case errors.Is(errAction, store.ErrNoResult):
+				responseErr(ctx, http.StatusNotFound, consts.ErrNotFound)
+			case errors.Is(errAction, errSelfApproval), errors.Is(errAction, errActionNotPending):
+				responseErr(ctx, http.StatusConflict, errAction)
+			default:
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+				log.Error("Failed to resolve pending action", zap.Error(errAction))
+			}
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, action)
+	}
+}
+
+func onAPIGetSuspicionFlags(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.SuspicionFlagQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		flags, count, errFlags := app.db.GetSuspicionFlags(ctx, req)
+		if errFlags != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch suspicion flags", zap.Error(errFlags))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, flags))
+	}
+}
+
+func onAPIPostSuspicionFlagState(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type stateRequest struct {
+		State store.SuspicionFlagState `json:"state"`
+	}
+
+	return func(ctx *gin.Context) {
+		suspicionFlagID, errID := getInt64Param(ctx, "suspicion_flag_id")
+		if errID != nil {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrInvalidParameter)
+
+			return
+		}
+
+		var req stateRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if req.State <= store.SuspicionStateAny || req.State > store.SuspicionStateDismissed {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrInvalidParameter)
+
+			return
+		}
+
+		if errState := app.db.SetSuspicionFlagState(ctx, suspicionFlagID, req.State); errState != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to update suspicion flag state", zap.Error(errState))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{})
+	}
+}
+
+func onAPIGetStacDetections(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.StacDetectionQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		detections, count, errDetections := app.db.GetStacDetections(ctx, req)
+		if errDetections != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch stac detections", zap.Error(errDetections))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, detections))
+	}
+}
+
+func onAPIPostRconExec(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	type execRequest struct {
+		rconTarget
+		Command string `json:"command"`
+	}
+
+	return func(ctx *gin.Context) {
+		if !app.conf.RconConsole.Enabled {
+			responseErr(ctx, http.StatusForbidden, consts.ErrPermissionDenied)
+
+			return
+		}
+
+		var req execRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		currentUser := currentUserProfile(ctx)
+
+		servers, errServers := app.resolveRconTarget(ctx, req.rconTarget)
+		if errServers == nil {
+			servers, errServers = app.rconPermittedServers(ctx, currentUser.PermissionLevel, servers)
+		}
+
+		if errServers != nil {
+			if errors.Is(errServers, errRconNoTargets) {
+				responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+			} else {
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+				log.Error("Failed to resolve rcon target", zap.Error(errServers))
+			}
+
+			return
+		}
+
+		results, errExec := app.execConsoleCommand(ctx.Request.Context(), currentUser.SteamID, currentUser.PermissionLevel,
+			servers, strings.TrimSpace(req.Command))
+		if errExec != nil {
+			responseErr(ctx, http.StatusForbidden, consts.ErrPermissionDenied)
+
+			return
+		}
+
+		log.Info("Console command executed", zap.String("command", req.Command),
+			zap.Int64("steam_id", currentUser.SteamID.Int64()), zap.Int("servers", len(servers)))
+
+		// Results are streamed back as server-sent events as each server responds
+		ctx.Stream(func(_ io.Writer) bool {
+			result, ok := <-results
+			if !ok {
+				ctx.SSEvent("done", gin.H{})
+
+				return false
+			}
+
+			ctx.SSEvent("result", result)
+
+			return true
+		})
+	}
+}
+
+func onAPIGetRconAudit(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.RconAuditQueryFilter
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		audits, count, errAudits := app.db.GetRconAudits(ctx, req)
+		if errAudits != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch rcon audit log", zap.Error(errAudits))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newLazyResult(count, audits))
+	}
+}
+
+func onAPIGetRconHistory(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		history, errHistory := app.db.GetRconHistory(ctx, currentUserProfile(ctx).SteamID, 50)
+		if errHistory != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to fetch rcon history", zap.Error(errHistory))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, history)
+	}
+}
+
+// playerCountMaxMinuteSpan limits the size of responses when per-minute samples are requested explicitly.
+const playerCountMaxMinuteSpan = time.Hour * 24 * 7
+
+func onAPIQueryPlayerCounts(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.PlayerCountQuery
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		now := time.Now()
+
+		if req.To.IsZero() {
+			req.To = now
+		}
+
+		if req.From.IsZero() {
+			req.From = req.To.Add(-time.Hour * 24)
+		}
+
+		if !req.From.Before(req.To) {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		if req.Resolution == "" {
+			req.Resolution = PlayerCountResolutionFor(req.From, req.To, now,
+				app.conf.PlayerCounts.MinuteRetentionValue, app.conf.PlayerCounts.HourRetentionValue)
+		}
+
+		if !req.Resolution.Valid() ||
+			(req.Resolution == store.PlayerCountMinute && req.To.Sub(req.From) > playerCountMaxMinuteSpan) {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		buckets, errBuckets := app.db.GetPlayerCountBuckets(ctx, req)
+		if errBuckets != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to query player counts", zap.Error(errBuckets))
+
+			return
+		}
+
+		servers, _, errServers := app.db.GetServers(ctx, store.ServerQueryFilter{
+			IncludeDisabled: true,
+			QueryFilter:     store.QueryFilter{Deleted: true},
+		})
+		if errServers != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load servers", zap.Error(errServers))
+
+			return
+		}
+
+		serversByID := map[int]store.Server{}
+		for _, server := range servers {
+			serversByID[server.ServerID] = server
+		}
+
+		ctx.JSON(http.StatusOK, NewPlayerCountResult(req.Resolution, buckets, serversByID))
+	}
+}
+
+func onAPIQueryServerPerformance(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req store.ServerPerformanceQuery
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if req.To.IsZero() {
+			req.To = time.Now()
+		}
+
+		if req.From.IsZero() {
+			req.From = req.To.Add(-time.Hour * 24)
+		}
+
+		if !req.From.Before(req.To) || req.To.Sub(req.From) > performanceMaxSpan {
+			responseErr(ctx, http.StatusBadRequest, consts.ErrBadRequest)
+
+			return
+		}
+
+		samples, errSamples := app.db.GetServerPerformanceSamples(ctx, req)
+		if errSamples != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to query server performance", zap.Error(errSamples))
+
+			return
+		}
+
+		servers, _, errServers := app.db.GetServers(ctx, store.ServerQueryFilter{
+			IncludeDisabled: true,
+			QueryFilter:     store.QueryFilter{Deleted: true},
+		})
+		if errServers != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+
+			log.Error("Failed to load servers", zap.Error(errServers))
+
+			return
+		}
+
+		serversByID := map[int]store.Server{}
+		for _, server := range servers {
+			serversByID[server.ServerID] = server
+		}
+
+		ctx.JSON(http.StatusOK, NewServerPerformanceSeries(samples, serversByID))
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
 	"github.com/leighmacdonald/gbans/internal/consts"
 	"github.com/leighmacdonald/gbans/internal/discord"
 	"github.com/leighmacdonald/gbans/internal/store"
@@ -44,24 +45,63 @@ func onSAPIPostServerAuth(app *App) gin.HandlerFunc {
 			return
 		}
 
-		var server store.Server
+		var (
+			credential store.ServerCredential
+			server     store.Server
+			now        = time.Now()
+		)
 
-		errGetServer := app.db.GetServerByPassword(ctx, req.Key, &server, true, false)
-		if errGetServer != nil {
+		if errCredential := app.db.GetServerCredentialBySecret(ctx, store.ServerCredentialKey, req.Key, &credential); errCredential != nil {
 			responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
-			log.Warn("Failed to find server auth by password", zap.Error(errGetServer))
+			log.Warn("Failed to find server credential", zap.Error(errCredential))
 
 			return
 		}
 
-		if server.Password != req.Key {
+		if !credential.Valid(now) {
 			responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
-			log.Error("Invalid server key used")
+			log.Warn("Expired or revoked server key used", zap.Int64("server_credential_id", credential.ServerCredentialID))
 
 			return
 		}
 
-		accessToken, errToken := newServerToken(server.ServerID, app.conf.HTTP.CookieKey)
+		if errGetServer := app.db.GetServer(ctx, credential.ServerID, &server); errGetServer != nil ||
+			server.Deleted || !server.IsEnabled || server.PendingApproval {
+			responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
+			log.Warn("Failed to load server for credential", zap.Error(errGetServer))
+
+			return
+		}
+
+		if errTouch := app.db.TouchServerCredential(ctx, credential.ServerCredentialID, now); errTouch != nil {
+			log.Warn("Failed to update server credential usage", zap.Error(errTouch))
+		}
+
+		tokenID, errTokenID := uuid.NewV4()
+		if errTokenID != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to generate server token id", zap.Error(errTokenID))
+
+			return
+		}
+
+		token := store.ServerToken{
+			ServerTokenID:      tokenID,
+			ServerID:           server.ServerID,
+			ServerCredentialID: credential.ServerCredentialID,
+			Scopes:             credential.Scopes,
+			ExpiresOn:          now.Add(authTokenDuration),
+			CreatedOn:          now,
+		}
+
+		if errSaveToken := app.db.SaveServerToken(ctx, &token); errSaveToken != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to save server token", zap.Error(errSaveToken))
+
+			return
+		}
+
+		accessToken, errToken := newServerToken(token, credential)
 		if errToken != nil {
 			responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
 			log.Error("Failed to create new server access token", zap.Error(errToken))
@@ -69,7 +109,7 @@ func onSAPIPostServerAuth(app *App) gin.HandlerFunc {
 			return
 		}
 
-		server.TokenCreatedOn = time.Now()
+		server.TokenCreatedOn = now
 		if errSaveServer := app.db.SaveServer(ctx, &server); errSaveServer != nil {
 			responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
 			log.Error("Failed to updated server token", zap.Error(errSaveServer))
@@ -82,6 +122,67 @@ func onSAPIPostServerAuth(app *App) gin.HandlerFunc {
 	}
 }
 
+type ServerEnrollRequest struct {
+	Token     string `json:"token"`
+	ShortName string `json:"short_name"`
+	Hostname  string `json:"hostname"`
+	// Address defaults to the address the request was sent from.
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+// ServerEnrollResponse contains the credentials the server must use from now on. They are only returned once.
+type ServerEnrollResponse struct {
+	ServerID  int    `json:"server_id"`
+	ShortName string `json:"short_name"`
+	Key       string `json:"key"`
+	RCON      string `json:"rcon"`
+	LogSecret int    `json:"log_secret"`
+}
+
+func onSAPIPostServerEnroll(app *App) gin.HandlerFunc {
+	log := app.log.Named(runtime.FuncForPC(make([]uintptr, 10)[0]).Name())
+
+	return func(ctx *gin.Context) {
+		var req ServerEnrollRequest
+		if !bind(ctx, log, &req) {
+			return
+		}
+
+		if req.Token == "" {
+			responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
+
+			return
+		}
+
+		server, errEnroll := app.enrollServer(ctx, req, ctx.ClientIP())
+		if errEnroll != nil {
+			switch {
+			case errors.Is(errEnroll, errEnrollmentAddress):
+				responseErr(ctx, http.StatusBadRequest, errEnrollmentAddress)
+			case errors.Is(errEnroll, store.ErrNoResult):
+				responseErr(ctx, http.StatusUnauthorized, consts.ErrPermissionDenied)
+				log.Warn("Invalid or expired enrollment token used", zap.String("ip", ctx.ClientIP()))
+			case errors.Is(errEnroll, store.ErrDuplicate):
+				responseErr(ctx, http.StatusConflict, consts.ErrDuplicate)
+			default:
+				responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+				log.Error("Failed to enroll server", zap.Error(errEnroll))
+			}
+
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, ServerEnrollResponse{
+			ServerID:  server.ServerID,
+			ShortName: server.ShortName,
+			Key:       server.Password,
+			RCON:      server.RCON,
+			LogSecret: server.LogSecret,
+		})
+	}
+}
+
 func onAPIGetServerAdmins(app *App) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		perms, err := app.db.GetServerPermissions(ctx)
@@ -91,10 +192,76 @@ func onAPIGetServerAdmins(app *App) gin.HandlerFunc {
 			return
 		}
 
+		var server store.Server
+		if errServer := app.db.GetServer(ctx, serverFromCtx(ctx), &server); errServer == nil {
+			perms = appendPatreonReservedSlots(ctx, app, server.ShortName, perms)
+		}
+
 		ctx.JSON(http.StatusOK, perms)
 	}
 }
 
+// appendPatreonReservedSlots adds the reservation flag for patrons with a reserved slot perk on the server
+// who do not already have a permission entry.
+func appendPatreonReservedSlots(ctx context.Context, app *App, serverName string, perms []store.ServerPermission) []store.ServerPermission {
+	reserved, errReserved := app.db.GetPatreonReservedSlots(ctx, serverName)
+	if errReserved != nil {
+		app.log.Error("Failed to load patreon reserved slots", zap.Error(errReserved))
+
+		return perms
+	}
+
+	existing := map[steamid.SID]bool{}
+	for _, perm := range perms {
+		existing[perm.SteamID] = true
+	}
+
+	for _, steamID := range reserved {
+		sid := steamid.SID64ToSID(steamID)
+		if existing[sid] {
+			continue
+		}
+
+		perms = append(perms, store.ServerPermission{
+			SteamID:         sid,
+			PermissionLevel: consts.PReserved,
+			Flags:           "a",
+		})
+	}
+
+	return perms
+}
+
+// reconnectReservation is a slot held for a player, with the steam id in the formats used by sourcemod.
+type reconnectReservation struct {
+	SteamID   steamid.SID   `json:"steam_id"`
+	SteamID3  steamid.SID3  `json:"steam_id3"`
+	SteamID64 steamid.SID64 `json:"steam_id64"`
+	ExpiresIn int           `json:"expires_in"`
+}
+
+// onAPIGetServerReconnects returns the players with a reserved slot on the calling server. The plugin uses this
+// to let returning players in when the server is full.
+func onAPIGetServerReconnects(app *App) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			now          = time.Now()
+			reservations = []reconnectReservation{}
+		)
+
+		for steamID, expires := range app.reconnects.Active(serverFromCtx(ctx), now) {
+			reservations = append(reservations, reconnectReservation{
+				SteamID:   steamid.SID64ToSID(steamID),
+				SteamID3:  steamid.SID64ToSID3(steamID),
+				SteamID64: steamID,
+				ExpiresIn: int(expires.Sub(now).Seconds()),
+			})
+		}
+
+		ctx.JSON(http.StatusOK, reservations)
+	}
+}
+
 type pingReq struct {
 	ServerName string        `json:"server_name"`
 	Name       string        `json:"name"`
@@ -112,6 +279,15 @@ func onAPIPostPingMod(app *App) gin.HandlerFunc {
 			return
 		}
 
+		// The server name is taken from the authenticated server rather than trusting the request.
+		var server store.Server
+		if errServer := app.db.GetServer(ctx, serverFromCtx(ctx), &server); errServer != nil {
+			responseErr(ctx, http.StatusInternalServerError, consts.ErrInternal)
+			log.Error("Failed to load server for /mod call", zap.Error(errServer))
+
+			return
+		}
+
 		state := app.state.current()
 		players := state.find(findOpts{SteamID: req.SteamID})
 
@@ -134,7 +310,7 @@ func onAPIPostPingMod(app *App) gin.HandlerFunc {
 		msgEmbed := discord.
 			NewEmbed("New User In-Game Report").
 			SetDescription(fmt.Sprintf("%s | <@&%s>", req.Reason, app.conf.Discord.ModPingRoleID)).
-			AddField("server", req.ServerName)
+			AddField("server", server.ShortName)
 
 		app.addAuthor(ctx, msgEmbed, req.SteamID).Truncate()
 
@@ -155,6 +331,10 @@ type CheckResponse struct {
 	BanType         store.BanType    `json:"ban_type"`
 	PermissionLevel consts.Privilege `json:"permission_level"`
 	Msg             string           `json:"msg"`
+	// Reconnected is set when the player is returning to a slot held for them after disconnecting unexpectedly.
+	Reconnected bool `json:"reconnected"`
+	// ChatTag is granted by the players patreon tier.
+	ChatTag string `json:"chat_tag"`
 }
 
 // onAPIPostServerCheck takes care of checking if the player connecting to the server is
@@ -168,6 +348,7 @@ type CheckResponse struct {
 // - Check if ip belongs to a banned AS Number range
 // - Check if steam_id is part of a local steam ban
 // - Check if player is connecting from a IP that belongs to a banned player
+// - Release any slot held for the player from an unexpected disconnect
 //
 // Returns a ok/muted/banned status for the player.
 func onAPIPostServerCheck(app *App) gin.HandlerFunc {
@@ -184,6 +365,8 @@ func onAPIPostServerCheck(app *App) gin.HandlerFunc {
 			return
 		}
 
+		serverID := serverFromCtx(ctx)
+
 		log.Debug("Player connecting",
 			zap.String("ip", request.IP.String()),
 			zap.Int64("sid64", steamid.SIDToSID64(request.SteamID).Int64()),
@@ -194,11 +377,13 @@ func onAPIPostServerCheck(app *App) gin.HandlerFunc {
 			SteamID:     steamid.SIDToSID64(request.SteamID),
 			PersonaName: request.Name,
 			CreatedOn:   time.Now(),
-			ServerID:    ctx.GetInt("server_id"),
+			ServerID:    serverID,
 		}); errAddHist != nil {
 			log.Error("Failed to add conn history", zap.Error(errAddHist))
 		}
 
+		app.trackConnection(request.IP, steamid.SIDToSID64(request.SteamID), serverID)
+
 		resp := CheckResponse{
 			ClientID: request.ClientID,
 			SteamID:  request.SteamID,
@@ -245,6 +430,29 @@ func onAPIPostServerCheck(app *App) gin.HandlerFunc {
 		}
 
 		resp.PermissionLevel = person.PermissionLevel
+		resp.Reconnected = app.reconnects.Claim(serverID, steamID, time.Now())
+
+		var perk store.PatreonPerk
+		if errPerk := app.db.GetPatreonPerk(responseCtx, steamID, &perk); errPerk == nil {
+			resp.ChatTag = perk.ChatTag
+		}
+
+		if method, detail, isBot := app.checkBot(responseCtx, person, request.Name, serverID); isBot {
+			resp.BanType = store.Banned
+			resp.Msg = "Bot detected"
+
+			ctx.JSON(http.StatusOK, resp)
+
+			app.recordBotDetection(responseCtx, store.BotDetection{
+				SteamID:  steamID,
+				ServerID: serverID,
+				Name:     request.Name,
+				Method:   method,
+				Detail:   detail,
+			})
+
+			return
+		}
 
 		if cidrBanned, source := app.netBlock.IsMatch(request.IP); cidrBanned {
 			resp.BanType = store.Network
@@ -572,6 +780,12 @@ func onAPIPostBanSteamCreate(app *App) gin.HandlerFunc {
 		}
 
 		if errBan := app.BanSteam(ctx, &banSteam); errBan != nil {
+			if errors.Is(errBan, consts.ErrPendingApproval) {
+				responseErr(ctx, http.StatusAccepted, consts.ErrPendingApproval)
+
+				return
+			}
+
 			log.Error("Failed to ban steam profile",
 				zap.Error(errBan), zap.Int64("target_id", banSteam.TargetID.Int64()))
 
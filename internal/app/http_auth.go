@@ -8,10 +8,12 @@ import (
 	"net/url"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/leighmacdonald/gbans/internal/consts"
 	"github.com/leighmacdonald/gbans/internal/store"
@@ -56,9 +58,32 @@ func authServerMiddleWare(app *App) gin.HandlerFunc {
 			reqAuthHeader = parts[1]
 		}
 
-		claims := &serverAuthClaims{}
+		var (
+			claims = &serverAuthClaims{}
+			token  store.ServerToken
+		)
 
-		parsedToken, errParseClaims := jwt.ParseWithClaims(reqAuthHeader, claims, makeGetTokenKey(app.conf.HTTP.CookieKey))
+		parsedToken, errParseClaims := jwt.ParseWithClaims(reqAuthHeader, claims, func(parsed *jwt.Token) (any, error) {
+			if _, ok := parsed.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.Errorf("Unexpected signing method: %v", parsed.Header["alg"])
+			}
+
+			tokenID, errTokenID := uuid.FromString(claims.ID)
+			if errTokenID != nil {
+				return nil, errors.Wrap(errTokenID, "Invalid token id")
+			}
+
+			signingKey, errSigningKey := app.db.GetServerTokenSigningKey(ctx, tokenID, &token)
+			if errSigningKey != nil {
+				return nil, errors.Wrap(errSigningKey, "Failed to load token signing key")
+			}
+
+			if token.ServerID != claims.ServerID {
+				return nil, errors.New("Token server mismatch")
+			}
+
+			return []byte(signingKey), nil
+		})
 		if errParseClaims != nil {
 			if errors.Is(errParseClaims, jwt.ErrSignatureInvalid) {
 				log.Error("jwt signature invalid!", zap.Error(errParseClaims))
@@ -94,7 +119,13 @@ func authServerMiddleWare(app *App) gin.HandlerFunc {
 			return
 		}
 
+		if errTouch := app.db.TouchServerToken(ctx, token.ServerTokenID, time.Now(), time.Minute); errTouch != nil {
+			log.Warn("Failed to update server token usage", zap.Error(errTouch))
+		}
+
+		// Scopes are taken from the stored token rather than the claims so that they cannot be widened.
 		ctx.Set("server_id", claims.ServerID)
+		ctx.Set("server_scopes", token.Scopes)
 
 		ctx.Next()
 	}
@@ -266,7 +297,8 @@ type userToken struct {
 }
 
 type serverAuthClaims struct {
-	ServerID int `json:"server_id"`
+	ServerID int      `json:"server_id"`
+	Scopes   []string `json:"scopes"`
 	// A random string which is used to fingerprint and prevent sidejacking
 	jwt.RegisteredClaims
 }
@@ -306,21 +338,23 @@ func newUserToken(steamID steamid.SID64, cookieKey string, userContext string, v
 	return signedToken, nil
 }
 
-func newServerToken(serverID int, cookieKey string) (string, error) {
-	curTime := time.Now()
-
+// newServerToken creates a token signed with the key of the credential the server authenticated with. The token
+// id is used to find the signing key again when the token is presented.
+func newServerToken(token store.ServerToken, credential store.ServerCredential) (string, error) {
 	claims := &serverAuthClaims{
-		ServerID: serverID,
+		ServerID: token.ServerID,
+		Scopes:   token.Scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(curTime.Add(authTokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(curTime),
-			NotBefore: jwt.NewNumericDate(curTime),
+			ID:        token.ServerTokenID.String(),
+			ExpiresAt: jwt.NewNumericDate(token.ExpiresOn),
+			IssuedAt:  jwt.NewNumericDate(token.CreatedOn),
+			NotBefore: jwt.NewNumericDate(token.CreatedOn),
 		},
 	}
 
 	tokenWithClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	signedToken, errSigned := tokenWithClaims.SignedString([]byte(cookieKey))
+	signedToken, errSigned := tokenWithClaims.SignedString([]byte(credential.SigningKey))
 	if errSigned != nil {
 		return "", errors.Wrap(errSigned, "Failed create signed string")
 	}
@@ -328,6 +362,48 @@ func newServerToken(serverID int, cookieKey string) (string, error) {
 	return signedToken, nil
 }
 
+// Scopes which can be assigned to server credentials. A credential without any scopes is permitted to do everything.
+const (
+	serverScopeAdmins = "admins"
+	serverScopePing   = "ping"
+	serverScopeCheck  = "check"
+	serverScopeDemo   = "demo"
+	serverScopeBan    = "ban"
+	serverScopeReport = "report"
+	serverScopeState  = "state"
+)
+
+var serverScopes = []string{ //nolint:gochecknoglobals
+	serverScopeAdmins, serverScopePing, serverScopeCheck, serverScopeDemo,
+	serverScopeBan, serverScopeReport, serverScopeState,
+}
+
+// hasServerScope checks if the granted scopes permit the scope.
+func hasServerScope(granted []string, scope string) bool {
+	if len(granted) == 0 {
+		return true
+	}
+
+	return slices.Contains(granted, scope)
+}
+
+// requireServerScope must be used after authServerMiddleWare.
+func requireServerScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		granted, _ := ctx.Get("server_scopes")
+
+		scopes, ok := granted.([]string)
+		if !ok || !hasServerScope(scopes, scope) {
+			responseErr(ctx, http.StatusForbidden, consts.ErrPermissionDenied)
+			ctx.Abort()
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
 type authHeader struct {
 	Authorization string `header:"Authorization"`
 }
@@ -139,7 +139,12 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 		"/wiki/*slug", "/log/:match_id", "/logs/:steam_id", "/logs", "/ban/:ban_id", "/chatlogs", "/admin/appeals", "/login",
 		"/pug", "/quickplay", "/global_stats", "/stv", "/login/discord", "/notifications", "/admin/network", "/stats",
 		"/stats/weapon/:weapon_id", "/stats/player/:steam_id", "/privacy-policy", "/admin/contests", "/contests", "/contests/:contest_id",
-		"/forums", "/forums/:forum_id", "/forums/thread/:forum_thread_id",
+		"/forums", "/forums/:forum_id", "/forums/thread/:forum_thread_id", "/admin/approvals",
+		"/admin/suspicion", "/admin/bot_defense", "/admin/rcon", "/live", "/admin/health", "/admin/rcon_tasks",
+		"/admin/cvar_templates", "/admin/map_rotation", "/admin/server_groups", "/community_servers",
+		"/admin/patreon",
+		"/admin/player_counts",
+		"/admin/server_performance",
 	}
 	for _, rt := range jsRoutes {
 		engine.GET(rt, func(c *gin.Context) {
@@ -178,10 +183,13 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 
 	// Service discovery endpoints
 	engine.GET("/api/sd/prometheus/hosts", onAPIGetPrometheusHosts(app))
-	engine.GET("/api/sd/ansible/hosts", onAPIGetPrometheusHosts(app))
+	engine.GET("/api/sd/ansible/hosts", onAPIGetAnsibleHosts(app))
+	engine.GET("/api/sd/consul/v1/catalog/services", onAPIGetConsulServices(app))
+	engine.GET("/api/sd/consul/v1/catalog/service/:service_name", onAPIGetConsulService(app))
 
 	// Game server plugin routes
 	engine.POST("/api/server/auth", onSAPIPostServerAuth(app))
+	engine.POST("/api/server/enroll", onSAPIPostServerEnroll(app))
 
 	engine.GET("/export/sourcemod/admins_simple.ini", onAPIExportSourcemodSimpleAdmins(app))
 
@@ -193,6 +201,10 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 	optionalAuth := engine.Group("/")
 	{
 		optional := optionalAuth.Use(authMiddleware(app, consts.PGuest))
+		optional.GET("/api/servers/state/stream", onAPIGetServerStateStream(app))
+		optional.GET("/api/server_groups", onAPIGetServerGroups(app))
+		optional.POST("/api/quickplay", onAPIPostQuickPlay(app))
+		optional.POST("/api/community_servers", onAPIPostCommunityServers(app))
 		optional.GET("/api/contests", onAPIGetContests(app))
 		optional.GET("/api/contests/:contest_id", onAPIGetContest(app))
 		optional.GET("/api/contests/:contest_id/entries", onAPIGetContestEntries(app))
@@ -209,14 +221,15 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 	{
 		// Server Auth Request
 		serverAuth := srvGrp.Use(authServerMiddleWare(app))
-		serverAuth.GET("/api/server/admins", onAPIGetServerAdmins(app))
-		serverAuth.POST("/api/ping_mod", onAPIPostPingMod(app))
-		serverAuth.POST("/api/check", onAPIPostServerCheck(app))
-		serverAuth.POST("/api/demo", onAPIPostDemo(app))
+		serverAuth.GET("/api/server/admins", requireServerScope(serverScopeAdmins), onAPIGetServerAdmins(app))
+		serverAuth.POST("/api/ping_mod", requireServerScope(serverScopePing), onAPIPostPingMod(app))
+		serverAuth.POST("/api/check", requireServerScope(serverScopeCheck), onAPIPostServerCheck(app))
+		serverAuth.GET("/api/server/reconnects", requireServerScope(serverScopeCheck), onAPIGetServerReconnects(app))
+		serverAuth.POST("/api/demo", requireServerScope(serverScopeDemo), onAPIPostDemo(app))
 		// Duplicated since we need to authenticate via server middleware
-		serverAuth.POST("/api/sm/bans/steam/create", onAPIPostBanSteamCreate(app))
-		serverAuth.POST("/api/sm/report/create", onAPIPostReportCreate(app))
-		serverAuth.POST("/api/state_update", onAPIPostServerState(app))
+		serverAuth.POST("/api/sm/bans/steam/create", requireServerScope(serverScopeBan), onAPIPostBanSteamCreate(app))
+		serverAuth.POST("/api/sm/report/create", requireServerScope(serverScopeReport), onAPIPostReportCreate(app))
+		serverAuth.POST("/api/state_update", requireServerScope(serverScopeState), onAPIPostServerState(app))
 	}
 
 	authedGrp := engine.Group("/")
@@ -250,8 +263,10 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 		authed.GET("/api/sourcebans/:steam_id", onAPIGetSourceBans(app))
 
 		authed.GET("/api/log/:match_id", onAPIGetMatch(app))
+		authed.GET("/api/log/:match_id/download", onAPIGetMatchLogDownload(app))
 		authed.POST("/api/logs", onAPIGetMatches(app))
 		authed.POST("/api/messages", onAPIQueryMessages(app))
+		authed.GET("/api/events", onAPIGetEventStream(app))
 
 		authed.GET("/api/stats/weapons", onAPIGetStatsWeaponsOverall(ctx, app))
 		authed.GET("/api/stats/weapon/:weapon_id", onAPIGetsStatsWeapon(app))
@@ -320,6 +335,17 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 		modRoute.DELETE("/api/bans/group/:ban_group_id", onAPIDeleteBansGroup(app))
 		modRoute.POST("/api/bans/group/:ban_group_id", onAPIPostBansGroupUpdate(app))
 
+		modRoute.POST("/api/pending_actions", onAPIGetPendingActions(app))
+		modRoute.GET("/api/pending_actions/:pending_action_id", onAPIGetPendingAction(app))
+		modRoute.POST("/api/pending_actions/:pending_action_id/approve", onAPIPostPendingActionResolve(app, true))
+		modRoute.POST("/api/pending_actions/:pending_action_id/reject", onAPIPostPendingActionResolve(app, false))
+
+		modRoute.POST("/api/suspicion", onAPIGetSuspicionFlags(app))
+		modRoute.POST("/api/suspicion/:suspicion_flag_id/state", onAPIPostSuspicionFlagState(app))
+		modRoute.POST("/api/rcon/exec", onAPIPostRconExec(app))
+		modRoute.GET("/api/rcon/history", onAPIGetRconHistory(app))
+		modRoute.POST("/api/stac", onAPIGetStacDetections(app))
+
 		modRoute.GET("/api/patreon/pledges", onAPIGetPatreonPledges(app))
 
 		modRoute.POST("/api/contests", onAPIPostContest(app))
@@ -337,6 +363,8 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 		modRoute.DELETE("/api/block_list/whitelist/:cidr_block_whitelist_id", onAPIDeleteBlockListWhitelist(app))
 		modRoute.GET("/api/block_list", onAPIGetBlockLists(app))
 		modRoute.POST("/api/block_list/checker", onAPIPostBlocklistCheck(app))
+		modRoute.POST("/api/player_counts", onAPIQueryPlayerCounts(app))
+		modRoute.POST("/api/server_performance", onAPIQueryServerPerformance(app))
 	}
 
 	adminGrp := engine.Group("/")
@@ -347,11 +375,59 @@ func createRouter(ctx context.Context, app *App) *gin.Engine {
 		adminRoute.POST("/api/servers/:server_id", onAPIPostServerUpdate(app))
 		adminRoute.DELETE("/api/servers/:server_id", onAPIPostServerDelete(app))
 		adminRoute.POST("/api/servers_admin", onAPIGetServersAdmin(app))
+		adminRoute.GET("/api/servers/:server_id/credentials", onAPIGetServerCredentials(app))
+		adminRoute.POST("/api/servers/:server_id/credentials/rotate", onAPIPostServerCredentialRotate(app))
+		adminRoute.DELETE("/api/server_credentials/:server_credential_id", onAPIDeleteServerCredential(app))
+		adminRoute.GET("/api/servers/:server_id/tokens", onAPIGetServerTokens(app))
+		adminRoute.DELETE("/api/server_tokens/:server_token_id", onAPIDeleteServerToken(app))
+		adminRoute.POST("/api/servers/:server_id/approve", onAPIPostServerApprove(app))
+		adminRoute.GET("/api/server_enrollment_tokens", onAPIGetServerEnrollmentTokens(app))
+		adminRoute.POST("/api/server_enrollment_tokens", onAPIPostServerEnrollmentToken(app))
+		adminRoute.DELETE("/api/server_enrollment_tokens/:server_enrollment_token_id", onAPIDeleteServerEnrollmentToken(app))
 		adminRoute.PUT("/api/player/:steam_id/permissions", onAPIPutPlayerPermission(app))
 
 		adminRoute.POST("/api/block_list", onAPIPostBlockListCreate(app))
 		adminRoute.POST("/api/block_list/:cidr_block_source_id", onAPIPostBlockListUpdate(app))
 		adminRoute.DELETE("/api/block_list/:cidr_block_source_id", onAPIDeleteBlockList(app))
+
+		adminRoute.GET("/api/bot_defense/patterns", onAPIGetBotNamePatterns(app))
+		adminRoute.POST("/api/bot_defense/patterns", onAPIPostBotNamePattern(app))
+		adminRoute.DELETE("/api/bot_defense/patterns/:bot_name_pattern_id", onAPIDeleteBotNamePattern(app))
+		adminRoute.POST("/api/bot_defense/steam_ids", onAPIPostBotSteamIDs(app))
+		adminRoute.DELETE("/api/bot_defense/steam_ids/:steam_id", onAPIDeleteBotSteamID(app))
+		adminRoute.GET("/api/bot_defense/report", onAPIGetBotReport(app))
+		adminRoute.POST("/api/rcon/audit", onAPIGetRconAudit(app))
+		adminRoute.GET("/api/servers_health", onAPIGetServerHealth(app))
+		adminRoute.GET("/api/servers_health/:server_id", onAPIGetServerHealthHistory(app))
+		adminRoute.GET("/api/rcon_tasks", onAPIGetRconTasks(app))
+		adminRoute.POST("/api/rcon_tasks", onAPIPostRconTask(app))
+		adminRoute.POST("/api/rcon_tasks/:rcon_task_id", onAPIPostRconTask(app))
+		adminRoute.DELETE("/api/rcon_tasks/:rcon_task_id", onAPIDeleteRconTask(app))
+		adminRoute.POST("/api/rcon_tasks/:rcon_task_id/run", onAPIPostRconTaskRun(app))
+		adminRoute.POST("/api/rcon_task_runs", onAPIGetRconTaskRuns(app))
+		adminRoute.GET("/api/cvar_templates", onAPIGetCvarTemplates(app))
+		adminRoute.POST("/api/cvar_templates", onAPIPostCvarTemplate(app))
+		adminRoute.POST("/api/cvar_templates/:cvar_template_id", onAPIPostCvarTemplate(app))
+		adminRoute.DELETE("/api/cvar_templates/:cvar_template_id", onAPIDeleteCvarTemplate(app))
+		adminRoute.GET("/api/cvar_drift", onAPIGetCvarDrift(app))
+		adminRoute.POST("/api/cvar_drift/check", onAPIPostCvarDriftCheck(app))
+		adminRoute.PUT("/api/servers/:server_id/cvar_template", onAPIPutServerCvarTemplate(app))
+		adminRoute.POST("/api/servers/:server_id/cvar_template/apply", onAPIPostServerCvarTemplateApply(app))
+		adminRoute.GET("/api/map_rotations", onAPIGetMapRotations(app))
+		adminRoute.POST("/api/map_rotations/:server_id", onAPIPostMapRotation(app))
+		adminRoute.DELETE("/api/map_rotations/:server_id", onAPIDeleteMapRotation(app))
+		adminRoute.GET("/api/map_rotations/:server_id/candidates", onAPIGetMapRotationCandidates(app))
+		adminRoute.POST("/api/map_rotations/:server_id/rotate", onAPIPostMapRotationRotate(app))
+		adminRoute.POST("/api/map_rotation_changes", onAPIGetMapRotationChanges(app))
+		adminRoute.POST("/api/server_groups", onAPIPostServerGroup(app))
+		adminRoute.POST("/api/server_groups/:server_group_id", onAPIPostServerGroup(app))
+		adminRoute.DELETE("/api/server_groups/:server_group_id", onAPIDeleteServerGroup(app))
+		adminRoute.PUT("/api/server_groups/:server_group_id/servers/:server_id", onAPIPutServerGroupMember(app))
+		adminRoute.DELETE("/api/server_groups/:server_group_id/servers/:server_id", onAPIDeleteServerGroupMember(app))
+		adminRoute.GET("/api/patreon/links", onAPIGetPatreonLinks(app))
+		adminRoute.POST("/api/patreon/links", onAPIPostPatreonLink(app))
+		adminRoute.DELETE("/api/patreon/links/:patreon_id", onAPIDeletePatreonLink(app))
+		adminRoute.POST("/api/patreon/perk_logs", onAPIQueryPatreonPerkLogs(app))
 	}
 
 	return engine
@@ -0,0 +1,315 @@
+package app
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"github.com/leighmacdonald/gbans/pkg/util"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// logImportNamespace is used to derive stable ids for imported matches so that importing the same logs again
+// can be detected.
+var logImportNamespace = uuid.Must(uuid.FromString("0b6f2f4c-5d1e-4c52-9c4e-8f7f1c2b9a61"))
+
+var errLogImportPath = errors.New("No log files found")
+
+// LogMatchSplitter builds matches from a sequence of log lines. Matches are ended using the same rules as the live
+// match summarizer, either when the log is closed or once both teams final scores have been reported following
+// the end of the game.
+type LogMatchSplitter struct {
+	parser      *logparse.LogParser
+	serverID    int
+	serverName  string
+	current     *logparse.Match
+	finalScores int
+	completed   []logparse.Match
+}
+
+func NewLogMatchSplitter(serverID int, serverName string) *LogMatchSplitter {
+	return &LogMatchSplitter{
+		parser:     logparse.NewLogParser(),
+		serverID:   serverID,
+		serverName: serverName,
+	}
+}
+
+// Add parses the line and applies it to the current match.
+func (s *LogMatchSplitter) Add(line string) {
+	result, errParse := s.parser.Parse(line)
+	if errParse != nil || result.EventType == logparse.IgnoredMsg {
+		return
+	}
+
+	if result.EventType == logparse.LogStart {
+		// A new log starting before the previous one was closed means the server stopped unexpectedly, so
+		// the unfinished match cannot be trusted.
+		s.current = nil
+		s.finalScores = 0
+	}
+
+	if s.current == nil {
+		match := logparse.NewMatch(s.serverID, s.serverName)
+		s.current = &match
+	}
+
+	// Errors are expected for events which cannot be applied yet, such as before the first round starts.
+	_ = s.current.Apply(result)
+
+	switch result.EventType { //nolint:exhaustive
+	case logparse.WTeamFinalScore:
+		s.finalScores++
+		if s.finalScores >= 2 {
+			s.finish()
+		}
+	case logparse.LogStop:
+		s.finish()
+	}
+}
+
+func (s *LogMatchSplitter) finish() {
+	match := *s.current
+	if match.TimeStart != nil {
+		match.MatchID = ImportedMatchID(match)
+	}
+
+	s.completed = append(s.completed, match)
+	s.current = nil
+	s.finalScores = 0
+}
+
+// Matches returns the matches which have been completed. Any match still in progress is not included.
+func (s *LogMatchSplitter) Matches() []logparse.Match {
+	return s.completed
+}
+
+// ImportedMatchID derives the id of an imported match from the server, map and start time, so the same match
+// always receives the same id.
+func ImportedMatchID(match logparse.Match) uuid.UUID {
+	var started int64
+	if match.TimeStart != nil {
+		started = match.TimeStart.Unix()
+	}
+
+	return uuid.NewV5(logImportNamespace, fmt.Sprintf("%d/%s/%d", match.ServerID, match.MapName, started))
+}
+
+// ReadLogLines calls the handler for each non-empty line of the log, decompressing it first when compressed is set.
+func ReadLogLines(reader io.Reader, compressed bool, handler func(line string)) error {
+	if compressed {
+		gzipReader, errGzip := gzip.NewReader(reader)
+		if errGzip != nil {
+			return errors.Wrap(errGzip, "Failed to open gzip reader")
+		}
+
+		defer func() {
+			_ = gzipReader.Close()
+		}()
+
+		reader = gzipReader
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		handler(line)
+	}
+
+	return errors.Wrap(scanner.Err(), "Failed to read log")
+}
+
+// FindLogFiles returns all the plain and gzip compressed log files under the path, sorted by path. SRCDS names
+// its logs by date, so this is also the order they were written in.
+func FindLogFiles(root string) ([]string, error) {
+	var files []string
+
+	errWalk := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := strings.ToLower(entry.Name())
+		if !entry.IsDir() && (strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if errWalk != nil {
+		return nil, errors.Wrap(errWalk, "Failed to search for log files")
+	}
+
+	if len(files) == 0 {
+		return nil, errLogImportPath
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+type LogImportOpts struct {
+	Path string
+	// ServerName is the short name of the server which all the logs belong to. When empty, the name of the
+	// directory containing each log is used instead, eg: logs/us-1/L0816001.log
+	ServerName string
+	DryRun     bool
+}
+
+type LogImportResult struct {
+	Files int
+	// Matches is the number of completed matches found in the logs.
+	Matches int
+	Saved   int
+	// Existing is the number of matches which were already imported previously.
+	Existing int
+	// Incomplete is the number of matches without enough data or players to be saved.
+	Incomplete int
+	Failed     int
+}
+
+// ImportMatchLogs reads SRCDS logs from disk and saves the matches found in them. Matches which have already been
+// imported are skipped so the import can be run repeatedly over the same logs.
+func ImportMatchLogs(ctx context.Context, database *store.Store, log *zap.Logger, opts LogImportOpts) (LogImportResult, error) {
+	var (
+		result  LogImportResult
+		servers = map[string]*store.Server{}
+	)
+
+	files, errFiles := FindLogFiles(opts.Path)
+	if errFiles != nil {
+		return result, errFiles
+	}
+
+	for _, path := range files {
+		if ctx.Err() != nil {
+			return result, errors.Wrap(ctx.Err(), "Import cancelled")
+		}
+
+		serverName := opts.ServerName
+		if serverName == "" {
+			serverName = filepath.Base(filepath.Dir(path))
+		}
+
+		server, found := servers[serverName]
+		if !found {
+			var loaded store.Server
+			if errServer := database.GetServerByName(ctx, serverName, &loaded, true, true); errServer != nil {
+				log.Error("Unknown server for log file", zap.String("file", path),
+					zap.String("server", serverName), zap.Error(errServer))
+			} else {
+				server = &loaded
+			}
+
+			servers[serverName] = server
+		}
+
+		if server == nil {
+			result.Failed++
+
+			continue
+		}
+
+		if !server.EnableStats {
+			log.Warn("Skipping log for server with stats disabled", zap.String("file", path))
+
+			continue
+		}
+
+		splitter := NewLogMatchSplitter(server.ServerID, server.ShortName)
+
+		if errRead := readLogFile(path, log, splitter.Add); errRead != nil {
+			log.Error("Failed to read log file", zap.String("file", path), zap.Error(errRead))
+			result.Failed++
+
+			continue
+		}
+
+		result.Files++
+
+		for _, match := range splitter.Matches() {
+			result.Matches++
+
+			if errSave := saveImportedMatch(ctx, database, server, match, opts.DryRun, &result); errSave != nil {
+				log.Error("Failed to save match", zap.String("file", path), zap.Error(errSave))
+				result.Failed++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func readLogFile(path string, log *zap.Logger, handler func(line string)) error {
+	file, errOpen := os.Open(path)
+	if errOpen != nil {
+		return errors.Wrap(errOpen, "Failed to open log file")
+	}
+
+	defer util.LogCloser(file, log)
+
+	return ReadLogLines(file, strings.HasSuffix(strings.ToLower(path), ".gz"), handler)
+}
+
+func saveImportedMatch(ctx context.Context, database *store.Store, server *store.Server, match logparse.Match,
+	dryRun bool, result *LogImportResult,
+) error {
+	if match.TimeStart == nil || match.MapName == "" {
+		result.Incomplete++
+
+		return nil
+	}
+
+	exists, errExists := database.MatchExists(ctx, match.MatchID)
+	if errExists != nil {
+		return errExists
+	}
+
+	if exists {
+		result.Existing++
+
+		return nil
+	}
+
+	if server.Name != "" {
+		match.Title = server.Name
+	}
+
+	if dryRun {
+		result.Saved++
+
+		return nil
+	}
+
+	if errSave := database.MatchSave(ctx, &match); errSave != nil {
+		if errors.Is(errSave, store.ErrIncompleteMatch) || errors.Is(errSave, store.ErrInsufficientPlayers) {
+			result.Incomplete++
+
+			return nil
+		}
+
+		return errSave
+	}
+
+	result.Saved++
+
+	return nil
+}
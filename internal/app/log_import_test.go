@@ -0,0 +1,84 @@
+package app_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/golib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMatchSplitter(t *testing.T) {
+	testFilePath := golib.FindFile(path.Join("testdata", "log_3124689.log"), "gbans")
+	if testFilePath == "" {
+		t.Skipf("Cant find test file: log_3124689.log")
+
+		return
+	}
+
+	body, errRead := os.ReadFile(testFilePath)
+	require.NoError(t, errRead)
+
+	split := func(compressed bool, data []byte) *app.LogMatchSplitter {
+		splitter := app.NewLogMatchSplitter(1, "test-1")
+		require.NoError(t, app.ReadLogLines(bytes.NewReader(data), compressed, splitter.Add))
+
+		return splitter
+	}
+
+	splitter := split(false, body)
+
+	var (
+		complete = 0
+		match    = splitter.Matches()[0]
+	)
+
+	for _, found := range splitter.Matches() {
+		if found.TimeStart != nil {
+			complete++
+		}
+	}
+
+	require.Equal(t, 1, complete)
+	require.NotNil(t, match.TimeStart)
+	require.Equal(t, 19, match.PlayerCount())
+	require.Equal(t, app.ImportedMatchID(match), match.MatchID)
+
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	_, errWrite := writer.Write(body)
+	require.NoError(t, errWrite)
+	require.NoError(t, writer.Close())
+
+	// Importing the same log again must give the same match ids
+	again := split(true, compressed.Bytes())
+	require.Len(t, again.Matches(), len(splitter.Matches()))
+	require.Equal(t, match.MatchID, again.Matches()[0].MatchID)
+}
+
+func TestFindLogFiles(t *testing.T) {
+	root := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "us-1"), 0o755))
+
+	for _, name := range []string{"us-1/L0102000.log", "us-1/L0101000.log.gz", "us-1/notes.txt", "L0101001.LOG"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte{}, 0o600))
+	}
+
+	files, errFiles := app.FindLogFiles(root)
+	require.NoError(t, errFiles)
+	require.Equal(t, []string{
+		filepath.Join(root, "L0101001.LOG"),
+		filepath.Join(root, "us-1/L0101000.log.gz"),
+		filepath.Join(root, "us-1/L0102000.log"),
+	}, files)
+
+	_, errEmpty := app.FindLogFiles(t.TempDir())
+	require.Error(t, errEmpty)
+}
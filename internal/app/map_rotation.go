@@ -0,0 +1,287 @@
+package app
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"regexp"
+	"strings"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// mapStatsFactorMin and mapStatsFactorMax bound how much playtime stats can change a maps weight.
+	mapStatsFactorMin = 0.5
+	mapStatsFactorMax = 2.0
+)
+
+var (
+	errMapRotationName    = errors.New("Invalid map name")
+	errMapRotationWeight  = errors.New("Map weight cannot be negative")
+	errMapRotationPlayers = errors.New("Invalid player range")
+	errMapRotationEmpty   = errors.New("No maps available for rotation")
+
+	mapNameRx = regexp.MustCompile(`^[A-Za-z0-9_\-./]+$`)
+)
+
+// MapCandidate is a map eligible to be selected next, along with its effective weight.
+type MapCandidate struct {
+	MapName string  `json:"map_name"`
+	Weight  float64 `json:"weight"`
+}
+
+// validateMapRotation checks the map pool of the rotation, removing blank and duplicate entries.
+func validateMapRotation(rotation *store.MapRotation) error {
+	var (
+		seen = map[string]bool{}
+		maps []store.MapRotationEntry
+	)
+
+	for _, entry := range rotation.Maps {
+		entry.MapName = strings.TrimSpace(entry.MapName)
+		if entry.MapName == "" || seen[entry.MapName] {
+			continue
+		}
+
+		if !mapNameRx.MatchString(entry.MapName) {
+			return errors.Wrapf(errMapRotationName, "%s", entry.MapName)
+		}
+
+		if entry.Weight < 0 {
+			return errors.Wrapf(errMapRotationWeight, "%s", entry.MapName)
+		}
+
+		if entry.MinPlayers < 0 || entry.MaxPlayers < 0 || (entry.MaxPlayers > 0 && entry.MaxPlayers < entry.MinPlayers) {
+			return errors.Wrapf(errMapRotationPlayers, "%s", entry.MapName)
+		}
+
+		seen[entry.MapName] = true
+		maps = append(maps, entry)
+	}
+
+	if rotation.ExcludeRecent < 0 {
+		rotation.ExcludeRecent = 0
+	}
+
+	rotation.Maps = maps
+
+	return nil
+}
+
+// MapCandidates returns the maps which may be selected for the given player count. Maps outside their player
+// range and recently played maps are excluded, unless doing so would leave nothing to choose from. When
+// usage is provided, weights are scaled by each maps share of playtime relative to the other candidates so
+// that popular maps are played more often.
+func MapCandidates(rotation store.MapRotation, players int, recent []string, usage map[string]float64) []MapCandidate {
+	var pool []store.MapRotationEntry
+
+	for _, entry := range rotation.Maps {
+		if entry.Weight > 0 {
+			pool = append(pool, entry)
+		}
+	}
+
+	var inRange []store.MapRotationEntry
+
+	for _, entry := range pool {
+		if players >= entry.MinPlayers && (entry.MaxPlayers == 0 || players <= entry.MaxPlayers) {
+			inRange = append(inRange, entry)
+		}
+	}
+
+	if len(inRange) > 0 {
+		pool = inRange
+	}
+
+	var fresh []store.MapRotationEntry
+
+	for _, entry := range pool {
+		if !containsString(recent, entry.MapName) {
+			fresh = append(fresh, entry)
+		}
+	}
+
+	if len(fresh) > 0 {
+		pool = fresh
+	}
+
+	var (
+		totalShare float64
+		withShare  int
+	)
+
+	for _, entry := range pool {
+		if share, found := usage[entry.MapName]; found && share > 0 {
+			totalShare += share
+			withShare++
+		}
+	}
+
+	candidates := make([]MapCandidate, len(pool))
+
+	for idx, entry := range pool {
+		weight := float64(entry.Weight)
+
+		if share, found := usage[entry.MapName]; found && share > 0 && withShare > 0 {
+			factor := share / (totalShare / float64(withShare))
+			weight *= math.Max(mapStatsFactorMin, math.Min(mapStatsFactorMax, factor))
+		}
+
+		candidates[idx] = MapCandidate{MapName: entry.MapName, Weight: weight}
+	}
+
+	return candidates
+}
+
+// PickMap selects a candidate by weight using roll, a value in the range [0, 1).
+func PickMap(candidates []MapCandidate, roll float64) string {
+	var total float64
+	for _, candidate := range candidates {
+		total += candidate.Weight
+	}
+
+	target := roll * total
+
+	for _, candidate := range candidates {
+		if target < candidate.Weight {
+			return candidate.MapName
+		}
+
+		target -= candidate.Weight
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	return candidates[len(candidates)-1].MapName
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mapRotationCandidates calculates the current candidates for the server. When the rotation has no maps
+// configured, the default maps are used.
+func (app *App) mapRotationCandidates(ctx context.Context, rotation store.MapRotation, players int, currentMap string) ([]MapCandidate, error) {
+	if len(rotation.Maps) == 0 {
+		for _, mapName := range app.conf.General.DefaultMaps {
+			rotation.Maps = append(rotation.Maps, store.MapRotationEntry{MapName: mapName, Weight: 1})
+		}
+	}
+
+	recent := []string{currentMap}
+
+	if rotation.ExcludeRecent > 0 {
+		recentMaps, errRecent := app.db.GetRecentRotationMaps(ctx, rotation.ServerID, uint64(rotation.ExcludeRecent))
+		if errRecent != nil {
+			return nil, errors.Wrap(errRecent, "Failed to load recent maps")
+		}
+
+		recent = append(recent, recentMaps...)
+	}
+
+	var usage map[string]float64
+
+	if rotation.UseStats {
+		stats, errStats := app.db.GetMapUsageStats(ctx)
+		if errStats != nil {
+			return nil, errors.Wrap(errStats, "Failed to load map usage stats")
+		}
+
+		usage = make(map[string]float64, len(stats))
+		for _, stat := range stats {
+			usage[stat.Map] = stat.Percent
+		}
+	}
+
+	candidates := MapCandidates(rotation, players, recent, usage)
+	if len(candidates) == 0 {
+		return nil, errMapRotationEmpty
+	}
+
+	return candidates, nil
+}
+
+// rotateMap chooses the next map for the server and sets it as the nextlevel. The change is recorded
+// whether it succeeds or not.
+func (app *App) rotateMap(ctx context.Context, rotation store.MapRotation) (store.MapRotationChange, error) {
+	change := store.MapRotationChange{ServerID: rotation.ServerID, ServerName: rotation.ServerName}
+
+	state := app.state.current()
+	if server, found := state.byServerID(rotation.ServerID); found {
+		change.PreviousMap = server.Map
+		change.Players = server.PlayerCount
+	}
+
+	candidates, errCandidates := app.mapRotationCandidates(ctx, rotation, change.Players, change.PreviousMap)
+	if errCandidates != nil {
+		return change, errCandidates
+	}
+
+	change.MapName = PickMap(candidates, rand.Float64()) //nolint:gosec
+
+	if _, errExec := app.state.rcon(rotation.ServerID, "nextlevel "+change.MapName); errExec != nil {
+		change.Error = errExec.Error()
+	}
+
+	if errSave := app.db.SaveMapRotationChange(ctx, &change); errSave != nil {
+		return change, errors.Wrap(errSave, "Failed to save map rotation change")
+	}
+
+	return change, nil
+}
+
+// mapRotator sets the next map for servers with an enabled rotation once the current map is over.
+func (app *App) mapRotator(ctx context.Context) {
+	var (
+		log             = app.log.Named("mapRotation")
+		serverEventChan = make(chan logparse.ServerEvent)
+	)
+
+	if errRegister := app.eb.Consume(serverEventChan, logparse.WGameOver); errRegister != nil {
+		log.Warn("mapRotator tried to register duplicate reader channel", zap.Error(errRegister))
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-serverEventChan:
+			var rotation store.MapRotation
+			if errRotation := app.db.GetMapRotation(ctx, evt.ServerID, &rotation); errRotation != nil {
+				if !errors.Is(errRotation, store.ErrNoResult) {
+					log.Error("Failed to load map rotation", zap.Error(errRotation))
+				}
+
+				continue
+			}
+
+			if !rotation.Enabled {
+				continue
+			}
+
+			change, errRotate := app.rotateMap(ctx, rotation)
+			if errRotate != nil {
+				log.Error("Failed to rotate map", zap.Int("server_id", evt.ServerID), zap.Error(errRotate))
+
+				continue
+			}
+
+			log.Info("Set next map", zap.Int("server_id", evt.ServerID),
+				zap.String("map", change.MapName), zap.String("error", change.Error))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func candidateNames(candidates []app.MapCandidate) []string {
+	names := make([]string, len(candidates))
+	for idx, candidate := range candidates {
+		names[idx] = candidate.MapName
+	}
+
+	return names
+}
+
+func TestMapCandidates(t *testing.T) {
+	rotation := store.MapRotation{Maps: []store.MapRotationEntry{
+		{MapName: "pl_upward", Weight: 2, MinPlayers: 12},
+		{MapName: "pl_badwater", Weight: 1, MinPlayers: 12},
+		{MapName: "koth_harvest", Weight: 1, MaxPlayers: 11},
+		{MapName: "cp_dustbowl", Weight: 0},
+	}}
+
+	require.Equal(t, []string{"koth_harvest"}, candidateNames(app.MapCandidates(rotation, 4, nil, nil)))
+	require.Equal(t, []string{"pl_upward", "pl_badwater"}, candidateNames(app.MapCandidates(rotation, 24, nil, nil)))
+
+	// Recently played maps are excluded, unless nothing else is available
+	require.Equal(t, []string{"pl_badwater"}, candidateNames(app.MapCandidates(rotation, 24, []string{"pl_upward"}, nil)))
+	require.Equal(t, []string{"koth_harvest"}, candidateNames(app.MapCandidates(rotation, 4, []string{"koth_harvest"}, nil)))
+
+	// Weights are scaled relative to the average share of playtime and clamped
+	candidates := app.MapCandidates(rotation, 24, nil, map[string]float64{"pl_upward": 60, "pl_badwater": 20})
+	require.InDelta(t, 3.0, candidates[0].Weight, 0.001)
+	require.InDelta(t, 0.5, candidates[1].Weight, 0.001)
+}
+
+func TestPickMap(t *testing.T) {
+	candidates := []app.MapCandidate{{MapName: "a", Weight: 1}, {MapName: "b", Weight: 3}}
+
+	require.Equal(t, "a", app.PickMap(candidates, 0))
+	require.Equal(t, "a", app.PickMap(candidates, 0.24))
+	require.Equal(t, "b", app.PickMap(candidates, 0.25))
+	require.Equal(t, "b", app.PickMap(candidates, 0.99))
+	require.Equal(t, "", app.PickMap(nil, 0.5))
+}
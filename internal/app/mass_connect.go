@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// newMassConnectTracker creates the connection tracker from the config, invalid whitelist entries are logged
+// and skipped.
+func newMassConnectTracker(conf *Config, logger *zap.Logger) *ConnectionTracker {
+	var whitelist []*net.IPNet
+
+	for _, cidr := range conf.MassConnect.Whitelist {
+		if !strings.Contains(cidr, "/") {
+			cidr += "/32"
+		}
+
+		_, network, errParse := net.ParseCIDR(cidr)
+		if errParse != nil {
+			logger.Warn("Invalid mass connect whitelist entry", zap.String("cidr", cidr), zap.Error(errParse))
+
+			continue
+		}
+
+		whitelist = append(whitelist, network)
+	}
+
+	return NewConnectionTracker(conf.MassConnect.WindowValue, conf.MassConnect.Threshold, conf.MassConnect.Subnet, whitelist)
+}
+
+// trackConnection records the connection with the mass connect detector, alerting if
+// the threshold is reached. It is only called from the server connect check, which sees every join
+// attempt including those which are then rejected as banned.
+func (app *App) trackConnection(addr net.IP, steamID steamid.SID64, serverID int) {
+	if !app.conf.MassConnect.Enabled {
+		return
+	}
+
+	for _, alert := range app.connTracker.Add(addr, steamID, serverID, time.Now()) {
+		go func(alert ConnectionAlert) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+
+			app.onMassConnect(ctx, alert)
+		}(alert)
+	}
+}
+
+func (app *App) onMassConnect(ctx context.Context, alert ConnectionAlert) {
+	app.log.Warn("Mass connect detected", zap.String("key", alert.Key), zap.Int("accounts", len(alert.Accounts)))
+
+	var banned steamid.Collection
+
+	if app.conf.MassConnect.AutoBanBotHosts {
+		newBans, errBan := app.banBotHostCluster(ctx, alert)
+		if errBan != nil {
+			app.log.Error("Failed to ban bot host cluster", zap.Error(errBan))
+		}
+
+		banned = newBans
+	}
+
+	var (
+		accounts = make([]string, len(alert.Accounts))
+		servers  = make([]string, len(alert.ServerIDs))
+		title    = "Mass connect from IP"
+	)
+
+	if alert.Subnet {
+		title = "Mass connect from network"
+	}
+
+	for idx, steamID := range alert.Accounts {
+		name := steamID.String()
+
+		var person store.Person
+		if errPerson := app.PersonBySID(ctx, steamID, &person); errPerson == nil && person.PersonaName != "" {
+			name = person.PersonaName
+		}
+
+		accounts[idx] = fmt.Sprintf("[%s](%s)", name, app.ExtURLRaw("/profile/%d", steamID.Int64()))
+
+		if banned.Contains(steamID) {
+			accounts[idx] += " (banned)"
+		}
+	}
+
+	for idx, serverID := range alert.ServerIDs {
+		servers[idx] = fmt.Sprintf("%d", serverID)
+
+		var server store.Server
+		if errServer := app.db.GetServer(ctx, serverID, &server); errServer == nil {
+			servers[idx] = server.ShortName
+		}
+	}
+
+	msgEmbed := discord.
+		NewEmbed(title).
+		SetColor(app.bot.Colour.Warn).
+		SetDescription(strings.Join(accounts, "\n")).
+		AddField("Address", alert.Key).
+		AddField("Accounts", fmt.Sprintf("%d", len(alert.Accounts))).
+		AddField("Window", app.conf.MassConnect.Window).
+		AddField("Servers", strings.Join(servers, ", "))
+
+	app.bot.SendPayload(discord.Payload{
+		ChannelID: app.conf.Discord.LogChannelID,
+		Embed:     msgEmbed.Truncate().MessageEmbed,
+	})
+}
+
+// banBotHostCluster checks if any of the accounts in the cluster are already banned as a bot host. If so,
+// the remaining accounts are assumed to belong to the same host and are banned as well.
+func (app *App) banBotHostCluster(ctx context.Context, alert ConnectionAlert) (steamid.Collection, error) {
+	var (
+		knownHost steamid.SID64
+		unbanned  steamid.Collection
+	)
+
+	for _, steamID := range alert.Accounts {
+		existing := store.NewBannedPerson()
+		if errBan := app.db.GetBanBySteamID(ctx, steamID, &existing, false); errBan != nil {
+			if !errors.Is(errBan, store.ErrNoResult) {
+				return nil, errors.Wrap(errBan, "Failed to check existing ban")
+			}
+
+			unbanned = append(unbanned, steamID)
+
+			continue
+		}
+
+		if existing.BanSteam.Reason == store.BotHost {
+			knownHost = steamID
+		}
+	}
+
+	if !knownHost.Valid() {
+		return nil, nil
+	}
+
+	var banned steamid.Collection
+
+	for _, steamID := range unbanned {
+		var banSteam store.BanSteam
+		if errNewBan := store.NewBanSteam(ctx,
+			store.StringSID(app.conf.General.Owner.String()),
+			store.StringSID(steamID.String()),
+			app.conf.MassConnect.AutoBanDurationValue,
+			store.BotHost,
+			"",
+			fmt.Sprintf("Automatic mass connect ban, shares %s with bot host %s", alert.Key, knownHost.String()),
+			store.System,
+			0,
+			store.Banned,
+			false,
+			&banSteam); errNewBan != nil {
+			return banned, errors.Wrap(errNewBan, "Failed to create bot host ban")
+		}
+
+		if errBan := app.BanSteam(ctx, &banSteam); errBan != nil {
+			return banned, errors.Wrap(errBan, "Failed to ban bot host")
+		}
+
+		banned = append(banned, steamID)
+	}
+
+	return banned, nil
+}
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errMatchLogNoMatch = errors.New("No match found in log")
+
+	// matchLogAddressRx matches the ipv4 addresses included in connection events and some plugin output.
+	matchLogAddressRx = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+// SanitizeLogLine removes player ip addresses from the line. The addresses are replaced instead of removed so that
+// the line can still be parsed.
+func SanitizeLogLine(line string) string {
+	return matchLogAddressRx.ReplaceAllString(line, "0.0.0.0")
+}
+
+// MatchLogName is the name of the archived log for a match within the match log bucket.
+func MatchLogName(matchID uuid.UUID) string {
+	return fmt.Sprintf("%s.log.gz", matchID.String())
+}
+
+// EncodeMatchLog sanitizes and gzip compresses the log lines.
+func EncodeMatchLog(lines []string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	for _, line := range lines {
+		if _, errWrite := io.WriteString(writer, SanitizeLogLine(line)+"\n"); errWrite != nil {
+			return nil, errors.Wrap(errWrite, "Failed to write log line")
+		}
+	}
+
+	if errClose := writer.Close(); errClose != nil {
+		return nil, errors.Wrap(errClose, "Failed to compress log")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeMatchLog reads the lines of an archived match log.
+func DecodeMatchLog(reader io.Reader) ([]string, error) {
+	var lines []string
+
+	if errRead := ReadLogLines(reader, true, func(line string) {
+		lines = append(lines, line)
+	}); errRead != nil {
+		return nil, errRead
+	}
+
+	return lines, nil
+}
+
+// MatchLogZip creates a zip archive containing the log in the same layout as the logs.tf downloads, so existing
+// tools which accept those can be used with it.
+func MatchLogZip(matchID uuid.UUID, lines []string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := zip.NewWriter(&buf)
+
+	file, errCreate := writer.Create(fmt.Sprintf("log_%s.log", matchID.String()))
+	if errCreate != nil {
+		return nil, errors.Wrap(errCreate, "Failed to create zip entry")
+	}
+
+	for _, line := range lines {
+		if _, errWrite := io.WriteString(file, line+"\n"); errWrite != nil {
+			return nil, errors.Wrap(errWrite, "Failed to write log line")
+		}
+	}
+
+	if errClose := writer.Close(); errClose != nil {
+		return nil, errors.Wrap(errClose, "Failed to close zip")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RebuildMatch parses the log lines into a match using the same rules as the log importer. The first match with a
+// known start time is returned.
+func RebuildMatch(lines []string, serverID int, serverName string) (logparse.Match, error) {
+	splitter := NewLogMatchSplitter(serverID, serverName)
+
+	for _, line := range lines {
+		splitter.Add(line)
+	}
+
+	for _, match := range splitter.Matches() {
+		if match.TimeStart != nil {
+			return match, nil
+		}
+	}
+
+	return logparse.Match{}, errMatchLogNoMatch
+}
+
+// matchLogsEnabled checks if match logs should be archived. The logs are stored in s3, so it must be enabled as well.
+func (app *App) matchLogsEnabled() bool {
+	return app.conf.MatchLogs.Enabled && app.conf.S3.Enabled
+}
+
+// archiveMatchLog uploads the raw log of a completed match to the asset store and records where it was stored.
+func (app *App) archiveMatchLog(ctx context.Context, matchID uuid.UUID, lines []string) {
+	log := app.log.Named("matchLog").With(zap.String("match_id", matchID.String()))
+
+	content, errEncode := EncodeMatchLog(lines)
+	if errEncode != nil {
+		log.Error("Failed to encode match log", zap.Error(errEncode))
+
+		return
+	}
+
+	matchLog := store.MatchLog{
+		MatchID:   matchID,
+		Bucket:    app.conf.S3.BucketMatchLog,
+		Name:      MatchLogName(matchID),
+		Size:      int64(len(content)),
+		Lines:     len(lines),
+		CreatedOn: time.Now(),
+	}
+
+	if errPut := app.assetStore.Put(ctx, matchLog.Bucket, matchLog.Name, bytes.NewReader(content),
+		matchLog.Size, "application/gzip"); errPut != nil {
+		log.Error("Failed to upload match log", zap.Error(errPut))
+
+		return
+	}
+
+	if errSave := app.db.SaveMatchLog(ctx, &matchLog); errSave != nil {
+		log.Error("Failed to save match log", zap.Error(errSave))
+
+		return
+	}
+
+	log.Debug("Archived match log", zap.Int("lines", matchLog.Lines), zap.Int64("size", matchLog.Size))
+}
+
+// loadMatchLog fetches and decodes the archived log of a match.
+func loadMatchLog(ctx context.Context, assets AssetStore, matchLog store.MatchLog) ([]string, error) {
+	reader, errGet := assets.Get(ctx, matchLog.Bucket, matchLog.Name)
+	if errGet != nil {
+		return nil, errGet
+	}
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	return DecodeMatchLog(reader)
+}
+
+type MatchReprocessResult struct {
+	Matches int
+	Updated int
+	// Incomplete is the number of logs which no longer produce a match with enough data or players to be saved.
+	Incomplete int
+	Failed     int
+}
+
+// ReprocessMatchLogs rebuilds the stats of matches from their archived logs, which allows fixes and additions to
+// the log parser to be applied to past matches. When no match ids are given, every archived match is processed.
+// The existing match records are updated in place so anything referencing them is retained.
+func ReprocessMatchLogs(ctx context.Context, database *store.Store, assets AssetStore, log *zap.Logger,
+	matchIDs []uuid.UUID,
+) (MatchReprocessResult, error) {
+	var (
+		result    MatchReprocessResult
+		matchLogs []store.MatchLog
+	)
+
+	if len(matchIDs) == 0 {
+		allLogs, errLogs := database.GetMatchLogs(ctx)
+		if errLogs != nil {
+			return result, errors.Wrap(errLogs, "Failed to load match logs")
+		}
+
+		matchLogs = allLogs
+	}
+
+	for _, matchID := range matchIDs {
+		var matchLog store.MatchLog
+		if errLog := database.GetMatchLog(ctx, matchID, &matchLog); errLog != nil {
+			log.Error("Failed to load match log", zap.String("match_id", matchID.String()), zap.Error(errLog))
+			result.Failed++
+
+			continue
+		}
+
+		matchLogs = append(matchLogs, matchLog)
+	}
+
+	for _, matchLog := range matchLogs {
+		if ctx.Err() != nil {
+			return result, errors.Wrap(ctx.Err(), "Reprocess cancelled")
+		}
+
+		result.Matches++
+
+		if errReprocess := reprocessMatchLog(ctx, database, assets, matchLog, &result); errReprocess != nil {
+			log.Error("Failed to reprocess match", zap.String("match_id", matchLog.MatchID.String()),
+				zap.Error(errReprocess))
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+func reprocessMatchLog(ctx context.Context, database *store.Store, assets AssetStore, matchLog store.MatchLog,
+	result *MatchReprocessResult,
+) error {
+	var existing store.MatchResult
+	if errExisting := database.MatchGetByID(ctx, matchLog.MatchID, &existing); errExisting != nil {
+		return errors.Wrap(errExisting, "Failed to load existing match")
+	}
+
+	lines, errLines := loadMatchLog(ctx, assets, matchLog)
+	if errLines != nil {
+		return errLines
+	}
+
+	match, errMatch := RebuildMatch(lines, existing.ServerID, "")
+	if errMatch != nil {
+		if errors.Is(errMatch, errMatchLogNoMatch) {
+			result.Incomplete++
+
+			return nil
+		}
+
+		return errMatch
+	}
+
+	match.MatchID = existing.MatchID
+	match.Title = existing.Title
+
+	if errReplace := database.MatchReplace(ctx, &match); errReplace != nil {
+		if errors.Is(errReplace, store.ErrIncompleteMatch) || errors.Is(errReplace, store.ErrInsufficientPlayers) {
+			result.Incomplete++
+
+			return nil
+		}
+
+		return errReplace
+	}
+
+	result.Updated++
+
+	return nil
+}
+
+// matchLogContentName returns the file name used for downloads, matching the logs.tf naming.
+func matchLogContentName(matchID uuid.UUID, zipped bool) string {
+	name := fmt.Sprintf("log_%s.log", matchID.String())
+	if zipped {
+		name += ".zip"
+	}
+
+	return name
+}
@@ -0,0 +1,83 @@
+package app_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/golib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeLogLine(t *testing.T) {
+	line := `L 02/21/2023 - 06:22:23: "Hacksaw<12><[U:1:68745073]><>" connected, address "1.2.3.4:27005"`
+
+	require.Equal(t,
+		`L 02/21/2023 - 06:22:23: "Hacksaw<12><[U:1:68745073]><>" connected, address "0.0.0.0:27005"`,
+		app.SanitizeLogLine(line))
+	require.Equal(t, `L 02/21/2023 - 06:22:23: World triggered "Round_Start"`,
+		app.SanitizeLogLine(`L 02/21/2023 - 06:22:23: World triggered "Round_Start"`))
+}
+
+func TestMatchLog(t *testing.T) {
+	testFilePath := golib.FindFile(path.Join("testdata", "log_3124689.log"), "gbans")
+	if testFilePath == "" {
+		t.Skipf("Cant find test file: log_3124689.log")
+
+		return
+	}
+
+	body, errRead := os.ReadFile(testFilePath)
+	require.NoError(t, errRead)
+
+	var lines []string
+
+	for _, line := range strings.Split(string(body), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	encoded, errEncode := app.EncodeMatchLog(lines)
+	require.NoError(t, errEncode)
+
+	decoded, errDecode := app.DecodeMatchLog(bytes.NewReader(encoded))
+	require.NoError(t, errDecode)
+	require.Len(t, decoded, len(lines))
+
+	for idx, line := range lines {
+		require.Equal(t, app.SanitizeLogLine(line), decoded[idx])
+	}
+
+	match, errMatch := app.RebuildMatch(decoded, 1, "test-1")
+	require.NoError(t, errMatch)
+	require.NotNil(t, match.TimeStart)
+	require.Equal(t, 19, match.PlayerCount())
+
+	_, errEmpty := app.RebuildMatch(nil, 1, "test-1")
+	require.Error(t, errEmpty)
+
+	matchID := uuid.Must(uuid.NewV4())
+
+	content, errZip := app.MatchLogZip(matchID, decoded)
+	require.NoError(t, errZip)
+
+	reader, errReader := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, errReader)
+	require.Len(t, reader.File, 1)
+	require.Equal(t, "log_"+matchID.String()+".log", reader.File[0].Name)
+
+	file, errOpen := reader.File[0].Open()
+	require.NoError(t, errOpen)
+
+	zipped, errZipped := io.ReadAll(file)
+	require.NoError(t, errZipped)
+	require.NoError(t, file.Close())
+	require.Equal(t, strings.Join(decoded, "\n")+"\n", string(zipped))
+}
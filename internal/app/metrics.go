@@ -24,6 +24,16 @@ type metricCollector struct {
 	disconnectedCounter *prometheus.CounterVec
 	classCounter        *prometheus.CounterVec
 	playerCounter       *prometheus.HistogramVec
+	serverCPU           *prometheus.GaugeVec
+	serverFPS           *prometheus.GaugeVec
+	serverFrameTime     *prometheus.GaugeVec
+	serverVar           *prometheus.GaugeVec
+	serverNetIn         *prometheus.GaugeVec
+	serverNetOut        *prometheus.GaugeVec
+	serverLossIn        *prometheus.GaugeVec
+	serverLossOut       *prometheus.GaugeVec
+	serverUptime        *prometheus.GaugeVec
+	serverTickHealth    *prometheus.GaugeVec
 }
 
 func newMetricCollector() *metricCollector {
@@ -78,6 +88,36 @@ func newMetricCollector() *metricCollector {
 		classCounter: prometheus.NewCounterVec(
 			prometheus.CounterOpts{Name: "gbans_player_class_total", Help: "Player class"},
 			[]string{"class"}),
+		serverCPU: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_cpu", Help: "Server process cpu usage"},
+			[]string{"server_name"}),
+		serverFPS: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_fps", Help: "Server frames per second"},
+			[]string{"server_name"}),
+		serverFrameTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_frame_ms", Help: "Average server frame time in milliseconds"},
+			[]string{"server_name"}),
+		serverVar: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_var_ms", Help: "Server frame time variance in milliseconds"},
+			[]string{"server_name"}),
+		serverNetIn: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_net_in_kbps", Help: "Server incoming traffic in KB/s"},
+			[]string{"server_name"}),
+		serverNetOut: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_net_out_kbps", Help: "Server outgoing traffic in KB/s"},
+			[]string{"server_name"}),
+		serverLossIn: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_loss_in", Help: "Server incoming packet loss"},
+			[]string{"server_name"}),
+		serverLossOut: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_loss_out", Help: "Server outgoing packet loss"},
+			[]string{"server_name"}),
+		serverUptime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_uptime_seconds", Help: "Server process uptime"},
+			[]string{"server_name"}),
+		serverTickHealth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "gbans_server_tick_health", Help: "Ratio of server fps to the expected tick rate"},
+			[]string{"server_name"}),
 	}
 	for _, metric := range []prometheus.Collector{
 		collector.damageCounter,
@@ -93,6 +133,16 @@ func newMetricCollector() *metricCollector {
 		collector.connectedCounter,
 		collector.disconnectedCounter,
 		collector.classCounter,
+		collector.serverCPU,
+		collector.serverFPS,
+		collector.serverFrameTime,
+		collector.serverVar,
+		collector.serverNetIn,
+		collector.serverNetOut,
+		collector.serverLossIn,
+		collector.serverLossOut,
+		collector.serverUptime,
+		collector.serverTickHealth,
 	} {
 		_ = prometheus.Register(metric)
 	}
@@ -5,7 +5,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/leighmacdonald/gbans/internal/discord"
 	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"gopkg.in/mxpv/patreon-go.v1"
@@ -14,6 +16,13 @@ import (
 type patreonStore interface {
 	SetPatreonAuth(ctx context.Context, accessToken string, refreshToken string) error
 	GetPatreonAuth(ctx context.Context) (string, string, error)
+	GetPatreonLinks(ctx context.Context) ([]store.PatreonLink, error)
+	GetPatreonPerks(ctx context.Context) ([]store.PatreonPerk, error)
+	SavePatreonPerk(ctx context.Context, perk *store.PatreonPerk) error
+	DeletePatreonPerk(ctx context.Context, steamID steamid.SID64) error
+	AddPatreonPerkLog(ctx context.Context, entry *store.PatreonPerkLog) error
+	GetPersonBySteamID(ctx context.Context, sid64 steamid.SID64, person *store.Person) error
+	SavePerson(ctx context.Context, person *store.Person) error
 }
 
 type patreonManager struct {
@@ -24,13 +33,15 @@ type patreonManager struct {
 	log              *zap.Logger
 	conf             *Config
 	db               patreonStore
+	bot              *discord.Bot
 }
 
-func newPatreonManager(logger *zap.Logger, conf *Config, db *store.Store) *patreonManager {
+func newPatreonManager(logger *zap.Logger, conf *Config, db *store.Store, bot *discord.Bot) *patreonManager {
 	return &patreonManager{
 		log:       logger.Named("patreon"),
 		conf:      conf,
 		db:        db,
+		bot:       bot,
 		patreonMu: &sync.RWMutex{},
 	}
 }
@@ -224,6 +235,10 @@ func (p *patreonManager) updater(ctx context.Context) {
 
 			log.Info("Patreon Updated", zap.Int("campaign_count", len(newCampaigns)),
 				zap.Int("current_cents", cents), zap.Int("total_cents", totalCents))
+
+			if errPerks := p.updatePerks(ctx, newPledges); errPerks != nil {
+				log.Error("Failed to update patreon perks", zap.Error(errPerks))
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -0,0 +1,307 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/mxpv/patreon-go.v1"
+)
+
+const (
+	patreonPerkGrant  = "grant"
+	patreonPerkRevoke = "revoke"
+	patreonPerkLapse  = "lapse"
+	patreonPerkRenew  = "renew"
+)
+
+// PatreonEntitlement is a linked player with an active pledge to a tier which has perks.
+type PatreonEntitlement struct {
+	SteamID   steamid.SID64
+	PatreonID string
+	TierID    string
+}
+
+// PatreonPerkPlan is the set of changes required to bring the granted perks in line with the current pledges.
+type PatreonPerkPlan struct {
+	Grant []PatreonEntitlement
+	// Revoke is applied before Grant so a tier change removes the old perks first.
+	Revoke []store.PatreonPerk
+	// Lapse are perks whose pledge has ended, but are still within the grace period.
+	Lapse []store.PatreonPerk
+	// Renew are lapsed perks whose pledge has resumed before the grace period ended.
+	Renew []store.PatreonPerk
+}
+
+// PlanPatreonPerks compares the current entitlements against the perks already granted.
+func PlanPatreonPerks(entitled []PatreonEntitlement, current []store.PatreonPerk, now time.Time, grace time.Duration) PatreonPerkPlan {
+	var (
+		plan          PatreonPerkPlan
+		currentBySID  = map[steamid.SID64]store.PatreonPerk{}
+		entitledBySID = map[steamid.SID64]bool{}
+	)
+
+	for _, perk := range current {
+		currentBySID[perk.SteamID] = perk
+	}
+
+	for _, entitlement := range entitled {
+		entitledBySID[entitlement.SteamID] = true
+
+		perk, found := currentBySID[entitlement.SteamID]
+
+		switch {
+		case !found:
+			plan.Grant = append(plan.Grant, entitlement)
+		case perk.TierID != entitlement.TierID:
+			plan.Revoke = append(plan.Revoke, perk)
+			plan.Grant = append(plan.Grant, entitlement)
+		case perk.LapsedOn != nil:
+			plan.Renew = append(plan.Renew, perk)
+		}
+	}
+
+	for _, perk := range current {
+		if entitledBySID[perk.SteamID] {
+			continue
+		}
+
+		switch {
+		case perk.LapsedOn == nil && grace > 0:
+			plan.Lapse = append(plan.Lapse, perk)
+		case perk.LapsedOn == nil || now.Sub(*perk.LapsedOn) >= grace:
+			plan.Revoke = append(plan.Revoke, perk)
+		}
+	}
+
+	return plan
+}
+
+// patreonEntitlements maps the active pledges of linked users to the tiers with configured perks.
+func patreonEntitlements(pledges []patreon.Pledge, links []store.PatreonLink, tiers []patreonTierPerks) []PatreonEntitlement {
+	var (
+		entitled    []PatreonEntitlement
+		linkedUsers = map[string]steamid.SID64{}
+		tierPerks   = map[string]bool{}
+	)
+
+	for _, link := range links {
+		linkedUsers[link.PatreonID] = link.SteamID
+	}
+
+	for _, tier := range tiers {
+		tierPerks[tier.TierID] = true
+	}
+
+	for _, pledge := range pledges {
+		if pledge.Relationships.Patron == nil || pledge.Relationships.Reward == nil {
+			continue
+		}
+
+		if pledge.Attributes.DeclinedSince.Valid ||
+			(pledge.Attributes.IsPaused != nil && *pledge.Attributes.IsPaused) {
+			continue
+		}
+
+		steamID, linked := linkedUsers[pledge.Relationships.Patron.Data.ID]
+		if !linked || !tierPerks[pledge.Relationships.Reward.Data.ID] {
+			continue
+		}
+
+		entitled = append(entitled, PatreonEntitlement{
+			SteamID:   steamID,
+			PatreonID: pledge.Relationships.Patron.Data.ID,
+			TierID:    pledge.Relationships.Reward.Data.ID,
+		})
+	}
+
+	return entitled
+}
+
+func (p *patreonManager) tierPerks(tierID string) (patreonTierPerks, bool) {
+	for _, tier := range p.conf.Patreon.Tiers {
+		if tier.TierID == tierID {
+			return tier, true
+		}
+	}
+
+	return patreonTierPerks{}, false
+}
+
+// logPerk records a single perk change to both the application log and the audit log.
+func (p *patreonManager) logPerk(ctx context.Context, steamID steamid.SID64, patreonID string, tierID string,
+	action string, perk string, value string,
+) {
+	p.log.Info("Patreon perk updated", zap.String("action", action), zap.String("perk", perk),
+		zap.String("value", value), zap.Int64("steam_id", steamID.Int64()), zap.String("tier_id", tierID))
+
+	if errLog := p.db.AddPatreonPerkLog(ctx, &store.PatreonPerkLog{
+		SteamID:   steamID,
+		PatreonID: patreonID,
+		TierID:    tierID,
+		Action:    action,
+		Perk:      perk,
+		Value:     value,
+	}); errLog != nil {
+		p.log.Error("Failed to save patreon perk log", zap.Error(errLog))
+	}
+}
+
+func (p *patreonManager) grantPerks(ctx context.Context, entitlement PatreonEntitlement) error {
+	tier, found := p.tierPerks(entitlement.TierID)
+	if !found {
+		return nil
+	}
+
+	var person store.Person
+	if errPerson := p.db.GetPersonBySteamID(ctx, entitlement.SteamID, &person); errPerson != nil {
+		return errors.Wrap(errPerson, "Failed to load patron")
+	}
+
+	perk := store.PatreonPerk{
+		SteamID:         entitlement.SteamID,
+		PatreonID:       entitlement.PatreonID,
+		TierID:          entitlement.TierID,
+		ForumBadge:      tier.ForumBadge,
+		ChatTag:         tier.ChatTag,
+		ReservedServers: tier.ReservedServers,
+	}
+
+	// Only players below the reserved level are promoted, so staff are never demoted on revocation.
+	if tier.Reserved && person.PermissionLevel < consts.PReserved {
+		perk.PreviousPermissionLevel = person.PermissionLevel
+		person.PermissionLevel = consts.PReserved
+
+		if errSave := p.db.SavePerson(ctx, &person); errSave != nil {
+			return errors.Wrap(errSave, "Failed to update patron permission")
+		}
+
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkGrant, "permission", consts.PReserved.String())
+	}
+
+	if tier.DiscordRoleID != "" && person.DiscordID != "" && p.bot != nil {
+		if errRole := p.bot.AddMemberRole(p.conf.Discord.GuildID, person.DiscordID, tier.DiscordRoleID); errRole != nil {
+			p.log.Error("Failed to add patron discord role", zap.Error(errRole))
+		} else {
+			perk.DiscordRoleID = tier.DiscordRoleID
+
+			p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkGrant, "discord_role", tier.DiscordRoleID)
+		}
+	}
+
+	if perk.ForumBadge != "" {
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkGrant, "forum_badge", perk.ForumBadge)
+	}
+
+	if perk.ChatTag != "" {
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkGrant, "chat_tag", perk.ChatTag)
+	}
+
+	if len(perk.ReservedServers) > 0 {
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkGrant, "reserved_servers",
+			strings.Join(perk.ReservedServers, ","))
+	}
+
+	return p.db.SavePatreonPerk(ctx, &perk)
+}
+
+func (p *patreonManager) revokePerks(ctx context.Context, perk store.PatreonPerk) error {
+	var person store.Person
+	if errPerson := p.db.GetPersonBySteamID(ctx, perk.SteamID, &person); errPerson != nil {
+		return errors.Wrap(errPerson, "Failed to load patron")
+	}
+
+	// Leave the permission alone if it has since been changed by someone else.
+	if perk.PreviousPermissionLevel > 0 && person.PermissionLevel == consts.PReserved {
+		person.PermissionLevel = perk.PreviousPermissionLevel
+
+		if errSave := p.db.SavePerson(ctx, &person); errSave != nil {
+			return errors.Wrap(errSave, "Failed to update patron permission")
+		}
+
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkRevoke, "permission", consts.PReserved.String())
+	}
+
+	if perk.DiscordRoleID != "" && person.DiscordID != "" && p.bot != nil {
+		if errRole := p.bot.RemoveMemberRole(p.conf.Discord.GuildID, person.DiscordID, perk.DiscordRoleID); errRole != nil {
+			p.log.Error("Failed to remove patron discord role", zap.Error(errRole))
+		} else {
+			p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkRevoke, "discord_role", perk.DiscordRoleID)
+		}
+	}
+
+	if perk.ForumBadge != "" {
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkRevoke, "forum_badge", perk.ForumBadge)
+	}
+
+	if perk.ChatTag != "" {
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkRevoke, "chat_tag", perk.ChatTag)
+	}
+
+	if len(perk.ReservedServers) > 0 {
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkRevoke, "reserved_servers",
+			strings.Join(perk.ReservedServers, ","))
+	}
+
+	return p.db.DeletePatreonPerk(ctx, perk.SteamID)
+}
+
+// updatePerks grants and revokes the perks of linked patrons to match their current pledges.
+func (p *patreonManager) updatePerks(ctx context.Context, pledges []patreon.Pledge) error {
+	links, errLinks := p.db.GetPatreonLinks(ctx)
+	if errLinks != nil {
+		return errors.Wrap(errLinks, "Failed to load patreon links")
+	}
+
+	current, errCurrent := p.db.GetPatreonPerks(ctx)
+	if errCurrent != nil {
+		return errors.Wrap(errCurrent, "Failed to load patreon perks")
+	}
+
+	now := time.Now()
+	plan := PlanPatreonPerks(patreonEntitlements(pledges, links, p.conf.Patreon.Tiers), current,
+		now, p.conf.Patreon.GracePeriodValue)
+
+	for _, perk := range plan.Revoke {
+		if errRevoke := p.revokePerks(ctx, perk); errRevoke != nil {
+			p.log.Error("Failed to revoke patreon perks", zap.Error(errRevoke))
+		}
+	}
+
+	for _, entitlement := range plan.Grant {
+		if errGrant := p.grantPerks(ctx, entitlement); errGrant != nil {
+			p.log.Error("Failed to grant patreon perks", zap.Error(errGrant))
+		}
+	}
+
+	for _, perk := range plan.Lapse {
+		perk.LapsedOn = &now
+
+		if errSave := p.db.SavePatreonPerk(ctx, &perk); errSave != nil {
+			p.log.Error("Failed to mark patreon perks lapsed", zap.Error(errSave))
+
+			continue
+		}
+
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkLapse, "pledge", "")
+	}
+
+	for _, perk := range plan.Renew {
+		perk.LapsedOn = nil
+
+		if errSave := p.db.SavePatreonPerk(ctx, &perk); errSave != nil {
+			p.log.Error("Failed to renew patreon perks", zap.Error(errSave))
+
+			continue
+		}
+
+		p.logPerk(ctx, perk.SteamID, perk.PatreonID, perk.TierID, patreonPerkRenew, "pledge", "")
+	}
+
+	return nil
+}
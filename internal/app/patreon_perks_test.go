@@ -0,0 +1,53 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPatreonPerks(t *testing.T) {
+	var (
+		now       = time.Now()
+		grace     = time.Hour * 24
+		recent    = now.Add(-time.Hour)
+		expired   = now.Add(-grace * 2)
+		newPatron = steamid.New(76561198044052046)
+		upgraded  = steamid.New(76561197960265728)
+		resumed   = steamid.New(76561197960265729)
+		cancelled = steamid.New(76561197960265730)
+		lapsed    = steamid.New(76561197960265731)
+		unchanged = steamid.New(76561197960265732)
+	)
+
+	entitled := []app.PatreonEntitlement{
+		{SteamID: newPatron, PatreonID: "1", TierID: "gold"},
+		{SteamID: upgraded, PatreonID: "2", TierID: "gold"},
+		{SteamID: resumed, PatreonID: "3", TierID: "silver"},
+		{SteamID: unchanged, PatreonID: "6", TierID: "silver"},
+	}
+
+	current := []store.PatreonPerk{
+		{SteamID: upgraded, PatreonID: "2", TierID: "silver"},
+		{SteamID: resumed, PatreonID: "3", TierID: "silver", LapsedOn: &recent},
+		{SteamID: cancelled, PatreonID: "4", TierID: "silver"},
+		{SteamID: lapsed, PatreonID: "5", TierID: "silver", LapsedOn: &expired},
+		{SteamID: unchanged, PatreonID: "6", TierID: "silver"},
+	}
+
+	plan := app.PlanPatreonPerks(entitled, current, now, grace)
+
+	require.Equal(t, []app.PatreonEntitlement{entitled[0], entitled[1]}, plan.Grant)
+	require.Equal(t, []store.PatreonPerk{current[0], current[3]}, plan.Revoke)
+	require.Equal(t, []store.PatreonPerk{current[2]}, plan.Lapse)
+	require.Equal(t, []store.PatreonPerk{current[1]}, plan.Renew)
+
+	// Without a grace period, perks are revoked as soon as the pledge ends
+	noGrace := app.PlanPatreonPerks(nil, current[2:3], now, 0)
+	require.Empty(t, noGrace.Lapse)
+	require.Equal(t, []store.PatreonPerk{current[2]}, noGrace.Revoke)
+}
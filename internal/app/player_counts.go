@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// playerCountStaleAfter is how long since the last state update before a server is no longer sampled.
+const playerCountStaleAfter = time.Minute * 2
+
+// playerCountRecorder samples the player count of each server every minute and periodically downsamples the
+// older samples.
+func (app *App) playerCountRecorder(ctx context.Context) {
+	if !app.conf.PlayerCounts.Enabled {
+		return
+	}
+
+	var (
+		log          = app.log.Named("playerCounts")
+		sampleTicker = time.NewTicker(time.Minute)
+		rollupTicker = time.NewTicker(time.Hour)
+	)
+
+	defer sampleTicker.Stop()
+	defer rollupTicker.Stop()
+
+	for {
+		select {
+		case <-sampleTicker.C:
+			now := time.Now()
+
+			var samples []store.PlayerCountBucket
+
+			for _, server := range app.state.current() {
+				if now.Sub(server.LastUpdate) > playerCountStaleAfter {
+					continue
+				}
+
+				samples = append(samples, store.NewPlayerCountSample(server.ServerID, server.PlayerCount, now))
+			}
+
+			if errSave := app.db.SavePlayerCountBuckets(ctx, samples); errSave != nil {
+				log.Error("Failed to save player count samples", zap.Error(errSave))
+			}
+		case <-rollupTicker.C:
+			if errRollup := app.rollupPlayerCounts(ctx, time.Now()); errRollup != nil {
+				log.Error("Failed to downsample player counts", zap.Error(errRollup))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rollupPlayerCounts rebuilds the hourly buckets from the minute samples, and the daily buckets from the hourly
+// buckets, then removes the samples which have passed their retention. Only buckets whose source data is still
+// complete are rebuilt, so it is safe to run repeatedly.
+func (app *App) rollupPlayerCounts(ctx context.Context, now time.Time) error {
+	var (
+		minuteCutoff = store.PlayerCountHour.Truncate(now.Add(-app.conf.PlayerCounts.MinuteRetentionValue))
+		hourCutoff   = store.PlayerCountDay.Truncate(now.Add(-app.conf.PlayerCounts.HourRetentionValue))
+	)
+
+	minutes, errMinutes := app.db.GetPlayerCountBuckets(ctx, store.PlayerCountQuery{
+		Resolution: store.PlayerCountMinute,
+		From:       minuteCutoff,
+		To:         now,
+	})
+	if errMinutes != nil {
+		return errors.Wrap(errMinutes, "Failed to load minute player counts")
+	}
+
+	if errSave := app.db.SavePlayerCountBuckets(ctx, store.DownsamplePlayerCounts(minutes, store.PlayerCountHour)); errSave != nil {
+		return errors.Wrap(errSave, "Failed to save hourly player counts")
+	}
+
+	dayFrom := store.PlayerCountDay.Truncate(minuteCutoff)
+	if dayFrom.Before(hourCutoff) {
+		dayFrom = hourCutoff
+	}
+
+	hours, errHours := app.db.GetPlayerCountBuckets(ctx, store.PlayerCountQuery{
+		Resolution: store.PlayerCountHour,
+		From:       dayFrom,
+		To:         now,
+	})
+	if errHours != nil {
+		return errors.Wrap(errHours, "Failed to load hourly player counts")
+	}
+
+	if errSave := app.db.SavePlayerCountBuckets(ctx, store.DownsamplePlayerCounts(hours, store.PlayerCountDay)); errSave != nil {
+		return errors.Wrap(errSave, "Failed to save daily player counts")
+	}
+
+	if errDelete := app.db.DeletePlayerCountBuckets(ctx, store.PlayerCountMinute, minuteCutoff); errDelete != nil {
+		return errors.Wrap(errDelete, "Failed to prune minute player counts")
+	}
+
+	if errDelete := app.db.DeletePlayerCountBuckets(ctx, store.PlayerCountHour, hourCutoff); errDelete != nil {
+		return errors.Wrap(errDelete, "Failed to prune hourly player counts")
+	}
+
+	return nil
+}
+
+// PlayerCountResolutionFor picks the finest resolution which still has data for the whole range, while keeping
+// the number of points returned reasonable.
+func PlayerCountResolutionFor(from time.Time, to time.Time, now time.Time, minuteRetention time.Duration,
+	hourRetention time.Duration,
+) store.PlayerCountResolution {
+	span := to.Sub(from)
+
+	switch {
+	case span <= time.Hour*24 && !from.Before(now.Add(-minuteRetention)):
+		return store.PlayerCountMinute
+	case span <= time.Hour*24*31 && !from.Before(now.Add(-hourRetention)):
+		return store.PlayerCountHour
+	default:
+		return store.PlayerCountDay
+	}
+}
+
+type PlayerCountPoint struct {
+	Bucket  time.Time `json:"bucket"`
+	Samples int       `json:"samples"`
+	Peak    int       `json:"peak"`
+	Average float64   `json:"average"`
+}
+
+type PlayerCountSeries struct {
+	ServerID  int                      `json:"server_id"`
+	ShortName string                   `json:"short_name"`
+	Region    string                   `json:"region"`
+	Summary   store.PlayerCountSummary `json:"summary"`
+	Points    []PlayerCountPoint       `json:"points"`
+}
+
+type PlayerCountResult struct {
+	Resolution store.PlayerCountResolution `json:"resolution"`
+	// Summary covers every server included in the results.
+	Summary store.PlayerCountSummary            `json:"summary"`
+	Regions map[string]store.PlayerCountSummary `json:"regions"`
+	Servers []PlayerCountSeries                 `json:"servers"`
+}
+
+// NewPlayerCountResult groups the buckets into a series for each server, along with the summaries for each region
+// and overall.
+func NewPlayerCountResult(resolution store.PlayerCountResolution, buckets []store.PlayerCountBucket,
+	servers map[int]store.Server,
+) PlayerCountResult {
+	var (
+		result = PlayerCountResult{
+			Resolution: resolution,
+			Summary:    store.SummarizePlayerCounts(buckets),
+			Regions:    map[string]store.PlayerCountSummary{},
+			Servers:    []PlayerCountSeries{},
+		}
+		byServer = map[int][]store.PlayerCountBucket{}
+		byRegion = map[string][]store.PlayerCountBucket{}
+		order    []int
+	)
+
+	for _, bucket := range buckets {
+		if _, found := byServer[bucket.ServerID]; !found {
+			order = append(order, bucket.ServerID)
+		}
+
+		byServer[bucket.ServerID] = append(byServer[bucket.ServerID], bucket)
+		region := servers[bucket.ServerID].Region
+		byRegion[region] = append(byRegion[region], bucket)
+	}
+
+	for region, regionBuckets := range byRegion {
+		result.Regions[region] = store.SummarizePlayerCounts(regionBuckets)
+	}
+
+	for _, serverID := range order {
+		series := PlayerCountSeries{
+			ServerID:  serverID,
+			ShortName: servers[serverID].ShortName,
+			Region:    servers[serverID].Region,
+			Summary:   store.SummarizePlayerCounts(byServer[serverID]),
+		}
+
+		for _, bucket := range byServer[serverID] {
+			series.Points = append(series.Points, PlayerCountPoint{
+				Bucket:  bucket.Bucket,
+				Samples: bucket.Samples,
+				Peak:    bucket.Peak,
+				Average: bucket.Average(),
+			})
+		}
+
+		result.Servers = append(result.Servers, series)
+	}
+
+	return result
+}
@@ -0,0 +1,52 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayerCountResolutionFor(t *testing.T) {
+	var (
+		now     = time.Now()
+		minutes = time.Hour * 48
+		hours   = time.Hour * 24 * 90
+	)
+
+	require.Equal(t, store.PlayerCountMinute, app.PlayerCountResolutionFor(now.Add(-time.Hour*6), now, now, minutes, hours))
+	// Minute samples for the start of the range have already been removed
+	require.Equal(t, store.PlayerCountHour, app.PlayerCountResolutionFor(now.Add(-time.Hour*72), now.Add(-time.Hour*60),
+		now, minutes, hours))
+	require.Equal(t, store.PlayerCountHour, app.PlayerCountResolutionFor(now.Add(-time.Hour*24*7), now, now, minutes, hours))
+	require.Equal(t, store.PlayerCountDay, app.PlayerCountResolutionFor(now.Add(-time.Hour*24*60), now, now, minutes, hours))
+	require.Equal(t, store.PlayerCountDay, app.PlayerCountResolutionFor(now.Add(-time.Hour*24*120),
+		now.Add(-time.Hour*24*110), now, minutes, hours))
+}
+
+func TestNewPlayerCountResult(t *testing.T) {
+	now := time.Now()
+	servers := map[int]store.Server{
+		1: {ServerID: 1, ShortName: "us-1", Region: "na"},
+		2: {ServerID: 2, ShortName: "us-2", Region: "na"},
+		3: {ServerID: 3, ShortName: "eu-1", Region: "eu"},
+	}
+	buckets := []store.PlayerCountBucket{
+		store.NewPlayerCountSample(1, 10, now),
+		store.NewPlayerCountSample(1, 20, now.Add(time.Minute)),
+		store.NewPlayerCountSample(2, 24, now),
+		store.NewPlayerCountSample(3, 2, now),
+	}
+
+	result := app.NewPlayerCountResult(store.PlayerCountMinute, buckets, servers)
+	require.Equal(t, 4, result.Summary.Samples)
+	require.Equal(t, 24, result.Summary.Peak)
+	require.Len(t, result.Servers, 3)
+	require.Equal(t, "us-1", result.Servers[0].ShortName)
+	require.Len(t, result.Servers[0].Points, 2)
+	require.InDelta(t, 15.0, result.Servers[0].Summary.Average, 0.001)
+	require.Equal(t, 3, result.Regions["na"].Samples)
+	require.Equal(t, 2, result.Regions["eu"].Peak)
+}
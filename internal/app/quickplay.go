@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/ip2location"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// quickPlayDistanceScale is the distance, in km, at which the distance score falls to half.
+	quickPlayDistanceScale = 1500.0
+	// quickPlayTargetFill is the share of slots filled which is considered the most desirable.
+	quickPlayTargetFill = 0.75
+
+	quickPlayWeightDistance   = 0.45
+	quickPlayWeightPopulation = 0.40
+	quickPlayWeightMap        = 0.15
+
+	quickPlayMaxResults = 10
+)
+
+// QuickPlayServer is the subset of a servers state used to rank it.
+type QuickPlayServer struct {
+	ServerID      int
+	Map           string
+	Tags          []string
+	Players       int
+	MaxPlayers    int
+	ReservedSlots int
+	Distance      float64
+}
+
+// QuickPlayPrefs are the users preferences for the server they want to join.
+type QuickPlayPrefs struct {
+	// GameModes restricts results to servers running one of the modes, eg: koth, pl.
+	GameModes []string `json:"game_modes"`
+	// Maps are preferred, but not required.
+	Maps []string `json:"maps"`
+	// Reserved indicates the user may use reserved slots.
+	Reserved bool `json:"-"`
+}
+
+// QuickPlayResult is a ranked server along with the link used to join it.
+type QuickPlayResult struct {
+	baseServer
+	Score      float64 `json:"score"`
+	ConnectURL string  `json:"connect_url"`
+}
+
+// mapGameMode returns the game mode of a map from its prefix, eg: koth_product_final -> koth.
+func mapGameMode(mapName string) string {
+	mapName = strings.ToLower(mapName[strings.LastIndex(mapName, "/")+1:])
+
+	idx := strings.Index(mapName, "_")
+	if idx <= 0 {
+		return ""
+	}
+
+	return mapName[:idx]
+}
+
+// QuickPlayScore scores how good a fit the server is for the user between 0 and 1. False is returned when the
+// server is full or does not run any of the requested game modes.
+func QuickPlayScore(server QuickPlayServer, prefs QuickPlayPrefs) (float64, bool) {
+	slots := server.MaxPlayers
+	if !prefs.Reserved {
+		slots -= server.ReservedSlots
+	}
+
+	if slots <= 0 || server.Players >= slots {
+		return 0, false
+	}
+
+	if len(prefs.GameModes) > 0 {
+		mode := mapGameMode(server.Map)
+		matched := false
+
+		for _, wanted := range prefs.GameModes {
+			wanted = strings.ToLower(wanted)
+			if wanted == mode || containsString(server.Tags, wanted) {
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			return 0, false
+		}
+	}
+
+	distanceScore := 1 / (1 + math.Max(0, server.Distance)/quickPlayDistanceScale)
+
+	// Servers close to the target fill are preferred, empty servers are the least desirable.
+	fill := float64(server.Players) / float64(slots)
+	populationScore := math.Max(0, 1-math.Abs(fill-quickPlayTargetFill)/quickPlayTargetFill)
+
+	var mapScore float64
+
+	for _, mapName := range prefs.Maps {
+		if strings.EqualFold(mapName, server.Map) {
+			mapScore = 1
+
+			break
+		}
+	}
+
+	return quickPlayWeightDistance*distanceScore +
+		quickPlayWeightPopulation*populationScore +
+		quickPlayWeightMap*mapScore, true
+}
+
+// quickPlayLocation finds the users location from their ip, falling back to the location provided by cloudflare.
+func (app *App) quickPlayLocation(ctx context.Context, ipAddr net.IP, fallback ip2location.LatLong) ip2location.LatLong {
+	if ipAddr == nil {
+		return fallback
+	}
+
+	var record ip2location.LocationRecord
+	if errLocation := app.db.GetLocationRecord(ctx, ipAddr, &record); errLocation != nil {
+		return fallback
+	}
+
+	return record.LatLong
+}
+
+// quickPlay ranks the enabled servers for the user. The top selection is only recorded for authenticated users so
+// that anonymous requests cannot be used to fill the selection history.
+func (app *App) quickPlay(ctx context.Context, steamID steamid.SID64, permission consts.Privilege,
+	location ip2location.LatLong, prefs QuickPlayPrefs,
+) ([]QuickPlayResult, error) {
+	prefs.Reserved = permission >= consts.PReserved
+
+	var (
+		state   = app.state.current()
+		results []QuickPlayResult
+	)
+
+	for _, srv := range state {
+		if srv.Password {
+			continue
+		}
+
+		server := newBaseServer(srv, location.Latitude, location.Longitude)
+
+		score, eligible := QuickPlayScore(QuickPlayServer{
+			ServerID:      srv.ServerID,
+			Map:           srv.Map,
+			Tags:          srv.Tags,
+			Players:       srv.PlayerCount,
+			MaxPlayers:    srv.MaxPlayers,
+			ReservedSlots: srv.ReservedSlots,
+			Distance:      server.Distance,
+		}, prefs)
+		if !eligible {
+			continue
+		}
+
+		results = append(results, QuickPlayResult{
+			baseServer: server,
+			Score:      score,
+			ConnectURL: fmt.Sprintf("steam://connect/%s:%d", srv.IP, srv.Port),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) == 0 {
+		return []QuickPlayResult{}, nil
+	}
+
+	if steamID.Valid() {
+		selection := store.QuickPlaySelection{
+			SteamID:    steamID,
+			ServerID:   results[0].ServerID,
+			GameModes:  prefs.GameModes,
+			Maps:       prefs.Maps,
+			Score:      results[0].Score,
+			Distance:   results[0].Distance,
+			Players:    results[0].Players,
+			MaxPlayers: results[0].MaxPlayers,
+			Candidates: len(results),
+		}
+
+		if errSave := app.db.SaveQuickPlaySelection(ctx, &selection); errSave != nil {
+			return nil, errors.Wrap(errSave, "Failed to save quickplay selection")
+		}
+	}
+
+	if len(results) > quickPlayMaxResults {
+		results = results[:quickPlayMaxResults]
+	}
+
+	return results, nil
+}
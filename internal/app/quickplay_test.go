@@ -0,0 +1,40 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickPlayScore(t *testing.T) {
+	server := app.QuickPlayServer{Map: "koth_harvest_final", Players: 15, MaxPlayers: 24, ReservedSlots: 4}
+
+	// Full servers are excluded unless the user can use the reserved slots
+	_, eligible := app.QuickPlayScore(app.QuickPlayServer{Map: "pl_upward", Players: 20, MaxPlayers: 24, ReservedSlots: 4}, app.QuickPlayPrefs{})
+	require.False(t, eligible)
+	_, eligible = app.QuickPlayScore(app.QuickPlayServer{Map: "pl_upward", Players: 20, MaxPlayers: 24, ReservedSlots: 4}, app.QuickPlayPrefs{Reserved: true})
+	require.True(t, eligible)
+
+	// Game modes are a requirement, maps are a preference
+	_, eligible = app.QuickPlayScore(server, app.QuickPlayPrefs{GameModes: []string{"pl"}})
+	require.False(t, eligible)
+
+	base, eligible := app.QuickPlayScore(server, app.QuickPlayPrefs{GameModes: []string{"KOTH"}})
+	require.True(t, eligible)
+	require.InDelta(t, 0.85, base, 0.001)
+
+	preferred, _ := app.QuickPlayScore(server, app.QuickPlayPrefs{Maps: []string{"koth_harvest_final"}})
+	require.Greater(t, preferred, base)
+
+	// Nearby and well populated servers rank above distant or empty ones
+	far := server
+	far.Distance = 5000
+	farScore, _ := app.QuickPlayScore(far, app.QuickPlayPrefs{})
+	require.Less(t, farScore, base)
+
+	empty := server
+	empty.Players = 0
+	emptyScore, _ := app.QuickPlayScore(empty, app.QuickPlayPrefs{})
+	require.Less(t, emptyScore, base)
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// rconAllowAll can be used in the allowed command list to permit any command.
+const rconAllowAll = "*"
+
+var (
+	errRconCommandDenied = errors.New("Command not permitted")
+	errRconNoTargets     = errors.New("No servers matched target")
+)
+
+// RconCommandAllowed checks if the command is permitted by the allowed list. Only the
+// command name is compared, and chaining multiple commands is only permitted when all commands are allowed.
+func RconCommandAllowed(allowed []string, command string) bool {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return false
+	}
+
+	for _, name := range allowed {
+		if name == rconAllowAll {
+			return true
+		}
+	}
+
+	if strings.ContainsAny(command, ";\n\r") {
+		return false
+	}
+
+	name := strings.ToLower(strings.Fields(command)[0])
+
+	for _, allowedName := range allowed {
+		if strings.ToLower(allowedName) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rconTarget defines the servers a console command is sent to. Region selects all servers
+// in the region, ServerGroupIDs selects all members of the groups, All selects every enabled server.
+type rconTarget struct {
+	ServerIDs      []int  `json:"server_ids"`
+	ServerGroupIDs []int  `json:"server_group_ids"`
+	Region         string `json:"region"`
+	All            bool   `json:"all"`
+}
+
+type rconResult struct {
+	ServerID   int    `json:"server_id"`
+	ServerName string `json:"server_name"`
+	Response   string `json:"response"`
+	Error      string `json:"error"`
+}
+
+func (app *App) rconAllowedCommands(level consts.Privilege) []string {
+	if level >= consts.PAdmin {
+		return app.conf.RconConsole.AdminCommands
+	}
+
+	if level >= consts.PModerator {
+		return app.conf.RconConsole.ModeratorCommands
+	}
+
+	return nil
+}
+
+func (app *App) resolveRconTarget(ctx context.Context, target rconTarget) ([]store.Server, error) {
+	servers, _, errServers := app.db.GetServers(ctx, store.ServerQueryFilter{})
+	if errServers != nil {
+		return nil, errors.Wrap(errServers, "Failed to load servers")
+	}
+
+	groupServerIDs, errGroups := app.db.GetServerGroupServerIDs(ctx, target.ServerGroupIDs)
+	if errGroups != nil {
+		return nil, errors.Wrap(errGroups, "Failed to load server group members")
+	}
+
+	var targets []store.Server
+
+	for _, server := range servers {
+		switch {
+		case target.All:
+		case target.Region != "" && strings.EqualFold(server.Region, target.Region):
+		case containsInt(target.ServerIDs, server.ServerID):
+		case containsInt(groupServerIDs, server.ServerID):
+		default:
+			continue
+		}
+
+		targets = append(targets, server)
+	}
+
+	if len(targets) == 0 {
+		return nil, errRconNoTargets
+	}
+
+	return targets, nil
+}
+
+// rconPermittedServers removes any servers the user is not permitted to target. Moderators are limited to
+// the configured server groups when any are set.
+func (app *App) rconPermittedServers(ctx context.Context, level consts.Privilege, servers []store.Server) ([]store.Server, error) {
+	if level >= consts.PAdmin || len(app.conf.RconConsole.ModeratorServerGroups) == 0 {
+		return servers, nil
+	}
+
+	var groupIDs []int
+
+	for _, name := range app.conf.RconConsole.ModeratorServerGroups {
+		var group store.ServerGroup
+		if errGroup := app.db.GetServerGroupByName(ctx, name, &group); errGroup != nil {
+			if errors.Is(errGroup, store.ErrNoResult) {
+				continue
+			}
+
+			return nil, errors.Wrap(errGroup, "Failed to load server group")
+		}
+
+		groupIDs = append(groupIDs, group.ServerGroupID)
+	}
+
+	allowedIDs, errAllowed := app.db.GetServerGroupServerIDs(ctx, groupIDs)
+	if errAllowed != nil {
+		return nil, errors.Wrap(errAllowed, "Failed to load server group members")
+	}
+
+	var permitted []store.Server
+
+	for _, server := range servers {
+		if containsInt(allowedIDs, server.ServerID) {
+			permitted = append(permitted, server)
+		}
+	}
+
+	if len(permitted) == 0 {
+		return nil, errRconNoTargets
+	}
+
+	return permitted, nil
+}
+
+// execConsoleCommand runs the command on each of the servers concurrently, recording each execution in the
+// audit log. Results are sent to the returned channel as they complete, which is closed once all servers
+// have responded.
+func (app *App) execConsoleCommand(ctx context.Context, author steamid.SID64, level consts.Privilege,
+	servers []store.Server, command string,
+) (<-chan rconResult, error) {
+	if !RconCommandAllowed(app.rconAllowedCommands(level), command) {
+		return nil, errRconCommandDenied
+	}
+
+	var (
+		results   = make(chan rconResult, len(servers))
+		waitGroup = &sync.WaitGroup{}
+		// Audits must still be recorded if the client disconnects before all servers respond
+		auditCtx = context.WithoutCancel(ctx)
+	)
+
+	for _, server := range servers {
+		waitGroup.Add(1)
+
+		go func(srv store.Server) {
+			defer waitGroup.Done()
+
+			result := rconResult{ServerID: srv.ServerID, ServerName: srv.ShortName}
+
+			resp, errExec := app.state.rcon(srv.ServerID, command)
+			if errExec != nil {
+				result.Error = errExec.Error()
+			} else {
+				result.Response = resp
+			}
+
+			audit := store.RconAudit{
+				SteamID:  author,
+				ServerID: srv.ServerID,
+				Command:  command,
+				Response: result.Response,
+				Error:    result.Error,
+			}
+
+			if errSave := app.db.SaveRconAudit(auditCtx, &audit); errSave != nil {
+				app.log.Error("Failed to save rcon audit", zap.Error(errSave))
+			}
+
+			results <- result
+		}(server)
+	}
+
+	go func() {
+		waitGroup.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
@@ -0,0 +1,20 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRconCommandAllowed(t *testing.T) {
+	allowed := []string{"status", "sm_kick"}
+
+	require.True(t, app.RconCommandAllowed(allowed, "status"))
+	require.True(t, app.RconCommandAllowed(allowed, "  SM_KICK #12 bye"))
+	require.False(t, app.RconCommandAllowed(allowed, "rcon_password x"))
+	require.False(t, app.RconCommandAllowed(allowed, "status; quit"))
+	require.False(t, app.RconCommandAllowed(allowed, ""))
+	require.False(t, app.RconCommandAllowed(nil, "status"))
+	require.True(t, app.RconCommandAllowed([]string{"*"}, "status; quit"))
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/cron"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errRconTaskName     = errors.New("Task name cannot be empty")
+	errRconTaskCommands = errors.New("Task must have at least one command")
+	errRconTaskTarget   = errors.New("Task must target at least one server")
+)
+
+// validateRconTask checks that the task is runnable.
+func (app *App) validateRconTask(task store.RconTask) error {
+	if strings.TrimSpace(task.Name) == "" {
+		return errRconTaskName
+	}
+
+	if _, errSchedule := cron.Parse(task.Schedule); errSchedule != nil {
+		return errors.Wrap(errSchedule, "Invalid schedule")
+	}
+
+	if len(task.Commands) == 0 {
+		return errRconTaskCommands
+	}
+
+	for _, command := range task.Commands {
+		if !RconCommandAllowed(app.conf.RconConsole.AdminCommands, command) {
+			return errors.Wrapf(errRconCommandDenied, "%s", command)
+		}
+	}
+
+	if !task.AllServers && task.Region == "" && len(task.ServerIDs) == 0 && len(task.ServerGroupIDs) == 0 {
+		return errRconTaskTarget
+	}
+
+	return nil
+}
+
+// rconTaskScheduler runs each enabled task when its schedule becomes due. Tasks are reloaded from the
+// database every minute so changes take effect without a restart.
+func (app *App) rconTaskScheduler(ctx context.Context) {
+	var (
+		log       = app.log.Named("rconTasks")
+		ticker    = time.NewTicker(time.Minute)
+		lastCheck = time.Now()
+	)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			tasks, errTasks := app.db.GetRconTasks(ctx)
+			if errTasks != nil {
+				log.Error("Failed to load rcon tasks", zap.Error(errTasks))
+
+				continue
+			}
+
+			for _, task := range tasks {
+				if !task.Enabled {
+					continue
+				}
+
+				schedule, errSchedule := cron.Parse(task.Schedule)
+				if errSchedule != nil {
+					log.Warn("Invalid rcon task schedule", zap.Int("task_id", task.RconTaskID), zap.Error(errSchedule))
+
+					continue
+				}
+
+				next, errNext := schedule.Next(lastCheck)
+				if errNext != nil || next.After(now) {
+					continue
+				}
+
+				go func(t store.RconTask) {
+					if _, errRun := app.runRconTask(ctx, t, app.conf.General.Owner); errRun != nil {
+						log.Error("Failed to run rcon task", zap.Int("task_id", t.RconTaskID), zap.Error(errRun))
+					}
+				}(task)
+			}
+
+			lastCheck = now
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runRconTask executes the task against each of its target servers concurrently. The commands are run in
+// order on each server, stopping at the first failure. Servers which do not meet the task conditions, or whose
+// state is unknown when there are conditions, are skipped. A run is recorded for every server and each command
+// is recorded in the rcon audit log under the author, which is the owner for scheduled runs.
+func (app *App) runRconTask(ctx context.Context, task store.RconTask, author steamid.SID64) ([]store.RconTaskRun, error) {
+	servers, errServers := app.resolveRconTarget(ctx, rconTarget{
+		ServerIDs:      task.ServerIDs,
+		ServerGroupIDs: task.ServerGroupIDs,
+		Region:         task.Region,
+		All:            task.AllServers,
+	})
+	if errServers != nil {
+		return nil, errServers
+	}
+
+	var (
+		state     = app.state.current()
+		runs      = make([]store.RconTaskRun, len(servers))
+		waitGroup = &sync.WaitGroup{}
+		// Runs and audits must still be recorded if a manually triggered run is abandoned by the client
+		saveCtx = context.WithoutCancel(ctx)
+	)
+
+	for idx, server := range servers {
+		waitGroup.Add(1)
+
+		go func(idx int, srv store.Server) {
+			defer waitGroup.Done()
+
+			run := store.RconTaskRun{
+				RconTaskID: task.RconTaskID,
+				TaskName:   task.Name,
+				ServerID:   srv.ServerID,
+				ServerName: srv.ShortName,
+			}
+
+			details, found := state.byServerID(srv.ServerID)
+
+			switch {
+			case task.PlayersBelow > 0 && !found:
+				run.Skipped = true
+				run.Output = "Skipped, server state unknown"
+			case task.PlayersBelow > 0 && details.PlayerCount >= task.PlayersBelow:
+				run.Skipped = true
+				run.Output = fmt.Sprintf("Skipped, %d players connected", details.PlayerCount)
+			default:
+				var output []string
+
+				for _, command := range task.Commands {
+					resp, errExec := app.state.rcon(srv.ServerID, command)
+
+					audit := store.RconAudit{
+						SteamID:  author,
+						ServerID: srv.ServerID,
+						Command:  command,
+						Response: resp,
+					}
+
+					if errExec != nil {
+						audit.Error = errExec.Error()
+					}
+
+					if errSave := app.db.SaveRconAudit(saveCtx, &audit); errSave != nil {
+						app.log.Error("Failed to save rcon audit", zap.Error(errSave))
+					}
+
+					if errExec != nil {
+						run.Error = fmt.Sprintf("%s: %s", command, errExec.Error())
+
+						break
+					}
+
+					output = append(output, resp)
+				}
+
+				run.Output = strings.Join(output, "\n")
+			}
+
+			if errSave := app.db.SaveRconTaskRun(saveCtx, &run); errSave != nil {
+				app.log.Error("Failed to save rcon task run", zap.Error(errSave))
+			}
+
+			runs[idx] = run
+		}(idx, server)
+	}
+
+	waitGroup.Wait()
+
+	return runs, nil
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/pkg/logparse"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"go.uber.org/zap"
+)
+
+// ReconnectReservations holds the slots of players who dropped from a server unexpectedly, such as from a crash
+// or timeout, so that they are able to rejoin even when the server has since filled up.
+type ReconnectReservations struct {
+	reservations map[int]map[steamid.SID64]time.Time
+	sync.Mutex
+}
+
+func NewReconnectReservations() *ReconnectReservations {
+	return &ReconnectReservations{reservations: map[int]map[steamid.SID64]time.Time{}}
+}
+
+// Reserve holds a slot on the server for the player until expires.
+func (r *ReconnectReservations) Reserve(serverID int, steamID steamid.SID64, expires time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, found := r.reservations[serverID]; !found {
+		r.reservations[serverID] = map[steamid.SID64]time.Time{}
+	}
+
+	r.reservations[serverID][steamID] = expires
+}
+
+// Claim removes the players reservation, returning true if they had one which has not expired.
+func (r *ReconnectReservations) Claim(serverID int, steamID steamid.SID64, now time.Time) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	expires, found := r.reservations[serverID][steamID]
+	if !found {
+		return false
+	}
+
+	delete(r.reservations[serverID], steamID)
+
+	return now.Before(expires)
+}
+
+// Active returns the players currently holding a reservation on the server, removing any which have expired.
+func (r *ReconnectReservations) Active(serverID int, now time.Time) map[steamid.SID64]time.Time {
+	r.Lock()
+	defer r.Unlock()
+
+	active := map[steamid.SID64]time.Time{}
+
+	for steamID, expires := range r.reservations[serverID] {
+		if !now.Before(expires) {
+			delete(r.reservations[serverID], steamID)
+
+			continue
+		}
+
+		active[steamID] = expires
+	}
+
+	return active
+}
+
+// isReconnectReason checks if the disconnect reason is one of the configured reasons which should reserve a slot.
+func isReconnectReason(reason string, reasons []string) bool {
+	reason = strings.ToLower(reason)
+
+	for _, match := range reasons {
+		if match != "" && strings.Contains(reason, strings.ToLower(match)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reconnectTracker reserves a slot for players who disconnect unexpectedly.
+func (app *App) reconnectTracker(ctx context.Context) {
+	if !app.conf.ReconnectGrace.Enabled || app.conf.ReconnectGrace.DurationValue <= 0 {
+		return
+	}
+
+	var (
+		log             = app.log.Named("reconnectTracker")
+		serverEventChan = make(chan logparse.ServerEvent)
+	)
+
+	if errRegister := app.eb.Consume(serverEventChan, logparse.Disconnected); errRegister != nil {
+		log.Warn("reconnectTracker tried to register duplicate reader channel", zap.Error(errRegister))
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-serverEventChan:
+			newServerEvent, ok := evt.Event.(logparse.DisconnectedEvt)
+			if !ok || !newServerEvent.SID.Valid() || newServerEvent.Bot {
+				continue
+			}
+
+			if !isReconnectReason(newServerEvent.Reason, app.conf.ReconnectGrace.Reasons) {
+				continue
+			}
+
+			app.reconnects.Reserve(evt.ServerID, newServerEvent.SID, time.Now().Add(app.conf.ReconnectGrace.DurationValue))
+
+			log.Debug("Reserved slot for reconnect", zap.Int("server_id", evt.ServerID),
+				zap.String("steam_id", newServerEvent.SID.String()), zap.String("reason", newServerEvent.Reason))
+		}
+	}
+}
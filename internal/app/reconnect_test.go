@@ -0,0 +1,38 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnectReservations(t *testing.T) {
+	var (
+		reservations = app.NewReconnectReservations()
+		now          = time.Now()
+		playerA      = steamid.New(76561198044052046)
+		playerB      = steamid.New(76561197960265728)
+	)
+
+	reservations.Reserve(1, playerA, now.Add(time.Minute*5))
+	reservations.Reserve(1, playerB, now.Add(time.Minute))
+
+	// Reservations are per server
+	require.False(t, reservations.Claim(2, playerA, now))
+	require.Len(t, reservations.Active(1, now), 2)
+	require.Len(t, reservations.Active(2, now), 0)
+
+	// Expired reservations are dropped
+	active := reservations.Active(1, now.Add(time.Minute*2))
+	require.Len(t, active, 1)
+	require.Contains(t, active, playerA)
+	require.False(t, reservations.Claim(1, playerB, now.Add(time.Minute*2)))
+
+	// A reservation can only be claimed once
+	require.True(t, reservations.Claim(1, playerA, now.Add(time.Minute*2)))
+	require.False(t, reservations.Claim(1, playerA, now.Add(time.Minute*2)))
+	require.Len(t, reservations.Active(1, now), 0)
+}
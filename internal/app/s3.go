@@ -16,6 +16,7 @@ import (
 
 type AssetStore interface {
 	Put(ctx context.Context, bucket string, name string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, bucket string, name string) (io.ReadCloser, error)
 	Remove(ctx context.Context, bucket string, name string) error
 }
 
@@ -98,6 +99,26 @@ func (s3 *S3Client) Put(ctx context.Context, bucket string, name string, body io
 	return nil
 }
 
+// Get opens the object for reading. The caller is responsible for closing it.
+func (s3 *S3Client) Get(ctx context.Context, bucket string, name string) (io.ReadCloser, error) {
+	s3.RLock()
+	defer s3.RUnlock()
+
+	object, errGet := s3.GetObject(ctx, bucket, name, minio.GetObjectOptions{})
+	if errGet != nil {
+		return nil, errors.Wrap(errGet, "Failed to get object")
+	}
+
+	// Errors such as the object not existing are only returned once the object is accessed.
+	if _, errStat := object.Stat(); errStat != nil {
+		_ = object.Close()
+
+		return nil, errors.Wrap(errStat, "Failed to stat object")
+	}
+
+	return object, nil
+}
+
 func (s3 *S3Client) Remove(ctx context.Context, bucket string, name string) error {
 	s3.Lock()
 	defer s3.Unlock()
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errUnknownCredentialKind = errors.New("Unknown credential kind")
+	errUnknownServerScope    = errors.New("Unknown server scope")
+)
+
+// serverKeyLength is limited by the size of the server password column.
+const serverKeyLength = 20
+
+// newCredentialSecret generates a new secret suitable for the kind of credential.
+func newCredentialSecret(kind store.ServerCredentialKind) (string, error) {
+	switch kind {
+	case store.ServerCredentialKey:
+		return store.SecureRandomString(serverKeyLength), nil
+	case store.ServerCredentialLogSecret:
+		// sv_logsecret only accepts a positive int32.
+		value, errRand := rand.Int(rand.Reader, big.NewInt(1<<31-2))
+		if errRand != nil {
+			return "", errors.Wrap(errRand, "Failed to generate log secret")
+		}
+
+		return fmt.Sprintf("%d", value.Int64()+1), nil
+	default:
+		return "", errUnknownCredentialKind
+	}
+}
+
+// validateServerScopes checks that all the scopes are known.
+func validateServerScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !hasServerScope(serverScopes, scope) {
+			return errors.Wrap(errUnknownServerScope, scope)
+		}
+	}
+
+	return nil
+}
+
+// rotateServerCredential replaces the servers current credential of the kind. The previous credential is accepted
+// until the overlap has passed. When rotating the log secret, the new value is also set on the server immediately
+// if it is reachable.
+func (app *App) rotateServerCredential(ctx context.Context, serverID int, kind store.ServerCredentialKind,
+	scopes []string, overlap time.Duration,
+) (store.ServerCredential, error) {
+	var server store.Server
+	if errServer := app.db.GetServer(ctx, serverID, &server); errServer != nil {
+		return store.ServerCredential{}, errors.Wrap(errServer, "Failed to load server")
+	}
+
+	secret, errSecret := newCredentialSecret(kind)
+	if errSecret != nil {
+		return store.ServerCredential{}, errSecret
+	}
+
+	credential, errRotate := app.db.RotateServerCredential(ctx, &server, kind, secret, scopes, time.Now().Add(overlap))
+	if errRotate != nil {
+		return credential, errors.Wrap(errRotate, "Failed to rotate server credential")
+	}
+
+	if kind == store.ServerCredentialLogSecret {
+		if app.logListener != nil {
+			app.updateSrcdsLogSecrets(ctx)
+		}
+
+		if _, errExec := app.state.rcon(server.ServerID, fmt.Sprintf("sv_logsecret %d", server.LogSecret)); errExec != nil {
+			app.log.Warn("Failed to set rotated log secret on server, it must be updated manually",
+				zap.String("server", server.ShortName), zap.Error(errExec))
+		}
+	}
+
+	app.log.Info("Server credential rotated", zap.String("server", server.ShortName), zap.String("kind", string(kind)))
+
+	return credential, nil
+}
+
+// warnServerCredentialConflicts reports servers which shared a secret with another server before credentials were
+// introduced. Only one of them could be given a credential, so the others cannot authenticate until a new secret is
+// set on them.
+func (app *App) warnServerCredentialConflicts(ctx context.Context) {
+	conflicts, errConflicts := app.db.GetServerCredentialConflicts(ctx)
+	if errConflicts != nil {
+		app.log.Error("Failed to check for server credential conflicts", zap.Error(errConflicts))
+
+		return
+	}
+
+	for _, conflict := range conflicts {
+		app.log.Warn("Server shares a secret with another server, rotate its credential to set a new one",
+			zap.String("server", conflict.ShortName), zap.String("kind", string(conflict.Kind)))
+	}
+}
+
+// logSecretUpdater periodically refreshes the accepted log secrets so that server changes and expired
+// credentials are picked up.
+func (app *App) logSecretUpdater(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+
+	app.updateSrcdsLogSecrets(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			app.updateSrcdsLogSecrets(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
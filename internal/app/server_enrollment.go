@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var errEnrollmentAddress = errors.New("Invalid server address")
+
+// maxShortNameLength is limited by the size of the server short_name column.
+const maxShortNameLength = 32
+
+// EnrollmentShortName chooses the short name of an enrolling server. A name set on the token by an admin takes
+// priority over the one requested by the server. When neither is set one is generated from the region.
+func EnrollmentShortName(tokenName string, requestedName string, region string, suffix string) string {
+	name := strings.TrimSpace(tokenName)
+	if name == "" {
+		name = strings.TrimSpace(requestedName)
+	}
+
+	if name == "" {
+		prefix := strings.ToLower(strings.TrimSpace(region))
+		if prefix == "" {
+			prefix = "server"
+		}
+
+		name = fmt.Sprintf("%s-%s", prefix, strings.ToLower(suffix))
+	}
+
+	if len(name) > maxShortNameLength {
+		name = name[:maxShortNameLength]
+	}
+
+	return name
+}
+
+// enrollServer creates a new server, pending approval, using the enrollment token. The server is given freshly
+// generated credentials which are returned to it only once.
+func (app *App) enrollServer(ctx context.Context, req ServerEnrollRequest, clientIP string) (store.Server, error) {
+	address := req.Address
+	if address == "" {
+		address = clientIP
+	}
+
+	if net.ParseIP(address) == nil || req.Port <= 0 || req.Port > 65535 {
+		return store.Server{}, errEnrollmentAddress
+	}
+
+	logSecret, errLogSecret := newCredentialSecret(store.ServerCredentialLogSecret)
+	if errLogSecret != nil {
+		return store.Server{}, errLogSecret
+	}
+
+	logSecretValue, errLogSecretValue := strconv.Atoi(logSecret)
+	if errLogSecretValue != nil {
+		return store.Server{}, errors.Wrap(errLogSecretValue, "Invalid log secret")
+	}
+
+	server, errEnroll := app.db.EnrollServer(ctx, req.Token, time.Now(), func(token store.ServerEnrollmentToken) store.Server {
+		shortName := EnrollmentShortName(token.ShortName, req.ShortName, token.Region, store.SecureRandomString(6))
+
+		server := store.NewServer(shortName, address, req.Port)
+		server.Name = req.Hostname
+		server.Password = store.SecureRandomString(serverKeyLength)
+		server.RCON = store.SecureRandomString(serverKeyLength)
+		server.LogSecret = logSecretValue
+		server.Region = token.Region
+		server.CC = token.CC
+		server.Latitude = token.Latitude
+		server.Longitude = token.Longitude
+		server.ReservedSlots = token.ReservedSlots
+		server.EnableStats = token.EnableStats
+
+		return server
+	})
+	if errEnroll != nil {
+		return server, errors.Wrap(errEnroll, "Failed to enroll server")
+	}
+
+	app.log.Info("Server enrolled, pending approval", zap.String("server", server.ShortName),
+		zap.String("addr", server.Addr()))
+
+	if app.conf.Discord.Enabled {
+		msgEmbed := discord.
+			NewEmbed("New Server Pending Approval").
+			SetDescription("A server has enrolled itself and must be approved before it can be used").
+			AddField("server", server.ShortName).
+			AddField("name", server.Name).
+			AddField("address", server.Addr()).
+			AddField("region", server.Region)
+
+		app.bot.SendPayload(discord.Payload{ChannelID: app.conf.Discord.LogChannelID, Embed: msgEmbed.Truncate().MessageEmbed})
+	}
+
+	return server, nil
+}
+
+// approveServer enables a server which enrolled itself.
+func (app *App) approveServer(ctx context.Context, server *store.Server) error {
+	server.PendingApproval = false
+	server.IsEnabled = true
+
+	if errSave := app.db.SaveServer(ctx, server); errSave != nil {
+		return errors.Wrap(errSave, "Failed to approve server")
+	}
+
+	if app.logListener != nil {
+		app.updateSrcdsLogSecrets(ctx)
+	}
+
+	return nil
+}
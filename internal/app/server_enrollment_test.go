@@ -0,0 +1,17 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrollmentShortName(t *testing.T) {
+	require.Equal(t, "us-1", app.EnrollmentShortName("us-1", "requested", "us", "abc"))
+	require.Equal(t, "requested", app.EnrollmentShortName("", " requested ", "us", "abc"))
+	require.Equal(t, "eu-abc", app.EnrollmentShortName("", "", "EU", "ABC"))
+	require.Equal(t, "server-abc", app.EnrollmentShortName("", "", "", "abc"))
+	require.Len(t, app.EnrollmentShortName(strings.Repeat("x", 40), "", "", "abc"), 32)
+}
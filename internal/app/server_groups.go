@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+)
+
+// serverGroupPrefix marks a server name argument as referring to a server group, eg: @mge.
+const serverGroupPrefix = "@"
+
+var (
+	errServerGroupName = errors.New("Group names may only contain lowercase letters, numbers, - and _")
+
+	serverGroupNameRx = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-]*$`)
+)
+
+// validateServerGroup checks the group name and removes duplicate members.
+func validateServerGroup(group *store.ServerGroup) error {
+	group.Name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(group.Name, serverGroupPrefix)))
+	if !serverGroupNameRx.MatchString(group.Name) {
+		return errServerGroupName
+	}
+
+	var serverIDs []int
+
+	for _, serverID := range group.ServerIDs {
+		if serverID > 0 && !containsInt(serverIDs, serverID) {
+			serverIDs = append(serverIDs, serverID)
+		}
+	}
+
+	group.ServerIDs = serverIDs
+
+	return nil
+}
+
+// serverIDsByTarget resolves a server name, wildcard or @group into the matching server ids.
+func (app *App) serverIDsByTarget(ctx context.Context, target string) ([]int, error) {
+	if !strings.HasPrefix(target, serverGroupPrefix) {
+		state := app.state.current()
+
+		return state.serverIDsByName(target, true), nil
+	}
+
+	var group store.ServerGroup
+	if errGroup := app.db.GetServerGroupByName(ctx, strings.ToLower(strings.TrimPrefix(target, serverGroupPrefix)), &group); errGroup != nil {
+		if errors.Is(errGroup, store.ErrNoResult) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(errGroup, "Failed to load server group")
+	}
+
+	return group.ServerIDs, nil
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"go.uber.org/zap"
+)
+
+// serverUptimePeriod is the period over which the uptime shown in the server health overview is calculated.
+const serverUptimePeriod = time.Hour * 24 * 7
+
+// serverHealthMonitor periodically evaluates the health of each server, recording and announcing any changes.
+func (app *App) serverHealthMonitor(ctx context.Context) {
+	if !app.conf.ServerHealth.Enabled {
+		return
+	}
+
+	var (
+		log     = app.log.Named("serverHealth")
+		ticker  = time.NewTicker(time.Minute)
+		monitor = NewHealthMonitor(HealthRules{
+			DownAfter:     app.conf.ServerHealth.DownAfterValue,
+			MapStuckAfter: app.conf.ServerHealth.MapStuckAfterValue,
+			PeakStart:     app.conf.ServerHealth.PeakHoursStart,
+			PeakEnd:       app.conf.ServerHealth.PeakHoursEnd,
+			RestartGap:    app.conf.ServerHealth.RestartGapValue,
+		}, time.Now())
+	)
+
+	defer ticker.Stop()
+
+	latest, errLatest := app.db.GetServerHealthLatest(ctx)
+	if errLatest != nil {
+		log.Error("Failed to load last known server health", zap.Error(errLatest))
+	}
+
+	for serverID, event := range latest {
+		monitor.SetState(serverID, event.State)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+
+			for _, server := range app.state.current() {
+				result := monitor.Update(HealthSample{
+					ServerID: server.ServerID,
+					LastSeen: server.RCONLastSeen,
+					Players:  server.PlayerCount,
+					Map:      server.Map,
+					Uptime:   app.serverUptime(server.ServerID, now),
+				}, now)
+
+				if !result.Changed {
+					continue
+				}
+
+				if errSave := app.db.SaveServerHealthEvent(ctx, &store.ServerHealthEvent{
+					ServerID:  server.ServerID,
+					State:     result.State,
+					Reason:    result.Reason,
+					CreatedOn: now,
+				}); errSave != nil {
+					log.Error("Failed to save server health event", zap.Error(errSave))
+				}
+
+				if result.Initial && result.State == store.HealthUp {
+					continue
+				}
+
+				app.onServerHealthChange(server, result)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// serverUptime returns the uptime of the server process from its latest performance sample, returning 0 when it is
+// unknown or the sample is stale. Samples are only collected when server_performance is enabled.
+func (app *App) serverUptime(serverID int, now time.Time) time.Duration {
+	sample, found := app.latestPerformance.Get(serverID)
+	if !found || now.Sub(sample.CreatedOn) > app.conf.ServerPerformance.IntervalValue*2 {
+		return 0
+	}
+
+	return time.Duration(sample.UptimeMinutes) * time.Minute
+}
+
+func (app *App) onServerHealthChange(server serverDetails, result HealthResult) {
+	channelID := app.conf.ServerHealth.AlertChannelID
+	if channelID == "" {
+		channelID = app.conf.Discord.LogChannelID
+	}
+
+	colour := app.bot.Colour.Success
+
+	switch result.State {
+	case store.HealthDown:
+		colour = app.bot.Colour.Error
+	case store.HealthDegraded:
+		colour = app.bot.Colour.Warn
+	case store.HealthUp:
+	}
+
+	title := fmt.Sprintf("Server %s is %s", server.NameShort, result.State.String())
+	if result.Restarted && result.State == result.Previous {
+		title = fmt.Sprintf("Server %s restarted", server.NameShort)
+		colour = app.bot.Colour.Warn
+	}
+
+	msgEmbed := discord.
+		NewEmbed(title).
+		SetColor(colour).
+		AddField("Server", server.Name)
+
+	if !result.Initial {
+		msgEmbed.AddField("Previous State", result.Previous.String())
+	}
+
+	if result.Reason != "" {
+		msgEmbed.AddField("Reason", result.Reason)
+	}
+
+	app.bot.SendPayload(discord.Payload{
+		ChannelID: channelID,
+		Embed:     msgEmbed.Truncate().MessageEmbed,
+	})
+}
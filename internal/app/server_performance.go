@@ -0,0 +1,311 @@
+package app
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// performanceMaxSpan limits how much history can be requested at once.
+const performanceMaxSpan = time.Hour * 24 * 7
+
+var (
+	errPerformanceStats = errors.New("Failed to parse stats response")
+
+	// netStatusLineRx matches the labelled lines of net_status, eg: "- Loss:    avg out 0.0, in 0.1".
+	netStatusLineRx = regexp.MustCompile(`(?m)^\s*-\s*(\w+)\s*:(.*)$`)
+	// netStatusValueRx matches the in and out values, which may be given in either order.
+	netStatusValueRx = regexp.MustCompile(`\b(in|out)\s+([\d.]+)`)
+)
+
+// ParseServerStats reads the values from the response to the stats command. Both the older format and the newer
+// format which includes the frame times are supported.
+//
+//	CPU   NetIn   NetOut    Uptime  Maps   FPS   Players  Svms    +-ms   ~tick
+//	10.0  1234.5  5678.9       123     4  66.67      24   4.27    0.95    0.53
+func ParseServerStats(response string, sample *store.ServerPerformanceSample) error {
+	lines := strings.Split(strings.ReplaceAll(response, "\r", ""), "\n")
+
+	for idx, line := range lines {
+		header := strings.Fields(line)
+		if len(header) == 0 || !strings.EqualFold(header[0], "cpu") || idx+1 >= len(lines) {
+			continue
+		}
+
+		values := strings.Fields(lines[idx+1])
+		if len(values) != len(header) {
+			return errPerformanceStats
+		}
+
+		for col, name := range header {
+			value, errValue := strconv.ParseFloat(values[col], 64)
+			if errValue != nil {
+				return errors.Wrapf(errPerformanceStats, "Invalid value for %s", name)
+			}
+
+			switch strings.ToLower(name) {
+			case "cpu":
+				sample.CPU = value
+			case "netin", "in_(kb/s)":
+				sample.NetInKBps = value
+			case "netout", "out_(kb/s)":
+				sample.NetOutKBps = value
+			case "uptime":
+				sample.UptimeMinutes = int(value)
+			case "fps":
+				sample.FPS = value
+			case "svms":
+				sample.FrameMS = value
+			case "+-ms":
+				sample.VarMS = value
+			case "~tick":
+				sample.TickMS = value
+			}
+		}
+
+		return nil
+	}
+
+	return errPerformanceStats
+}
+
+// ParseNetStatus reads the packet rates, loss and choke from the response to the net_status command. The values
+// are read by their in/out label as the engine prints the out value first. Choke is not printed by current
+// versions of the engine, so it is only set when present. eg: "- Packets: net total out  1580.5/s, in 1500.0/s".
+func ParseNetStatus(response string, sample *store.ServerPerformanceSample) {
+	for _, line := range netStatusLineRx.FindAllStringSubmatch(strings.ReplaceAll(response, "\r", ""), -1) {
+		var valueIn, valueOut float64
+
+		for _, match := range netStatusValueRx.FindAllStringSubmatch(line[2], -1) {
+			value, errValue := strconv.ParseFloat(match[2], 64)
+			if errValue != nil {
+				continue
+			}
+
+			if match[1] == "in" {
+				valueIn = value
+			} else {
+				valueOut = value
+			}
+		}
+
+		switch strings.ToLower(line[1]) {
+		case "packets":
+			sample.PacketsIn, sample.PacketsOut = valueIn, valueOut
+		case "loss":
+			sample.LossIn, sample.LossOut = valueIn, valueOut
+		case "choke":
+			sample.ChokeIn, sample.ChokeOut = valueIn, valueOut
+		}
+	}
+}
+
+// EvaluateTickHealth calculates how close the server is to running at the expected tick rate and flags the sample
+// as a lag spike when either the frame time variance is too high or the server cannot keep up with the tick rate.
+func EvaluateTickHealth(sample *store.ServerPerformanceSample, tickRate float64, minTickHealth float64, lagSpikeVar float64) {
+	if tickRate > 0 {
+		sample.TickHealth = sample.FPS / tickRate
+	}
+
+	sample.LagSpike = (lagSpikeVar > 0 && sample.VarMS >= lagSpikeVar) ||
+		(tickRate > 0 && sample.TickHealth < minTickHealth)
+}
+
+// serverPerformanceCollector polls the performance of each server over rcon, updating the prometheus gauges and
+// recording a short history.
+func (app *App) serverPerformanceCollector(ctx context.Context) {
+	if !app.conf.ServerPerformance.Enabled {
+		return
+	}
+
+	var (
+		log          = app.log.Named("serverPerformance")
+		pollTicker   = time.NewTicker(app.conf.ServerPerformance.IntervalValue)
+		pruneTicker  = time.NewTicker(time.Hour)
+		failedLabels = map[string]bool{}
+	)
+
+	defer pollTicker.Stop()
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-pollTicker.C:
+			samples, failed := app.pollServerPerformance()
+
+			for _, name := range failed {
+				// Remove the gauges so stale values are not exported for servers which are not responding.
+				if !failedLabels[name] {
+					app.mc.deleteServerPerformance(name)
+				}
+
+				failedLabels[name] = true
+			}
+
+			for _, sample := range samples {
+				delete(failedLabels, sample.name)
+				app.mc.setServerPerformance(sample.name, sample.sample)
+				app.latestPerformance.Set(sample.sample.ServerID, sample.sample)
+			}
+
+			records := make([]store.ServerPerformanceSample, len(samples))
+			for idx, sample := range samples {
+				records[idx] = sample.sample
+			}
+
+			if errSave := app.db.SaveServerPerformanceSamples(ctx, records); errSave != nil {
+				log.Error("Failed to save server performance samples", zap.Error(errSave))
+			}
+		case <-pruneTicker.C:
+			before := time.Now().Add(-app.conf.ServerPerformance.RetentionValue)
+			if errDelete := app.db.DeleteServerPerformanceSamples(ctx, before); errDelete != nil {
+				log.Error("Failed to prune server performance samples", zap.Error(errDelete))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type namedPerformanceSample struct {
+	name   string
+	sample store.ServerPerformanceSample
+}
+
+// pollServerPerformance queries all the servers concurrently, returning the samples collected and the names of
+// the servers which could not be queried.
+func (app *App) pollServerPerformance() ([]namedPerformanceSample, []string) {
+	var (
+		conf      = app.conf.ServerPerformance
+		now       = time.Now()
+		waitGroup = sync.WaitGroup{}
+		resultsMu = sync.Mutex{}
+		samples   []namedPerformanceSample
+		failed    []string
+	)
+
+	for _, server := range app.state.current() {
+		if !server.Enabled {
+			continue
+		}
+
+		waitGroup.Add(1)
+
+		go func(details serverDetails) {
+			defer waitGroup.Done()
+
+			sample := store.ServerPerformanceSample{
+				ServerID:  details.ServerID,
+				CreatedOn: now,
+				MapName:   details.Map,
+				Players:   details.PlayerCount,
+			}
+
+			statsResp, errStats := app.state.rcon(details.ServerID, "stats")
+			if errStats == nil {
+				errStats = ParseServerStats(statsResp, &sample)
+			}
+
+			if errStats != nil {
+				resultsMu.Lock()
+				failed = append(failed, details.NameShort)
+				resultsMu.Unlock()
+
+				return
+			}
+
+			// net_status is only supplementary, the sample is still useful without it.
+			if netResp, errNet := app.state.rcon(details.ServerID, "net_status"); errNet == nil {
+				ParseNetStatus(netResp, &sample)
+			}
+
+			EvaluateTickHealth(&sample, conf.TickRate, conf.MinTickHealth, conf.LagSpikeVar)
+
+			resultsMu.Lock()
+			samples = append(samples, namedPerformanceSample{name: details.NameShort, sample: sample})
+			resultsMu.Unlock()
+		}(server)
+	}
+
+	waitGroup.Wait()
+
+	return samples, failed
+}
+
+func (mc *metricCollector) setServerPerformance(serverName string, sample store.ServerPerformanceSample) {
+	labels := prometheus.Labels{"server_name": serverName}
+
+	mc.serverCPU.With(labels).Set(sample.CPU)
+	mc.serverFPS.With(labels).Set(sample.FPS)
+	mc.serverFrameTime.With(labels).Set(sample.FrameMS)
+	mc.serverVar.With(labels).Set(sample.VarMS)
+	mc.serverNetIn.With(labels).Set(sample.NetInKBps)
+	mc.serverNetOut.With(labels).Set(sample.NetOutKBps)
+	mc.serverLossIn.With(labels).Set(sample.LossIn)
+	mc.serverLossOut.With(labels).Set(sample.LossOut)
+	mc.serverUptime.With(labels).Set(float64(sample.UptimeMinutes * 60))
+	mc.serverTickHealth.With(labels).Set(sample.TickHealth)
+}
+
+func (mc *metricCollector) deleteServerPerformance(serverName string) {
+	for _, gauge := range []*prometheus.GaugeVec{
+		mc.serverCPU, mc.serverFPS, mc.serverFrameTime, mc.serverVar, mc.serverNetIn, mc.serverNetOut,
+		mc.serverLossIn, mc.serverLossOut, mc.serverUptime, mc.serverTickHealth,
+	} {
+		gauge.DeleteLabelValues(serverName)
+	}
+}
+
+type ServerMapChange struct {
+	MapName   string    `json:"map_name"`
+	ChangedOn time.Time `json:"changed_on"`
+}
+
+type ServerPerformanceSeries struct {
+	ServerID   int                             `json:"server_id"`
+	ShortName  string                          `json:"short_name"`
+	LagSpikes  int                             `json:"lag_spikes"`
+	MapChanges []ServerMapChange               `json:"map_changes"`
+	Samples    []store.ServerPerformanceSample `json:"samples"`
+}
+
+// NewServerPerformanceSeries groups the samples for each server and finds the map changes between them. The
+// samples must be ordered by server and then time.
+func NewServerPerformanceSeries(samples []store.ServerPerformanceSample, servers map[int]store.Server) []ServerPerformanceSeries {
+	results := []ServerPerformanceSeries{}
+
+	for _, sample := range samples {
+		if len(results) == 0 || results[len(results)-1].ServerID != sample.ServerID {
+			results = append(results, ServerPerformanceSeries{
+				ServerID:   sample.ServerID,
+				ShortName:  servers[sample.ServerID].ShortName,
+				MapChanges: []ServerMapChange{},
+			})
+		}
+
+		series := &results[len(results)-1]
+
+		if sample.LagSpike {
+			series.LagSpikes++
+		}
+
+		if len(series.Samples) > 0 && series.Samples[len(series.Samples)-1].MapName != sample.MapName {
+			series.MapChanges = append(series.MapChanges, ServerMapChange{
+				MapName:   sample.MapName,
+				ChangedOn: sample.CreatedOn,
+			})
+		}
+
+		series.Samples = append(series.Samples, sample)
+	}
+
+	return results
+}
@@ -0,0 +1,94 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerStats(t *testing.T) {
+	var sample store.ServerPerformanceSample
+
+	require.NoError(t, app.ParseServerStats(`CPU   NetIn   NetOut    Uptime  Maps   FPS   Players  Svms    +-ms   ~tick
+ 12.5  1234.5  5678.9       123     4   66.67      24   4.27    0.95    0.53
+`, &sample))
+	require.InDelta(t, 12.5, sample.CPU, 0.001)
+	require.InDelta(t, 1234.5, sample.NetInKBps, 0.001)
+	require.InDelta(t, 5678.9, sample.NetOutKBps, 0.001)
+	require.Equal(t, 123, sample.UptimeMinutes)
+	require.InDelta(t, 66.67, sample.FPS, 0.001)
+	require.InDelta(t, 4.27, sample.FrameMS, 0.001)
+	require.InDelta(t, 0.95, sample.VarMS, 0.001)
+	require.InDelta(t, 0.53, sample.TickMS, 0.001)
+
+	var legacy store.ServerPerformanceSample
+
+	require.NoError(t, app.ParseServerStats(`CPU    In_(KB/s)  Out_(KB/s)  Uptime  Map_changes  FPS      Players  Connects
+0.00   10.00      20.00       5       1            66.00    3        7
+`, &legacy))
+	require.InDelta(t, 10.0, legacy.NetInKBps, 0.001)
+	require.InDelta(t, 66.0, legacy.FPS, 0.001)
+
+	require.Error(t, app.ParseServerStats("Unknown command \"stats\"", &sample))
+	require.Error(t, app.ParseServerStats("CPU FPS\n1.0", &sample))
+}
+
+func TestParseNetStatus(t *testing.T) {
+	var sample store.ServerPerformanceSample
+
+	app.ParseNetStatus(`Net status for host 0.0.0.0:
+- Config: Multiplayer, dedicated, 24 connections
+- CPU Usage: 12.3%
+- Ports: 27015 UDP, 27020 HLTV, 27005 Client
+- Latency: avg out 0.09s, in 0.05s
+- Loss:    avg out 0.3, in 0.1
+- Packets: net total out  1580.5/s, in 1500.0/s
+           per client out 65.9/s, in 62.5/s
+- Data:    net total out  210.3, in 42.1 kB/s
+           per client out 8.8, in 1.8 kB/s
+`, &sample)
+	require.InDelta(t, 1500.0, sample.PacketsIn, 0.001)
+	require.InDelta(t, 1580.5, sample.PacketsOut, 0.001)
+	require.InDelta(t, 0.1, sample.LossIn, 0.001)
+	require.InDelta(t, 0.3, sample.LossOut, 0.001)
+	require.Zero(t, sample.ChokeIn)
+	require.Zero(t, sample.ChokeOut)
+}
+
+func TestEvaluateTickHealth(t *testing.T) {
+	healthy := store.ServerPerformanceSample{FPS: 66.67, VarMS: 0.5}
+	app.EvaluateTickHealth(&healthy, 66.67, 0.9, 5)
+	require.InDelta(t, 1.0, healthy.TickHealth, 0.001)
+	require.False(t, healthy.LagSpike)
+
+	slow := store.ServerPerformanceSample{FPS: 40, VarMS: 0.5}
+	app.EvaluateTickHealth(&slow, 66.67, 0.9, 5)
+	require.True(t, slow.LagSpike)
+
+	jittery := store.ServerPerformanceSample{FPS: 66.67, VarMS: 8}
+	app.EvaluateTickHealth(&jittery, 66.67, 0.9, 5)
+	require.True(t, jittery.LagSpike)
+}
+
+func TestNewServerPerformanceSeries(t *testing.T) {
+	now := time.Now()
+	servers := map[int]store.Server{1: {ServerID: 1, ShortName: "test-1"}}
+
+	series := app.NewServerPerformanceSeries([]store.ServerPerformanceSample{
+		{ServerID: 1, CreatedOn: now, MapName: "pl_upward"},
+		{ServerID: 1, CreatedOn: now.Add(time.Minute), MapName: "pl_upward", LagSpike: true},
+		{ServerID: 1, CreatedOn: now.Add(time.Minute * 2), MapName: "pl_badwater"},
+		{ServerID: 2, CreatedOn: now, MapName: "cp_process_final"},
+	}, servers)
+
+	require.Len(t, series, 2)
+	require.Equal(t, "test-1", series[0].ShortName)
+	require.Len(t, series[0].Samples, 3)
+	require.Equal(t, 1, series[0].LagSpikes)
+	require.Equal(t, []app.ServerMapChange{{MapName: "pl_badwater", ChangedOn: now.Add(time.Minute * 2)}},
+		series[0].MapChanges)
+	require.Empty(t, series[1].MapChanges)
+}
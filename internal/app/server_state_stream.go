@@ -0,0 +1,107 @@
+package app
+
+import (
+	"github.com/leighmacdonald/steamid/v3/extra"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+)
+
+// stateSubscriberBuffer is the number of state updates buffered for each subscriber. Updates for
+// subscribers with a full buffer are dropped, which clients detect as a gap in the sequence numbers.
+const stateSubscriberBuffer = 100
+
+// serverStateUpdate describes a change to the state of a single server. Seq increases by one with
+// every update published by the collector.
+type serverStateUpdate struct {
+	Seq      uint64
+	ServerID int
+	Removed  bool
+	Server   serverDetails
+	Joined   []extra.Player
+	Left     []extra.Player
+}
+
+type serverStateSubscription struct {
+	Updates chan serverStateUpdate
+}
+
+// subscribe registers a new subscriber and returns it along with the current state and the sequence
+// number it corresponds to. Any update with a higher sequence number will be sent to the subscriber.
+func (c *serverStateCollector) subscribe() (*serverStateSubscription, serverDetailsCollection, uint64) {
+	sub := &serverStateSubscription{Updates: make(chan serverStateUpdate, stateSubscriberBuffer)}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.subscribers[sub] = struct{}{}
+
+	return sub, c.sortedState(), c.seq
+}
+
+func (c *serverStateCollector) unsubscribe(sub *serverStateSubscription) {
+	c.stateMu.Lock()
+	delete(c.subscribers, sub)
+	c.stateMu.Unlock()
+}
+
+// publishChange sends an update to all subscribers when the server has changed in a way that is visible in
+// the server browser. The caller must hold the write lock on stateMu.
+func (c *serverStateCollector) publishChange(prev serverDetails, cur serverDetails, removed bool) {
+	joined, left := playersDiff(prev.Players, cur.Players)
+
+	if !removed && len(joined) == 0 && len(left) == 0 &&
+		prev.Name == cur.Name &&
+		prev.Map == cur.Map &&
+		prev.PlayerCount == cur.PlayerCount &&
+		prev.MaxPlayers == cur.MaxPlayers &&
+		prev.Bots == cur.Bots {
+		return
+	}
+
+	c.seq++
+
+	update := serverStateUpdate{
+		Seq:      c.seq,
+		ServerID: cur.ServerID,
+		Removed:  removed,
+		Server:   cur,
+		Joined:   joined,
+		Left:     left,
+	}
+
+	for sub := range c.subscribers {
+		select {
+		case sub.Updates <- update:
+		default:
+		}
+	}
+}
+
+// playersDiff returns the players present in cur but not prev, and those present in prev but not cur.
+func playersDiff(prev []extra.Player, cur []extra.Player) ([]extra.Player, []extra.Player) {
+	var (
+		joined  []extra.Player
+		left    []extra.Player
+		prevIDs = map[steamid.SID64]bool{}
+		curIDs  = map[steamid.SID64]bool{}
+	)
+
+	for _, player := range prev {
+		prevIDs[player.SID] = true
+	}
+
+	for _, player := range cur {
+		curIDs[player.SID] = true
+
+		if !prevIDs[player.SID] {
+			joined = append(joined, player)
+		}
+	}
+
+	for _, player := range prev {
+		if !curIDs[player.SID] {
+			left = append(left, player)
+		}
+	}
+
+	return joined, left
+}
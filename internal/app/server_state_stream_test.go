@@ -0,0 +1,133 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/steamid/v3/extra"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPlayersDiff(t *testing.T) {
+	var (
+		playerA = extra.Player{Name: "a", SID: steamid.New(76561198084134025)}
+		playerB = extra.Player{Name: "b", SID: steamid.New(76561197961279983)}
+		playerC = extra.Player{Name: "c", SID: steamid.New(76561197960265828)}
+	)
+
+	joined, left := playersDiff(nil, []extra.Player{playerA, playerB})
+	require.Equal(t, []extra.Player{playerA, playerB}, joined)
+	require.Empty(t, left)
+
+	joined, left = playersDiff([]extra.Player{playerA, playerB}, []extra.Player{playerB, playerC})
+	require.Equal(t, []extra.Player{playerC}, joined)
+	require.Equal(t, []extra.Player{playerA}, left)
+
+	// Players are matched by steam id, so changes to their other fields are not a join or leave
+	renamed := playerB
+	renamed.Name = "b2"
+	renamed.Ping = 100
+	joined, left = playersDiff([]extra.Player{playerB}, []extra.Player{renamed})
+	require.Empty(t, joined)
+	require.Empty(t, left)
+
+	joined, left = playersDiff([]extra.Player{playerA}, nil)
+	require.Empty(t, joined)
+	require.Equal(t, []extra.Player{playerA}, left)
+}
+
+func TestPublishChange(t *testing.T) {
+	var (
+		collector    = newServerStateCollector(zap.NewNop())
+		sub, _, seq0 = collector.subscribe()
+		player       = extra.Player{Name: "a", SID: steamid.New(76561198084134025)}
+		prev         = serverDetails{ServerID: 1, Name: "test-1", Map: "pl_upward", MaxPlayers: 24}
+	)
+
+	require.Equal(t, uint64(0), seq0)
+
+	next := func() serverStateUpdate {
+		t.Helper()
+
+		require.Len(t, sub.Updates, 1)
+
+		return <-sub.Updates
+	}
+
+	// Nothing visible changed
+	collector.stateMu.Lock()
+	collector.publishChange(prev, prev, false)
+	collector.stateMu.Unlock()
+	require.Empty(t, sub.Updates)
+
+	// Join
+	cur := prev
+	cur.Players = []extra.Player{player}
+	cur.PlayerCount = 1
+
+	collector.stateMu.Lock()
+	collector.publishChange(prev, cur, false)
+	collector.stateMu.Unlock()
+
+	update := next()
+	require.Equal(t, uint64(1), update.Seq)
+	require.Equal(t, 1, update.ServerID)
+	require.Equal(t, []extra.Player{player}, update.Joined)
+	require.Empty(t, update.Left)
+
+	// Changes to players which are not shown in the browser are not published
+	pinged := cur
+	pinged.Players = []extra.Player{{Name: "a", SID: player.SID, Ping: 120}}
+
+	collector.stateMu.Lock()
+	collector.publishChange(cur, pinged, false)
+	collector.stateMu.Unlock()
+	require.Empty(t, sub.Updates)
+
+	// Field change
+	changed := cur
+	changed.Map = "pl_badwater"
+
+	collector.stateMu.Lock()
+	collector.publishChange(cur, changed, false)
+	collector.stateMu.Unlock()
+
+	update = next()
+	require.Equal(t, uint64(2), update.Seq)
+	require.Equal(t, "pl_badwater", update.Server.Map)
+	require.Empty(t, update.Joined)
+	require.Empty(t, update.Left)
+
+	// Leave
+	empty := changed
+	empty.Players = nil
+	empty.PlayerCount = 0
+
+	collector.stateMu.Lock()
+	collector.publishChange(changed, empty, false)
+	collector.stateMu.Unlock()
+
+	update = next()
+	require.Equal(t, uint64(3), update.Seq)
+	require.Equal(t, []extra.Player{player}, update.Left)
+
+	// Removal is always published
+	collector.stateMu.Lock()
+	collector.publishChange(empty, empty, true)
+	collector.stateMu.Unlock()
+
+	update = next()
+	require.Equal(t, uint64(4), update.Seq)
+	require.True(t, update.Removed)
+
+	_, _, seq := collector.subscribe()
+	require.Equal(t, uint64(4), seq)
+
+	collector.unsubscribe(sub)
+
+	collector.stateMu.Lock()
+	collector.publishChange(empty, cur, false)
+	collector.stateMu.Unlock()
+	require.Empty(t, sub.Updates)
+}
@@ -0,0 +1,355 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+)
+
+// consulGameService is the consul service name used for the game servers themselves, exporters use their
+// configured names.
+const consulGameService = "srcds"
+
+var ansibleGroupRx = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// PrometheusTargetGroup is a single target group in the prometheus file_sd / http_sd format.
+//
+// https://prometheus.io/docs/prometheus/latest/configuration/configuration/#file_sd_config
+type PrometheusTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// serverHosts groups the servers by address as several game servers commonly share a single host, and therefore
+// a single set of exporters. Hosts are returned sorted by address, with the servers on each sorted by short name.
+func serverHosts(servers []store.Server) ([]string, map[string][]store.Server) {
+	var (
+		addresses []string
+		hosts     = map[string][]store.Server{}
+	)
+
+	for _, server := range servers {
+		if _, found := hosts[server.Address]; !found {
+			addresses = append(addresses, server.Address)
+		}
+
+		hosts[server.Address] = append(hosts[server.Address], server)
+	}
+
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		sort.Slice(hosts[address], func(i, j int) bool {
+			return hosts[address][i].ShortName < hosts[address][j].ShortName
+		})
+	}
+
+	return addresses, hosts
+}
+
+func joinServerNames(servers []store.Server) string {
+	names := make([]string, len(servers))
+	for idx, server := range servers {
+		names[idx] = server.ShortName
+	}
+
+	return strings.Join(names, ",")
+}
+
+// PrometheusTargets creates a target group for each exporter on each host. When multiple servers share a host,
+// the server_name label lists all of them.
+func PrometheusTargets(servers []store.Server, exporters []ServiceDiscoveryExporter) []PrometheusTargetGroup {
+	var (
+		groups           = []PrometheusTargetGroup{}
+		addresses, hosts = serverHosts(servers)
+	)
+
+	for _, exporter := range exporters {
+		for _, address := range addresses {
+			hostServers := hosts[address]
+
+			groups = append(groups, PrometheusTargetGroup{
+				Targets: []string{fmt.Sprintf("%s:%d", address, exporter.Port)},
+				Labels: map[string]string{
+					"__meta_prometheus_job": exporter.Name,
+					"exporter":              exporter.Name,
+					"region":                hostServers[0].Region,
+					"cc":                    hostServers[0].CC,
+					"server_name":           joinServerNames(hostServers),
+				},
+			})
+		}
+	}
+
+	return groups
+}
+
+// AnsibleGroupName converts a name into a valid ansible group name.
+func AnsibleGroupName(prefix string, name string) string {
+	group := strings.Trim(ansibleGroupRx.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if group == "" {
+		group = "unknown"
+	}
+
+	return prefix + "_" + group
+}
+
+type AnsibleHostGroup struct {
+	Hosts    []string `json:"hosts"`
+	Children []string `json:"children,omitempty"`
+}
+
+type AnsibleServerVars struct {
+	ServerID  int     `json:"server_id"`
+	ShortName string  `json:"short_name"`
+	Name      string  `json:"name"`
+	Port      int     `json:"port"`
+	Region    string  `json:"region"`
+	CC        string  `json:"cc"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type AnsibleHostVars struct {
+	Region  string              `json:"gbans_region"`
+	CC      string              `json:"gbans_cc"`
+	Servers []AnsibleServerVars `json:"gbans_servers"`
+}
+
+type AnsibleInventory struct {
+	HostVars map[string]AnsibleHostVars
+	Groups   map[string]AnsibleHostGroup
+}
+
+// MarshalJSON produces the dynamic inventory format, where the groups sit at the top level next to _meta.
+//
+// https://docs.ansible.com/ansible/latest/dev_guide/developing_inventory.html
+func (inv AnsibleInventory) MarshalJSON() ([]byte, error) {
+	out := map[string]any{
+		"_meta": map[string]any{"hostvars": inv.HostVars},
+	}
+
+	for name, group := range inv.Groups {
+		out[name] = group
+	}
+
+	return json.Marshal(out)
+}
+
+// NewAnsibleInventory builds a dynamic inventory with a group for each region and server group. The hosts are
+// the server addresses, with the details of the servers on each host provided as host vars.
+func NewAnsibleInventory(servers []store.Server, serverGroups []store.ServerGroup) AnsibleInventory {
+	var (
+		inventory = AnsibleInventory{
+			HostVars: map[string]AnsibleHostVars{},
+			Groups:   map[string]AnsibleHostGroup{},
+		}
+		addresses, hosts = serverHosts(servers)
+		hostByServerID   = map[int]string{}
+		children         []string
+	)
+
+	addHost := func(groupName string, address string) {
+		group, found := inventory.Groups[groupName]
+		if !found {
+			children = append(children, groupName)
+			group.Hosts = []string{}
+		}
+
+		for _, existing := range group.Hosts {
+			if existing == address {
+				return
+			}
+		}
+
+		group.Hosts = append(group.Hosts, address)
+		inventory.Groups[groupName] = group
+	}
+
+	for _, address := range addresses {
+		vars := AnsibleHostVars{Region: hosts[address][0].Region, CC: hosts[address][0].CC}
+
+		for _, server := range hosts[address] {
+			hostByServerID[server.ServerID] = address
+			vars.Servers = append(vars.Servers, AnsibleServerVars{
+				ServerID:  server.ServerID,
+				ShortName: server.ShortName,
+				Name:      server.Name,
+				Port:      server.Port,
+				Region:    server.Region,
+				CC:        server.CC,
+				Latitude:  server.Latitude,
+				Longitude: server.Longitude,
+			})
+
+			addHost(AnsibleGroupName("region", server.Region), address)
+		}
+
+		inventory.HostVars[address] = vars
+	}
+
+	for _, serverGroup := range serverGroups {
+		for _, serverID := range serverGroup.ServerIDs {
+			if address, found := hostByServerID[serverID]; found {
+				addHost(AnsibleGroupName("server_group", serverGroup.Name), address)
+			}
+		}
+	}
+
+	sort.Strings(children)
+
+	inventory.Groups["gbans"] = AnsibleHostGroup{Hosts: append([]string{}, addresses...), Children: children}
+
+	return inventory
+}
+
+// ConsulCatalogService matches the entries returned by the consul /v1/catalog/service/:service endpoint, which
+// allows the consul_sd support in prometheus and other tools to be pointed at gbans.
+//
+// https://developer.hashicorp.com/consul/api-docs/catalog#list-nodes-for-service
+type ConsulCatalogService struct {
+	ID             string            `json:"ID"`
+	Node           string            `json:"Node"`
+	Address        string            `json:"Address"`
+	Datacenter     string            `json:"Datacenter"`
+	NodeMeta       map[string]string `json:"NodeMeta"`
+	ServiceID      string            `json:"ServiceID"`
+	ServiceName    string            `json:"ServiceName"`
+	ServiceTags    []string          `json:"ServiceTags"`
+	ServiceAddress string            `json:"ServiceAddress"`
+	ServicePort    int               `json:"ServicePort"`
+	ServiceMeta    map[string]string `json:"ServiceMeta"`
+}
+
+// ConsulCatalog generates the consul service entries for the game servers and each configured exporter. Server
+// groups and the region are provided as tags.
+func ConsulCatalog(datacenter string, servers []store.Server, serverGroups []store.ServerGroup,
+	exporters []ServiceDiscoveryExporter,
+) map[string][]ConsulCatalogService {
+	var (
+		catalog          = map[string][]ConsulCatalogService{consulGameService: {}}
+		addresses, hosts = serverHosts(servers)
+		groupsByServer   = map[int][]string{}
+	)
+
+	for _, serverGroup := range serverGroups {
+		for _, serverID := range serverGroup.ServerIDs {
+			groupsByServer[serverID] = append(groupsByServer[serverID], serverGroup.Name)
+		}
+	}
+
+	for _, address := range addresses {
+		var hostTags []string
+
+		for _, server := range hosts[address] {
+			var tags []string
+			if server.Region != "" {
+				tags = append(tags, server.Region)
+			}
+
+			tags = append(tags, groupsByServer[server.ServerID]...)
+			hostTags = append(hostTags, tags...)
+
+			catalog[consulGameService] = append(catalog[consulGameService], ConsulCatalogService{
+				ID:             address,
+				Node:           address,
+				Address:        address,
+				Datacenter:     datacenter,
+				NodeMeta:       map[string]string{"region": server.Region, "cc": server.CC},
+				ServiceID:      server.ShortName,
+				ServiceName:    consulGameService,
+				ServiceTags:    tags,
+				ServiceAddress: address,
+				ServicePort:    server.Port,
+				ServiceMeta: map[string]string{
+					"server_id":   strconv.Itoa(server.ServerID),
+					"server_name": server.ShortName,
+					"region":      server.Region,
+					"cc":          server.CC,
+				},
+			})
+		}
+
+		hostTags = uniqueStrings(hostTags)
+
+		for _, exporter := range exporters {
+			catalog[exporter.Name] = append(catalog[exporter.Name], ConsulCatalogService{
+				ID:             address,
+				Node:           address,
+				Address:        address,
+				Datacenter:     datacenter,
+				NodeMeta:       map[string]string{"region": hosts[address][0].Region, "cc": hosts[address][0].CC},
+				ServiceID:      fmt.Sprintf("%s-%s", exporter.Name, address),
+				ServiceName:    exporter.Name,
+				ServiceTags:    hostTags,
+				ServiceAddress: address,
+				ServicePort:    exporter.Port,
+				ServiceMeta: map[string]string{
+					"server_name": joinServerNames(hosts[address]),
+					"region":      hosts[address][0].Region,
+					"cc":          hosts[address][0].CC,
+				},
+			})
+		}
+	}
+
+	return catalog
+}
+
+// ConsulServices produces the /v1/catalog/services response, mapping each service to its tags.
+func ConsulServices(catalog map[string][]ConsulCatalogService) map[string][]string {
+	services := map[string][]string{}
+
+	for name, entries := range catalog {
+		var tags []string
+		for _, entry := range entries {
+			tags = append(tags, entry.ServiceTags...)
+		}
+
+		services[name] = uniqueStrings(tags)
+	}
+
+	return services
+}
+
+func uniqueStrings(values []string) []string {
+	var (
+		seen   = map[string]bool{}
+		unique = []string{}
+	)
+
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+
+		seen[value] = true
+		unique = append(unique, value)
+	}
+
+	sort.Strings(unique)
+
+	return unique
+}
+
+// serviceDiscoveryData loads the enabled servers and the server groups used to build the discovery outputs.
+func (app *App) serviceDiscoveryData(ctx context.Context) ([]store.Server, []store.ServerGroup, error) {
+	servers, _, errServers := app.db.GetServers(ctx, store.ServerQueryFilter{})
+	if errServers != nil {
+		return nil, nil, errors.Wrap(errServers, "Failed to fetch servers")
+	}
+
+	serverGroups, errGroups := app.db.GetServerGroups(ctx)
+	if errGroups != nil {
+		return nil, nil, errors.Wrap(errGroups, "Failed to fetch server groups")
+	}
+
+	return servers, serverGroups, nil
+}
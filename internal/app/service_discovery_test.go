@@ -0,0 +1,88 @@
+package app_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func testSDServers() ([]store.Server, []store.ServerGroup) {
+	servers := []store.Server{
+		{ServerID: 1, ShortName: "us-2", Address: "10.0.0.1", Port: 27016, Region: "North America", CC: "us"},
+		{ServerID: 2, ShortName: "us-1", Address: "10.0.0.1", Port: 27015, Region: "North America", CC: "us"},
+		{ServerID: 3, ShortName: "eu-1", Address: "10.0.0.2", Port: 27015, Region: "eu", CC: "de"},
+	}
+	groups := []store.ServerGroup{{Name: "MGE Servers", ServerIDs: []int{2, 3}}}
+
+	return servers, groups
+}
+
+func TestPrometheusTargets(t *testing.T) {
+	servers, _ := testSDServers()
+
+	targets := app.PrometheusTargets(servers, []app.ServiceDiscoveryExporter{
+		{Name: "node", Port: 9100},
+		{Name: "srcds", Port: 9200},
+	})
+	require.Len(t, targets, 4)
+	require.Equal(t, []string{"10.0.0.1:9100"}, targets[0].Targets)
+	require.Equal(t, "us-1,us-2", targets[0].Labels["server_name"])
+	require.Equal(t, "North America", targets[0].Labels["region"])
+	require.Equal(t, "us", targets[0].Labels["cc"])
+	require.Equal(t, "node", targets[0].Labels["exporter"])
+	require.Equal(t, []string{"10.0.0.2:9200"}, targets[3].Targets)
+	require.Equal(t, "eu-1", targets[3].Labels["server_name"])
+}
+
+func TestAnsibleInventory(t *testing.T) {
+	servers, groups := testSDServers()
+
+	require.Equal(t, "region_north_america", app.AnsibleGroupName("region", "North America"))
+	require.Equal(t, "region_unknown", app.AnsibleGroupName("region", ""))
+
+	body, errJSON := json.Marshal(app.NewAnsibleInventory(servers, groups))
+	require.NoError(t, errJSON)
+
+	var inventory map[string]json.RawMessage
+
+	require.NoError(t, json.Unmarshal(body, &inventory))
+
+	var all app.AnsibleHostGroup
+
+	require.NoError(t, json.Unmarshal(inventory["gbans"], &all))
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, all.Hosts)
+	require.Equal(t, []string{"region_eu", "region_north_america", "server_group_mge_servers"}, all.Children)
+
+	var mge app.AnsibleHostGroup
+
+	require.NoError(t, json.Unmarshal(inventory["server_group_mge_servers"], &mge))
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, mge.Hosts)
+
+	var meta struct {
+		HostVars map[string]app.AnsibleHostVars `json:"hostvars"`
+	}
+
+	require.NoError(t, json.Unmarshal(inventory["_meta"], &meta))
+	require.Len(t, meta.HostVars["10.0.0.1"].Servers, 2)
+	require.Equal(t, "us-1", meta.HostVars["10.0.0.1"].Servers[0].ShortName)
+	require.Equal(t, "de", meta.HostVars["10.0.0.2"].CC)
+}
+
+func TestConsulCatalog(t *testing.T) {
+	servers, groups := testSDServers()
+
+	catalog := app.ConsulCatalog("dc1", servers, groups, []app.ServiceDiscoveryExporter{{Name: "node", Port: 9100}})
+	require.Len(t, catalog["srcds"], 3)
+	require.Len(t, catalog["node"], 2)
+	require.Equal(t, "us-1", catalog["srcds"][0].ServiceID)
+	require.Equal(t, 27015, catalog["srcds"][0].ServicePort)
+	require.Equal(t, []string{"North America", "MGE Servers"}, catalog["srcds"][0].ServiceTags)
+	require.Equal(t, "dc1", catalog["node"][0].Datacenter)
+	require.Equal(t, 9100, catalog["node"][0].ServicePort)
+
+	services := app.ConsulServices(catalog)
+	require.Equal(t, []string{"MGE Servers", "North America", "eu"}, services["srcds"])
+}
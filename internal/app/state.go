@@ -80,6 +80,11 @@ type serverDetails struct {
 
 	Tags    []string       `json:"tags"`
 	Players []extra.Player `json:"players"`
+	// StatusSource is the query used for the last status update, either rcon or a2s.
+	StatusSource string `json:"status_source"`
+	// RCONLastSeen is when the server last answered a rcon status. Unlike LastUpdate, it is not updated by the a2s
+	// fallback.
+	RCONLastSeen time.Time `json:"rcon_last_seen"`
 }
 
 type baseServer struct {
@@ -266,6 +271,8 @@ type serverStateCollector struct {
 	connectionsMu    *sync.RWMutex
 	serverState      map[int]serverDetails
 	stateMu          *sync.RWMutex
+	subscribers      map[*serverStateSubscription]struct{}
+	seq              uint64
 	configs          []serverConfig
 	maxPlayersRx     *regexp.Regexp
 }
@@ -286,6 +293,7 @@ func newServerStateCollector(logger *zap.Logger) *serverStateCollector {
 		connectionsMu:    &sync.RWMutex{},
 		serverState:      map[int]serverDetails{},
 		stateMu:          &sync.RWMutex{},
+		subscribers:      map[*serverStateSubscription]struct{}{},
 		maxPlayersRx:     regexp.MustCompile(`^"sv_visiblemaxplayers" = "(\d{1,2})"\s`),
 	}
 }
@@ -341,6 +349,11 @@ func (c *serverStateCollector) current() serverDetailsCollection {
 	c.stateMu.RLock()
 	defer c.stateMu.RUnlock()
 
+	return c.sortedState()
+}
+
+// sortedState returns the state of all servers sorted by name. The caller must hold stateMu.
+func (c *serverStateCollector) sortedState() serverDetailsCollection {
 	var curState []serverDetails //nolint:prealloc
 	for _, s := range c.serverState {
 		curState = append(curState, s)
@@ -382,7 +395,8 @@ func (c *serverStateCollector) onStatusUpdate(conf serverConfig, newState extra.
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
-	server := c.serverState[conf.ServerID]
+	prev := c.serverState[conf.ServerID]
+	server := prev
 	server.PlayerCount = newState.PlayersCount
 
 	if maxVisible >= 0 {
@@ -404,8 +418,13 @@ func (c *serverStateCollector) onStatusUpdate(conf serverConfig, newState extra.
 	}
 
 	server.Players = newState.Players
+	server.StatusSource = statusSourceRCON
+	server.LastUpdate = time.Now()
+	server.RCONLastSeen = server.LastUpdate
 
 	c.serverState[conf.ServerID] = server
+
+	c.publishChange(prev, server, false)
 }
 
 func (c *serverStateCollector) setServerConfigs(configs []serverConfig) {
@@ -431,6 +450,10 @@ func (c *serverStateCollector) setServerConfigs(configs []serverConfig) {
 	}
 
 	for _, conf := range gone {
+		if prev, found := c.serverState[conf.ServerID]; found {
+			c.publishChange(prev, prev, true)
+		}
+
 		delete(c.serverState, conf.ServerID)
 	}
 
@@ -442,7 +465,7 @@ func (c *serverStateCollector) setServerConfigs(configs []serverConfig) {
 				addr = config.Host
 			}
 
-			c.serverState[config.ServerID] = serverDetails{
+			newState := serverDetails{
 				ServerID:      config.ServerID,
 				Name:          config.DefaultHostname,
 				NameShort:     config.Tag,
@@ -456,6 +479,9 @@ func (c *serverStateCollector) setServerConfigs(configs []serverConfig) {
 				Longitude:     config.Longitude,
 				IP:            addr,
 			}
+
+			c.serverState[config.ServerID] = newState
+			c.publishChange(serverDetails{}, newState, false)
 		}
 	}
 
@@ -489,11 +515,13 @@ func (c *serverStateCollector) updateState(serverID int, update partialStateUpda
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
-	curState, ok := c.serverState[serverID]
+	prev, ok := c.serverState[serverID]
 	if !ok {
 		return errUnknownServer
 	}
 
+	curState := prev
+
 	if update.Hostname != "" {
 		curState.Name = update.Hostname
 	}
@@ -504,6 +532,8 @@ func (c *serverStateCollector) updateState(serverID int, update partialStateUpda
 	curState.Bots = update.PlayersTotal - update.PlayersReal
 	c.serverState[serverID] = curState
 
+	c.publishChange(prev, curState, false)
+
 	return nil
 }
 
@@ -578,6 +608,7 @@ func (c *serverStateCollector) startStatus(ctx context.Context) {
 			waitGroup := &sync.WaitGroup{}
 			successful := atomic.Int32{}
 			existing := atomic.Int32{}
+			fallback := atomic.Int32{}
 
 			c.stateMu.RLock()
 			configs := c.configs
@@ -605,11 +636,7 @@ func (c *serverStateCollector) startStatus(ctx context.Context) {
 
 					connected := controller.connected()
 
-					if !connected && !controller.allowedToConnect() {
-						return
-					}
-
-					if !connected {
+					if !connected && controller.allowedToConnect() {
 						dialCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 						newConsole, errDial := rcon.Dial(dialCtx, conf.addr(), conf.RconPassword, c.updateTimeout)
 
@@ -635,27 +662,34 @@ func (c *serverStateCollector) startStatus(ctx context.Context) {
 						existing.Add(1)
 					}
 
-					if !connected {
-						return
-					}
+					rconOk := false
 
-					status, errStatus := c.status(controller)
-					if errStatus != nil {
-						return
-					}
+					if connected {
+						status, errStatus := c.status(controller)
+						if errStatus == nil {
+							maxVisible, errMaxVisible := c.maxVisiblePlayers(controller)
+							if errMaxVisible != nil {
+								log.Warn("Got invalid max players value", zap.Error(errMaxVisible))
+							}
 
-					maxVisible, errMaxVisible := c.maxVisiblePlayers(controller)
-					if errMaxVisible != nil {
-						log.Warn("Got invalid max players value", zap.Error(errMaxVisible))
-					}
+							c.onStatusUpdate(conf, status, maxVisible)
 
-					c.onStatusUpdate(conf, status, maxVisible)
+							c.connectionsMu.Lock()
+							c.connections[conf.ServerID] = controller
+							c.connectionsMu.Unlock()
 
-					c.connectionsMu.Lock()
-					c.connections[conf.ServerID] = controller
-					c.connectionsMu.Unlock()
+							successful.Add(1)
 
-					successful.Add(1)
+							rconOk = true
+						}
+					}
+
+					// A2S fills in the details rcon cannot provide and takes over when rcon is unavailable.
+					if errA2S := c.a2sUpdate(conf, !rconOk); errA2S != nil {
+						log.Debug("Failed to query a2s", zap.String("err", errA2S.Error()))
+					} else if !rconOk {
+						fallback.Add(1)
+					}
 				}(serverConfigInstance)
 			}
 
@@ -664,6 +698,7 @@ func (c *serverStateCollector) startStatus(ctx context.Context) {
 			logger.Debug("RCON update cycle complete",
 				zap.Int32("success", successful.Load()),
 				zap.Int32("existing", existing.Load()),
+				zap.Int32("a2s_fallback", fallback.Load()),
 				zap.Int32("fail", int32(len(configs))-successful.Load()),
 				zap.Duration("duration", time.Since(startTIme)))
 		case <-ctx.Done():
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/gbans/pkg/a2s"
+	"github.com/leighmacdonald/steamid/v3/extra"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	statusSourceRCON = "rcon"
+	statusSourceA2S  = "a2s"
+)
+
+// a2sUpdate queries the server over A2S. The details which are only available over A2S are always updated. When
+// fallback is set, rcon could not be used so the player counts, map and name are taken from A2S instead, and the
+// player list and rules are also queried to stand in for the rcon status.
+func (c *serverStateCollector) a2sUpdate(conf serverConfig, fallback bool) error {
+	client, errDial := a2s.Dial(conf.addr(), c.updateTimeout)
+	if errDial != nil {
+		return errors.Wrap(errDial, "Failed to dial a2s")
+	}
+
+	defer func() {
+		if errClose := client.Close(); errClose != nil {
+			c.log.Debug("Failed to close a2s client", zap.Error(errClose))
+		}
+	}()
+
+	info, errInfo := client.Info()
+	if errInfo != nil {
+		return errors.Wrap(errInfo, "Failed to query a2s info")
+	}
+
+	var (
+		players []a2s.Player
+		rules   map[string]string
+	)
+
+	if fallback {
+		// These are best effort, some servers do not answer them
+		result, errPlayers := client.Players()
+		if errPlayers != nil {
+			c.log.Debug("Failed to query a2s players", zap.String("server", conf.Tag), zap.Error(errPlayers))
+		}
+
+		players = result
+
+		cvars, errRules := client.Rules()
+		if errRules != nil {
+			c.log.Debug("Failed to query a2s rules", zap.String("server", conf.Tag), zap.Error(errRules))
+		}
+
+		rules = cvars
+	}
+
+	c.onA2SUpdate(conf, info, players, rules, fallback)
+
+	return nil
+}
+
+// a2sKnownPlayers returns the players from the last rcon status which are still listed by A2S. A2S does not identify
+// players, so they are matched by name, and players which cannot be matched are left out.
+func a2sKnownPlayers(known []extra.Player, players []a2s.Player) []extra.Player {
+	names := make(map[string]int, len(players))
+
+	for _, player := range players {
+		if player.Name != "" {
+			names[player.Name]++
+		}
+	}
+
+	var found []extra.Player
+
+	for _, player := range known {
+		if names[player.Name] > 0 {
+			names[player.Name]--
+
+			found = append(found, player)
+		}
+	}
+
+	return found
+}
+
+func (c *serverStateCollector) onA2SUpdate(conf serverConfig, info a2s.Info, players []a2s.Player,
+	rules map[string]string, fallback bool,
+) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	prev, found := c.serverState[conf.ServerID]
+	if !found {
+		return
+	}
+
+	server := prev
+	server.Protocol = info.Protocol
+	server.Folder = info.Folder
+	server.Game = info.Game
+	server.AppID = info.AppID
+	server.Bots = info.Bots
+	server.Password = info.Password
+	server.VAC = info.VAC
+	server.SteamID = steamid.New(info.SteamID)
+	server.Keywords = info.Keywords
+	server.GameID = info.GameID
+	server.STVPort = info.STVPort
+	server.STVName = info.STVName
+
+	if fallback {
+		if info.Name != "" {
+			server.Name = info.Name
+		}
+
+		server.Map = info.Map
+		server.Version = info.Version
+		server.PlayerCount = info.Players - info.Bots
+		server.MaxPlayers = info.MaxPlayers
+		server.Players = a2sKnownPlayers(prev.Players, players)
+
+		if tags, ok := rules["sv_tags"]; ok {
+			server.Tags = strings.Split(tags, ",")
+		}
+
+		server.StatusSource = statusSourceA2S
+		server.LastUpdate = time.Now()
+	} else if humans := info.Players - info.Bots; humans != server.PlayerCount {
+		c.log.Debug("Player count differs between rcon and a2s", zap.String("server", conf.Tag),
+			zap.Int("rcon", server.PlayerCount), zap.Int("a2s", humans))
+	}
+
+	c.serverState[conf.ServerID] = server
+
+	c.publishChange(prev, server, false)
+}
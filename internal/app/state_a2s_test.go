@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/pkg/a2s"
+	"github.com/leighmacdonald/steamid/v3/extra"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestA2SKnownPlayers(t *testing.T) {
+	var (
+		playerA = extra.Player{Name: "a", SID: steamid.New(76561198084134025)}
+		playerB = extra.Player{Name: "b", SID: steamid.New(76561197961279983)}
+		playerC = extra.Player{Name: "b", SID: steamid.New(76561197960265828)}
+	)
+
+	known := []extra.Player{playerA, playerB, playerC}
+
+	require.Empty(t, a2sKnownPlayers(known, nil))
+	require.Empty(t, a2sKnownPlayers(nil, []a2s.Player{{Name: "a"}}))
+
+	// Players without a name are still connecting and cannot be matched
+	require.Equal(t, []extra.Player{playerA},
+		a2sKnownPlayers(known, []a2s.Player{{Name: "a"}, {Name: ""}, {Name: "new"}}))
+
+	// Duplicate names are only matched as many times as they are listed
+	require.Equal(t, []extra.Player{playerB},
+		a2sKnownPlayers(known, []a2s.Player{{Name: "b"}}))
+	require.Equal(t, []extra.Player{playerA, playerB, playerC},
+		a2sKnownPlayers(known, []a2s.Player{{Name: "b"}, {Name: "a"}, {Name: "b"}}))
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/gbans/internal/discord"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const suspicionBaselineRefresh = time.Hour * 6
+
+// suspicionAnalyzer compares the results of newly completed matches against the population baselines and
+// records any significant outliers as suspicion flags for moderators to review.
+func (app *App) suspicionAnalyzer(ctx context.Context) {
+	if !app.conf.Suspicion.Enabled {
+		return
+	}
+
+	var (
+		log           = app.log.Named("suspicion")
+		baselines     store.SuspicionBaselines
+		refreshTicker = time.NewTicker(suspicionBaselineRefresh)
+		refresh       = func() {
+			newBaselines, errBaselines := app.db.GetSuspicionBaselines(ctx, app.conf.Suspicion.MinShots)
+			if errBaselines != nil {
+				log.Error("Failed to update suspicion baselines", zap.Error(errBaselines))
+
+				return
+			}
+
+			baselines = newBaselines
+
+			log.Debug("Updated suspicion baselines", zap.Int("weapons", len(newBaselines.Accuracy)))
+		}
+	)
+
+	defer refreshTicker.Stop()
+
+	refresh()
+
+	for {
+		select {
+		case <-refreshTicker.C:
+			refresh()
+		case matchID := <-app.suspicionChan:
+			if errAnalyze := app.analyzeMatch(ctx, matchID, baselines); errAnalyze != nil {
+				log.Error("Failed to analyze match",
+					zap.String("match_id", matchID.String()), zap.Error(errAnalyze))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// queueSuspicionAnalysis schedules the match for analysis without blocking the caller.
+func (app *App) queueSuspicionAnalysis(matchID uuid.UUID) {
+	if !app.conf.Suspicion.Enabled {
+		return
+	}
+
+	select {
+	case app.suspicionChan <- matchID:
+	default:
+		app.log.Warn("Suspicion analysis queue full, skipping match", zap.String("match_id", matchID.String()))
+	}
+}
+
+func (app *App) analyzeMatch(ctx context.Context, matchID uuid.UUID, baselines store.SuspicionBaselines) error {
+	weaponSamples, kdSamples, errSamples := app.db.GetMatchSuspicionSamples(ctx, matchID)
+	if errSamples != nil {
+		return errors.Wrap(errSamples, "Failed to load match samples")
+	}
+
+	flags := EvaluateSuspicion(baselines, weaponSamples, kdSamples, app.conf.Suspicion.MinShots, app.conf.Suspicion.FlagScore)
+	if len(flags) == 0 {
+		return nil
+	}
+
+	demoID, errDemo := app.db.GetMatchDemoID(ctx, matchID)
+	if errDemo != nil && !errors.Is(errDemo, store.ErrNoResult) {
+		app.log.Warn("Failed to lookup match demo", zap.Error(errDemo))
+	}
+
+	for _, flag := range flags {
+		suspicionFlag := flag
+		suspicionFlag.MatchID = matchID
+		suspicionFlag.DemoID = demoID
+
+		if errSave := app.db.SaveSuspicionFlag(ctx, &suspicionFlag); errSave != nil {
+			return errors.Wrap(errSave, "Failed to save suspicion flag")
+		}
+
+		if suspicionFlag.Score >= app.conf.Suspicion.AlertScore {
+			app.sendSuspicionAlert(ctx, suspicionFlag)
+		}
+	}
+
+	app.log.Info("Match suspicion flags created",
+		zap.String("match_id", matchID.String()), zap.Int("count", len(flags)))
+
+	return nil
+}
+
+// EvaluateSuspicion scores each sample against its baseline, returning a flag for every sample
+// meeting the minimum score.
+func EvaluateSuspicion(baselines store.SuspicionBaselines, weaponSamples []store.SuspicionWeaponSample,
+	kdSamples []store.SuspicionKDSample, minShots int, minScore float64,
+) []store.SuspicionFlag {
+	var flags []store.SuspicionFlag
+
+	check := func(sample store.SuspicionWeaponSample, metric store.SuspicionMetric, baseline store.SuspicionBaseline, value float64) {
+		if score := baseline.Score(value); score >= minScore {
+			flags = append(flags, store.SuspicionFlag{
+				SteamID:        sample.SteamID,
+				WeaponID:       sample.WeaponID,
+				Metric:         metric,
+				Value:          value,
+				BaselineMean:   baseline.Mean,
+				BaselineStdDev: baseline.StdDev,
+				Score:          score,
+				State:          store.SuspicionStateOpen,
+			})
+		}
+	}
+
+	for _, sample := range weaponSamples {
+		if baseline, found := baselines.Accuracy[sample.WeaponID]; found && sample.Shots > 0 && sample.Shots >= minShots {
+			check(sample, store.SuspicionAccuracy, baseline, float64(sample.Hits)/float64(sample.Shots))
+		}
+
+		if baseline, found := baselines.HeadshotRatio[sample.WeaponID]; found && sample.Hits > 0 && sample.Hits >= minShots {
+			check(sample, store.SuspicionHeadshotRatio, baseline, float64(sample.Headshots)/float64(sample.Hits))
+		}
+	}
+
+	for _, sample := range kdSamples {
+		if sample.Kills+sample.Deaths < minShots {
+			continue
+		}
+
+		if score := baselines.KD.Score(sample.KD()); score >= minScore {
+			flags = append(flags, store.SuspicionFlag{
+				SteamID:        sample.SteamID,
+				Metric:         store.SuspicionKD,
+				Value:          sample.KD(),
+				BaselineMean:   baselines.KD.Mean,
+				BaselineStdDev: baselines.KD.StdDev,
+				Score:          score,
+				State:          store.SuspicionStateOpen,
+			})
+		}
+	}
+
+	return flags
+}
+
+func (app *App) sendSuspicionAlert(ctx context.Context, flag store.SuspicionFlag) {
+	channelID := app.conf.Suspicion.AlertChannelID
+	if channelID == "" {
+		channelID = app.conf.Discord.LogChannelID
+	}
+
+	msgEmbed := discord.
+		NewEmbed(fmt.Sprintf("Suspicion Flag: %s", flag.Metric)).
+		SetColor(app.bot.Colour.Warn).
+		SetURL(app.ExtURL(flag)).
+		AddField("Score", fmt.Sprintf("%.2f", flag.Score)).
+		AddField("Value", fmt.Sprintf("%.3f", flag.Value)).
+		AddField("Baseline", fmt.Sprintf("%.3f ± %.3f", flag.BaselineMean, flag.BaselineStdDev)).
+		AddField("Match", app.ExtURLRaw("/log/%s", flag.MatchID.String()))
+
+	if flag.DemoID > 0 {
+		msgEmbed.AddField("Demo ID", fmt.Sprintf("%d", flag.DemoID))
+	}
+
+	app.addTarget(ctx, msgEmbed, flag.SteamID)
+
+	app.bot.SendPayload(discord.Payload{
+		ChannelID: channelID,
+		Embed:     msgEmbed.Truncate().MessageEmbed,
+	})
+}
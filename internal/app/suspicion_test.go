@@ -0,0 +1,105 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateSuspicion(t *testing.T) {
+	var (
+		player    = steamid.New(76561198084134025)
+		baselines = store.SuspicionBaselines{
+			Accuracy:      map[int]store.SuspicionBaseline{1: {Mean: 0.3, StdDev: 0.05, Samples: 100}},
+			HeadshotRatio: map[int]store.SuspicionBaseline{1: {Mean: 0.2, StdDev: 0.1, Samples: 100}},
+			KD:            store.SuspicionBaseline{Mean: 1, StdDev: 0.5, Samples: 100},
+		}
+	)
+
+	type flag struct {
+		metric store.SuspicionMetric
+		score  float64
+	}
+
+	testCases := []struct {
+		name     string
+		weapons  []store.SuspicionWeaponSample
+		kd       []store.SuspicionKDSample
+		minShots int
+		minScore float64
+		expected []flag
+	}{
+		{
+			name:     "average",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 1, Shots: 100, Hits: 30, Headshots: 6}},
+			kd:       []store.SuspicionKDSample{{SteamID: player, Kills: 10, Deaths: 10}},
+			minShots: 20,
+			minScore: 3,
+		},
+		{
+			name:     "accuracy",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 1, Shots: 100, Hits: 50}},
+			minShots: 20,
+			minScore: 3,
+			expected: []flag{{metric: store.SuspicionAccuracy, score: 4}},
+		},
+		{
+			name:     "below_min_score",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 1, Shots: 100, Hits: 40}},
+			minShots: 20,
+			minScore: 3,
+		},
+		{
+			name:     "headshots",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 1, Shots: 100, Hits: 30, Headshots: 18}},
+			minShots: 20,
+			minScore: 3,
+			expected: []flag{{metric: store.SuspicionHeadshotRatio, score: 4}},
+		},
+		{
+			name:     "kd",
+			kd:       []store.SuspicionKDSample{{SteamID: player, Kills: 30, Deaths: 10}},
+			minShots: 20,
+			minScore: 3,
+			expected: []flag{{metric: store.SuspicionKD, score: 4}},
+		},
+		{
+			name:     "too_few_samples",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 1, Shots: 10, Hits: 10, Headshots: 10}},
+			kd:       []store.SuspicionKDSample{{SteamID: player, Kills: 10}},
+			minShots: 20,
+			minScore: 3,
+		},
+		{
+			name:     "unknown_weapon",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 2, Shots: 100, Hits: 100}},
+			minShots: 20,
+			minScore: 3,
+		},
+		{
+			name:     "no_shots",
+			weapons:  []store.SuspicionWeaponSample{{SteamID: player, WeaponID: 1}},
+			minShots: 0,
+			minScore: 3,
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+
+		t.Run(tc.name, func(t *testing.T) {
+			flags := app.EvaluateSuspicion(baselines, tc.weapons, tc.kd, tc.minShots, tc.minScore)
+			require.Len(t, flags, len(tc.expected))
+
+			for idx, expected := range tc.expected {
+				require.Equal(t, expected.metric, flags[idx].Metric)
+				require.InDelta(t, expected.score, flags[idx].Score, 0.001)
+				require.Equal(t, player, flags[idx].SteamID)
+				require.Equal(t, store.SuspicionStateOpen, flags[idx].State)
+			}
+		})
+	}
+}
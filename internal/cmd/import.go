@@ -313,3 +313,68 @@ func importMessagesCmd() *cobra.Command {
 		},
 	}
 }
+
+func importLogsCmd() *cobra.Command {
+	var (
+		serverName string
+		dryRun     bool
+	)
+
+	command := &cobra.Command{
+		Use:   "logs <path>",
+		Short: "Import matches from SRCDS log files",
+		Long: `Import matches from SRCDS log files (.log or .log.gz) found under the path. Logs are assigned to the
+server given with --server, otherwise to the server whose short name matches the directory containing each log.
+Matches which have already been imported are skipped.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			rootCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var conf app.Config
+			if errConfig := app.ReadConfig(&conf, false); errConfig != nil {
+				panic("Failed to read config")
+			}
+
+			rootLogger := app.MustCreateLogger(&conf)
+			defer func() {
+				if conf.Log.File != "" {
+					_ = rootLogger.Sync()
+				}
+			}()
+
+			database := store.New(rootLogger, conf.DB.DSN, conf.DB.AutoMigrate, conf.DB.LogQueries)
+			if errConnect := database.Connect(rootCtx); errConnect != nil {
+				rootLogger.Fatal("Cannot initialize database", zap.Error(errConnect))
+			}
+
+			defer util.LogCloser(database, rootLogger)
+
+			result, errImport := app.ImportMatchLogs(rootCtx, database, rootLogger, app.LogImportOpts{
+				Path:       args[0],
+				ServerName: serverName,
+				DryRun:     dryRun,
+			})
+
+			rootLogger.Info("Log import finished",
+				zap.Int("files", result.Files),
+				zap.Int("matches", result.Matches),
+				zap.Int("saved", result.Saved),
+				zap.Int("existing", result.Existing),
+				zap.Int("incomplete", result.Incomplete),
+				zap.Int("failed", result.Failed),
+				zap.Bool("dry_run", dryRun))
+
+			if errImport != nil {
+				rootLogger.Fatal("Failed to import logs", zap.Error(errImport))
+			}
+		},
+	}
+
+	command.Flags().StringVarP(&serverName, "server", "s", "",
+		"Short name of the server the logs belong to, defaults to the name of the directory containing each log")
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "Parse the logs and report the matches found without saving them")
+
+	return command
+}
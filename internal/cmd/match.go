@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/gbans/internal/app"
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/leighmacdonald/gbans/pkg/util"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func matchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "match",
+		Short: "Match management",
+		Long:  `Match management`,
+	}
+}
+
+func matchReprocessCmd() *cobra.Command {
+	var all bool
+
+	command := &cobra.Command{
+		Use:   "reprocess [match_id...]",
+		Short: "Rebuild match stats from the archived match logs",
+		Long: `Rebuild the stats of matches by parsing their archived logs again. This allows fixes and additions to the
+log parser to be applied to past matches. Either the match ids to reprocess, or --all, must be given.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 && !all {
+				_ = cmd.Help()
+
+				return
+			}
+
+			ctx := context.Background()
+			rootCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var conf app.Config
+			if errConfig := app.ReadConfig(&conf, false); errConfig != nil {
+				panic("Failed to read config")
+			}
+
+			rootLogger := app.MustCreateLogger(&conf)
+			defer func() {
+				if conf.Log.File != "" {
+					_ = rootLogger.Sync()
+				}
+			}()
+
+			var matchIDs []uuid.UUID
+
+			if !all {
+				for _, arg := range args {
+					matchID, errID := uuid.FromString(arg)
+					if errID != nil {
+						rootLogger.Fatal("Invalid match id", zap.String("match_id", arg), zap.Error(errID))
+					}
+
+					matchIDs = append(matchIDs, matchID)
+				}
+			}
+
+			database := store.New(rootLogger, conf.DB.DSN, conf.DB.AutoMigrate, conf.DB.LogQueries)
+			if errConnect := database.Connect(rootCtx); errConnect != nil {
+				rootLogger.Fatal("Cannot initialize database", zap.Error(errConnect))
+			}
+
+			defer util.LogCloser(database, rootLogger)
+
+			s3Client, errClient := app.NewS3Client(rootLogger, conf.S3.Endpoint, conf.S3.AccessKey, conf.S3.SecretKey, conf.S3.SSL, conf.S3.Region)
+			if errClient != nil {
+				rootLogger.Fatal("Failed to setup S3 client", zap.Error(errClient))
+			}
+
+			result, errReprocess := app.ReprocessMatchLogs(rootCtx, database, s3Client, rootLogger, matchIDs)
+
+			rootLogger.Info("Match reprocess finished",
+				zap.Int("matches", result.Matches),
+				zap.Int("updated", result.Updated),
+				zap.Int("incomplete", result.Incomplete),
+				zap.Int("failed", result.Failed))
+
+			if errReprocess != nil {
+				rootLogger.Fatal("Failed to reprocess matches", zap.Error(errReprocess))
+			}
+		},
+	}
+
+	command.Flags().BoolVar(&all, "all", false, "Reprocess every match which has an archived log")
+
+	return command
+}
@@ -4,6 +4,8 @@
 // ban cidr - Ban an IP or network with CIDR notation
 // ban steam - Ban a player via steamid or vanity name
 // import - Imports bans from a folder in json format
+// import logs - Imports matches from SRCDS log files
+// match reprocess - Rebuild match stats from the archived match logs
 // migrate - Initiate a database migration manually
 // net update - Download and import the latest ip2location databases
 // seed - Pre seed the database with data, used for development mostly
@@ -57,12 +59,17 @@ func setupRootCmd() *cobra.Command {
 	importCommands := importCmd()
 	importCommands.AddCommand(importConnectionsCmd())
 	importCommands.AddCommand(importMessagesCmd())
+	importCommands.AddCommand(importLogsCmd())
+
+	matchCommands := matchCmd()
+	matchCommands.AddCommand(matchReprocessCmd())
 
 	netCommands := netCmd()
 	netCommands.AddCommand(netUpdateCmd())
 
 	root.AddCommand(netCommands)
 	root.AddCommand(importCommands)
+	root.AddCommand(matchCommands)
 	root.AddCommand(serveCmd())
 	root.AddCommand(refreshCommands)
 	// root.PersistentFlags().StringVar(&cfgFile, "config", "gbans.yml", "config file (default is $HOME/.gbans.yaml)").
@@ -19,4 +19,5 @@ var (
 	ErrNotFound         = errors.New("entity not found")
 	ErrDuplicate        = errors.New("entity already exists")
 	ErrInvalidParameter = errors.New("invalid parameter format")
+	ErrPendingApproval  = errors.New("action is pending approval")
 )
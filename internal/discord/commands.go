@@ -2,6 +2,7 @@ package discord
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -89,6 +90,12 @@ func (bot *Bot) botRegisterSlashCommands(appID string) error {
 		Description: "Short server name",
 		Required:    true,
 	}
+	optServerTarget := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        OptServerIdentifier,
+		Description: "Short server name, `*` for all or `@group` for a server group",
+		Required:    true,
+	}
 	// optReason := &discordgo.ApplicationCommandOption{
 	//	Type:        discordgo.ApplicationCommandOptionString,
 	//	Name:        "reason",
@@ -242,12 +249,7 @@ func (bot *Bot) botRegisterSlashCommands(appID string) error {
 			DMPermission:             &dmPerms,
 			DefaultMemberPermissions: &modPerms,
 			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        OptServerIdentifier,
-					Description: "Short server name or `*` for all",
-					Required:    true,
-				},
+				optServerTarget,
 				optMessage,
 			},
 		},
@@ -257,7 +259,7 @@ func (bot *Bot) botRegisterSlashCommands(appID string) error {
 			DMPermission:             &dmPerms,
 			DefaultMemberPermissions: &modPerms,
 			Options: []*discordgo.ApplicationCommandOption{
-				optServerID,
+				optServerTarget,
 				optMessage,
 			},
 		},
@@ -503,6 +505,18 @@ type CommandOptions map[optionKey]*discordgo.ApplicationCommandInteractionDataOp
 
 type CommandHandler func(ctx context.Context, s *discordgo.Session, m *discordgo.InteractionCreate) (*discordgo.MessageEmbed, error)
 
+// ComponentIDSep separates the handler prefix from its argument in a components custom_id, eg: `approve:123`.
+const ComponentIDSep = ":"
+
+// ComponentHandler handles message component interactions. The arg value is the portion of the custom_id
+// following the handler prefix.
+type ComponentHandler func(ctx context.Context, s *discordgo.Session, m *discordgo.InteractionCreate, arg string) (*discordgo.MessageEmbed, error)
+
+// ComponentID builds a custom_id value suitable for routing to a registered ComponentHandler.
+func ComponentID(prefix string, arg string) string {
+	return prefix + ComponentIDSep + arg
+}
+
 // OptionMap will take the recursive discord slash commands and flatten them into a simple
 // map.
 func OptionMap(options []*discordgo.ApplicationCommandInteractionDataOption) CommandOptions {
@@ -533,6 +547,12 @@ func (opts CommandOptions) String(key optionKey) string {
 // https://discord.com/developers/docs/interactions/receiving-and-responding#receiving-an-interaction
 
 func (bot *Bot) onInteractionCreate(session *discordgo.Session, interaction *discordgo.InteractionCreate) {
+	if interaction.Type == discordgo.InteractionMessageComponent {
+		bot.onComponentInteraction(session, interaction)
+
+		return
+	}
+
 	var (
 		data    = interaction.ApplicationCommandData()
 		command = Cmd(data.Name)
@@ -582,3 +602,51 @@ func (bot *Bot) onInteractionCreate(session *discordgo.Session, interaction *dis
 		}
 	}
 }
+
+// onComponentInteraction routes message component interactions, such as button presses, to the
+// handler registered for the prefix of the components custom_id.
+func (bot *Bot) onComponentInteraction(session *discordgo.Session, interaction *discordgo.InteractionCreate) {
+	prefix, arg, _ := strings.Cut(interaction.MessageComponentData().CustomID, ComponentIDSep)
+
+	handler, handlerFound := bot.componentHandlers[prefix]
+	if !handlerFound {
+		return
+	}
+
+	initialResponse := &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}
+
+	if errRespond := session.InteractionRespond(interaction.Interaction, initialResponse); errRespond != nil {
+		bot.log.Error("Failed sending deferred response for component interaction", zap.Error(errRespond))
+
+		return
+	}
+
+	componentCtx, cancelComponent := context.WithTimeout(context.TODO(), time.Second*30)
+	defer cancelComponent()
+
+	response, errHandle := handler(componentCtx, session, interaction, arg)
+	if errHandle != nil || response == nil {
+		errMsg := "No response"
+		if errHandle != nil {
+			errMsg = errHandle.Error()
+		}
+
+		errEmbed := NewEmbed("Error Returned").
+			SetColor(bot.Colour.Error).
+			AddField("action", prefix).
+			SetDescription(errMsg)
+		if _, errFollow := session.FollowupMessageCreate(interaction.Interaction, true, &discordgo.WebhookParams{
+			Embeds: []*discordgo.MessageEmbed{errEmbed.MessageEmbed},
+		}); errFollow != nil {
+			bot.log.Error("Failed sending error response for component interaction", zap.Error(errFollow))
+		}
+
+		return
+	}
+
+	if errSend := bot.sendInteractionResponse(session, interaction.Interaction, response); errSend != nil {
+		bot.log.Error("Failed sending success response for component interaction", zap.Error(errSend))
+	}
+}
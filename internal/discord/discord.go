@@ -12,13 +12,17 @@ import (
 	"go.uber.org/zap"
 )
 
-var ErrCommandFailed = errors.New("Command failed")
+var (
+	ErrCommandFailed = errors.New("Command failed")
+	ErrNotReady      = errors.New("Bot not ready")
+)
 
 type Bot struct {
 	log               *zap.Logger
 	session           *discordgo.Session
 	isReady           atomic.Bool
 	commandHandlers   map[Cmd]CommandHandler
+	componentHandlers map[string]ComponentHandler
 	Colour            LevelColors
 	unregisterOnStart bool
 	appID             string
@@ -31,8 +35,9 @@ const (
 )
 
 type Payload struct {
-	ChannelID string
-	Embed     *discordgo.MessageEmbed
+	ChannelID  string
+	Embed      *discordgo.MessageEmbed
+	Components []discordgo.MessageComponent
 }
 
 func NewEmbed(args ...string) *embed.Embed {
@@ -77,6 +82,7 @@ func New(logger *zap.Logger, token string, appID string, unregisterOnStart bool,
 		appID:             appID,
 		extURL:            extURL,
 		commandHandlers:   map[Cmd]CommandHandler{},
+		componentHandlers: map[string]ComponentHandler{},
 		Colour: LevelColors{
 			Success: 302673,
 			Debug:   10170623,
@@ -105,6 +111,19 @@ func (bot *Bot) RegisterHandler(cmd Cmd, handler CommandHandler) error {
 	return nil
 }
 
+// RegisterComponentHandler registers a handler for message component interactions, such as buttons. The
+// prefix is matched against the portion of the components custom_id before the first ComponentIDSep.
+func (bot *Bot) RegisterComponentHandler(prefix string, handler ComponentHandler) error {
+	_, found := bot.componentHandlers[prefix]
+	if found {
+		return errors.New("Duplicate component handler")
+	}
+
+	bot.componentHandlers[prefix] = handler
+
+	return nil
+}
+
 func (bot *Bot) Shutdown(guildID string) {
 	if bot.session != nil {
 		defer util.LogCloser(bot.session, bot.log)
@@ -230,11 +249,48 @@ func (bot *Bot) SendPayload(payload Payload) {
 		return
 	}
 
+	if len(payload.Components) > 0 {
+		if _, errSend := bot.session.ChannelMessageSendComplex(payload.ChannelID, &discordgo.MessageSend{
+			Embeds:     []*discordgo.MessageEmbed{payload.Embed},
+			Components: payload.Components,
+		}); errSend != nil {
+			bot.log.Error("Failed to send discord payload", zap.Error(errSend))
+		}
+
+		return
+	}
+
 	if _, errSend := bot.session.ChannelMessageSendEmbed(payload.ChannelID, payload.Embed); errSend != nil {
 		bot.log.Error("Failed to send discord payload", zap.Error(errSend))
 	}
 }
 
+// AddMemberRole assigns the role to the guild member.
+func (bot *Bot) AddMemberRole(guildID string, userID string, roleID string) error {
+	if !bot.isReady.Load() {
+		return ErrNotReady
+	}
+
+	if errAdd := bot.session.GuildMemberRoleAdd(guildID, userID, roleID); errAdd != nil {
+		return errors.Wrap(errAdd, "Failed to add member role")
+	}
+
+	return nil
+}
+
+// RemoveMemberRole removes the role from the guild member.
+func (bot *Bot) RemoveMemberRole(guildID string, userID string, roleID string) error {
+	if !bot.isReady.Load() {
+		return ErrNotReady
+	}
+
+	if errRemove := bot.session.GuildMemberRoleRemove(guildID, userID, roleID); errRemove != nil {
+		return errors.Wrap(errRemove, "Failed to remove member role")
+	}
+
+	return nil
+}
+
 // LevelColors is a struct of the possible colors used in Discord color format (0x[RGB] converted to int).
 type LevelColors struct {
 	Debug   int
@@ -0,0 +1,343 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+// PendingActionType defines the kind of moderation action that is awaiting approval.
+type PendingActionType int
+
+const (
+	PendingBanSteam PendingActionType = iota + 1
+	PendingBanASN
+	PendingBanCIDR
+	PendingUnbanSteam
+)
+
+func (t PendingActionType) String() string {
+	switch t {
+	case PendingBanSteam:
+		return "Steam Ban"
+	case PendingBanASN:
+		return "ASN Ban"
+	case PendingBanCIDR:
+		return "CIDR Ban"
+	case PendingUnbanSteam:
+		return "Steam Unban"
+	default:
+		return "Unknown"
+	}
+}
+
+// PendingActionState tracks the lifecycle of a pending action.
+type PendingActionState int
+
+const (
+	PendingStateAny PendingActionState = iota - 1
+	PendingStateOpen
+	PendingStateApproved
+	PendingStateRejected
+	PendingStateExpired
+	PendingStateFailed
+)
+
+func (s PendingActionState) String() string {
+	switch s {
+	case PendingStateOpen:
+		return "Pending"
+	case PendingStateApproved:
+		return "Approved"
+	case PendingStateRejected:
+		return "Rejected"
+	case PendingStateExpired:
+		return "Expired"
+	case PendingStateFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// PendingActionEventType is used for the audit trail of a pending action.
+type PendingActionEventType int
+
+const (
+	PendingEventCreated PendingActionEventType = iota + 1
+	PendingEventApproved
+	PendingEventRejected
+	PendingEventExpired
+	PendingEventApplied
+	PendingEventFailed
+)
+
+// PendingAction is a moderation action that requires a second moderator to approve it before
+// it takes effect. The original action is serialized into Payload so that it can be executed
+// unmodified once approved.
+type PendingAction struct {
+	PendingActionID int64              `json:"pending_action_id"`
+	ActionType      PendingActionType  `json:"action_type"`
+	State           PendingActionState `json:"state"`
+	SourceID        steamid.SID64      `json:"source_id"`
+	TargetID        steamid.SID64      `json:"target_id"`
+	ApproverID      steamid.SID64      `json:"approver_id"`
+	Payload         json.RawMessage    `json:"payload"`
+	Reason          string             `json:"reason"`
+	ExpiresOn       time.Time          `json:"expires_on"`
+	TimeStamped
+}
+
+func (action PendingAction) Path() string {
+	return "/admin/approvals"
+}
+
+func (action PendingAction) Expired() bool {
+	return time.Now().After(action.ExpiresOn)
+}
+
+type PendingActionEvent struct {
+	PendingActionEventID int64                  `json:"pending_action_event_id"`
+	PendingActionID      int64                  `json:"pending_action_id"`
+	SteamID              steamid.SID64          `json:"steam_id"`
+	Event                PendingActionEventType `json:"event"`
+	Note                 string                 `json:"note"`
+	CreatedOn            time.Time              `json:"created_on"`
+}
+
+type PendingActionQueryFilter struct {
+	QueryFilter
+	State PendingActionState `json:"state"`
+}
+
+func (db *Store) SavePendingAction(ctx context.Context, action *PendingAction) error {
+	action.UpdatedOn = time.Now()
+
+	var approverID *int64
+
+	if action.ApproverID.Valid() {
+		sid := action.ApproverID.Int64()
+		approverID = &sid
+	}
+
+	if action.PendingActionID > 0 {
+		return db.ExecUpdateBuilder(ctx, db.sb.
+			Update("pending_action").
+			SetMap(map[string]interface{}{
+				"state":       action.State,
+				"approver_id": approverID,
+				"updated_on":  action.UpdatedOn,
+			}).
+			Where(sq.Eq{"pending_action_id": action.PendingActionID}))
+	}
+
+	action.CreatedOn = action.UpdatedOn
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("pending_action").
+		SetMap(map[string]interface{}{
+			"action_type": action.ActionType,
+			"state":       action.State,
+			"source_id":   action.SourceID.Int64(),
+			"target_id":   action.TargetID.Int64(),
+			"approver_id": approverID,
+			"payload":     string(action.Payload),
+			"reason":      action.Reason,
+			"expires_on":  action.ExpiresOn,
+			"created_on":  action.CreatedOn,
+			"updated_on":  action.UpdatedOn,
+		}).
+		Suffix("RETURNING pending_action_id"), &action.PendingActionID)
+}
+
+// TransitionPendingAction updates the state and approver of an existing action, but only when it is still in
+// the expected state. This guards against two moderators acting on the same action at once, the loser receives
+// ErrNoResult.
+func (db *Store) TransitionPendingAction(ctx context.Context, action *PendingAction, from PendingActionState) error {
+	action.UpdatedOn = time.Now()
+
+	var approverID *int64
+
+	if action.ApproverID.Valid() {
+		sid := action.ApproverID.Int64()
+		approverID = &sid
+	}
+
+	query, args, errQuery := db.sb.
+		Update("pending_action").
+		SetMap(map[string]interface{}{
+			"state":       action.State,
+			"approver_id": approverID,
+			"updated_on":  action.UpdatedOn,
+		}).
+		Where(sq.And{sq.Eq{"pending_action_id": action.PendingActionID}, sq.Eq{"state": from}}).
+		ToSql()
+	if errQuery != nil {
+		return Err(errQuery)
+	}
+
+	tag, errExec := db.conn.Exec(ctx, query, args...)
+	if errExec != nil {
+		return Err(errExec)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoResult
+	}
+
+	return nil
+}
+
+var pendingActionColumns = []string{ //nolint:gochecknoglobals
+	"pending_action_id", "action_type", "state", "source_id", "target_id",
+	"coalesce(approver_id, 0)", "payload", "reason", "expires_on", "created_on", "updated_on",
+}
+
+func scanPendingAction(row interface{ Scan(dest ...any) error }, action *PendingAction) error {
+	var sourceID, targetID, approverID int64
+
+	if errScan := row.Scan(&action.PendingActionID, &action.ActionType, &action.State, &sourceID, &targetID,
+		&approverID, &action.Payload, &action.Reason, &action.ExpiresOn, &action.CreatedOn, &action.UpdatedOn); errScan != nil {
+		return Err(errScan)
+	}
+
+	action.SourceID = steamid.New(sourceID)
+	action.TargetID = steamid.New(targetID)
+	action.ApproverID = steamid.New(approverID)
+
+	return nil
+}
+
+func (db *Store) GetPendingAction(ctx context.Context, pendingActionID int64, action *PendingAction) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.sb.
+		Select(pendingActionColumns...).
+		From("pending_action").
+		Where(sq.Eq{"pending_action_id": pendingActionID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return scanPendingAction(row, action)
+}
+
+func (db *Store) GetPendingActions(ctx context.Context, filter PendingActionQueryFilter) ([]PendingAction, int64, error) {
+	var constraints sq.And
+
+	if filter.State > PendingStateAny {
+		constraints = append(constraints, sq.Eq{"state": filter.State})
+	}
+
+	builder := filter.applySafeOrder(db.sb.
+		Select(pendingActionColumns...).
+		From("pending_action").
+		Where(constraints), map[string][]string{
+		"": {"pending_action_id", "action_type", "state", "source_id", "target_id", "expires_on", "created_on", "updated_on"},
+	}, "pending_action_id")
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	actions := make([]PendingAction, 0)
+
+	for rows.Next() {
+		var action PendingAction
+		if errScan := scanPendingAction(rows, &action); errScan != nil {
+			return nil, 0, errScan
+		}
+
+		actions = append(actions, action)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("count(pending_action_id)").
+		From("pending_action").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return actions, count, nil
+}
+
+// GetExpiredPendingActions returns all open actions which have passed their expiry time.
+func (db *Store) GetExpiredPendingActions(ctx context.Context) ([]PendingAction, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select(pendingActionColumns...).
+		From("pending_action").
+		Where(sq.And{sq.Eq{"state": PendingStateOpen}, sq.Lt{"expires_on": time.Now()}}))
+	if errRows != nil {
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	var actions []PendingAction
+
+	for rows.Next() {
+		var action PendingAction
+		if errScan := scanPendingAction(rows, &action); errScan != nil {
+			return nil, errScan
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+func (db *Store) AddPendingActionEvent(ctx context.Context, event *PendingActionEvent) error {
+	if event.CreatedOn.IsZero() {
+		event.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("pending_action_event").
+		SetMap(map[string]interface{}{
+			"pending_action_id": event.PendingActionID,
+			"steam_id":          event.SteamID.Int64(),
+			"event":             event.Event,
+			"note":              event.Note,
+			"created_on":        event.CreatedOn,
+		}).
+		Suffix("RETURNING pending_action_event_id"), &event.PendingActionEventID)
+}
+
+func (db *Store) GetPendingActionEvents(ctx context.Context, pendingActionID int64) ([]PendingActionEvent, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("pending_action_event_id", "pending_action_id", "steam_id", "event", "note", "created_on").
+		From("pending_action_event").
+		Where(sq.Eq{"pending_action_id": pendingActionID}).
+		OrderBy("created_on"))
+	if errRows != nil {
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	events := make([]PendingActionEvent, 0)
+
+	for rows.Next() {
+		var (
+			event   PendingActionEvent
+			steamID int64
+		)
+
+		if errScan := rows.Scan(&event.PendingActionEventID, &event.PendingActionID, &steamID,
+			&event.Event, &event.Note, &event.CreatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan pending action event")
+		}
+
+		event.SteamID = steamid.New(steamID)
+		events = append(events, event)
+	}
+
+	return events, nil
+}
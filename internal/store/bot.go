@@ -0,0 +1,249 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+// BotDetectionMethod defines how a player was determined to be a bot.
+type BotDetectionMethod int
+
+const (
+	BotMethodName BotDetectionMethod = iota
+	BotMethodSteamList
+	BotMethodWave
+	BotMethodChatSpam
+)
+
+func (m BotDetectionMethod) String() string {
+	switch m {
+	case BotMethodName:
+		return "Name Pattern"
+	case BotMethodSteamList:
+		return "SteamID List"
+	case BotMethodWave:
+		return "Connection Wave"
+	case BotMethodChatSpam:
+		return "Chat Spam"
+	default:
+		return "Unknown"
+	}
+}
+
+// BotNamePattern is a regular expression matched against the names of connecting players.
+type BotNamePattern struct {
+	BotNamePatternID int    `json:"bot_name_pattern_id"`
+	Pattern          string `json:"pattern"`
+	Note             string `json:"note"`
+	TimeStamped
+}
+
+// BotDetection records a single instance of a bot being blocked.
+type BotDetection struct {
+	BotDetectionID int64              `json:"bot_detection_id"`
+	SteamID        steamid.SID64      `json:"steam_id"`
+	ServerID       int                `json:"server_id"`
+	Name           string             `json:"name"`
+	Method         BotDetectionMethod `json:"method"`
+	Detail         string             `json:"detail"`
+	CreatedOn      time.Time          `json:"created_on"`
+}
+
+// BotReportEntry is the total number of bots blocked by a method on a server.
+type BotReportEntry struct {
+	ServerID   int                `json:"server_id"`
+	ServerName string             `json:"server_name"`
+	Method     BotDetectionMethod `json:"method"`
+	Detections int64              `json:"detections"`
+	Accounts   int64              `json:"accounts"`
+}
+
+func (db *Store) GetBotNamePatterns(ctx context.Context) ([]BotNamePattern, error) {
+	patterns := make([]BotNamePattern, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("bot_name_pattern_id", "pattern", "note", "created_on", "updated_on").
+		From("bot_name_pattern").
+		OrderBy("bot_name_pattern_id"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return patterns, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var pattern BotNamePattern
+		if errScan := rows.Scan(&pattern.BotNamePatternID, &pattern.Pattern, &pattern.Note,
+			&pattern.CreatedOn, &pattern.UpdatedOn); errScan != nil {
+			return nil, Err(errScan)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+func (db *Store) SaveBotNamePattern(ctx context.Context, pattern *BotNamePattern) error {
+	pattern.UpdatedOn = time.Now()
+
+	if pattern.BotNamePatternID > 0 {
+		return db.ExecUpdateBuilder(ctx, db.sb.
+			Update("bot_name_pattern").
+			SetMap(map[string]interface{}{
+				"pattern":    pattern.Pattern,
+				"note":       pattern.Note,
+				"updated_on": pattern.UpdatedOn,
+			}).
+			Where(sq.Eq{"bot_name_pattern_id": pattern.BotNamePatternID}))
+	}
+
+	pattern.CreatedOn = pattern.UpdatedOn
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("bot_name_pattern").
+		SetMap(map[string]interface{}{
+			"pattern":    pattern.Pattern,
+			"note":       pattern.Note,
+			"created_on": pattern.CreatedOn,
+			"updated_on": pattern.UpdatedOn,
+		}).
+		Suffix("RETURNING bot_name_pattern_id"), &pattern.BotNamePatternID)
+}
+
+func (db *Store) DeleteBotNamePattern(ctx context.Context, patternID int) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("bot_name_pattern").
+		Where(sq.Eq{"bot_name_pattern_id": patternID}))
+}
+
+func (db *Store) GetBotSteamIDs(ctx context.Context) (steamid.Collection, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("steam_id").
+		From("bot_steam_id"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return steamid.Collection{}, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	var ids steamid.Collection
+
+	for rows.Next() {
+		var sid64 int64
+		if errScan := rows.Scan(&sid64); errScan != nil {
+			return nil, Err(errScan)
+		}
+
+		ids = append(ids, steamid.New(sid64))
+	}
+
+	return ids, nil
+}
+
+// SaveBotSteamIDs imports a list of known bot accounts, ids that already exist are ignored.
+func (db *Store) SaveBotSteamIDs(ctx context.Context, steamIDs steamid.Collection, source string) error {
+	const batchSize = 1000
+
+	now := time.Now()
+
+	for start := 0; start < len(steamIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(steamIDs) {
+			end = len(steamIDs)
+		}
+
+		builder := db.sb.
+			Insert("bot_steam_id").
+			Columns("steam_id", "source", "created_on")
+
+		for _, steamID := range steamIDs[start:end] {
+			builder = builder.Values(steamID.Int64(), source, now)
+		}
+
+		if errInsert := db.ExecInsertBuilder(ctx, builder.Suffix("ON CONFLICT DO NOTHING")); errInsert != nil {
+			return errInsert
+		}
+	}
+
+	return nil
+}
+
+func (db *Store) DeleteBotSteamID(ctx context.Context, steamID steamid.SID64) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("bot_steam_id").
+		Where(sq.Eq{"steam_id": steamID.Int64()}))
+}
+
+func (db *Store) SaveBotDetection(ctx context.Context, detection *BotDetection) error {
+	if detection.CreatedOn.IsZero() {
+		detection.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("bot_detection").
+		SetMap(map[string]interface{}{
+			"steam_id":   detection.SteamID.Int64(),
+			"server_id":  detection.ServerID,
+			"name":       detection.Name,
+			"method":     detection.Method,
+			"detail":     detection.Detail,
+			"created_on": detection.CreatedOn,
+		}).
+		Suffix("RETURNING bot_detection_id"), &detection.BotDetectionID)
+}
+
+// GetBotDetectionReport returns the totals of blocked bots for each server and detection method
+// since the time provided.
+func (db *Store) GetBotDetectionReport(ctx context.Context, since time.Time) ([]BotReportEntry, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("d.server_id", "s.short_name", "d.method", "count(d.bot_detection_id)", "count(DISTINCT d.steam_id)").
+		From("bot_detection d").
+		LeftJoin("server s ON s.server_id = d.server_id").
+		Where(sq.GtOrEq{"d.created_on": since}).
+		GroupBy("d.server_id", "s.short_name", "d.method").
+		OrderBy("s.short_name", "d.method"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return []BotReportEntry{}, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	entries := make([]BotReportEntry, 0)
+
+	for rows.Next() {
+		var entry BotReportEntry
+		if errScan := rows.Scan(&entry.ServerID, &entry.ServerName, &entry.Method,
+			&entry.Detections, &entry.Accounts); errScan != nil {
+			return nil, Err(errScan)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// GetPlayerMatchCount returns the total number of matches the player has participated in.
+func (db *Store) GetPlayerMatchCount(ctx context.Context, steamID steamid.SID64) (int64, error) {
+	return db.GetCount(ctx, db.sb.
+		Select("count(match_player_id)").
+		From("match_player").
+		Where(sq.Eq{"steam_id": steamID.Int64()}))
+}
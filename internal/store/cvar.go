@@ -0,0 +1,380 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// CvarTemplate defines the expected values of a set of cvars for the servers it is assigned to.
+type CvarTemplate struct {
+	CvarTemplateID int               `json:"cvar_template_id"`
+	Name           string            `json:"name"`
+	Note           string            `json:"note"`
+	Values         map[string]string `json:"values"`
+	TimeStamped
+}
+
+// CvarDrift is a cvar whose value on a server did not match its assigned template during the last check.
+type CvarDrift struct {
+	ServerID   int       `json:"server_id"`
+	ServerName string    `json:"server_name"`
+	Cvar       string    `json:"cvar"`
+	Expected   string    `json:"expected"`
+	Actual     string    `json:"actual"`
+	CheckedOn  time.Time `json:"checked_on"`
+}
+
+func (db *Store) getCvarTemplateValues(ctx context.Context, templateIDs []int) (map[int]map[string]string, error) {
+	values := map[int]map[string]string{}
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("cvar_template_id", "cvar", "value").
+		From("cvar_template_value").
+		Where(sq.Eq{"cvar_template_id": templateIDs}))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return values, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			templateID  int
+			cvar, value string
+		)
+
+		if errScan := rows.Scan(&templateID, &cvar, &value); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan cvar template value")
+		}
+
+		if _, found := values[templateID]; !found {
+			values[templateID] = map[string]string{}
+		}
+
+		values[templateID][cvar] = value
+	}
+
+	return values, nil
+}
+
+func (db *Store) GetCvarTemplates(ctx context.Context) ([]CvarTemplate, error) {
+	templates := make([]CvarTemplate, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("cvar_template_id", "name", "note", "created_on", "updated_on").
+		From("cvar_template").
+		OrderBy("name"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return templates, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	var templateIDs []int
+
+	for rows.Next() {
+		var template CvarTemplate
+		if errScan := rows.Scan(&template.CvarTemplateID, &template.Name, &template.Note,
+			&template.CreatedOn, &template.UpdatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan cvar template")
+		}
+
+		templates = append(templates, template)
+		templateIDs = append(templateIDs, template.CvarTemplateID)
+	}
+
+	rows.Close()
+
+	if len(templates) == 0 {
+		return templates, nil
+	}
+
+	values, errValues := db.getCvarTemplateValues(ctx, templateIDs)
+	if errValues != nil {
+		return nil, errValues
+	}
+
+	for idx := range templates {
+		templates[idx].Values = values[templates[idx].CvarTemplateID]
+		if templates[idx].Values == nil {
+			templates[idx].Values = map[string]string{}
+		}
+	}
+
+	return templates, nil
+}
+
+func (db *Store) GetCvarTemplate(ctx context.Context, templateID int, template *CvarTemplate) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.sb.
+		Select("cvar_template_id", "name", "note", "created_on", "updated_on").
+		From("cvar_template").
+		Where(sq.Eq{"cvar_template_id": templateID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	if errScan := row.Scan(&template.CvarTemplateID, &template.Name, &template.Note,
+		&template.CreatedOn, &template.UpdatedOn); errScan != nil {
+		return Err(errScan)
+	}
+
+	values, errValues := db.getCvarTemplateValues(ctx, []int{templateID})
+	if errValues != nil {
+		return errValues
+	}
+
+	template.Values = values[templateID]
+	if template.Values == nil {
+		template.Values = map[string]string{}
+	}
+
+	return nil
+}
+
+func txExec(ctx context.Context, transaction pgx.Tx, builder sq.Sqlizer) error {
+	query, args, errQuery := builder.ToSql()
+	if errQuery != nil {
+		return Err(errQuery)
+	}
+
+	_, errExec := transaction.Exec(ctx, query, args...)
+
+	return Err(errExec)
+}
+
+func (db *Store) rollback(ctx context.Context, transaction pgx.Tx) {
+	if errRollback := transaction.Rollback(ctx); errRollback != nil {
+		db.log.Error("Failed to rollback tx", zap.Error(errRollback))
+	}
+}
+
+// SaveCvarTemplate creates or updates the template, replacing all of its existing values.
+func (db *Store) SaveCvarTemplate(ctx context.Context, template *CvarTemplate) error {
+	template.UpdatedOn = time.Now()
+
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create cvar template tx")
+	}
+
+	if template.CvarTemplateID > 0 {
+		if errUpdate := txExec(ctx, transaction, db.sb.
+			Update("cvar_template").
+			SetMap(map[string]interface{}{
+				"name":       template.Name,
+				"note":       template.Note,
+				"updated_on": template.UpdatedOn,
+			}).
+			Where(sq.Eq{"cvar_template_id": template.CvarTemplateID})); errUpdate != nil {
+			db.rollback(ctx, transaction)
+
+			return errUpdate
+		}
+
+		if errDelete := txExec(ctx, transaction, db.sb.
+			Delete("cvar_template_value").
+			Where(sq.Eq{"cvar_template_id": template.CvarTemplateID})); errDelete != nil {
+			db.rollback(ctx, transaction)
+
+			return errDelete
+		}
+	} else {
+		template.CreatedOn = template.UpdatedOn
+
+		query, args, errQuery := db.sb.
+			Insert("cvar_template").
+			SetMap(map[string]interface{}{
+				"name":       template.Name,
+				"note":       template.Note,
+				"created_on": template.CreatedOn,
+				"updated_on": template.UpdatedOn,
+			}).
+			Suffix("RETURNING cvar_template_id").
+			ToSql()
+		if errQuery != nil {
+			db.rollback(ctx, transaction)
+
+			return Err(errQuery)
+		}
+
+		if errInsert := transaction.QueryRow(ctx, query, args...).Scan(&template.CvarTemplateID); errInsert != nil {
+			db.rollback(ctx, transaction)
+
+			return Err(errInsert)
+		}
+	}
+
+	if len(template.Values) > 0 {
+		cvars := make([]string, 0, len(template.Values))
+		for cvar := range template.Values {
+			cvars = append(cvars, cvar)
+		}
+
+		sort.Strings(cvars)
+
+		builder := db.sb.
+			Insert("cvar_template_value").
+			Columns("cvar_template_id", "cvar", "value")
+
+		for _, cvar := range cvars {
+			builder = builder.Values(template.CvarTemplateID, cvar, template.Values[cvar])
+		}
+
+		if errValues := txExec(ctx, transaction, builder); errValues != nil {
+			db.rollback(ctx, transaction)
+
+			return errValues
+		}
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrap(errCommit, "Failed to commit cvar template")
+	}
+
+	return nil
+}
+
+func (db *Store) DeleteCvarTemplate(ctx context.Context, templateID int) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("cvar_template").
+		Where(sq.Eq{"cvar_template_id": templateID}))
+}
+
+// GetServerCvarTemplates returns the template id assigned to each server, keyed by server id.
+func (db *Store) GetServerCvarTemplates(ctx context.Context) (map[int]int, error) {
+	assigned := map[int]int{}
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("server_id", "cvar_template_id").
+		From("server_cvar_template"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return assigned, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var serverID, templateID int
+		if errScan := rows.Scan(&serverID, &templateID); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server cvar template")
+		}
+
+		assigned[serverID] = templateID
+	}
+
+	return assigned, nil
+}
+
+// SetServerCvarTemplate assigns a template to the server. A templateID of 0 removes the assignment along
+// with any drift recorded against the previous template.
+func (db *Store) SetServerCvarTemplate(ctx context.Context, serverID int, templateID int) error {
+	if templateID <= 0 {
+		if errDelete := db.ExecDeleteBuilder(ctx, db.sb.
+			Delete("server_cvar_template").
+			Where(sq.Eq{"server_id": serverID})); errDelete != nil {
+			return errDelete
+		}
+
+		return db.SaveCvarDrift(ctx, serverID, nil)
+	}
+
+	return db.ExecInsertBuilder(ctx, db.sb.
+		Insert("server_cvar_template").
+		Columns("server_id", "cvar_template_id").
+		Values(serverID, templateID).
+		Suffix("ON CONFLICT (server_id) DO UPDATE SET cvar_template_id = EXCLUDED.cvar_template_id"))
+}
+
+// SaveCvarDrift replaces the drift recorded for the server with the results of the latest check.
+func (db *Store) SaveCvarDrift(ctx context.Context, serverID int, drift []CvarDrift) error {
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create cvar drift tx")
+	}
+
+	if errDelete := txExec(ctx, transaction, db.sb.
+		Delete("cvar_drift").
+		Where(sq.Eq{"server_id": serverID})); errDelete != nil {
+		db.rollback(ctx, transaction)
+
+		return errDelete
+	}
+
+	if len(drift) > 0 {
+		builder := db.sb.
+			Insert("cvar_drift").
+			Columns("server_id", "cvar", "expected", "actual", "checked_on")
+
+		for _, item := range drift {
+			builder = builder.Values(serverID, item.Cvar, item.Expected, item.Actual, item.CheckedOn)
+		}
+
+		if errInsert := txExec(ctx, transaction, builder); errInsert != nil {
+			db.rollback(ctx, transaction)
+
+			return errInsert
+		}
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrap(errCommit, "Failed to commit cvar drift")
+	}
+
+	return nil
+}
+
+// GetCvarDrift returns all currently drifted cvars, optionally limited to a single server.
+func (db *Store) GetCvarDrift(ctx context.Context, serverID int) ([]CvarDrift, error) {
+	drift := make([]CvarDrift, 0)
+
+	builder := db.sb.
+		Select("d.server_id", "s.short_name", "d.cvar", "d.expected", "d.actual", "d.checked_on").
+		From("cvar_drift d").
+		LeftJoin("server s ON s.server_id = d.server_id").
+		OrderBy("s.short_name", "d.cvar")
+
+	if serverID > 0 {
+		builder = builder.Where(sq.Eq{"d.server_id": serverID})
+	}
+
+	rows, errRows := db.QueryBuilder(ctx, builder)
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return drift, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var item CvarDrift
+		if errScan := rows.Scan(&item.ServerID, &item.ServerName, &item.Cvar, &item.Expected,
+			&item.Actual, &item.CheckedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan cvar drift")
+		}
+
+		drift = append(drift, item)
+	}
+
+	return drift, nil
+}
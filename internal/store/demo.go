@@ -253,6 +253,19 @@ func (db *Store) SaveDemo(ctx context.Context, demoFile *DemoFile) error {
 		demoFile.Archive = true
 	}
 
+	// Demos containing anti-cheat detections are also kept for later review.
+	stacCount, errStacCount := db.GetCount(ctx, db.sb.
+		Select("count(stac_detection_id)").
+		From("stac_detection").
+		Where(sq.Eq{"demo_name": demoFile.Title}))
+	if errStacCount != nil {
+		return errors.Wrap(errStacCount, "Failed to select stac detections")
+	}
+
+	if stacCount > 0 {
+		demoFile.Archive = true
+	}
+
 	var err error
 	if demoFile.DemoID > 0 {
 		err = db.updateDemo(ctx, demoFile)
@@ -97,6 +97,7 @@ type ForumMessage struct {
 	Title          string        `json:"title"`
 	Online         bool          `json:"online"`
 	Signature      string        `json:"signature"`
+	ForumBadge     string        `json:"forum_badge"`
 	SimplePerson
 	TimeStamped
 }
@@ -634,10 +635,12 @@ func (db *Store) ForumMessages(ctx context.Context, filters ThreadMessagesQueryF
 
 	builder := db.sb.
 		Select("m.forum_message_id", "m.forum_thread_id", "m.source_id", "m.body_md", "m.created_on",
-			"m.updated_on", "p.personaname", "p.avatarhash", "p.permission_level", "coalesce(s.forum_signature, '')").
+			"m.updated_on", "p.personaname", "p.avatarhash", "p.permission_level", "coalesce(s.forum_signature, '')",
+			"coalesce(b.forum_badge, '')").
 		From("forum_message m").
 		LeftJoin("person p ON p.steam_id = m.source_id").
 		LeftJoin("person_settings s ON s.steam_id = m.source_id").
+		LeftJoin("patreon_perk b ON b.steam_id = m.source_id").
 		Where(constraints).
 		OrderBy("m.forum_message_id")
 
@@ -652,7 +655,7 @@ func (db *Store) ForumMessages(ctx context.Context, filters ThreadMessagesQueryF
 	for rows.Next() {
 		var msg ForumMessage
 		if errScan := rows.Scan(&msg.ForumMessageID, &msg.ForumThreadID, &msg.SourceID, &msg.BodyMD, &msg.CreatedOn, &msg.UpdatedOn,
-			&msg.Personaname, &msg.Avatarhash, &msg.PermissionLevel, &msg.Signature); errScan != nil {
+			&msg.Personaname, &msg.Avatarhash, &msg.PermissionLevel, &msg.Signature, &msg.ForumBadge); errScan != nil {
 			return nil, 0, Err(errScan)
 		}
 
@@ -0,0 +1,330 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+// MapRotationEntry is a map within a servers rotation pool.
+type MapRotationEntry struct {
+	MapName string `json:"map_name"`
+	// Weight is the relative chance of the map being selected
+	Weight int `json:"weight"`
+	// MinPlayers and MaxPlayers restrict the map to a range of player counts, a MaxPlayers of 0 is unbounded.
+	MinPlayers int `json:"min_players"`
+	MaxPlayers int `json:"max_players"`
+}
+
+// MapRotation is the map pool and selection rules of a single server.
+type MapRotation struct {
+	ServerID   int    `json:"server_id"`
+	ServerName string `json:"server_name"`
+	Enabled    bool   `json:"enabled"`
+	// ExcludeRecent prevents the last n maps from being selected again
+	ExcludeRecent int `json:"exclude_recent"`
+	// UseStats adjusts map weights by their share of the overall playtime
+	UseStats bool               `json:"use_stats"`
+	Maps     []MapRotationEntry `json:"maps"`
+	TimeStamped
+}
+
+// MapRotationChange records the next map chosen for a server at the end of a map.
+type MapRotationChange struct {
+	MapRotationChangeID int64     `json:"map_rotation_change_id"`
+	ServerID            int       `json:"server_id"`
+	ServerName          string    `json:"server_name"`
+	PreviousMap         string    `json:"previous_map"`
+	MapName             string    `json:"map_name"`
+	Players             int       `json:"players"`
+	Error               string    `json:"error"`
+	CreatedOn           time.Time `json:"created_on"`
+}
+
+type MapRotationChangeQueryFilter struct {
+	QueryFilter
+	ServerID int `json:"server_id,omitempty"`
+}
+
+func (db *Store) getMapRotationEntries(ctx context.Context, serverIDs []int) (map[int][]MapRotationEntry, error) {
+	entries := map[int][]MapRotationEntry{}
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("server_id", "map_name", "weight", "min_players", "max_players").
+		From("map_rotation_entry").
+		Where(sq.Eq{"server_id": serverIDs}).
+		OrderBy("map_name"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return entries, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			serverID int
+			entry    MapRotationEntry
+		)
+
+		if errScan := rows.Scan(&serverID, &entry.MapName, &entry.Weight, &entry.MinPlayers, &entry.MaxPlayers); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan map rotation entry")
+		}
+
+		entries[serverID] = append(entries[serverID], entry)
+	}
+
+	return entries, nil
+}
+
+func (db *Store) mapRotationQuery() sq.SelectBuilder {
+	return db.sb.
+		Select("r.server_id", "s.short_name", "r.enabled", "r.exclude_recent", "r.use_stats",
+			"r.created_on", "r.updated_on").
+		From("map_rotation r").
+		LeftJoin("server s ON s.server_id = r.server_id")
+}
+
+func (db *Store) GetMapRotations(ctx context.Context) ([]MapRotation, error) {
+	rotations := make([]MapRotation, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.mapRotationQuery().OrderBy("s.short_name"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return rotations, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	var serverIDs []int
+
+	for rows.Next() {
+		var rotation MapRotation
+		if errScan := rows.Scan(&rotation.ServerID, &rotation.ServerName, &rotation.Enabled, &rotation.ExcludeRecent,
+			&rotation.UseStats, &rotation.CreatedOn, &rotation.UpdatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan map rotation")
+		}
+
+		rotations = append(rotations, rotation)
+		serverIDs = append(serverIDs, rotation.ServerID)
+	}
+
+	rows.Close()
+
+	if len(rotations) == 0 {
+		return rotations, nil
+	}
+
+	entries, errEntries := db.getMapRotationEntries(ctx, serverIDs)
+	if errEntries != nil {
+		return nil, errEntries
+	}
+
+	for idx := range rotations {
+		rotations[idx].Maps = entries[rotations[idx].ServerID]
+		if rotations[idx].Maps == nil {
+			rotations[idx].Maps = []MapRotationEntry{}
+		}
+	}
+
+	return rotations, nil
+}
+
+func (db *Store) GetMapRotation(ctx context.Context, serverID int, rotation *MapRotation) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.mapRotationQuery().Where(sq.Eq{"r.server_id": serverID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	if errScan := row.Scan(&rotation.ServerID, &rotation.ServerName, &rotation.Enabled, &rotation.ExcludeRecent,
+		&rotation.UseStats, &rotation.CreatedOn, &rotation.UpdatedOn); errScan != nil {
+		return Err(errScan)
+	}
+
+	entries, errEntries := db.getMapRotationEntries(ctx, []int{serverID})
+	if errEntries != nil {
+		return errEntries
+	}
+
+	rotation.Maps = entries[serverID]
+	if rotation.Maps == nil {
+		rotation.Maps = []MapRotationEntry{}
+	}
+
+	return nil
+}
+
+// SaveMapRotation creates or updates the servers rotation, replacing its existing map pool.
+func (db *Store) SaveMapRotation(ctx context.Context, rotation *MapRotation) error {
+	rotation.UpdatedOn = time.Now()
+	if rotation.CreatedOn.IsZero() {
+		rotation.CreatedOn = rotation.UpdatedOn
+	}
+
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create map rotation tx")
+	}
+
+	if errUpsert := txExec(ctx, transaction, db.sb.
+		Insert("map_rotation").
+		SetMap(map[string]interface{}{
+			"server_id":      rotation.ServerID,
+			"enabled":        rotation.Enabled,
+			"exclude_recent": rotation.ExcludeRecent,
+			"use_stats":      rotation.UseStats,
+			"created_on":     rotation.CreatedOn,
+			"updated_on":     rotation.UpdatedOn,
+		}).
+		Suffix(`ON CONFLICT (server_id) DO UPDATE SET enabled = EXCLUDED.enabled,
+			exclude_recent = EXCLUDED.exclude_recent, use_stats = EXCLUDED.use_stats,
+			updated_on = EXCLUDED.updated_on`)); errUpsert != nil {
+		db.rollback(ctx, transaction)
+
+		return errUpsert
+	}
+
+	if errDelete := txExec(ctx, transaction, db.sb.
+		Delete("map_rotation_entry").
+		Where(sq.Eq{"server_id": rotation.ServerID})); errDelete != nil {
+		db.rollback(ctx, transaction)
+
+		return errDelete
+	}
+
+	if len(rotation.Maps) > 0 {
+		builder := db.sb.
+			Insert("map_rotation_entry").
+			Columns("server_id", "map_name", "weight", "min_players", "max_players")
+
+		for _, entry := range rotation.Maps {
+			builder = builder.Values(rotation.ServerID, entry.MapName, entry.Weight, entry.MinPlayers, entry.MaxPlayers)
+		}
+
+		if errInsert := txExec(ctx, transaction, builder); errInsert != nil {
+			db.rollback(ctx, transaction)
+
+			return errInsert
+		}
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrap(errCommit, "Failed to commit map rotation")
+	}
+
+	return nil
+}
+
+func (db *Store) DeleteMapRotation(ctx context.Context, serverID int) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("map_rotation").
+		Where(sq.Eq{"server_id": serverID}))
+}
+
+func (db *Store) SaveMapRotationChange(ctx context.Context, change *MapRotationChange) error {
+	if change.CreatedOn.IsZero() {
+		change.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("map_rotation_change").
+		SetMap(map[string]interface{}{
+			"server_id":    change.ServerID,
+			"previous_map": change.PreviousMap,
+			"map_name":     change.MapName,
+			"players":      change.Players,
+			"error":        change.Error,
+			"created_on":   change.CreatedOn,
+		}).
+		Suffix("RETURNING map_rotation_change_id"), &change.MapRotationChangeID)
+}
+
+// GetRecentRotationMaps returns the last maps successfully chosen for the server, most recent first.
+func (db *Store) GetRecentRotationMaps(ctx context.Context, serverID int, limit uint64) ([]string, error) {
+	maps := make([]string, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("map_name").
+		From("map_rotation_change").
+		Where(sq.And{sq.Eq{"server_id": serverID}, sq.Eq{"error": ""}}).
+		OrderBy("created_on DESC").
+		Limit(limit))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return maps, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var mapName string
+		if errScan := rows.Scan(&mapName); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan recent map")
+		}
+
+		maps = append(maps, mapName)
+	}
+
+	return maps, nil
+}
+
+func (db *Store) GetMapRotationChanges(ctx context.Context, filter MapRotationChangeQueryFilter) ([]MapRotationChange, int64, error) {
+	var constraints sq.And
+
+	if filter.ServerID > 0 {
+		constraints = append(constraints, sq.Eq{"c.server_id": filter.ServerID})
+	}
+
+	if filter.OrderBy == "" {
+		filter.Desc = true
+	}
+
+	builder := filter.applySafeOrder(db.sb.
+		Select("c.map_rotation_change_id", "c.server_id", "s.short_name", "c.previous_map", "c.map_name",
+			"c.players", "c.error", "c.created_on").
+		From("map_rotation_change c").
+		LeftJoin("server s ON s.server_id = c.server_id").
+		Where(constraints), map[string][]string{
+		"c.": {"map_rotation_change_id", "server_id", "previous_map", "map_name", "players", "created_on"},
+	}, "created_on")
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	changes := make([]MapRotationChange, 0)
+
+	for rows.Next() {
+		var change MapRotationChange
+		if errScan := rows.Scan(&change.MapRotationChangeID, &change.ServerID, &change.ServerName,
+			&change.PreviousMap, &change.MapName, &change.Players, &change.Error, &change.CreatedOn); errScan != nil {
+			return nil, 0, errors.Wrap(Err(errScan), "Failed to scan map rotation change")
+		}
+
+		changes = append(changes, change)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("count(c.map_rotation_change_id)").
+		From("map_rotation_change c").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return changes, count, nil
+}
@@ -19,11 +19,12 @@ import (
 
 type MatchesQueryOpts struct {
 	QueryFilter
-	SteamID   steamid.SID64 `json:"steam_id"`
-	ServerID  int           `json:"server_id"`
-	Map       string        `json:"map"`
-	TimeStart *time.Time    `json:"time_start,omitempty"`
-	TimeEnd   *time.Time    `json:"time_end,omitempty"`
+	SteamID       steamid.SID64 `json:"steam_id"`
+	ServerID      int           `json:"server_id"`
+	ServerGroupID int           `json:"server_group_id"`
+	Map           string        `json:"map"`
+	TimeStart     *time.Time    `json:"time_start,omitempty"`
+	TimeEnd       *time.Time    `json:"time_end,omitempty"`
 }
 
 type MatchPlayerKillstreak struct {
@@ -524,6 +525,18 @@ func (db *Store) matchGetChat(ctx context.Context, matchID uuid.UUID) (PersonMes
 	return messages, nil
 }
 
+// MatchExists checks if a match with the id has already been saved.
+func (db *Store) MatchExists(ctx context.Context, matchID uuid.UUID) (bool, error) {
+	var exists bool
+	if errQuery := db.
+		QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM match WHERE match_id = $1)", matchID).
+		Scan(&exists); errQuery != nil {
+		return false, Err(errQuery)
+	}
+
+	return exists, nil
+}
+
 func (db *Store) MatchGetByID(ctx context.Context, matchID uuid.UUID, match *MatchResult) error {
 	const query = `
 		SELECT match_id, server_id, map, title, score_red, score_blu, time_red, time_blu, time_start, time_end, winner
@@ -636,14 +649,8 @@ var (
 
 const MinMedicHealing = 500
 
-func (db *Store) MatchSave(ctx context.Context, match *logparse.Match) error {
-	const (
-		minPlayers = 6
-		query      = `
-		INSERT INTO match (match_id, server_id, map, title, score_red, score_blu, time_red, time_blu, time_start, time_end, winner) 
-		VALUES ($1, $2, $3, $4, $5, $6,$7, $8, $9, $10, $11) 
-		RETURNING match_id`
-	)
+func validateMatchSave(match *logparse.Match) error {
+	const minPlayers = 6
 
 	if match.TimeStart == nil || match.MapName == "" {
 		return ErrIncompleteMatch
@@ -653,6 +660,19 @@ func (db *Store) MatchSave(ctx context.Context, match *logparse.Match) error {
 		return ErrInsufficientPlayers
 	}
 
+	return nil
+}
+
+func (db *Store) MatchSave(ctx context.Context, match *logparse.Match) error {
+	const query = `
+		INSERT INTO match (match_id, server_id, map, title, score_red, score_blu, time_red, time_blu, time_start, time_end, winner) 
+		VALUES ($1, $2, $3, $4, $5, $6,$7, $8, $9, $10, $11) 
+		RETURNING match_id`
+
+	if errValid := validateMatchSave(match); errValid != nil {
+		return errValid
+	}
+
 	transaction, errTx := db.conn.Begin(ctx)
 	if errTx != nil {
 		return errors.Wrap(errTx, "Failed to create match tx")
@@ -670,6 +690,80 @@ func (db *Store) MatchSave(ctx context.Context, match *logparse.Match) error {
 		return errors.Wrap(errQuery, "Failed to create match")
 	}
 
+	if errSave := db.saveMatchPlayers(ctx, transaction, match); errSave != nil {
+		if errRollback := transaction.Rollback(ctx); errRollback != nil {
+			db.log.Error("Failed to rollback tx", zap.Error(errRollback))
+		}
+
+		return errSave
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrapf(errCommit, "Failed to commit match")
+	}
+
+	return nil
+}
+
+// MatchReplace replaces the stats of an existing match while keeping its id, so anything else referencing the
+// match is left intact. This is used when a match is reprocessed from its archived log.
+func (db *Store) MatchReplace(ctx context.Context, match *logparse.Match) error {
+	const query = `
+		UPDATE match SET server_id = $2, map = $3, title = $4, score_red = $5, score_blu = $6, time_red = $7,
+			time_blu = $8, time_start = $9, time_end = $10, winner = $11
+		WHERE match_id = $1`
+
+	if errValid := validateMatchSave(match); errValid != nil {
+		return errValid
+	}
+
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create match tx")
+	}
+
+	rollback := func() {
+		if errRollback := transaction.Rollback(ctx); errRollback != nil {
+			db.log.Error("Failed to rollback tx", zap.Error(errRollback))
+		}
+	}
+
+	tag, errUpdate := transaction.Exec(ctx, query, match.MatchID, match.ServerID, match.MapName, match.Title,
+		match.TeamScores.Red, match.TeamScores.Blu, match.TeamScores.RedTime, match.TeamScores.BluTime,
+		match.TimeStart, match.TimeEnd, match.Winner())
+	if errUpdate != nil {
+		rollback()
+
+		return errors.Wrap(errUpdate, "Failed to update match")
+	}
+
+	if tag.RowsAffected() == 0 {
+		rollback()
+
+		return ErrNoResult
+	}
+
+	// The weapon, class, killstreak and medic stats are removed along with the players.
+	if _, errDelete := transaction.Exec(ctx, "DELETE FROM match_player WHERE match_id = $1", match.MatchID); errDelete != nil {
+		rollback()
+
+		return errors.Wrap(errDelete, "Failed to remove match players")
+	}
+
+	if errSave := db.saveMatchPlayers(ctx, transaction, match); errSave != nil {
+		rollback()
+
+		return errSave
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrapf(errCommit, "Failed to commit match")
+	}
+
+	return nil
+}
+
+func (db *Store) saveMatchPlayers(ctx context.Context, transaction pgx.Tx, match *logparse.Match) error {
 	for _, player := range match.PlayerSums {
 		if !player.SteamID.Valid() {
 			// TODO Why can this happen? stv host?
@@ -678,60 +772,32 @@ func (db *Store) MatchSave(ctx context.Context, match *logparse.Match) error {
 
 		var loadPlayerTest Person
 		if errPlayer := db.GetOrCreatePersonBySteamID(ctx, player.SteamID, &loadPlayerTest); errPlayer != nil {
-			if errRollback := transaction.Rollback(ctx); errRollback != nil {
-				db.log.Error("Failed to rollback tx", zap.Error(errRollback))
-			}
-
 			return errors.Wrapf(errPlayer, "Failed to load person")
 		}
 
 		if errSave := db.saveMatchPlayerStats(ctx, transaction, match, player); errSave != nil {
-			if errRollback := transaction.Rollback(ctx); errRollback != nil {
-				db.log.Error("Failed to rollback tx", zap.Error(errRollback))
-			}
-
 			return errSave
 		}
 
 		if errSave := db.saveMatchWeaponStats(ctx, transaction, player); errSave != nil {
-			if errRollback := transaction.Rollback(ctx); errRollback != nil {
-				db.log.Error("Failed to rollback tx", zap.Error(errRollback))
-			}
-
 			return errSave
 		}
 
 		if errSave := db.saveMatchPlayerClassStats(ctx, transaction, player); errSave != nil {
-			if errRollback := transaction.Rollback(ctx); errRollback != nil {
-				db.log.Error("Failed to rollback tx", zap.Error(errRollback))
-			}
-
 			return errSave
 		}
 
 		if errSave := db.saveMatchKillstreakStats(ctx, transaction, player); errSave != nil {
-			if errRollback := transaction.Rollback(ctx); errRollback != nil {
-				db.log.Error("Failed to rollback tx", zap.Error(errRollback))
-			}
-
 			return errSave
 		}
 
 		if player.HealingStats != nil && player.HealingStats.Healing >= MinMedicHealing {
 			if errSave := db.saveMatchMedicStats(ctx, transaction, player.MatchPlayerID, player.HealingStats); errSave != nil {
-				if errRollback := transaction.Rollback(ctx); errRollback != nil {
-					db.log.Error("Failed to rollback tx", zap.Error(errRollback))
-				}
-
 				return errSave
 			}
 		}
 	}
 
-	if errCommit := transaction.Commit(ctx); errCommit != nil {
-		return errors.Wrapf(errCommit, "Failed to commit match")
-	}
-
 	return nil
 }
 
@@ -1324,6 +1390,18 @@ func (db *Store) Matches(ctx context.Context, opts MatchesQueryOpts) ([]MatchSum
 		countBuilder = countBuilder.Where(sq.Eq{"mp.steam_id": opts.SteamID.Int64()})
 	}
 
+	if opts.ServerID > 0 {
+		builder = builder.Where(sq.Eq{"m.server_id": opts.ServerID})
+		countBuilder = countBuilder.Where(sq.Eq{"m.server_id": opts.ServerID})
+	}
+
+	if opts.ServerGroupID > 0 {
+		groupFilter := sq.Expr("m.server_id IN (SELECT server_id FROM server_group_member WHERE server_group_id = ?)",
+			opts.ServerGroupID)
+		builder = builder.Where(groupFilter)
+		countBuilder = countBuilder.Where(groupFilter)
+	}
+
 	builder = opts.QueryFilter.applySafeOrder(builder, map[string][]string{
 		"":   {"winner"},
 		"m.": {"match_id", "server_id", "map", "score_blu", "score_red", "time_start", "time_end"},
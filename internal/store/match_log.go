@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// MatchLog points to the archived raw log of a match in the asset store.
+type MatchLog struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Bucket  string    `json:"bucket"`
+	Name    string    `json:"name"`
+	// Size is the compressed size of the archive.
+	Size      int64     `json:"size"`
+	Lines     int       `json:"lines"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+func (db *Store) matchLogQuery() sq.SelectBuilder {
+	return db.sb.
+		Select("match_id", "bucket", "name", "size", "lines", "created_on").
+		From("match_log")
+}
+
+func scanMatchLog(row pgx.Row, matchLog *MatchLog) error {
+	if errScan := row.Scan(&matchLog.MatchID, &matchLog.Bucket, &matchLog.Name, &matchLog.Size, &matchLog.Lines,
+		&matchLog.CreatedOn); errScan != nil {
+		return Err(errScan)
+	}
+
+	return nil
+}
+
+// SaveMatchLog records the archive of a match, replacing any existing record.
+func (db *Store) SaveMatchLog(ctx context.Context, matchLog *MatchLog) error {
+	return db.ExecInsertBuilder(ctx, db.sb.
+		Insert("match_log").
+		SetMap(map[string]interface{}{
+			"match_id":   matchLog.MatchID,
+			"bucket":     matchLog.Bucket,
+			"name":       matchLog.Name,
+			"size":       matchLog.Size,
+			"lines":      matchLog.Lines,
+			"created_on": matchLog.CreatedOn,
+		}).
+		Suffix(`ON CONFLICT (match_id) DO UPDATE SET bucket = EXCLUDED.bucket, name = EXCLUDED.name,
+			size = EXCLUDED.size, lines = EXCLUDED.lines, created_on = EXCLUDED.created_on`))
+}
+
+func (db *Store) GetMatchLog(ctx context.Context, matchID uuid.UUID, matchLog *MatchLog) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.matchLogQuery().Where(sq.Eq{"match_id": matchID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return scanMatchLog(row, matchLog)
+}
+
+// GetMatchLogs returns all the archived match logs, oldest first.
+func (db *Store) GetMatchLogs(ctx context.Context) ([]MatchLog, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.matchLogQuery().OrderBy("created_on"))
+	if errRows != nil {
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	matchLogs := make([]MatchLog, 0)
+
+	for rows.Next() {
+		var matchLog MatchLog
+		if errScan := scanMatchLog(rows, &matchLog); errScan != nil {
+			return nil, errScan
+		}
+
+		matchLogs = append(matchLogs, matchLog)
+	}
+
+	if rows.Err() != nil {
+		return nil, Err(rows.Err())
+	}
+
+	return matchLogs, nil
+}
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE pending_action_event;
+DROP TABLE pending_action;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE pending_action (
+    pending_action_id bigserial primary key,
+    action_type int not null,
+    state int not null default 0,
+    source_id bigint not null references person (steam_id),
+    target_id bigint not null default 0,
+    approver_id bigint,
+    payload jsonb not null,
+    reason text not null default '',
+    expires_on timestamptz not null,
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE INDEX pending_action_state_idx ON pending_action (state);
+
+CREATE TABLE pending_action_event (
+    pending_action_event_id bigserial primary key,
+    pending_action_id bigint not null references pending_action (pending_action_id) ON DELETE CASCADE,
+    steam_id bigint not null,
+    event int not null,
+    note text not null default '',
+    created_on timestamptz not null
+);
+
+COMMIT;
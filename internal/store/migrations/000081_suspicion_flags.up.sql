@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE suspicion_flag (
+    suspicion_flag_id bigserial primary key,
+    steam_id bigint not null references person (steam_id) ON DELETE CASCADE,
+    match_id uuid not null references match (match_id) ON DELETE CASCADE,
+    demo_id bigint references demo (demo_id) ON DELETE SET NULL,
+    weapon_id int references weapon (weapon_id) ON DELETE CASCADE,
+    metric int not null,
+    value double precision not null,
+    baseline_mean double precision not null,
+    baseline_stddev double precision not null,
+    score double precision not null,
+    state int not null default 0,
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE UNIQUE INDEX suspicion_flag_uidx ON suspicion_flag (steam_id, match_id, coalesce(weapon_id, 0), metric);
+CREATE INDEX suspicion_flag_state_idx ON suspicion_flag (state);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE stac_detection (
+    stac_detection_id bigserial primary key,
+    steam_id bigint not null references person (steam_id) ON DELETE CASCADE,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    match_id uuid,
+    demo_name text not null default '',
+    demo_tick int not null default -1,
+    detection text not null,
+    count int not null default 0,
+    created_on timestamptz not null
+);
+
+CREATE INDEX stac_detection_steam_id_idx ON stac_detection (steam_id);
+CREATE INDEX stac_detection_demo_name_idx ON stac_detection (demo_name);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS bot_detection;
+DROP TABLE IF EXISTS bot_steam_id;
+DROP TABLE IF EXISTS bot_name_pattern;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE bot_name_pattern (
+    bot_name_pattern_id serial primary key,
+    pattern text not null unique,
+    note text not null default '',
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE TABLE bot_steam_id (
+    steam_id bigint primary key,
+    source text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE TABLE bot_detection (
+    bot_detection_id bigserial primary key,
+    steam_id bigint not null references person (steam_id) ON DELETE CASCADE,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    name text not null default '',
+    method int not null,
+    detail text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE INDEX bot_detection_created_on_idx ON bot_detection (created_on);
+
+COMMIT;
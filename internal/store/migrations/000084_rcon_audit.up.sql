@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE rcon_audit (
+    rcon_audit_id bigserial primary key,
+    steam_id bigint not null references person (steam_id) ON DELETE CASCADE,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    command text not null,
+    response text not null default '',
+    error text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE INDEX rcon_audit_steam_id_idx ON rcon_audit (steam_id);
+CREATE INDEX rcon_audit_created_on_idx ON rcon_audit (created_on);
+
+COMMIT;
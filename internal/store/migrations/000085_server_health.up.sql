@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE server_health (
+    server_health_id bigserial primary key,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    state int not null,
+    reason text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE INDEX server_health_server_id_created_on_idx ON server_health (server_id, created_on);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS rcon_task_run;
+DROP TABLE IF EXISTS rcon_task;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE rcon_task (
+    rcon_task_id serial primary key,
+    name text not null,
+    schedule text not null,
+    server_ids int[] not null default '{}',
+    region text not null default '',
+    all_servers bool not null default false,
+    commands text[] not null,
+    players_below int not null default 0,
+    enabled bool not null default true,
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE TABLE rcon_task_run (
+    rcon_task_run_id bigserial primary key,
+    rcon_task_id int not null references rcon_task (rcon_task_id) ON DELETE CASCADE,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    skipped bool not null default false,
+    output text not null default '',
+    error text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE INDEX rcon_task_run_task_id_created_on_idx ON rcon_task_run (rcon_task_id, created_on);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS cvar_drift;
+DROP TABLE IF EXISTS server_cvar_template;
+DROP TABLE IF EXISTS cvar_template_value;
+DROP TABLE IF EXISTS cvar_template;
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+CREATE TABLE cvar_template (
+    cvar_template_id serial primary key,
+    name text not null unique,
+    note text not null default '',
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE TABLE cvar_template_value (
+    cvar_template_id int not null references cvar_template (cvar_template_id) ON DELETE CASCADE,
+    cvar text not null,
+    value text not null,
+    primary key (cvar_template_id, cvar)
+);
+
+CREATE TABLE server_cvar_template (
+    server_id int primary key references server (server_id) ON DELETE CASCADE,
+    cvar_template_id int not null references cvar_template (cvar_template_id) ON DELETE CASCADE
+);
+
+CREATE TABLE cvar_drift (
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    cvar text not null,
+    expected text not null,
+    actual text not null,
+    checked_on timestamptz not null,
+    primary key (server_id, cvar)
+);
+
+COMMIT;
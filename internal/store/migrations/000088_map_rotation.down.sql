@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS map_rotation_change;
+DROP TABLE IF EXISTS map_rotation_entry;
+DROP TABLE IF EXISTS map_rotation;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE map_rotation (
+    server_id int primary key references server (server_id) ON DELETE CASCADE,
+    enabled bool not null default false,
+    exclude_recent int not null default 0,
+    use_stats bool not null default false,
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE TABLE map_rotation_entry (
+    server_id int not null references map_rotation (server_id) ON DELETE CASCADE,
+    map_name text not null,
+    weight int not null default 1,
+    min_players int not null default 0,
+    max_players int not null default 0,
+    primary key (server_id, map_name)
+);
+
+CREATE TABLE map_rotation_change (
+    map_rotation_change_id bigserial primary key,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    previous_map text not null,
+    map_name text not null,
+    players int not null default 0,
+    error text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE INDEX map_rotation_change_server_idx ON map_rotation_change (server_id, created_on);
+
+COMMIT;
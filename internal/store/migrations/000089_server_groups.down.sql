@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE rcon_task DROP COLUMN IF EXISTS server_group_ids;
+
+DROP TABLE IF EXISTS server_group_member;
+DROP TABLE IF EXISTS server_group;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE server_group (
+    server_group_id serial primary key,
+    name text not null unique,
+    description text not null default '',
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE TABLE server_group_member (
+    server_group_id int not null references server_group (server_group_id) ON DELETE CASCADE,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    primary key (server_group_id, server_id)
+);
+
+ALTER TABLE rcon_task ADD COLUMN server_group_ids int[] not null default '{}';
+
+COMMIT;
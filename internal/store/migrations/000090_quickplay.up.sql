@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE quickplay_selection (
+    quickplay_selection_id bigserial primary key,
+    steam_id bigint not null default 0,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    game_modes text[] not null default '{}',
+    maps text[] not null default '{}',
+    score float not null,
+    distance float not null,
+    players int not null default 0,
+    max_players int not null default 0,
+    candidates int not null default 0,
+    created_on timestamptz not null
+);
+
+CREATE INDEX quickplay_selection_created_idx ON quickplay_selection (created_on);
+
+COMMIT;
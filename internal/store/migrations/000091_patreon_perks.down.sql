@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS patreon_perk_log;
+DROP TABLE IF EXISTS patreon_perk;
+DROP TABLE IF EXISTS patreon_link;
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+CREATE TABLE patreon_link (
+    patreon_id text primary key,
+    steam_id bigint not null unique references person (steam_id) ON DELETE CASCADE,
+    created_on timestamptz not null
+);
+
+CREATE TABLE patreon_perk (
+    steam_id bigint primary key references person (steam_id) ON DELETE CASCADE,
+    patreon_id text not null,
+    tier_id text not null,
+    previous_permission_level int not null default 0,
+    discord_role_id text not null default '',
+    forum_badge text not null default '',
+    chat_tag text not null default '',
+    reserved_servers text[] not null default '{}',
+    lapsed_on timestamptz,
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+CREATE TABLE patreon_perk_log (
+    patreon_perk_log_id bigserial primary key,
+    steam_id bigint not null references person (steam_id) ON DELETE CASCADE,
+    patreon_id text not null,
+    tier_id text not null,
+    action text not null,
+    perk text not null,
+    value text not null default '',
+    created_on timestamptz not null
+);
+
+CREATE INDEX patreon_perk_log_steam_id_idx ON patreon_perk_log (steam_id);
+
+COMMIT;
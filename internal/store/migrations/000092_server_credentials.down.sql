@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS server_token;
+DROP TABLE IF EXISTS server_credential;
+
+COMMIT;
@@ -0,0 +1,69 @@
+BEGIN;
+
+CREATE TABLE server_credential (
+    server_credential_id bigserial primary key,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    kind text not null,
+    secret text not null,
+    signing_key text not null,
+    scopes text[] not null default '{}',
+    revoked bool not null default false,
+    expires_on timestamptz,
+    last_used_on timestamptz,
+    created_on timestamptz not null
+);
+
+CREATE UNIQUE INDEX server_credential_secret_idx ON server_credential (kind, secret);
+CREATE INDEX server_credential_server_id_idx ON server_credential (server_id);
+
+CREATE TABLE server_token (
+    server_token_id uuid primary key,
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    server_credential_id bigint not null references server_credential (server_credential_id) ON DELETE CASCADE,
+    scopes text[] not null default '{}',
+    revoked bool not null default false,
+    expires_on timestamptz not null,
+    last_used_on timestamptz,
+    created_on timestamptz not null
+);
+
+CREATE INDEX server_token_server_id_idx ON server_token (server_id);
+
+-- gen_random_uuid uses the strong random source, two of them give the signing key ~244 bits of randomness.
+-- A secret can only identify a single server, so when servers share one, only the lowest server id receives it.
+-- The others are reported at startup and must be given a new secret.
+INSERT INTO server_credential (server_id, kind, secret, signing_key, created_on)
+SELECT DISTINCT ON (password) server_id, 'key', password,
+       replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', ''), now()
+FROM server
+WHERE password != ''
+ORDER BY password, server_id;
+
+INSERT INTO server_credential (server_id, kind, secret, signing_key, created_on)
+SELECT DISTINCT ON (log_secret) server_id, 'log_secret', log_secret::text,
+       replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', ''), now()
+FROM server
+WHERE log_secret > 0
+ORDER BY log_secret, server_id;
+
+DO
+$$
+    DECLARE
+        shared record;
+    BEGIN
+        FOR shared IN
+            SELECT s.short_name, k.kind
+            FROM server s
+                     CROSS JOIN LATERAL (VALUES ('key', s.password), ('log_secret', s.log_secret::text)) k(kind, secret)
+            WHERE k.secret NOT IN ('', '0')
+              AND NOT EXISTS (SELECT 1
+                              FROM server_credential c
+                              WHERE c.server_id = s.server_id
+                                AND c.kind = k.kind)
+            LOOP
+                RAISE WARNING 'Server % shares its % with another server, a new one must be set', shared.short_name, shared.kind;
+            END LOOP;
+    END
+$$;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS server_enrollment_token;
+
+ALTER TABLE server DROP COLUMN IF EXISTS pending_approval;
+
+COMMIT;
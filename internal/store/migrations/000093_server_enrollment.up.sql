@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE server ADD COLUMN IF NOT EXISTS pending_approval bool not null default false;
+
+CREATE TABLE server_enrollment_token (
+    server_enrollment_token_id bigserial primary key,
+    token text not null unique,
+    short_name text not null default '',
+    region text not null default '',
+    cc text not null default '',
+    latitude float not null default 0,
+    longitude float not null default 0,
+    reserved_slots int not null default 0,
+    enable_stats bool not null default true,
+    note text not null default '',
+    author_id bigint not null references person (steam_id) ON DELETE CASCADE,
+    server_id int references server (server_id) ON DELETE SET NULL,
+    used_on timestamptz,
+    expires_on timestamptz not null,
+    created_on timestamptz not null
+);
+
+COMMIT;
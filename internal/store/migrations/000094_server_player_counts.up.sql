@@ -0,0 +1,18 @@
+BEGIN;
+
+-- Histogram holds the number of samples seen at each player count, indexed by the player count. This allows
+-- buckets to be merged while downsampling without losing the ability to calculate percentiles.
+CREATE TABLE server_player_count (
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    resolution text not null,
+    bucket timestamptz not null,
+    samples int not null,
+    peak int not null,
+    player_sum bigint not null,
+    histogram int[] not null default '{}',
+    PRIMARY KEY (server_id, resolution, bucket)
+);
+
+CREATE INDEX server_player_count_bucket_idx ON server_player_count (resolution, bucket);
+
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+
+-- Short history of the performance of each server as reported by the stats and net_status commands. The map
+-- and player count are recorded alongside so lag spikes can be compared against map changes and population.
+CREATE TABLE server_performance (
+    server_id int not null references server (server_id) ON DELETE CASCADE,
+    created_on timestamptz not null,
+    map_name text not null default '',
+    players int not null default 0,
+    cpu double precision not null default 0,
+    fps double precision not null default 0,
+    frame_ms double precision not null default 0,
+    var_ms double precision not null default 0,
+    tick_ms double precision not null default 0,
+    net_in_kbps double precision not null default 0,
+    net_out_kbps double precision not null default 0,
+    packets_in double precision not null default 0,
+    packets_out double precision not null default 0,
+    loss_in double precision not null default 0,
+    loss_out double precision not null default 0,
+    choke_in double precision not null default 0,
+    choke_out double precision not null default 0,
+    uptime_minutes int not null default 0,
+    tick_health double precision not null default 0,
+    lag_spike bool not null default false,
+    PRIMARY KEY (server_id, created_on)
+);
+
+CREATE INDEX server_performance_created_on_idx ON server_performance (created_on);
+
+COMMIT;
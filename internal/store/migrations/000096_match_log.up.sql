@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Location of the archived raw log lines for a match.
+CREATE TABLE match_log (
+    match_id uuid primary key references match (match_id) ON DELETE CASCADE,
+    bucket text not null,
+    name text not null,
+    size bigint not null,
+    lines int not null,
+    created_on timestamptz not null
+);
+
+COMMIT;
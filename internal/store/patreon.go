@@ -2,7 +2,12 @@ package store
 
 import (
 	"context"
+	"time"
 
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/leighmacdonald/gbans/internal/consts"
+	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/pkg/errors"
 )
 
@@ -31,3 +36,305 @@ func (db *Store) GetPatreonAuth(ctx context.Context) (string, string, error) {
 
 	return creatorAccessToken, creatorRefreshToken, nil
 }
+
+// PatreonLink associates a patreon user with their steam account so pledges can be mapped to perks.
+type PatreonLink struct {
+	PatreonID string        `json:"patreon_id"`
+	SteamID   steamid.SID64 `json:"steam_id"`
+	CreatedOn time.Time     `json:"created_on"`
+}
+
+func (db *Store) GetPatreonLinks(ctx context.Context) ([]PatreonLink, error) {
+	links := make([]PatreonLink, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("patreon_id", "steam_id", "created_on").
+		From("patreon_link").
+		OrderBy("created_on"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return links, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			link    PatreonLink
+			steamID int64
+		)
+
+		if errScan := rows.Scan(&link.PatreonID, &steamID, &link.CreatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan patreon link")
+		}
+
+		link.SteamID = steamid.New(steamID)
+
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// SavePatreonLink creates the link, replacing the steam id of any existing link for the patreon user.
+func (db *Store) SavePatreonLink(ctx context.Context, link *PatreonLink) error {
+	if link.CreatedOn.IsZero() {
+		link.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilder(ctx, db.sb.
+		Insert("patreon_link").
+		SetMap(map[string]interface{}{
+			"patreon_id": link.PatreonID,
+			"steam_id":   link.SteamID.Int64(),
+			"created_on": link.CreatedOn,
+		}).
+		Suffix("ON CONFLICT (patreon_id) DO UPDATE SET steam_id = EXCLUDED.steam_id"))
+}
+
+func (db *Store) DeletePatreonLink(ctx context.Context, patreonID string) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("patreon_link").
+		Where(sq.Eq{"patreon_id": patreonID}))
+}
+
+// PatreonPerk is the set of perks currently granted to a player from their patreon tier. The perk values are
+// recorded at the time they are granted so that they can be revoked even if the tier mapping has since changed.
+type PatreonPerk struct {
+	SteamID                 steamid.SID64    `json:"steam_id"`
+	PatreonID               string           `json:"patreon_id"`
+	TierID                  string           `json:"tier_id"`
+	PreviousPermissionLevel consts.Privilege `json:"previous_permission_level"`
+	DiscordRoleID           string           `json:"discord_role_id"`
+	ForumBadge              string           `json:"forum_badge"`
+	ChatTag                 string           `json:"chat_tag"`
+	ReservedServers         []string         `json:"reserved_servers"`
+	// LapsedOn is set once the pledge has ended, the perks are revoked after the grace period.
+	LapsedOn *time.Time `json:"lapsed_on"`
+	TimeStamped
+}
+
+func (db *Store) patreonPerkQuery() sq.SelectBuilder {
+	return db.sb.
+		Select("steam_id", "patreon_id", "tier_id", "previous_permission_level", "discord_role_id",
+			"forum_badge", "chat_tag", "reserved_servers", "lapsed_on", "created_on", "updated_on").
+		From("patreon_perk")
+}
+
+func scanPatreonPerk(row pgx.Row, perk *PatreonPerk) error {
+	var steamID int64
+
+	if errScan := row.Scan(&steamID, &perk.PatreonID, &perk.TierID, &perk.PreviousPermissionLevel,
+		&perk.DiscordRoleID, &perk.ForumBadge, &perk.ChatTag, &perk.ReservedServers, &perk.LapsedOn,
+		&perk.CreatedOn, &perk.UpdatedOn); errScan != nil {
+		return Err(errScan)
+	}
+
+	perk.SteamID = steamid.New(steamID)
+
+	return nil
+}
+
+func (db *Store) GetPatreonPerks(ctx context.Context) ([]PatreonPerk, error) {
+	perks := make([]PatreonPerk, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.patreonPerkQuery().OrderBy("created_on"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return perks, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var perk PatreonPerk
+		if errScan := scanPatreonPerk(rows, &perk); errScan != nil {
+			return nil, errors.Wrap(errScan, "Failed to scan patreon perk")
+		}
+
+		perks = append(perks, perk)
+	}
+
+	return perks, nil
+}
+
+func (db *Store) GetPatreonPerk(ctx context.Context, steamID steamid.SID64, perk *PatreonPerk) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.patreonPerkQuery().Where(sq.Eq{"steam_id": steamID.Int64()}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return scanPatreonPerk(row, perk)
+}
+
+// GetPatreonReservedSlots returns the players with a reserved slot perk on the server.
+func (db *Store) GetPatreonReservedSlots(ctx context.Context, serverName string) ([]steamid.SID64, error) {
+	steamIDs := make([]steamid.SID64, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("steam_id").
+		From("patreon_perk").
+		Where(sq.Expr("? = ANY(reserved_servers)", serverName)))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return steamIDs, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var steamID int64
+		if errScan := rows.Scan(&steamID); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan patreon reserved slot")
+		}
+
+		steamIDs = append(steamIDs, steamid.New(steamID))
+	}
+
+	return steamIDs, nil
+}
+
+func (db *Store) SavePatreonPerk(ctx context.Context, perk *PatreonPerk) error {
+	perk.UpdatedOn = time.Now()
+
+	if perk.CreatedOn.IsZero() {
+		perk.CreatedOn = perk.UpdatedOn
+	}
+
+	if perk.ReservedServers == nil {
+		perk.ReservedServers = []string{}
+	}
+
+	return db.ExecInsertBuilder(ctx, db.sb.
+		Insert("patreon_perk").
+		SetMap(map[string]interface{}{
+			"steam_id":                  perk.SteamID.Int64(),
+			"patreon_id":                perk.PatreonID,
+			"tier_id":                   perk.TierID,
+			"previous_permission_level": perk.PreviousPermissionLevel,
+			"discord_role_id":           perk.DiscordRoleID,
+			"forum_badge":               perk.ForumBadge,
+			"chat_tag":                  perk.ChatTag,
+			"reserved_servers":          perk.ReservedServers,
+			"lapsed_on":                 perk.LapsedOn,
+			"created_on":                perk.CreatedOn,
+			"updated_on":                perk.UpdatedOn,
+		}).
+		Suffix(`ON CONFLICT (steam_id) DO UPDATE SET patreon_id = EXCLUDED.patreon_id, tier_id = EXCLUDED.tier_id,
+			previous_permission_level = EXCLUDED.previous_permission_level, discord_role_id = EXCLUDED.discord_role_id,
+			forum_badge = EXCLUDED.forum_badge, chat_tag = EXCLUDED.chat_tag,
+			reserved_servers = EXCLUDED.reserved_servers, lapsed_on = EXCLUDED.lapsed_on,
+			updated_on = EXCLUDED.updated_on`))
+}
+
+func (db *Store) DeletePatreonPerk(ctx context.Context, steamID steamid.SID64) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("patreon_perk").
+		Where(sq.Eq{"steam_id": steamID.Int64()}))
+}
+
+// PatreonPerkLog is an audit entry for a single perk being granted or revoked.
+type PatreonPerkLog struct {
+	PatreonPerkLogID int64         `json:"patreon_perk_log_id"`
+	SteamID          steamid.SID64 `json:"steam_id"`
+	PatreonID        string        `json:"patreon_id"`
+	TierID           string        `json:"tier_id"`
+	Action           string        `json:"action"`
+	Perk             string        `json:"perk"`
+	Value            string        `json:"value"`
+	CreatedOn        time.Time     `json:"created_on"`
+}
+
+func (db *Store) AddPatreonPerkLog(ctx context.Context, entry *PatreonPerkLog) error {
+	if entry.CreatedOn.IsZero() {
+		entry.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("patreon_perk_log").
+		SetMap(map[string]interface{}{
+			"steam_id":   entry.SteamID.Int64(),
+			"patreon_id": entry.PatreonID,
+			"tier_id":    entry.TierID,
+			"action":     entry.Action,
+			"perk":       entry.Perk,
+			"value":      entry.Value,
+			"created_on": entry.CreatedOn,
+		}).
+		Suffix("RETURNING patreon_perk_log_id"), &entry.PatreonPerkLogID)
+}
+
+type PatreonPerkLogQueryFilter struct {
+	QueryFilter
+	SteamID StringSID `json:"steam_id"`
+}
+
+func (db *Store) GetPatreonPerkLogs(ctx context.Context, filter PatreonPerkLogQueryFilter) ([]PatreonPerkLog, int64, error) {
+	constraints := sq.And{}
+
+	if filter.SteamID != "" {
+		steamID, errSteamID := filter.SteamID.SID64(ctx)
+		if errSteamID != nil {
+			return nil, 0, errors.Wrap(errSteamID, "Invalid steam id")
+		}
+
+		constraints = append(constraints, sq.Eq{"steam_id": steamID.Int64()})
+	}
+
+	builder := db.sb.
+		Select("patreon_perk_log_id", "steam_id", "patreon_id", "tier_id", "action", "perk", "value", "created_on").
+		From("patreon_perk_log").
+		Where(constraints)
+
+	builder = filter.applySafeOrder(builder, map[string][]string{
+		"": {"patreon_perk_log_id", "created_on", "steam_id", "tier_id"},
+	}, "patreon_perk_log_id")
+
+	entries := make([]PatreonPerkLog, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return entries, 0, nil
+		}
+
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			entry   PatreonPerkLog
+			steamID int64
+		)
+
+		if errScan := rows.Scan(&entry.PatreonPerkLogID, &steamID, &entry.PatreonID, &entry.TierID, &entry.Action,
+			&entry.Perk, &entry.Value, &entry.CreatedOn); errScan != nil {
+			return nil, 0, errors.Wrap(Err(errScan), "Failed to scan patreon perk log")
+		}
+
+		entry.SteamID = steamid.New(steamID)
+
+		entries = append(entries, entry)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("COUNT(patreon_perk_log_id)").
+		From("patreon_perk_log").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return entries, count, nil
+}
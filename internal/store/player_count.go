@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+// PlayerCountResolution is the period covered by a single player count bucket. Samples are recorded each minute
+// and downsampled into the coarser resolutions as they age.
+type PlayerCountResolution string
+
+const (
+	PlayerCountMinute PlayerCountResolution = "minute"
+	PlayerCountHour   PlayerCountResolution = "hour"
+	PlayerCountDay    PlayerCountResolution = "day"
+)
+
+var ErrInvalidResolution = errors.New("Invalid player count resolution")
+
+// Truncate returns the start of the bucket which contains the time.
+func (r PlayerCountResolution) Truncate(t time.Time) time.Time {
+	switch r {
+	case PlayerCountHour:
+		return t.UTC().Truncate(time.Hour)
+	case PlayerCountDay:
+		year, month, day := t.UTC().Date()
+
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	default:
+		return t.UTC().Truncate(time.Minute)
+	}
+}
+
+func (r PlayerCountResolution) Valid() bool {
+	return r == PlayerCountMinute || r == PlayerCountHour || r == PlayerCountDay
+}
+
+// PlayerCountBucket holds the player counts of a server sampled over the period of the bucket.
+type PlayerCountBucket struct {
+	ServerID   int                   `json:"server_id"`
+	Resolution PlayerCountResolution `json:"resolution"`
+	Bucket     time.Time             `json:"bucket"`
+	Samples    int                   `json:"samples"`
+	Peak       int                   `json:"peak"`
+	PlayerSum  int64                 `json:"-"`
+	// Histogram is the number of samples at each player count, indexed by player count.
+	Histogram []int32 `json:"-"`
+}
+
+// NewPlayerCountSample creates a minute bucket containing a single sample.
+func NewPlayerCountSample(serverID int, players int, sampledOn time.Time) PlayerCountBucket {
+	if players < 0 {
+		players = 0
+	}
+
+	histogram := make([]int32, players+1)
+	histogram[players] = 1
+
+	return PlayerCountBucket{
+		ServerID:   serverID,
+		Resolution: PlayerCountMinute,
+		Bucket:     PlayerCountMinute.Truncate(sampledOn),
+		Samples:    1,
+		Peak:       players,
+		PlayerSum:  int64(players),
+		Histogram:  histogram,
+	}
+}
+
+func (b PlayerCountBucket) Average() float64 {
+	if b.Samples == 0 {
+		return 0
+	}
+
+	return float64(b.PlayerSum) / float64(b.Samples)
+}
+
+func (b *PlayerCountBucket) merge(other PlayerCountBucket) {
+	b.Samples += other.Samples
+	b.PlayerSum += other.PlayerSum
+
+	if other.Peak > b.Peak {
+		b.Peak = other.Peak
+	}
+
+	if len(other.Histogram) > len(b.Histogram) {
+		b.Histogram = append(b.Histogram, make([]int32, len(other.Histogram)-len(b.Histogram))...)
+	}
+
+	for players, count := range other.Histogram {
+		b.Histogram[players] += count
+	}
+}
+
+// DownsamplePlayerCounts merges the buckets into buckets of the coarser resolution. The result is sorted by server
+// and then bucket.
+func DownsamplePlayerCounts(buckets []PlayerCountBucket, resolution PlayerCountResolution) []PlayerCountBucket {
+	type key struct {
+		serverID int
+		bucket   time.Time
+	}
+
+	merged := map[key]*PlayerCountBucket{}
+
+	for _, bucket := range buckets {
+		bucketKey := key{serverID: bucket.ServerID, bucket: resolution.Truncate(bucket.Bucket)}
+
+		existing, found := merged[bucketKey]
+		if !found {
+			existing = &PlayerCountBucket{ServerID: bucket.ServerID, Resolution: resolution, Bucket: bucketKey.bucket}
+			merged[bucketKey] = existing
+		}
+
+		existing.merge(bucket)
+	}
+
+	results := make([]PlayerCountBucket, 0, len(merged))
+	for _, bucket := range merged {
+		results = append(results, *bucket)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ServerID != results[j].ServerID {
+			return results[i].ServerID < results[j].ServerID
+		}
+
+		return results[i].Bucket.Before(results[j].Bucket)
+	})
+
+	return results
+}
+
+// PlayerCountSummary describes the distribution of player counts over a period.
+type PlayerCountSummary struct {
+	Samples int     `json:"samples"`
+	Peak    int     `json:"peak"`
+	Average float64 `json:"average"`
+	P50     int     `json:"p50"`
+	P90     int     `json:"p90"`
+	P95     int     `json:"p95"`
+	P99     int     `json:"p99"`
+}
+
+// SummarizePlayerCounts calculates the summary of all the samples within the buckets. Percentiles use the
+// nearest rank method.
+func SummarizePlayerCounts(buckets []PlayerCountBucket) PlayerCountSummary {
+	var total PlayerCountBucket
+	for _, bucket := range buckets {
+		total.merge(bucket)
+	}
+
+	summary := PlayerCountSummary{
+		Samples: total.Samples,
+		Peak:    total.Peak,
+		Average: total.Average(),
+	}
+
+	if total.Samples == 0 {
+		return summary
+	}
+
+	percentile := func(pct float64) int {
+		rank := int64(math.Ceil(pct * float64(total.Samples)))
+		if rank < 1 {
+			rank = 1
+		}
+
+		var seen int64
+
+		for players, count := range total.Histogram {
+			seen += int64(count)
+			if seen >= rank {
+				return players
+			}
+		}
+
+		return total.Peak
+	}
+
+	summary.P50 = percentile(0.50)
+	summary.P90 = percentile(0.90)
+	summary.P95 = percentile(0.95)
+	summary.P99 = percentile(0.99)
+
+	return summary
+}
+
+// SavePlayerCountBuckets inserts the buckets, replacing any existing buckets for the same period.
+func (db *Store) SavePlayerCountBuckets(ctx context.Context, buckets []PlayerCountBucket) error {
+	const batchSize = 500
+
+	for start := 0; start < len(buckets); start += batchSize {
+		builder := db.sb.
+			Insert("server_player_count").
+			Columns("server_id", "resolution", "bucket", "samples", "peak", "player_sum", "histogram")
+
+		for _, bucket := range buckets[start:min(start+batchSize, len(buckets))] {
+			builder = builder.Values(bucket.ServerID, bucket.Resolution, bucket.Bucket, bucket.Samples, bucket.Peak,
+				bucket.PlayerSum, bucket.Histogram)
+		}
+
+		if errInsert := db.ExecInsertBuilder(ctx, builder.
+			Suffix(`ON CONFLICT (server_id, resolution, bucket) DO UPDATE SET samples = EXCLUDED.samples,
+				peak = EXCLUDED.peak, player_sum = EXCLUDED.player_sum, histogram = EXCLUDED.histogram`)); errInsert != nil {
+			return errInsert
+		}
+	}
+
+	return nil
+}
+
+type PlayerCountQuery struct {
+	ServerIDs  []int                 `json:"server_ids"`
+	Region     string                `json:"region"`
+	Resolution PlayerCountResolution `json:"resolution"`
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+}
+
+// GetPlayerCountBuckets returns the buckets within the time range, ordered by server and then bucket.
+func (db *Store) GetPlayerCountBuckets(ctx context.Context, query PlayerCountQuery) ([]PlayerCountBucket, error) {
+	if !query.Resolution.Valid() {
+		return nil, ErrInvalidResolution
+	}
+
+	constraints := sq.And{
+		sq.Eq{"c.resolution": query.Resolution},
+		sq.GtOrEq{"c.bucket": query.From},
+		sq.Lt{"c.bucket": query.To},
+	}
+
+	if len(query.ServerIDs) > 0 {
+		constraints = append(constraints, sq.Eq{"c.server_id": query.ServerIDs})
+	}
+
+	if query.Region != "" {
+		constraints = append(constraints, sq.Eq{"s.region": query.Region})
+	}
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("c.server_id", "c.resolution", "c.bucket", "c.samples", "c.peak", "c.player_sum", "c.histogram").
+		From("server_player_count c").
+		InnerJoin("server s USING (server_id)").
+		Where(constraints).
+		OrderBy("c.server_id", "c.bucket"))
+	if errRows != nil {
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	buckets := make([]PlayerCountBucket, 0)
+
+	for rows.Next() {
+		var bucket PlayerCountBucket
+		if errScan := rows.Scan(&bucket.ServerID, &bucket.Resolution, &bucket.Bucket, &bucket.Samples, &bucket.Peak,
+			&bucket.PlayerSum, &bucket.Histogram); errScan != nil {
+			return nil, Err(errScan)
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	if rows.Err() != nil {
+		return nil, Err(rows.Err())
+	}
+
+	return buckets, nil
+}
+
+// DeletePlayerCountBuckets removes the buckets of the resolution which started before the time.
+func (db *Store) DeletePlayerCountBuckets(ctx context.Context, resolution PlayerCountResolution, before time.Time) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("server_player_count").
+		Where(sq.And{sq.Eq{"resolution": resolution}, sq.Lt{"bucket": before}}))
+}
@@ -0,0 +1,60 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsamplePlayerCounts(t *testing.T) {
+	start := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	var samples []store.PlayerCountBucket
+
+	for minute, players := range []int{10, 20, 30} {
+		samples = append(samples, store.NewPlayerCountSample(1, players, start.Add(time.Minute*time.Duration(minute))))
+	}
+
+	samples = append(samples,
+		store.NewPlayerCountSample(1, 4, start.Add(time.Hour)),
+		store.NewPlayerCountSample(2, 24, start))
+
+	hours := store.DownsamplePlayerCounts(samples, store.PlayerCountHour)
+	require.Len(t, hours, 3)
+	require.Equal(t, 1, hours[0].ServerID)
+	require.Equal(t, start, hours[0].Bucket)
+	require.Equal(t, 3, hours[0].Samples)
+	require.Equal(t, 30, hours[0].Peak)
+	require.InDelta(t, 20.0, hours[0].Average(), 0.001)
+	require.Equal(t, start.Add(time.Hour), hours[1].Bucket)
+	require.Equal(t, 2, hours[2].ServerID)
+
+	// Merging the hours again must give the same result as merging the samples directly
+	days := store.DownsamplePlayerCounts(hours, store.PlayerCountDay)
+	require.Equal(t, store.DownsamplePlayerCounts(samples, store.PlayerCountDay), days)
+	require.Len(t, days, 2)
+	require.Equal(t, 4, days[0].Samples)
+	require.Equal(t, store.PlayerCountDay, days[0].Resolution)
+	require.Equal(t, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), days[0].Bucket)
+}
+
+func TestSummarizePlayerCounts(t *testing.T) {
+	var samples []store.PlayerCountBucket
+
+	for players := 1; players <= 100; players++ {
+		samples = append(samples, store.NewPlayerCountSample(1, players, time.Now()))
+	}
+
+	summary := store.SummarizePlayerCounts(store.DownsamplePlayerCounts(samples, store.PlayerCountDay))
+	require.Equal(t, 100, summary.Samples)
+	require.Equal(t, 100, summary.Peak)
+	require.InDelta(t, 50.5, summary.Average, 0.001)
+	require.Equal(t, 50, summary.P50)
+	require.Equal(t, 90, summary.P90)
+	require.Equal(t, 95, summary.P95)
+	require.Equal(t, 99, summary.P99)
+
+	require.Equal(t, store.PlayerCountSummary{}, store.SummarizePlayerCounts(nil))
+}
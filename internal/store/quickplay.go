@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v3/steamid"
+)
+
+// QuickPlaySelection records the server chosen for a quickplay request so the ranking can be tuned.
+type QuickPlaySelection struct {
+	QuickPlaySelectionID int64         `json:"quickplay_selection_id"`
+	SteamID              steamid.SID64 `json:"steam_id"`
+	ServerID             int           `json:"server_id"`
+	GameModes            []string      `json:"game_modes"`
+	Maps                 []string      `json:"maps"`
+	Score                float64       `json:"score"`
+	Distance             float64       `json:"distance"`
+	Players              int           `json:"players"`
+	MaxPlayers           int           `json:"max_players"`
+	Candidates           int           `json:"candidates"`
+	CreatedOn            time.Time     `json:"created_on"`
+}
+
+func (db *Store) SaveQuickPlaySelection(ctx context.Context, selection *QuickPlaySelection) error {
+	if selection.CreatedOn.IsZero() {
+		selection.CreatedOn = time.Now()
+	}
+
+	if selection.GameModes == nil {
+		selection.GameModes = []string{}
+	}
+
+	if selection.Maps == nil {
+		selection.Maps = []string{}
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("quickplay_selection").
+		SetMap(map[string]interface{}{
+			"steam_id":    selection.SteamID.Int64(),
+			"server_id":   selection.ServerID,
+			"game_modes":  selection.GameModes,
+			"maps":        selection.Maps,
+			"score":       selection.Score,
+			"distance":    selection.Distance,
+			"players":     selection.Players,
+			"max_players": selection.MaxPlayers,
+			"candidates":  selection.Candidates,
+			"created_on":  selection.CreatedOn,
+		}).
+		Suffix("RETURNING quickplay_selection_id"), &selection.QuickPlaySelectionID)
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+// RconAudit is a record of a rcon command executed via the web console.
+type RconAudit struct {
+	RconAuditID int64         `json:"rcon_audit_id"`
+	SteamID     steamid.SID64 `json:"steam_id"`
+	PersonaName string        `json:"personaname"`
+	ServerID    int           `json:"server_id"`
+	ServerName  string        `json:"server_name"`
+	Command     string        `json:"command"`
+	Response    string        `json:"response"`
+	Error       string        `json:"error"`
+	CreatedOn   time.Time     `json:"created_on"`
+}
+
+type RconAuditQueryFilter struct {
+	QueryFilter
+	SteamID  StringSID `json:"steam_id,omitempty"`
+	ServerID int       `json:"server_id,omitempty"`
+}
+
+func (db *Store) SaveRconAudit(ctx context.Context, audit *RconAudit) error {
+	if audit.CreatedOn.IsZero() {
+		audit.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("rcon_audit").
+		SetMap(map[string]interface{}{
+			"steam_id":   audit.SteamID.Int64(),
+			"server_id":  audit.ServerID,
+			"command":    audit.Command,
+			"response":   audit.Response,
+			"error":      audit.Error,
+			"created_on": audit.CreatedOn,
+		}).
+		Suffix("RETURNING rcon_audit_id"), &audit.RconAuditID)
+}
+
+func (db *Store) GetRconAudits(ctx context.Context, filter RconAuditQueryFilter) ([]RconAudit, int64, error) {
+	var constraints sq.And
+
+	if filter.SteamID != "" {
+		steamID, errSteamID := filter.SteamID.SID64(ctx)
+		if errSteamID != nil {
+			return nil, 0, errSteamID
+		}
+
+		constraints = append(constraints, sq.Eq{"a.steam_id": steamID.Int64()})
+	}
+
+	if filter.ServerID > 0 {
+		constraints = append(constraints, sq.Eq{"a.server_id": filter.ServerID})
+	}
+
+	if filter.Query != "" {
+		constraints = append(constraints, sq.ILike{"a.command": "%" + filter.Query + "%"})
+	}
+
+	if filter.OrderBy == "" {
+		filter.Desc = true
+	}
+
+	builder := filter.applySafeOrder(db.sb.
+		Select("a.rcon_audit_id", "a.steam_id", "coalesce(p.personaname, '')", "a.server_id", "s.short_name",
+			"a.command", "a.response", "a.error", "a.created_on").
+		From("rcon_audit a").
+		LeftJoin("person p ON p.steam_id = a.steam_id").
+		LeftJoin("server s ON s.server_id = a.server_id").
+		Where(constraints), map[string][]string{
+		"a.": {"rcon_audit_id", "steam_id", "server_id", "command", "created_on"},
+	}, "created_on")
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	audits := make([]RconAudit, 0)
+
+	for rows.Next() {
+		var (
+			audit   RconAudit
+			steamID int64
+		)
+
+		if errScan := rows.Scan(&audit.RconAuditID, &steamID, &audit.PersonaName, &audit.ServerID,
+			&audit.ServerName, &audit.Command, &audit.Response, &audit.Error, &audit.CreatedOn); errScan != nil {
+			return nil, 0, errors.Wrap(Err(errScan), "Failed to scan rcon audit")
+		}
+
+		audit.SteamID = steamid.New(steamID)
+		audits = append(audits, audit)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("count(a.rcon_audit_id)").
+		From("rcon_audit a").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return audits, count, nil
+}
+
+// GetRconHistory returns the most recently used distinct commands of the user, newest first.
+func (db *Store) GetRconHistory(ctx context.Context, steamID steamid.SID64, limit uint64) ([]string, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("command").
+		From("rcon_audit").
+		Where(sq.Eq{"steam_id": steamID.Int64()}).
+		GroupBy("command").
+		OrderBy("max(created_on) DESC").
+		Limit(limit))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return []string{}, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	commands := make([]string, 0)
+
+	for rows.Next() {
+		var command string
+		if errScan := rows.Scan(&command); errScan != nil {
+			return nil, Err(errScan)
+		}
+
+		commands = append(commands, command)
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// RconTask is a sequence of rcon commands executed on a cron schedule against a set of servers.
+type RconTask struct {
+	RconTaskID int    `json:"rcon_task_id"`
+	Name       string `json:"name"`
+	// Schedule is a standard 5 field cron expression
+	Schedule       string   `json:"schedule"`
+	ServerIDs      []int    `json:"server_ids"`
+	ServerGroupIDs []int    `json:"server_group_ids"`
+	Region         string   `json:"region"`
+	AllServers     bool     `json:"all_servers"`
+	Commands       []string `json:"commands"`
+	// PlayersBelow skips servers which have this many players or more, 0 disables the check.
+	PlayersBelow int  `json:"players_below"`
+	Enabled      bool `json:"enabled"`
+	TimeStamped
+}
+
+// RconTaskRun records the result of a task running against a single server.
+type RconTaskRun struct {
+	RconTaskRunID int64     `json:"rcon_task_run_id"`
+	RconTaskID    int       `json:"rcon_task_id"`
+	TaskName      string    `json:"task_name"`
+	ServerID      int       `json:"server_id"`
+	ServerName    string    `json:"server_name"`
+	Skipped       bool      `json:"skipped"`
+	Output        string    `json:"output"`
+	Error         string    `json:"error"`
+	CreatedOn     time.Time `json:"created_on"`
+}
+
+type RconTaskRunQueryFilter struct {
+	QueryFilter
+	RconTaskID int `json:"rcon_task_id,omitempty"`
+	ServerID   int `json:"server_id,omitempty"`
+}
+
+var rconTaskColumns = []string{"rcon_task_id", "name", "schedule", "server_ids", "region", "all_servers", //nolint:gochecknoglobals
+	"commands", "players_below", "enabled", "created_on", "updated_on", "server_group_ids"}
+
+func scanRconTask(row pgx.Row, task *RconTask) error {
+	return row.Scan(&task.RconTaskID, &task.Name, &task.Schedule, &task.ServerIDs, &task.Region,
+		&task.AllServers, &task.Commands, &task.PlayersBelow, &task.Enabled, &task.CreatedOn, &task.UpdatedOn,
+		&task.ServerGroupIDs)
+}
+
+func (db *Store) GetRconTasks(ctx context.Context) ([]RconTask, error) {
+	tasks := make([]RconTask, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select(rconTaskColumns...).
+		From("rcon_task").
+		OrderBy("rcon_task_id"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return tasks, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var task RconTask
+		if errScan := scanRconTask(rows, &task); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan rcon task")
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func (db *Store) GetRconTask(ctx context.Context, taskID int, task *RconTask) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.sb.
+		Select(rconTaskColumns...).
+		From("rcon_task").
+		Where(sq.Eq{"rcon_task_id": taskID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return Err(scanRconTask(row, task))
+}
+
+func (db *Store) SaveRconTask(ctx context.Context, task *RconTask) error {
+	task.UpdatedOn = time.Now()
+
+	if task.ServerIDs == nil {
+		task.ServerIDs = []int{}
+	}
+
+	if task.ServerGroupIDs == nil {
+		task.ServerGroupIDs = []int{}
+	}
+
+	values := map[string]interface{}{
+		"name":             task.Name,
+		"schedule":         task.Schedule,
+		"server_ids":       task.ServerIDs,
+		"server_group_ids": task.ServerGroupIDs,
+		"region":           task.Region,
+		"all_servers":      task.AllServers,
+		"commands":         task.Commands,
+		"players_below":    task.PlayersBelow,
+		"enabled":          task.Enabled,
+		"updated_on":       task.UpdatedOn,
+	}
+
+	if task.RconTaskID > 0 {
+		return db.ExecUpdateBuilder(ctx, db.sb.
+			Update("rcon_task").
+			SetMap(values).
+			Where(sq.Eq{"rcon_task_id": task.RconTaskID}))
+	}
+
+	task.CreatedOn = task.UpdatedOn
+	values["created_on"] = task.CreatedOn
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("rcon_task").
+		SetMap(values).
+		Suffix("RETURNING rcon_task_id"), &task.RconTaskID)
+}
+
+func (db *Store) DeleteRconTask(ctx context.Context, taskID int) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("rcon_task").
+		Where(sq.Eq{"rcon_task_id": taskID}))
+}
+
+func (db *Store) SaveRconTaskRun(ctx context.Context, run *RconTaskRun) error {
+	if run.CreatedOn.IsZero() {
+		run.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("rcon_task_run").
+		SetMap(map[string]interface{}{
+			"rcon_task_id": run.RconTaskID,
+			"server_id":    run.ServerID,
+			"skipped":      run.Skipped,
+			"output":       run.Output,
+			"error":        run.Error,
+			"created_on":   run.CreatedOn,
+		}).
+		Suffix("RETURNING rcon_task_run_id"), &run.RconTaskRunID)
+}
+
+func (db *Store) GetRconTaskRuns(ctx context.Context, filter RconTaskRunQueryFilter) ([]RconTaskRun, int64, error) {
+	var constraints sq.And
+
+	if filter.RconTaskID > 0 {
+		constraints = append(constraints, sq.Eq{"r.rcon_task_id": filter.RconTaskID})
+	}
+
+	if filter.ServerID > 0 {
+		constraints = append(constraints, sq.Eq{"r.server_id": filter.ServerID})
+	}
+
+	if filter.OrderBy == "" {
+		filter.Desc = true
+	}
+
+	builder := filter.applySafeOrder(db.sb.
+		Select("r.rcon_task_run_id", "r.rcon_task_id", "t.name", "r.server_id", "s.short_name",
+			"r.skipped", "r.output", "r.error", "r.created_on").
+		From("rcon_task_run r").
+		LeftJoin("rcon_task t ON t.rcon_task_id = r.rcon_task_id").
+		LeftJoin("server s ON s.server_id = r.server_id").
+		Where(constraints), map[string][]string{
+		"r.": {"rcon_task_run_id", "rcon_task_id", "server_id", "skipped", "created_on"},
+	}, "created_on")
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	runs := make([]RconTaskRun, 0)
+
+	for rows.Next() {
+		var run RconTaskRun
+		if errScan := rows.Scan(&run.RconTaskRunID, &run.RconTaskID, &run.TaskName, &run.ServerID,
+			&run.ServerName, &run.Skipped, &run.Output, &run.Error, &run.CreatedOn); errScan != nil {
+			return nil, 0, errors.Wrap(Err(errScan), "Failed to scan rcon task run")
+		}
+
+		runs = append(runs, run)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("count(r.rcon_task_run_id)").
+		From("rcon_task_run r").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return runs, count, nil
+}
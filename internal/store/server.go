@@ -7,6 +7,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/leighmacdonald/gbans/internal/consts"
 	"github.com/leighmacdonald/steamid/v3/steamid"
 	"github.com/pkg/errors"
@@ -57,6 +58,8 @@ type Server struct {
 	Longitude   float64 `json:"longitude"`
 	LogSecret   int     `json:"log_secret"`
 	EnableStats bool    `json:"enable_stats"`
+	// PendingApproval is set for servers which enrolled themselves and have not yet been approved by an admin.
+	PendingApproval bool `json:"pending_approval"`
 	// TokenCreatedOn is set when changing the token
 	TokenCreatedOn time.Time `db:"token_created_on" json:"token_created_on"`
 	CreatedOn      time.Time `db:"created_on" json:"created_on"`
@@ -90,7 +93,7 @@ func (db *Store) GetServer(ctx context.Context, serverID int, server *Server) er
 	row, rowErr := db.QueryRowBuilder(ctx, db.sb.
 		Select("server_id", "short_name", "name", "address", "port", "rcon", "password",
 			"token_created_on", "created_on", "updated_on", "reserved_slots", "is_enabled", "region", "cc",
-			"latitude", "longitude", "deleted", "log_secret", "enable_stats").
+			"latitude", "longitude", "deleted", "log_secret", "enable_stats", "pending_approval").
 		From(string(tableServer)).
 		Where(sq.And{sq.Eq{"server_id": serverID}, sq.Eq{"deleted": false}}))
 	if rowErr != nil {
@@ -101,7 +104,7 @@ func (db *Store) GetServer(ctx context.Context, serverID int, server *Server) er
 		&server.Password, &server.TokenCreatedOn, &server.CreatedOn, &server.UpdatedOn,
 		&server.ReservedSlots, &server.IsEnabled, &server.Region, &server.CC,
 		&server.Latitude, &server.Longitude,
-		&server.Deleted, &server.LogSecret, &server.EnableStats); errScan != nil {
+		&server.Deleted, &server.LogSecret, &server.EnableStats, &server.PendingApproval); errScan != nil {
 		return Err(errScan)
 	}
 
@@ -162,7 +165,7 @@ func (db *Store) GetServers(ctx context.Context, filter ServerQueryFilter) ([]Se
 	builder := db.sb.
 		Select("s.server_id", "s.short_name", "s.name", "s.address", "s.port", "s.rcon", "s.password",
 			"s.token_created_on", "s.created_on", "s.updated_on", "s.reserved_slots", "s.is_enabled", "s.region", "s.cc",
-			"s.latitude", "s.longitude", "s.deleted", "s.log_secret", "s.enable_stats").
+			"s.latitude", "s.longitude", "s.deleted", "s.log_secret", "s.enable_stats", "s.pending_approval").
 		From("server s")
 
 	var constraints sq.And
@@ -200,7 +203,7 @@ func (db *Store) GetServers(ctx context.Context, filter ServerQueryFilter) ([]Se
 			Scan(&server.ServerID, &server.ShortName, &server.Name, &server.Address, &server.Port, &server.RCON,
 				&server.Password, &server.TokenCreatedOn, &server.CreatedOn, &server.UpdatedOn, &server.ReservedSlots,
 				&server.IsEnabled, &server.Region, &server.CC, &server.Latitude, &server.Longitude,
-				&server.Deleted, &server.LogSecret, &server.EnableStats); errScan != nil {
+				&server.Deleted, &server.LogSecret, &server.EnableStats, &server.PendingApproval); errScan != nil {
 			return nil, 0, errors.Wrap(errScan, "Failed to scan server")
 		}
 
@@ -235,7 +238,7 @@ func (db *Store) GetServerByName(ctx context.Context, serverName string, server
 	row, errRow := db.QueryRowBuilder(ctx, db.sb.
 		Select("server_id", "short_name", "name", "address", "port", "rcon", "password",
 			"token_created_on", "created_on", "updated_on", "reserved_slots", "is_enabled", "region", "cc",
-			"latitude", "longitude", "deleted", "log_secret", "enable_stats").
+			"latitude", "longitude", "deleted", "log_secret", "enable_stats", "pending_approval").
 		From(string(tableServer)).
 		Where(and))
 	if errRow != nil {
@@ -251,7 +254,7 @@ func (db *Store) GetServerByName(ctx context.Context, serverName string, server
 		&server.RCON,
 		&server.Password, &server.TokenCreatedOn, &server.CreatedOn, &server.UpdatedOn, &server.ReservedSlots,
 		&server.IsEnabled, &server.Region, &server.CC, &server.Latitude, &server.Longitude,
-		&server.Deleted, &server.LogSecret, &server.EnableStats))
+		&server.Deleted, &server.LogSecret, &server.EnableStats, &server.PendingApproval))
 }
 
 func (db *Store) GetServerByPassword(ctx context.Context, serverPassword string, server *Server, disabledOk bool, deletedOk bool) error {
@@ -267,7 +270,7 @@ func (db *Store) GetServerByPassword(ctx context.Context, serverPassword string,
 	row, errRow := db.QueryRowBuilder(ctx, db.sb.
 		Select("server_id", "short_name", "name", "address", "port", "rcon", "password",
 			"token_created_on", "created_on", "updated_on", "reserved_slots", "is_enabled", "region", "cc",
-			"latitude", "longitude", "deleted", "log_secret", "enable_stats").
+			"latitude", "longitude", "deleted", "log_secret", "enable_stats", "pending_approval").
 		From(string(tableServer)).
 		Where(and))
 	if errRow != nil {
@@ -283,34 +286,66 @@ func (db *Store) GetServerByPassword(ctx context.Context, serverPassword string,
 		&server.RCON,
 		&server.Password, &server.TokenCreatedOn, &server.CreatedOn, &server.UpdatedOn, &server.ReservedSlots,
 		&server.IsEnabled, &server.Region, &server.CC, &server.Latitude, &server.Longitude,
-		&server.Deleted, &server.LogSecret, &server.EnableStats))
+		&server.Deleted, &server.LogSecret, &server.EnableStats, &server.PendingApproval))
 }
 
-// SaveServer updates or creates the server data in the database.
+// SaveServer updates or creates the server data in the database, along with the credentials matching its password
+// and log secret. ErrDuplicate is returned when either secret already belongs to another server.
 func (db *Store) SaveServer(ctx context.Context, server *Server) error {
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create server tx")
+	}
+
+	if errSave := db.saveServer(ctx, transaction, server); errSave != nil {
+		db.rollback(ctx, transaction)
+
+		return errSave
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrap(errCommit, "Failed to commit server")
+	}
+
+	return nil
+}
+
+// saveServer writes the server and its credentials within the transaction, so it can be part of a larger operation.
+func (db *Store) saveServer(ctx context.Context, transaction pgx.Tx, server *Server) error {
+	shared, errShared := db.checkServerSecrets(ctx, transaction, server)
+	if errShared != nil {
+		return errShared
+	}
+
 	server.UpdatedOn = time.Now()
 	if server.ServerID > 0 {
-		return db.updateServer(ctx, server)
-	}
+		if errUpdate := db.updateServer(ctx, transaction, server); errUpdate != nil {
+			return errUpdate
+		}
+	} else {
+		server.CreatedOn = time.Now()
 
-	server.CreatedOn = time.Now()
+		if errInsert := db.insertServer(ctx, transaction, server); errInsert != nil {
+			return errInsert
+		}
+	}
 
-	return db.insertServer(ctx, server)
+	return db.syncServerCredentials(ctx, transaction, server, shared)
 }
 
-func (db *Store) insertServer(ctx context.Context, server *Server) error {
+func (db *Store) insertServer(ctx context.Context, transaction pgx.Tx, server *Server) error {
 	const query = `
 		INSERT INTO server (
 		    short_name, name, address, port, rcon, token_created_on, 
 		    reserved_slots, created_on, updated_on, password, is_enabled, region, cc, latitude, longitude, 
-			deleted, log_secret, enable_stats) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			deleted, log_secret, enable_stats, pending_approval) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING server_id;`
 
-	err := db.QueryRow(ctx, query, server.ShortName, server.Name, server.Address, server.Port,
+	err := transaction.QueryRow(ctx, query, server.ShortName, server.Name, server.Address, server.Port,
 		server.RCON, server.TokenCreatedOn, server.ReservedSlots, server.CreatedOn, server.UpdatedOn,
 		server.Password, server.IsEnabled, server.Region, server.CC,
-		server.Latitude, server.Longitude, server.Deleted, &server.LogSecret, &server.EnableStats).
+		server.Latitude, server.Longitude, server.Deleted, &server.LogSecret, &server.EnableStats, server.PendingApproval).
 		Scan(&server.ServerID)
 	if err != nil {
 		return Err(err)
@@ -319,10 +354,10 @@ func (db *Store) insertServer(ctx context.Context, server *Server) error {
 	return nil
 }
 
-func (db *Store) updateServer(ctx context.Context, server *Server) error {
+func (db *Store) updateServer(ctx context.Context, transaction pgx.Tx, server *Server) error {
 	server.UpdatedOn = time.Now()
 
-	return db.ExecUpdateBuilder(ctx, db.sb.
+	return txExec(ctx, transaction, db.sb.
 		Update("server").
 		Set("short_name", server.ShortName).
 		Set("name", server.Name).
@@ -341,6 +376,7 @@ func (db *Store) updateServer(ctx context.Context, server *Server) error {
 		Set("longitude", server.Longitude).
 		Set("log_secret", server.LogSecret).
 		Set("enable_stats", server.EnableStats).
+		Set("pending_approval", server.PendingApproval).
 		Where(sq.Eq{"server_id": server.ServerID}))
 }
 
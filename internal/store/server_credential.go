@@ -0,0 +1,529 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+type ServerCredentialKind string
+
+const (
+	// ServerCredentialKey is used by the server plugin to authenticate and obtain access tokens.
+	ServerCredentialKey ServerCredentialKind = "key"
+	// ServerCredentialLogSecret is the sv_logsecret used to identify the servers udp log stream.
+	ServerCredentialLogSecret ServerCredentialKind = "log_secret"
+)
+
+// ServerCredential is a secret belonging to a single server. When rotated, the previous credential remains
+// valid until ExpiresOn so that the server can be updated without any downtime.
+type ServerCredential struct {
+	ServerCredentialID int64                `json:"server_credential_id"`
+	ServerID           int                  `json:"server_id"`
+	Kind               ServerCredentialKind `json:"kind"`
+	Secret             string               `json:"-"`
+	// SigningKey is used to sign the access tokens issued for the credential.
+	SigningKey string `json:"-"`
+	// Scopes limits the operations tokens issued for the credential can perform. Empty allows all.
+	Scopes     []string   `json:"scopes"`
+	Revoked    bool       `json:"revoked"`
+	ExpiresOn  *time.Time `json:"expires_on"`
+	LastUsedOn *time.Time `json:"last_used_on"`
+	CreatedOn  time.Time  `json:"created_on"`
+}
+
+// Valid checks that the credential has been neither revoked nor expired.
+func (c ServerCredential) Valid(now time.Time) bool {
+	return !c.Revoked && (c.ExpiresOn == nil || now.Before(*c.ExpiresOn))
+}
+
+func newServerCredential(serverID int, kind ServerCredentialKind, secret string, scopes []string) ServerCredential {
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return ServerCredential{
+		ServerID:   serverID,
+		Kind:       kind,
+		Secret:     secret,
+		SigningKey: SecureRandomString(48),
+		Scopes:     scopes,
+		CreatedOn:  time.Now(),
+	}
+}
+
+func (db *Store) serverCredentialQuery() sq.SelectBuilder {
+	return db.sb.
+		Select("server_credential_id", "server_id", "kind", "secret", "signing_key", "scopes", "revoked",
+			"expires_on", "last_used_on", "created_on").
+		From("server_credential")
+}
+
+func scanServerCredential(row pgx.Row, credential *ServerCredential) error {
+	return Err(row.Scan(&credential.ServerCredentialID, &credential.ServerID, &credential.Kind, &credential.Secret,
+		&credential.SigningKey, &credential.Scopes, &credential.Revoked, &credential.ExpiresOn,
+		&credential.LastUsedOn, &credential.CreatedOn))
+}
+
+func (db *Store) queryServerCredentials(ctx context.Context, builder sq.SelectBuilder) ([]ServerCredential, error) {
+	credentials := make([]ServerCredential, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, builder)
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return credentials, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var credential ServerCredential
+		if errScan := scanServerCredential(rows, &credential); errScan != nil {
+			return nil, errors.Wrap(errScan, "Failed to scan server credential")
+		}
+
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, nil
+}
+
+func (db *Store) GetServerCredentials(ctx context.Context, serverID int) ([]ServerCredential, error) {
+	return db.queryServerCredentials(ctx, db.serverCredentialQuery().
+		Where(sq.Eq{"server_id": serverID}).
+		OrderBy("server_credential_id DESC"))
+}
+
+// GetValidServerCredentials returns all credentials of the kind which have not been revoked or expired.
+func (db *Store) GetValidServerCredentials(ctx context.Context, kind ServerCredentialKind, now time.Time) ([]ServerCredential, error) {
+	return db.queryServerCredentials(ctx, db.serverCredentialQuery().
+		Where(sq.And{
+			sq.Eq{"kind": kind, "revoked": false},
+			sq.Or{sq.Eq{"expires_on": nil}, sq.Gt{"expires_on": now}},
+		}))
+}
+
+func (db *Store) GetServerCredential(ctx context.Context, credentialID int64, credential *ServerCredential) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.serverCredentialQuery().
+		Where(sq.Eq{"server_credential_id": credentialID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return scanServerCredential(row, credential)
+}
+
+func (db *Store) GetServerCredentialBySecret(ctx context.Context, kind ServerCredentialKind, secret string, credential *ServerCredential) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.serverCredentialQuery().
+		Where(sq.Eq{"kind": kind, "secret": secret}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return scanServerCredential(row, credential)
+}
+
+func (db *Store) TouchServerCredential(ctx context.Context, credentialID int64, now time.Time) error {
+	return db.ExecUpdateBuilder(ctx, db.sb.
+		Update("server_credential").
+		Set("last_used_on", now).
+		Where(sq.Eq{"server_credential_id": credentialID}))
+}
+
+// RevokeServerCredential revokes the credential along with any access tokens issued for it.
+func (db *Store) RevokeServerCredential(ctx context.Context, credentialID int64) error {
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create server credential tx")
+	}
+
+	if errRevoke := txExec(ctx, transaction, db.sb.
+		Update("server_credential").
+		Set("revoked", true).
+		Where(sq.Eq{"server_credential_id": credentialID})); errRevoke != nil {
+		db.rollback(ctx, transaction)
+
+		return errRevoke
+	}
+
+	if errTokens := txExec(ctx, transaction, db.sb.
+		Update("server_token").
+		Set("revoked", true).
+		Where(sq.Eq{"server_credential_id": credentialID})); errTokens != nil {
+		db.rollback(ctx, transaction)
+
+		return errTokens
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrap(errCommit, "Failed to commit server credential revocation")
+	}
+
+	return nil
+}
+
+// RotateServerCredential replaces the current credential of the kind with a new secret. The previous credential
+// remains valid until overlapUntil. The server record is updated to reflect the new secret.
+func (db *Store) RotateServerCredential(ctx context.Context, server *Server, kind ServerCredentialKind,
+	secret string, scopes []string, overlapUntil time.Time,
+) (ServerCredential, error) {
+	credential := newServerCredential(server.ServerID, kind, secret, scopes)
+
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return credential, errors.Wrap(errTx, "Failed to create server credential tx")
+	}
+
+	if errExpire := txExec(ctx, transaction, db.sb.
+		Update("server_credential").
+		Set("expires_on", overlapUntil).
+		Where(sq.Eq{"server_id": server.ServerID, "kind": kind, "expires_on": nil})); errExpire != nil {
+		db.rollback(ctx, transaction)
+
+		return credential, errExpire
+	}
+
+	if errInsert := db.insertServerCredential(ctx, transaction, &credential); errInsert != nil {
+		db.rollback(ctx, transaction)
+
+		return credential, errInsert
+	}
+
+	server.UpdatedOn = credential.CreatedOn
+
+	update := db.sb.
+		Update("server").
+		Set("updated_on", server.UpdatedOn).
+		Where(sq.Eq{"server_id": server.ServerID})
+
+	switch kind {
+	case ServerCredentialKey:
+		server.Password = secret
+		update = update.Set("password", server.Password)
+	case ServerCredentialLogSecret:
+		logSecret, errLogSecret := strconv.Atoi(secret)
+		if errLogSecret != nil {
+			db.rollback(ctx, transaction)
+
+			return credential, errors.Wrap(errLogSecret, "Invalid log secret")
+		}
+
+		server.LogSecret = logSecret
+		update = update.Set("log_secret", server.LogSecret)
+	}
+
+	if errUpdate := txExec(ctx, transaction, update); errUpdate != nil {
+		db.rollback(ctx, transaction)
+
+		return credential, errUpdate
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return credential, errors.Wrap(errCommit, "Failed to commit server credential rotation")
+	}
+
+	return credential, nil
+}
+
+func (db *Store) insertServerCredential(ctx context.Context, transaction pgx.Tx, credential *ServerCredential) error {
+	query, args, errQuery := db.sb.
+		Insert("server_credential").
+		SetMap(map[string]interface{}{
+			"server_id":   credential.ServerID,
+			"kind":        credential.Kind,
+			"secret":      credential.Secret,
+			"signing_key": credential.SigningKey,
+			"scopes":      credential.Scopes,
+			"revoked":     credential.Revoked,
+			"expires_on":  credential.ExpiresOn,
+			"created_on":  credential.CreatedOn,
+		}).
+		Suffix("RETURNING server_credential_id").
+		ToSql()
+	if errQuery != nil {
+		return Err(errQuery)
+	}
+
+	return Err(transaction.QueryRow(ctx, query, args...).Scan(&credential.ServerCredentialID))
+}
+
+// serverSecrets returns the secrets set directly on the server which are used as credentials.
+func serverSecrets(server *Server) map[ServerCredentialKind]string {
+	secrets := map[ServerCredentialKind]string{}
+
+	if server.Password != "" {
+		secrets[ServerCredentialKey] = server.Password
+	}
+
+	if server.LogSecret > 0 {
+		secrets[ServerCredentialLogSecret] = strconv.Itoa(server.LogSecret)
+	}
+
+	return secrets
+}
+
+// checkServerSecrets returns ErrDuplicate when a secret of the server already belongs to another server. Servers
+// which shared a secret before credentials were introduced can still be saved as long as that secret is unchanged,
+// the kinds of these secrets are returned so no credential is created for them.
+func (db *Store) checkServerSecrets(ctx context.Context, transaction pgx.Tx, server *Server) (map[ServerCredentialKind]bool, error) {
+	previous := map[ServerCredentialKind]string{}
+
+	if server.ServerID > 0 {
+		var existing Server
+
+		query, args, errQuery := db.sb.
+			Select("password", "log_secret").
+			From("server").
+			Where(sq.Eq{"server_id": server.ServerID}).
+			ToSql()
+		if errQuery != nil {
+			return nil, Err(errQuery)
+		}
+
+		if errExisting := transaction.QueryRow(ctx, query, args...).
+			Scan(&existing.Password, &existing.LogSecret); errExisting != nil {
+			return nil, Err(errExisting)
+		}
+
+		previous = serverSecrets(&existing)
+	}
+
+	shared := map[ServerCredentialKind]bool{}
+
+	for kind, secret := range serverSecrets(server) {
+		query, args, errQuery := db.sb.
+			Select("server_id").
+			From("server_credential").
+			Where(sq.And{
+				sq.Eq{"kind": kind, "secret": secret},
+				sq.NotEq{"server_id": server.ServerID},
+			}).
+			Limit(1).
+			ToSql()
+		if errQuery != nil {
+			return nil, Err(errQuery)
+		}
+
+		var ownerID int
+		if errOwner := transaction.QueryRow(ctx, query, args...).Scan(&ownerID); errOwner != nil {
+			if errors.Is(Err(errOwner), ErrNoResult) {
+				continue
+			}
+
+			return nil, Err(errOwner)
+		}
+
+		if previous[kind] != secret {
+			return nil, ErrDuplicate
+		}
+
+		shared[kind] = true
+	}
+
+	return shared, nil
+}
+
+// syncServerCredentials makes sure the password and log secret set directly on the server have a matching
+// credential. Credentials replaced by editing the server, rather than rotating, are expired immediately. Secrets
+// which are shared with another server are skipped, they can only be resolved by setting a new secret.
+func (db *Store) syncServerCredentials(ctx context.Context, transaction pgx.Tx, server *Server,
+	shared map[ServerCredentialKind]bool,
+) error {
+	for kind, secret := range serverSecrets(server) {
+		if shared[kind] {
+			continue
+		}
+
+		var existingID int64
+
+		query, args, errQuery := db.sb.
+			Select("server_credential_id").
+			From("server_credential").
+			Where(sq.Eq{"server_id": server.ServerID, "kind": kind, "secret": secret}).
+			ToSql()
+		if errQuery != nil {
+			return Err(errQuery)
+		}
+
+		if errExisting := transaction.QueryRow(ctx, query, args...).Scan(&existingID); errExisting == nil {
+			continue
+		} else if !errors.Is(Err(errExisting), ErrNoResult) {
+			return Err(errExisting)
+		}
+
+		if errExpire := txExec(ctx, transaction, db.sb.
+			Update("server_credential").
+			Set("expires_on", server.UpdatedOn).
+			Where(sq.Eq{"server_id": server.ServerID, "kind": kind, "expires_on": nil})); errExpire != nil {
+			return errExpire
+		}
+
+		credential := newServerCredential(server.ServerID, kind, secret, nil)
+		if errInsert := db.insertServerCredential(ctx, transaction, &credential); errInsert != nil {
+			return errInsert
+		}
+	}
+
+	return nil
+}
+
+// ServerCredentialConflict is a server whose current secret has no credential because another server was already
+// using the same secret when credentials were introduced.
+type ServerCredentialConflict struct {
+	ServerID  int
+	ShortName string
+	Kind      ServerCredentialKind
+}
+
+// GetServerCredentialConflicts returns the servers whose password or log secret has no matching credential.
+func (db *Store) GetServerCredentialConflicts(ctx context.Context) ([]ServerCredentialConflict, error) {
+	const query = `
+		SELECT s.server_id, s.short_name, k.kind
+		FROM server s
+		CROSS JOIN LATERAL (VALUES ('key', s.password), ('log_secret', s.log_secret::text)) k(kind, secret)
+		WHERE k.secret NOT IN ('', '0') AND NOT EXISTS (
+			SELECT 1 FROM server_credential c
+			WHERE c.server_id = s.server_id AND c.kind = k.kind AND c.secret = k.secret)
+		ORDER BY s.server_id`
+
+	rows, errRows := db.Query(ctx, query)
+	if errRows != nil {
+		return nil, Err(errRows)
+	}
+
+	defer rows.Close()
+
+	var conflicts []ServerCredentialConflict
+
+	for rows.Next() {
+		var conflict ServerCredentialConflict
+		if errScan := rows.Scan(&conflict.ServerID, &conflict.ShortName, &conflict.Kind); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server credential conflict")
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts, nil
+}
+
+// ServerToken is an access token issued to a server after authenticating with a credential.
+type ServerToken struct {
+	ServerTokenID      uuid.UUID  `json:"server_token_id"`
+	ServerID           int        `json:"server_id"`
+	ServerCredentialID int64      `json:"server_credential_id"`
+	Scopes             []string   `json:"scopes"`
+	Revoked            bool       `json:"revoked"`
+	ExpiresOn          time.Time  `json:"expires_on"`
+	LastUsedOn         *time.Time `json:"last_used_on"`
+	CreatedOn          time.Time  `json:"created_on"`
+}
+
+func (db *Store) SaveServerToken(ctx context.Context, token *ServerToken) error {
+	if token.Scopes == nil {
+		token.Scopes = []string{}
+	}
+
+	return db.ExecInsertBuilder(ctx, db.sb.
+		Insert("server_token").
+		SetMap(map[string]interface{}{
+			"server_token_id":      token.ServerTokenID,
+			"server_id":            token.ServerID,
+			"server_credential_id": token.ServerCredentialID,
+			"scopes":               token.Scopes,
+			"revoked":              token.Revoked,
+			"expires_on":           token.ExpiresOn,
+			"created_on":           token.CreatedOn,
+		}))
+}
+
+// GetServerTokenSigningKey loads the token along with the signing key of the credential it was issued for. Tokens
+// which have been revoked, or whose credential has been revoked or has expired, are not returned.
+func (db *Store) GetServerTokenSigningKey(ctx context.Context, tokenID uuid.UUID, token *ServerToken) (string, error) {
+	row, errRow := db.QueryRowBuilder(ctx, db.sb.
+		Select("t.server_token_id", "t.server_id", "t.server_credential_id", "t.scopes", "t.revoked",
+			"t.expires_on", "t.last_used_on", "t.created_on", "c.signing_key").
+		From("server_token t").
+		InnerJoin("server_credential c ON c.server_credential_id = t.server_credential_id").
+		Where(sq.And{
+			sq.Eq{"t.server_token_id": tokenID, "t.revoked": false, "c.revoked": false},
+			sq.Or{sq.Eq{"c.expires_on": nil}, sq.Gt{"c.expires_on": time.Now()}},
+		}))
+	if errRow != nil {
+		return "", errRow
+	}
+
+	var signingKey string
+
+	if errScan := row.Scan(&token.ServerTokenID, &token.ServerID, &token.ServerCredentialID, &token.Scopes,
+		&token.Revoked, &token.ExpiresOn, &token.LastUsedOn, &token.CreatedOn, &signingKey); errScan != nil {
+		return "", Err(errScan)
+	}
+
+	return signingKey, nil
+}
+
+func (db *Store) GetServerTokens(ctx context.Context, serverID int, now time.Time) ([]ServerToken, error) {
+	tokens := make([]ServerToken, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("server_token_id", "server_id", "server_credential_id", "scopes", "revoked",
+			"expires_on", "last_used_on", "created_on").
+		From("server_token").
+		Where(sq.And{sq.Eq{"server_id": serverID}, sq.Gt{"expires_on": now}}).
+		OrderBy("created_on DESC"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return tokens, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var token ServerToken
+		if errScan := rows.Scan(&token.ServerTokenID, &token.ServerID, &token.ServerCredentialID, &token.Scopes,
+			&token.Revoked, &token.ExpiresOn, &token.LastUsedOn, &token.CreatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server token")
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// TouchServerToken updates the last used time of the token. To avoid a write for every request, it is only
+// updated once it is older than staleAfter.
+func (db *Store) TouchServerToken(ctx context.Context, tokenID uuid.UUID, now time.Time, staleAfter time.Duration) error {
+	return db.ExecUpdateBuilder(ctx, db.sb.
+		Update("server_token").
+		Set("last_used_on", now).
+		Where(sq.And{
+			sq.Eq{"server_token_id": tokenID},
+			sq.Or{sq.Eq{"last_used_on": nil}, sq.Lt{"last_used_on": now.Add(-staleAfter)}},
+		}))
+}
+
+func (db *Store) RevokeServerToken(ctx context.Context, tokenID uuid.UUID) error {
+	return db.ExecUpdateBuilder(ctx, db.sb.
+		Update("server_token").
+		Set("revoked", true).
+		Where(sq.Eq{"server_token_id": tokenID}))
+}
+
+func (db *Store) DeleteExpiredServerTokens(ctx context.Context, now time.Time) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("server_token").
+		Where(sq.Lt{"expires_on": now}))
+}
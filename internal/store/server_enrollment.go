@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+// ServerEnrollmentToken is a single use token which allows a new server to register itself. The values set on the
+// token are used as the defaults for the new server.
+type ServerEnrollmentToken struct {
+	ServerEnrollmentTokenID int64         `json:"server_enrollment_token_id"`
+	Token                   string        `json:"token"`
+	ShortName               string        `json:"short_name"`
+	Region                  string        `json:"region"`
+	CC                      string        `json:"cc"`
+	Latitude                float64       `json:"latitude"`
+	Longitude               float64       `json:"longitude"`
+	ReservedSlots           int           `json:"reserved_slots"`
+	EnableStats             bool          `json:"enable_stats"`
+	Note                    string        `json:"note"`
+	AuthorID                steamid.SID64 `json:"author_id"`
+	// ServerID is the server which was created using the token, if any.
+	ServerID  *int       `json:"server_id"`
+	UsedOn    *time.Time `json:"used_on"`
+	ExpiresOn time.Time  `json:"expires_on"`
+	CreatedOn time.Time  `json:"created_on"`
+}
+
+// Usable checks that the token has not been used already and has not expired.
+func (t ServerEnrollmentToken) Usable(now time.Time) bool {
+	return t.UsedOn == nil && now.Before(t.ExpiresOn)
+}
+
+func (db *Store) serverEnrollmentTokenQuery() sq.SelectBuilder {
+	return db.sb.
+		Select("server_enrollment_token_id", "token", "short_name", "region", "cc", "latitude", "longitude",
+			"reserved_slots", "enable_stats", "note", "author_id", "server_id", "used_on", "expires_on", "created_on").
+		From("server_enrollment_token")
+}
+
+func scanServerEnrollmentToken(row pgx.Row, token *ServerEnrollmentToken) error {
+	var authorID int64
+
+	if errScan := row.Scan(&token.ServerEnrollmentTokenID, &token.Token, &token.ShortName, &token.Region, &token.CC,
+		&token.Latitude, &token.Longitude, &token.ReservedSlots, &token.EnableStats, &token.Note, &authorID,
+		&token.ServerID, &token.UsedOn, &token.ExpiresOn, &token.CreatedOn); errScan != nil {
+		return Err(errScan)
+	}
+
+	token.AuthorID = steamid.New(authorID)
+
+	return nil
+}
+
+// NewServerEnrollmentToken creates a token which remains usable until the validity period has passed.
+func NewServerEnrollmentToken(authorID steamid.SID64, validFor time.Duration) ServerEnrollmentToken {
+	now := time.Now()
+
+	return ServerEnrollmentToken{
+		Token:       SecureRandomString(32),
+		AuthorID:    authorID,
+		EnableStats: true,
+		ExpiresOn:   now.Add(validFor),
+		CreatedOn:   now,
+	}
+}
+
+func (db *Store) SaveServerEnrollmentToken(ctx context.Context, token *ServerEnrollmentToken) error {
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("server_enrollment_token").
+		SetMap(map[string]interface{}{
+			"token":          token.Token,
+			"short_name":     token.ShortName,
+			"region":         token.Region,
+			"cc":             token.CC,
+			"latitude":       token.Latitude,
+			"longitude":      token.Longitude,
+			"reserved_slots": token.ReservedSlots,
+			"enable_stats":   token.EnableStats,
+			"note":           token.Note,
+			"author_id":      token.AuthorID.Int64(),
+			"expires_on":     token.ExpiresOn,
+			"created_on":     token.CreatedOn,
+		}).
+		Suffix("RETURNING server_enrollment_token_id"), &token.ServerEnrollmentTokenID)
+}
+
+func (db *Store) GetServerEnrollmentTokens(ctx context.Context) ([]ServerEnrollmentToken, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.serverEnrollmentTokenQuery().OrderBy("created_on DESC"))
+	if errRows != nil {
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	tokens := make([]ServerEnrollmentToken, 0)
+
+	for rows.Next() {
+		var token ServerEnrollmentToken
+		if errScan := scanServerEnrollmentToken(rows, &token); errScan != nil {
+			return nil, errScan
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if rows.Err() != nil {
+		return nil, Err(rows.Err())
+	}
+
+	return tokens, nil
+}
+
+func (db *Store) GetServerEnrollmentToken(ctx context.Context, tokenID int64, token *ServerEnrollmentToken) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.serverEnrollmentTokenQuery().
+		Where(sq.Eq{"server_enrollment_token_id": tokenID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return scanServerEnrollmentToken(row, token)
+}
+
+func (db *Store) DeleteServerEnrollmentToken(ctx context.Context, tokenID int64) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("server_enrollment_token").
+		Where(sq.Eq{"server_enrollment_token_id": tokenID}))
+}
+
+// EnrollServer consumes the token and creates the server using it. The server is created with pending approval
+// set and is disabled until approved. The token can only be used once, concurrent attempts will fail with ErrNoResult.
+// The token is only consumed when the server and its credentials are created successfully.
+func (db *Store) EnrollServer(ctx context.Context, tokenValue string, now time.Time,
+	createServer func(token ServerEnrollmentToken) Server,
+) (Server, error) {
+	query, args, errQuery := db.sb.
+		Update("server_enrollment_token").
+		Set("used_on", now).
+		Where(sq.And{sq.Eq{"token": tokenValue, "used_on": nil}, sq.Gt{"expires_on": now}}).
+		Suffix("RETURNING server_enrollment_token_id, token, short_name, region, cc, latitude, longitude, " +
+			"reserved_slots, enable_stats, note, author_id, server_id, used_on, expires_on, created_on").
+		ToSql()
+	if errQuery != nil {
+		return Server{}, Err(errQuery)
+	}
+
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return Server{}, errors.Wrap(errTx, "Failed to create enrollment tx")
+	}
+
+	var token ServerEnrollmentToken
+	if errClaim := scanServerEnrollmentToken(transaction.QueryRow(ctx, query, args...), &token); errClaim != nil {
+		db.rollback(ctx, transaction)
+
+		return Server{}, errClaim
+	}
+
+	server := createServer(token)
+	server.IsEnabled = false
+	server.PendingApproval = true
+
+	if errSave := db.saveServer(ctx, transaction, &server); errSave != nil {
+		db.rollback(ctx, transaction)
+
+		return server, errSave
+	}
+
+	if errUpdate := txExec(ctx, transaction, db.sb.
+		Update("server_enrollment_token").
+		Set("server_id", server.ServerID).
+		Where(sq.Eq{"server_enrollment_token_id": token.ServerEnrollmentTokenID})); errUpdate != nil {
+		db.rollback(ctx, transaction)
+
+		return server, errUpdate
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return server, errors.Wrap(errCommit, "Failed to commit server enrollment")
+	}
+
+	return server, nil
+}
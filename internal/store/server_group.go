@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+// ServerGroup is a named set of servers which can be targeted as a whole.
+type ServerGroup struct {
+	ServerGroupID int    `json:"server_group_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	ServerIDs     []int  `json:"server_ids"`
+	TimeStamped
+}
+
+func (db *Store) serverGroupQuery() sq.SelectBuilder {
+	return db.sb.
+		Select("g.server_group_id", "g.name", "g.description",
+			"coalesce(array_agg(m.server_id ORDER BY m.server_id) FILTER (WHERE m.server_id IS NOT NULL), '{}')",
+			"g.created_on", "g.updated_on").
+		From("server_group g").
+		LeftJoin("server_group_member m ON m.server_group_id = g.server_group_id").
+		GroupBy("g.server_group_id")
+}
+
+func (db *Store) GetServerGroups(ctx context.Context) ([]ServerGroup, error) {
+	groups := make([]ServerGroup, 0)
+
+	rows, errRows := db.QueryBuilder(ctx, db.serverGroupQuery().OrderBy("g.name"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return groups, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var group ServerGroup
+		if errScan := rows.Scan(&group.ServerGroupID, &group.Name, &group.Description, &group.ServerIDs,
+			&group.CreatedOn, &group.UpdatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server group")
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (db *Store) GetServerGroup(ctx context.Context, groupID int, group *ServerGroup) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.serverGroupQuery().Where(sq.Eq{"g.server_group_id": groupID}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return Err(row.Scan(&group.ServerGroupID, &group.Name, &group.Description, &group.ServerIDs,
+		&group.CreatedOn, &group.UpdatedOn))
+}
+
+func (db *Store) GetServerGroupByName(ctx context.Context, name string, group *ServerGroup) error {
+	row, errRow := db.QueryRowBuilder(ctx, db.serverGroupQuery().Where(sq.Eq{"g.name": name}))
+	if errRow != nil {
+		return errRow
+	}
+
+	return Err(row.Scan(&group.ServerGroupID, &group.Name, &group.Description, &group.ServerIDs,
+		&group.CreatedOn, &group.UpdatedOn))
+}
+
+// GetServerGroupServerIDs returns the unique ids of all servers which belong to any of the groups.
+func (db *Store) GetServerGroupServerIDs(ctx context.Context, groupIDs []int) ([]int, error) {
+	serverIDs := make([]int, 0)
+
+	if len(groupIDs) == 0 {
+		return serverIDs, nil
+	}
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("DISTINCT server_id").
+		From("server_group_member").
+		Where(sq.Eq{"server_group_id": groupIDs}).
+		OrderBy("server_id"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return serverIDs, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var serverID int
+		if errScan := rows.Scan(&serverID); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server group member")
+		}
+
+		serverIDs = append(serverIDs, serverID)
+	}
+
+	return serverIDs, nil
+}
+
+// SaveServerGroup creates or updates the group, replacing its existing members.
+func (db *Store) SaveServerGroup(ctx context.Context, group *ServerGroup) error {
+	group.UpdatedOn = time.Now()
+
+	transaction, errTx := db.conn.Begin(ctx)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to create server group tx")
+	}
+
+	if group.ServerGroupID > 0 {
+		if errUpdate := txExec(ctx, transaction, db.sb.
+			Update("server_group").
+			SetMap(map[string]interface{}{
+				"name":        group.Name,
+				"description": group.Description,
+				"updated_on":  group.UpdatedOn,
+			}).
+			Where(sq.Eq{"server_group_id": group.ServerGroupID})); errUpdate != nil {
+			db.rollback(ctx, transaction)
+
+			return errUpdate
+		}
+
+		if errDelete := txExec(ctx, transaction, db.sb.
+			Delete("server_group_member").
+			Where(sq.Eq{"server_group_id": group.ServerGroupID})); errDelete != nil {
+			db.rollback(ctx, transaction)
+
+			return errDelete
+		}
+	} else {
+		group.CreatedOn = group.UpdatedOn
+
+		query, args, errQuery := db.sb.
+			Insert("server_group").
+			SetMap(map[string]interface{}{
+				"name":        group.Name,
+				"description": group.Description,
+				"created_on":  group.CreatedOn,
+				"updated_on":  group.UpdatedOn,
+			}).
+			Suffix("RETURNING server_group_id").
+			ToSql()
+		if errQuery != nil {
+			db.rollback(ctx, transaction)
+
+			return Err(errQuery)
+		}
+
+		if errInsert := transaction.QueryRow(ctx, query, args...).Scan(&group.ServerGroupID); errInsert != nil {
+			db.rollback(ctx, transaction)
+
+			return Err(errInsert)
+		}
+	}
+
+	if len(group.ServerIDs) > 0 {
+		builder := db.sb.
+			Insert("server_group_member").
+			Columns("server_group_id", "server_id")
+
+		for _, serverID := range group.ServerIDs {
+			builder = builder.Values(group.ServerGroupID, serverID)
+		}
+
+		if errMembers := txExec(ctx, transaction, builder); errMembers != nil {
+			db.rollback(ctx, transaction)
+
+			return errMembers
+		}
+	}
+
+	if errCommit := transaction.Commit(ctx); errCommit != nil {
+		return errors.Wrap(errCommit, "Failed to commit server group")
+	}
+
+	return nil
+}
+
+func (db *Store) DeleteServerGroup(ctx context.Context, groupID int) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("server_group").
+		Where(sq.Eq{"server_group_id": groupID}))
+}
+
+func (db *Store) AddServerGroupMember(ctx context.Context, groupID int, serverID int) error {
+	return db.ExecInsertBuilder(ctx, db.sb.
+		Insert("server_group_member").
+		Columns("server_group_id", "server_id").
+		Values(groupID, serverID).
+		Suffix("ON CONFLICT DO NOTHING"))
+}
+
+func (db *Store) RemoveServerGroupMember(ctx context.Context, groupID int, serverID int) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("server_group_member").
+		Where(sq.And{sq.Eq{"server_group_id": groupID}, sq.Eq{"server_id": serverID}}))
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+// HealthState is the overall health of a game server.
+type HealthState int
+
+const (
+	HealthUp HealthState = iota
+	HealthDegraded
+	HealthDown
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthUp:
+		return "Up"
+	case HealthDegraded:
+		return "Degraded"
+	case HealthDown:
+		return "Down"
+	default:
+		return "Unknown"
+	}
+}
+
+// ServerHealthEvent records a change of a servers health state. The server remains in the state until the next
+// event is recorded.
+type ServerHealthEvent struct {
+	ServerHealthID int64       `json:"server_health_id"`
+	ServerID       int         `json:"server_id"`
+	State          HealthState `json:"state"`
+	Reason         string      `json:"reason"`
+	CreatedOn      time.Time   `json:"created_on"`
+}
+
+func (db *Store) SaveServerHealthEvent(ctx context.Context, event *ServerHealthEvent) error {
+	if event.CreatedOn.IsZero() {
+		event.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("server_health").
+		SetMap(map[string]interface{}{
+			"server_id":  event.ServerID,
+			"state":      event.State,
+			"reason":     event.Reason,
+			"created_on": event.CreatedOn,
+		}).
+		Suffix("RETURNING server_health_id"), &event.ServerHealthID)
+}
+
+// GetServerHealthLatest returns the most recent health event for each server.
+func (db *Store) GetServerHealthLatest(ctx context.Context) (map[int]ServerHealthEvent, error) {
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("DISTINCT ON (server_id) server_health_id", "server_id", "state", "reason", "created_on").
+		From("server_health").
+		OrderBy("server_id", "created_on DESC"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return map[int]ServerHealthEvent{}, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	events := map[int]ServerHealthEvent{}
+
+	for rows.Next() {
+		var event ServerHealthEvent
+		if errScan := rows.Scan(&event.ServerHealthID, &event.ServerID, &event.State,
+			&event.Reason, &event.CreatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server health event")
+		}
+
+		events[event.ServerID] = event
+	}
+
+	return events, nil
+}
+
+// GetServerHealthEvents returns the health events of a server since the time provided, oldest first. The last
+// event before since is included so that the state at the start of the period is known. A serverID of 0
+// returns the events for all servers.
+func (db *Store) GetServerHealthEvents(ctx context.Context, serverID int, since time.Time) ([]ServerHealthEvent, error) {
+	var constraints sq.And
+
+	if serverID > 0 {
+		constraints = append(constraints, sq.Eq{"h.server_id": serverID})
+	}
+
+	constraints = append(constraints, sq.Or{
+		sq.GtOrEq{"h.created_on": since},
+		sq.Expr(`h.created_on = (SELECT max(p.created_on) FROM server_health p
+			WHERE p.server_id = h.server_id AND p.created_on < ?)`, since),
+	})
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("h.server_health_id", "h.server_id", "h.state", "h.reason", "h.created_on").
+		From("server_health h").
+		Where(constraints).
+		OrderBy("h.created_on"))
+	if errRows != nil {
+		if errors.Is(errRows, ErrNoResult) {
+			return []ServerHealthEvent{}, nil
+		}
+
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	events := make([]ServerHealthEvent, 0)
+
+	for rows.Next() {
+		var event ServerHealthEvent
+		if errScan := rows.Scan(&event.ServerHealthID, &event.ServerID, &event.State,
+			&event.Reason, &event.CreatedOn); errScan != nil {
+			return nil, errors.Wrap(Err(errScan), "Failed to scan server health event")
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
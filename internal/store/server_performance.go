@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ServerPerformanceSample is a single reading of the stats and net_status commands of a server.
+type ServerPerformanceSample struct {
+	ServerID  int       `json:"server_id"`
+	CreatedOn time.Time `json:"created_on"`
+	MapName   string    `json:"map_name"`
+	Players   int       `json:"players"`
+	CPU       float64   `json:"cpu"`
+	FPS       float64   `json:"fps"`
+	// FrameMS is the average time taken to simulate a server frame.
+	FrameMS float64 `json:"frame_ms"`
+	// VarMS is the variance of the frame time, high values are felt as lag by players.
+	VarMS      float64 `json:"var_ms"`
+	TickMS     float64 `json:"tick_ms"`
+	NetInKBps  float64 `json:"net_in_kbps"`
+	NetOutKBps float64 `json:"net_out_kbps"`
+	PacketsIn  float64 `json:"packets_in"`
+	PacketsOut float64 `json:"packets_out"`
+	LossIn     float64 `json:"loss_in"`
+	LossOut    float64 `json:"loss_out"`
+	ChokeIn    float64 `json:"choke_in"`
+	ChokeOut   float64 `json:"choke_out"`
+	// UptimeMinutes is how long the server process has been running.
+	UptimeMinutes int `json:"uptime_minutes"`
+	// TickHealth is the ratio of the FPS to the expected tick rate, 1.0 or higher is healthy.
+	TickHealth float64 `json:"tick_health"`
+	LagSpike   bool    `json:"lag_spike"`
+}
+
+func (db *Store) SaveServerPerformanceSamples(ctx context.Context, samples []ServerPerformanceSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	builder := db.sb.
+		Insert("server_performance").
+		Columns("server_id", "created_on", "map_name", "players", "cpu", "fps", "frame_ms", "var_ms", "tick_ms",
+			"net_in_kbps", "net_out_kbps", "packets_in", "packets_out", "loss_in", "loss_out", "choke_in",
+			"choke_out", "uptime_minutes", "tick_health", "lag_spike")
+
+	for _, sample := range samples {
+		builder = builder.Values(sample.ServerID, sample.CreatedOn, sample.MapName, sample.Players, sample.CPU,
+			sample.FPS, sample.FrameMS, sample.VarMS, sample.TickMS, sample.NetInKBps, sample.NetOutKBps,
+			sample.PacketsIn, sample.PacketsOut, sample.LossIn, sample.LossOut, sample.ChokeIn, sample.ChokeOut,
+			sample.UptimeMinutes, sample.TickHealth, sample.LagSpike)
+	}
+
+	return db.ExecInsertBuilder(ctx, builder.Suffix("ON CONFLICT (server_id, created_on) DO NOTHING"))
+}
+
+type ServerPerformanceQuery struct {
+	ServerIDs []int     `json:"server_ids"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	// LagSpikesOnly limits the results to the samples which were flagged as lag spikes.
+	LagSpikesOnly bool `json:"lag_spikes_only"`
+}
+
+// GetServerPerformanceSamples returns the samples within the time range, ordered by server and then time.
+func (db *Store) GetServerPerformanceSamples(ctx context.Context, query ServerPerformanceQuery) ([]ServerPerformanceSample, error) {
+	constraints := sq.And{sq.GtOrEq{"created_on": query.From}, sq.Lt{"created_on": query.To}}
+
+	if len(query.ServerIDs) > 0 {
+		constraints = append(constraints, sq.Eq{"server_id": query.ServerIDs})
+	}
+
+	if query.LagSpikesOnly {
+		constraints = append(constraints, sq.Eq{"lag_spike": true})
+	}
+
+	rows, errRows := db.QueryBuilder(ctx, db.sb.
+		Select("server_id", "created_on", "map_name", "players", "cpu", "fps", "frame_ms", "var_ms", "tick_ms",
+			"net_in_kbps", "net_out_kbps", "packets_in", "packets_out", "loss_in", "loss_out", "choke_in",
+			"choke_out", "uptime_minutes", "tick_health", "lag_spike").
+		From("server_performance").
+		Where(constraints).
+		OrderBy("server_id", "created_on"))
+	if errRows != nil {
+		return nil, errRows
+	}
+
+	defer rows.Close()
+
+	samples := make([]ServerPerformanceSample, 0)
+
+	for rows.Next() {
+		var sample ServerPerformanceSample
+		if errScan := rows.Scan(&sample.ServerID, &sample.CreatedOn, &sample.MapName, &sample.Players, &sample.CPU,
+			&sample.FPS, &sample.FrameMS, &sample.VarMS, &sample.TickMS, &sample.NetInKBps, &sample.NetOutKBps,
+			&sample.PacketsIn, &sample.PacketsOut, &sample.LossIn, &sample.LossOut, &sample.ChokeIn,
+			&sample.ChokeOut, &sample.UptimeMinutes, &sample.TickHealth, &sample.LagSpike); errScan != nil {
+			return nil, Err(errScan)
+		}
+
+		samples = append(samples, sample)
+	}
+
+	if rows.Err() != nil {
+		return nil, Err(rows.Err())
+	}
+
+	return samples, nil
+}
+
+// DeleteServerPerformanceSamples removes the samples recorded before the time.
+func (db *Store) DeleteServerPerformanceSamples(ctx context.Context, before time.Time) error {
+	return db.ExecDeleteBuilder(ctx, db.sb.
+		Delete("server_performance").
+		Where(sq.Lt{"created_on": before}))
+}
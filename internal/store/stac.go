@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+// StacDetection is a single detection reported by the StAC anti-cheat plugin.
+type StacDetection struct {
+	StacDetectionID int64         `json:"stac_detection_id"`
+	SteamID         steamid.SID64 `json:"steam_id"`
+	PersonaName     string        `json:"personaname"`
+	ServerID        int           `json:"server_id"`
+	ServerName      string        `json:"server_name"`
+	MatchID         uuid.UUID     `json:"match_id"`
+	DemoName        string        `json:"demo_name"`
+	DemoTick        int           `json:"demo_tick"`
+	Detection       string        `json:"detection"`
+	Count           int           `json:"count"`
+	CreatedOn       time.Time     `json:"created_on"`
+}
+
+type StacDetectionQueryFilter struct {
+	QueryFilter
+	SteamID  StringSID `json:"steam_id,omitempty"`
+	ServerID int       `json:"server_id,omitempty"`
+	MatchID  uuid.UUID `json:"match_id,omitempty"`
+	DemoName string    `json:"demo_name,omitempty"`
+}
+
+func (db *Store) SaveStacDetection(ctx context.Context, detection *StacDetection) error {
+	var matchID *uuid.UUID
+
+	if !detection.MatchID.IsNil() {
+		matchID = &detection.MatchID
+	}
+
+	if detection.CreatedOn.IsZero() {
+		detection.CreatedOn = time.Now()
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("stac_detection").
+		SetMap(map[string]interface{}{
+			"steam_id":   detection.SteamID.Int64(),
+			"server_id":  detection.ServerID,
+			"match_id":   matchID,
+			"demo_name":  detection.DemoName,
+			"demo_tick":  detection.DemoTick,
+			"detection":  detection.Detection,
+			"count":      detection.Count,
+			"created_on": detection.CreatedOn,
+		}).
+		Suffix("RETURNING stac_detection_id"), &detection.StacDetectionID)
+}
+
+func (db *Store) GetStacDetections(ctx context.Context, filter StacDetectionQueryFilter) ([]StacDetection, int64, error) {
+	var constraints sq.And
+
+	if filter.SteamID != "" {
+		steamID, errSteamID := filter.SteamID.SID64(ctx)
+		if errSteamID != nil {
+			return nil, 0, errSteamID
+		}
+
+		constraints = append(constraints, sq.Eq{"d.steam_id": steamID.Int64()})
+	}
+
+	if filter.ServerID > 0 {
+		constraints = append(constraints, sq.Eq{"d.server_id": filter.ServerID})
+	}
+
+	if !filter.MatchID.IsNil() {
+		constraints = append(constraints, sq.Eq{"d.match_id": filter.MatchID})
+	}
+
+	if filter.DemoName != "" {
+		constraints = append(constraints, sq.Eq{"d.demo_name": filter.DemoName})
+	}
+
+	if filter.OrderBy == "" {
+		filter.Desc = true
+	}
+
+	builder := filter.applySafeOrder(db.sb.
+		Select("d.stac_detection_id", "d.steam_id", "coalesce(p.personaname, '')", "d.server_id", "s.short_name",
+			"d.match_id", "d.demo_name", "d.demo_tick", "d.detection", "d.count", "d.created_on").
+		From("stac_detection d").
+		LeftJoin("person p ON p.steam_id = d.steam_id").
+		LeftJoin("server s ON s.server_id = d.server_id").
+		Where(constraints), map[string][]string{
+		"d.": {"stac_detection_id", "steam_id", "server_id", "detection", "count", "created_on"},
+	}, "created_on")
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	detections := make([]StacDetection, 0)
+
+	for rows.Next() {
+		var (
+			detection StacDetection
+			steamID   int64
+			matchID   *uuid.UUID
+		)
+
+		if errScan := rows.Scan(&detection.StacDetectionID, &steamID, &detection.PersonaName, &detection.ServerID,
+			&detection.ServerName, &matchID, &detection.DemoName, &detection.DemoTick, &detection.Detection,
+			&detection.Count, &detection.CreatedOn); errScan != nil {
+			return nil, 0, errors.Wrap(Err(errScan), "Failed to scan stac detection")
+		}
+
+		if matchID != nil {
+			detection.MatchID = *matchID
+		}
+
+		detection.SteamID = steamid.New(steamID)
+		detections = append(detections, detection)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("count(d.stac_detection_id)").
+		From("stac_detection d").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return detections, count, nil
+}
+
+// ArchiveDemoByName marks the demo as archived so that it is exempt from demo cleanup.
+func (db *Store) ArchiveDemoByName(ctx context.Context, demoName string) error {
+	return db.ExecUpdateBuilder(ctx, db.sb.
+		Update("demo").
+		Set("archive", true).
+		Where(sq.Eq{"title": demoName}))
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/leighmacdonald/gbans/internal/store"
 	"github.com/leighmacdonald/golib"
 	"github.com/leighmacdonald/steamid/v3/steamid"
@@ -73,6 +74,11 @@ func TestStore(t *testing.T) {
 	t.Run("chat_hist", testChatHistory(database))
 	t.Run("filters", testFilters(database))
 	t.Run("forum", testForum(database))
+	t.Run("server_group", testServerGroup(database))
+	t.Run("server_credential", testServerCredential(database))
+	t.Run("server_enrollment", testServerEnrollment(database))
+	t.Run("server_performance", testServerPerformance(database))
+	t.Run("pending_action", testPendingAction(database))
 }
 
 func testServerTest(database *store.Store) func(t *testing.T) {
@@ -126,6 +132,277 @@ func testServerTest(database *store.Store) func(t *testing.T) {
 	}
 }
 
+func testServerGroup(database *store.Store) func(t *testing.T) {
+	return func(t *testing.T) {
+		ctx := context.Background()
+
+		serverA := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+		serverB := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+
+		require.NoError(t, database.SaveServer(ctx, &serverA))
+		require.NoError(t, database.SaveServer(ctx, &serverB))
+
+		group := store.ServerGroup{Name: fmt.Sprintf("test-%s", golib.RandomString(10)), ServerIDs: []int{serverA.ServerID}}
+		require.NoError(t, database.SaveServerGroup(ctx, &group))
+		require.True(t, group.ServerGroupID > 0)
+
+		require.NoError(t, database.AddServerGroupMember(ctx, group.ServerGroupID, serverB.ServerID))
+
+		var fetched store.ServerGroup
+
+		require.NoError(t, database.GetServerGroupByName(ctx, group.Name, &fetched))
+		require.Equal(t, []int{serverA.ServerID, serverB.ServerID}, fetched.ServerIDs)
+
+		require.NoError(t, database.RemoveServerGroupMember(ctx, group.ServerGroupID, serverA.ServerID))
+
+		serverIDs, errServerIDs := database.GetServerGroupServerIDs(ctx, []int{group.ServerGroupID})
+		require.NoError(t, errServerIDs)
+		require.Equal(t, []int{serverB.ServerID}, serverIDs)
+
+		require.NoError(t, database.DeleteServerGroup(ctx, group.ServerGroupID))
+		require.True(t, errors.Is(database.GetServerGroup(ctx, group.ServerGroupID, &fetched), store.ErrNoResult))
+	}
+}
+
+func testServerCredential(database *store.Store) func(t *testing.T) {
+	return func(t *testing.T) {
+		ctx := context.Background()
+
+		server := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+		require.NoError(t, database.SaveServer(ctx, &server))
+
+		var original store.ServerCredential
+
+		require.NoError(t, database.GetServerCredentialBySecret(ctx, store.ServerCredentialKey, server.Password, &original))
+		require.Equal(t, server.ServerID, original.ServerID)
+		require.True(t, original.Valid(time.Now()))
+
+		overlapUntil := time.Now().Add(time.Hour)
+		rotated, errRotate := database.RotateServerCredential(ctx, &server, store.ServerCredentialKey,
+			golib.RandomString(20), []string{"check"}, overlapUntil)
+		require.NoError(t, errRotate)
+		require.Equal(t, rotated.Secret, server.Password)
+
+		require.NoError(t, database.GetServerCredential(ctx, original.ServerCredentialID, &original))
+		require.True(t, original.Valid(time.Now()))
+		require.False(t, original.Valid(overlapUntil))
+
+		var fetched store.Server
+
+		require.NoError(t, database.GetServer(ctx, server.ServerID, &fetched))
+		require.Equal(t, rotated.Secret, fetched.Password)
+
+		require.NoError(t, database.RevokeServerCredential(ctx, rotated.ServerCredentialID))
+		require.NoError(t, database.GetServerCredential(ctx, rotated.ServerCredentialID, &rotated))
+		require.False(t, rotated.Valid(time.Now()))
+
+		// Servers sharing a secret, which could only exist before credentials were introduced, are reported
+		shared := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+		require.NoError(t, database.SaveServer(ctx, &shared))
+		require.NoError(t, database.Exec(ctx, `UPDATE server SET password = $1 WHERE server_id = $2`,
+			server.Password, shared.ServerID))
+
+		conflicts, errConflicts := database.GetServerCredentialConflicts(ctx)
+		require.NoError(t, errConflicts)
+		require.Contains(t, conflicts, store.ServerCredentialConflict{
+			ServerID: shared.ServerID, ShortName: shared.ShortName, Kind: store.ServerCredentialKey,
+		})
+
+		for _, conflict := range conflicts {
+			require.NotEqual(t, server.ServerID, conflict.ServerID)
+		}
+
+		// They can still be saved while the shared secret is unchanged
+		shared.Password = server.Password
+		shared.Name = golib.RandomString(10)
+		require.NoError(t, database.SaveServer(ctx, &shared))
+
+		// Secrets already belonging to another server cannot be used
+		other := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+		require.NoError(t, database.SaveServer(ctx, &other))
+
+		otherPassword := other.Password
+		other.Password = server.Password
+		require.ErrorIs(t, database.SaveServer(ctx, &other), store.ErrDuplicate)
+
+		duplicate := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+		duplicate.Password = otherPassword
+		require.ErrorIs(t, database.SaveServer(ctx, &duplicate), store.ErrDuplicate)
+
+		var fetchedOther store.Server
+
+		require.NoError(t, database.GetServer(ctx, other.ServerID, &fetchedOther))
+		require.Equal(t, otherPassword, fetchedOther.Password)
+
+		// Tokens are no longer accepted once the credential they were issued for is replaced by an edit
+		var otherCredential store.ServerCredential
+
+		require.NoError(t, database.GetServerCredentialBySecret(ctx, store.ServerCredentialKey, otherPassword, &otherCredential))
+
+		token := store.ServerToken{
+			ServerTokenID:      uuid.Must(uuid.NewV4()),
+			ServerID:           other.ServerID,
+			ServerCredentialID: otherCredential.ServerCredentialID,
+			Scopes:             []string{},
+			ExpiresOn:          time.Now().Add(time.Hour),
+			CreatedOn:          time.Now(),
+		}
+		require.NoError(t, database.SaveServerToken(ctx, &token))
+
+		_, errKey := database.GetServerTokenSigningKey(ctx, token.ServerTokenID, &store.ServerToken{})
+		require.NoError(t, errKey)
+
+		other.Password = golib.RandomString(20)
+		require.NoError(t, database.SaveServer(ctx, &other))
+
+		_, errKey = database.GetServerTokenSigningKey(ctx, token.ServerTokenID, &store.ServerToken{})
+		require.ErrorIs(t, errKey, store.ErrNoResult)
+	}
+}
+
+func testServerEnrollment(database *store.Store) func(t *testing.T) {
+	return func(t *testing.T) {
+		ctx := context.Background()
+
+		var author store.Person
+
+		require.NoError(t, database.GetOrCreatePersonBySteamID(ctx, steamid.New(76561198003911389), &author))
+
+		token := store.NewServerEnrollmentToken(author.SteamID, time.Hour)
+		token.Region = "eu"
+		token.ReservedSlots = 2
+		require.NoError(t, database.SaveServerEnrollmentToken(ctx, &token))
+		require.True(t, token.ServerEnrollmentTokenID > 0)
+
+		existing := store.NewServer(golib.RandomString(10), "localhost", rand.Intn(65535)) //nolint:gosec
+		require.NoError(t, database.SaveServer(ctx, &existing))
+
+		create := func(enrollToken store.ServerEnrollmentToken) store.Server {
+			server := store.NewServer(golib.RandomString(10), "127.0.0.1", 27015)
+			server.Region = enrollToken.Region
+			server.ReservedSlots = enrollToken.ReservedSlots
+
+			return server
+		}
+
+		// A failed enrollment leaves no server behind and the token can be used again
+		_, errFailed := database.EnrollServer(ctx, token.Token, time.Now(), func(enrollToken store.ServerEnrollmentToken) store.Server {
+			server := create(enrollToken)
+			server.Password = existing.Password
+
+			return server
+		})
+		require.ErrorIs(t, errFailed, store.ErrDuplicate)
+
+		require.NoError(t, database.GetServerEnrollmentToken(ctx, token.ServerEnrollmentTokenID, &token))
+		require.True(t, token.Usable(time.Now()))
+		require.Nil(t, token.ServerID)
+
+		server, errEnroll := database.EnrollServer(ctx, token.Token, time.Now(), create)
+		require.NoError(t, errEnroll)
+		require.True(t, server.PendingApproval)
+		require.False(t, server.IsEnabled)
+		require.Equal(t, "eu", server.Region)
+
+		// Tokens can only be used once
+		_, errReuse := database.EnrollServer(ctx, token.Token, time.Now(), create)
+		require.ErrorIs(t, errReuse, store.ErrNoResult)
+
+		require.NoError(t, database.GetServerEnrollmentToken(ctx, token.ServerEnrollmentTokenID, &token))
+		require.False(t, token.Usable(time.Now()))
+		require.NotNil(t, token.ServerID)
+		require.Equal(t, server.ServerID, *token.ServerID)
+
+		require.NoError(t, database.DeleteServerEnrollmentToken(ctx, token.ServerEnrollmentTokenID))
+	}
+}
+
+func testPendingAction(database *store.Store) func(t *testing.T) {
+	return func(t *testing.T) {
+		ctx := context.Background()
+
+		var author store.Person
+
+		require.NoError(t, database.GetOrCreatePersonBySteamID(ctx, steamid.New(76561198003911389), &author))
+
+		action := store.PendingAction{
+			ActionType: store.PendingBanSteam,
+			State:      store.PendingStateOpen,
+			SourceID:   author.SteamID,
+			TargetID:   steamid.RandSID64(),
+			Payload:    []byte(`{}`),
+			Reason:     "test",
+			ExpiresOn:  time.Now().Add(time.Hour),
+		}
+
+		require.NoError(t, database.SavePendingAction(ctx, &action))
+		require.True(t, action.PendingActionID > 0)
+
+		approved := action
+		approved.State = store.PendingStateApproved
+		approved.ApproverID = steamid.RandSID64()
+		require.NoError(t, database.TransitionPendingAction(ctx, &approved, store.PendingStateOpen))
+
+		// The action is no longer open, so a second moderator acting on the same stale copy must fail
+		rejected := action
+		rejected.State = store.PendingStateRejected
+		require.ErrorIs(t, database.TransitionPendingAction(ctx, &rejected, store.PendingStateOpen), store.ErrNoResult)
+
+		var fetched store.PendingAction
+		require.NoError(t, database.GetPendingAction(ctx, action.PendingActionID, &fetched))
+		require.Equal(t, store.PendingStateApproved, fetched.State)
+		require.Equal(t, approved.ApproverID, fetched.ApproverID)
+	}
+}
+
+func testServerPerformance(database *store.Store) func(t *testing.T) {
+	return func(t *testing.T) {
+		ctx := context.Background()
+
+		server := store.NewServer(golib.RandomString(10), "127.0.0.1", 27015)
+		require.NoError(t, database.SaveServer(ctx, &server))
+
+		now := time.Now().Truncate(time.Second)
+		samples := []store.ServerPerformanceSample{
+			{ServerID: server.ServerID, CreatedOn: now.Add(-time.Hour), MapName: "pl_upward", FPS: 66.6, TickHealth: 1},
+			{ServerID: server.ServerID, CreatedOn: now, MapName: "pl_badwater", FPS: 40, VarMS: 6, LagSpike: true},
+		}
+
+		require.NoError(t, database.SaveServerPerformanceSamples(ctx, samples))
+		// Saving the same sample again is ignored
+		require.NoError(t, database.SaveServerPerformanceSamples(ctx, samples[1:]))
+
+		fetched, errFetch := database.GetServerPerformanceSamples(ctx, store.ServerPerformanceQuery{
+			ServerIDs: []int{server.ServerID},
+			From:      now.Add(-time.Hour * 2),
+			To:        now.Add(time.Minute),
+		})
+		require.NoError(t, errFetch)
+		require.Len(t, fetched, 2)
+		require.Equal(t, "pl_upward", fetched[0].MapName)
+		require.True(t, fetched[1].LagSpike)
+
+		spikes, errSpikes := database.GetServerPerformanceSamples(ctx, store.ServerPerformanceQuery{
+			ServerIDs:     []int{server.ServerID},
+			From:          now.Add(-time.Hour * 2),
+			To:            now.Add(time.Minute),
+			LagSpikesOnly: true,
+		})
+		require.NoError(t, errSpikes)
+		require.Len(t, spikes, 1)
+
+		require.NoError(t, database.DeleteServerPerformanceSamples(ctx, now.Add(-time.Minute)))
+
+		remaining, errRemaining := database.GetServerPerformanceSamples(ctx, store.ServerPerformanceQuery{
+			ServerIDs: []int{server.ServerID},
+			From:      now.Add(-time.Hour * 2),
+			To:        now.Add(time.Minute),
+		})
+		require.NoError(t, errRemaining)
+		require.Len(t, remaining, 1)
+	}
+}
+
 func randIP() string {
 	return fmt.Sprintf("%d.%d.%d.%d", rand.Intn(255), rand.Intn(255), rand.Intn(255), rand.Intn(255)) //nolint:gosec
 }
@@ -0,0 +1,411 @@
+package store
+
+import (
+	"context"
+	"math"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/leighmacdonald/steamid/v3/steamid"
+	"github.com/pkg/errors"
+)
+
+// SuspicionMetric defines which statistic a suspicion flag was raised against.
+type SuspicionMetric int
+
+const (
+	SuspicionAccuracy SuspicionMetric = iota + 1
+	SuspicionHeadshotRatio
+	SuspicionKD
+)
+
+func (m SuspicionMetric) String() string {
+	switch m {
+	case SuspicionAccuracy:
+		return "Accuracy"
+	case SuspicionHeadshotRatio:
+		return "Headshot Ratio"
+	case SuspicionKD:
+		return "K/D"
+	default:
+		return "Unknown"
+	}
+}
+
+// SuspicionFlagState tracks moderator review of a flag.
+type SuspicionFlagState int
+
+const (
+	SuspicionStateAny SuspicionFlagState = iota - 1
+	SuspicionStateOpen
+	SuspicionStateConfirmed
+	SuspicionStateDismissed
+)
+
+func (s SuspicionFlagState) String() string {
+	switch s {
+	case SuspicionStateOpen:
+		return "Open"
+	case SuspicionStateConfirmed:
+		return "Confirmed"
+	case SuspicionStateDismissed:
+		return "Dismissed"
+	default:
+		return "Unknown"
+	}
+}
+
+// SuspicionBaseline holds the population distribution of a single metric.
+type SuspicionBaseline struct {
+	Mean    float64 `json:"mean"`
+	StdDev  float64 `json:"stddev"`
+	Samples int64   `json:"samples"`
+}
+
+// Score returns the number of standard deviations the value sits above the population mean. Values
+// at or below the mean, or baselines without any spread, always score 0.
+func (b SuspicionBaseline) Score(value float64) float64 {
+	if b.StdDev <= 0 || b.Samples == 0 || value <= b.Mean {
+		return 0
+	}
+
+	return math.Round((value-b.Mean)/b.StdDev*100) / 100
+}
+
+// SuspicionBaselines holds the population baselines, per weapon_id where applicable.
+type SuspicionBaselines struct {
+	Accuracy      map[int]SuspicionBaseline
+	HeadshotRatio map[int]SuspicionBaseline
+	KD            SuspicionBaseline
+}
+
+// SuspicionWeaponSample is a single players usage of a weapon over the course of a match.
+type SuspicionWeaponSample struct {
+	SteamID   steamid.SID64
+	WeaponID  int
+	Shots     int
+	Hits      int
+	Headshots int
+}
+
+// SuspicionKDSample is a single players overall kills and deaths over the course of a match.
+type SuspicionKDSample struct {
+	SteamID steamid.SID64
+	Kills   int
+	Deaths  int
+}
+
+func (s SuspicionKDSample) KD() float64 {
+	return float64(s.Kills) / math.Max(float64(s.Deaths), 1)
+}
+
+// SuspicionFlag records a statistically significant outlier for a player within a single match.
+type SuspicionFlag struct {
+	SuspicionFlagID int64              `json:"suspicion_flag_id"`
+	SteamID         steamid.SID64      `json:"steam_id"`
+	Personaname     string             `json:"personaname"`
+	AvatarHash      string             `json:"avatarhash"`
+	MatchID         uuid.UUID          `json:"match_id"`
+	DemoID          int64              `json:"demo_id"`
+	WeaponID        int                `json:"weapon_id"`
+	WeaponName      string             `json:"weapon_name"`
+	Metric          SuspicionMetric    `json:"metric"`
+	Value           float64            `json:"value"`
+	BaselineMean    float64            `json:"baseline_mean"`
+	BaselineStdDev  float64            `json:"baseline_stddev"`
+	Score           float64            `json:"score"`
+	State           SuspicionFlagState `json:"state"`
+	TimeStamped
+}
+
+func (flag SuspicionFlag) Path() string {
+	return "/admin/suspicion"
+}
+
+type SuspicionFlagQueryFilter struct {
+	QueryFilter
+	SteamID  StringSID          `json:"steam_id,omitempty"`
+	State    SuspicionFlagState `json:"state"`
+	MinScore float64            `json:"min_score,omitempty"`
+}
+
+// GetSuspicionBaselines computes the population baselines over all stored matches. Per weapon metrics
+// only consider samples with at least minShots shots fired, and the K/D baseline only considers players
+// with at least minShots kills and deaths combined.
+func (db *Store) GetSuspicionBaselines(ctx context.Context, minShots int) (SuspicionBaselines, error) {
+	const (
+		accuracyQuery = `
+		SELECT weapon_id, avg(hits::float / shots), coalesce(stddev_samp(hits::float / shots), 0), count(*)
+		FROM match_weapon
+		WHERE shots >= $1 AND shots > 0
+		GROUP BY weapon_id`
+		headshotQuery = `
+		SELECT weapon_id, avg(headshots::float / hits), coalesce(stddev_samp(headshots::float / hits), 0), count(*)
+		FROM match_weapon
+		WHERE hits >= $1 AND hits > 0
+		GROUP BY weapon_id
+		HAVING sum(headshots) > 0`
+		kdQuery = `
+		SELECT coalesce(avg(kd), 0), coalesce(stddev_samp(kd), 0), count(*)
+		FROM (
+			SELECT sum(kills)::float / greatest(sum(deaths), 1) as kd
+			FROM match_player_class
+			GROUP BY match_player_id
+			HAVING sum(kills) + sum(deaths) >= $1
+		) s`
+	)
+
+	baselines := SuspicionBaselines{
+		Accuracy:      map[int]SuspicionBaseline{},
+		HeadshotRatio: map[int]SuspicionBaseline{},
+	}
+
+	for query, results := range map[string]map[int]SuspicionBaseline{
+		accuracyQuery: baselines.Accuracy,
+		headshotQuery: baselines.HeadshotRatio,
+	} {
+		if errWeapons := db.scanWeaponBaselines(ctx, query, minShots, results); errWeapons != nil {
+			return baselines, errWeapons
+		}
+	}
+
+	if errKD := db.QueryRow(ctx, kdQuery, minShots).
+		Scan(&baselines.KD.Mean, &baselines.KD.StdDev, &baselines.KD.Samples); errKD != nil {
+		return baselines, Err(errKD)
+	}
+
+	return baselines, nil
+}
+
+func (db *Store) scanWeaponBaselines(ctx context.Context, query string, minShots int, results map[int]SuspicionBaseline) error {
+	rows, errRows := db.Query(ctx, query, minShots)
+	if errRows != nil {
+		return Err(errRows)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			weaponID int
+			baseline SuspicionBaseline
+		)
+
+		if errScan := rows.Scan(&weaponID, &baseline.Mean, &baseline.StdDev, &baseline.Samples); errScan != nil {
+			return Err(errScan)
+		}
+
+		results[weaponID] = baseline
+	}
+
+	return nil
+}
+
+// GetMatchSuspicionSamples loads the per weapon and overall K/D samples for each player in the match.
+func (db *Store) GetMatchSuspicionSamples(ctx context.Context, matchID uuid.UUID) ([]SuspicionWeaponSample, []SuspicionKDSample, error) {
+	const (
+		weaponQuery = `
+		SELECT mp.steam_id, mw.weapon_id, sum(mw.shots), sum(mw.hits), sum(mw.headshots)
+		FROM match_player mp
+		LEFT JOIN match_weapon mw on mp.match_player_id = mw.match_player_id
+		WHERE mp.match_id = $1 AND mw.weapon_id IS NOT NULL
+		GROUP BY mp.steam_id, mw.weapon_id`
+		kdQuery = `
+		SELECT mp.steam_id, sum(mc.kills), sum(mc.deaths)
+		FROM match_player mp
+		LEFT JOIN match_player_class mc on mp.match_player_id = mc.match_player_id
+		WHERE mp.match_id = $1 AND mc.match_player_class_id IS NOT NULL
+		GROUP BY mp.steam_id`
+	)
+
+	weaponRows, errWeaponRows := db.Query(ctx, weaponQuery, matchID)
+	if errWeaponRows != nil {
+		return nil, nil, Err(errWeaponRows)
+	}
+
+	defer weaponRows.Close()
+
+	var weaponSamples []SuspicionWeaponSample
+
+	for weaponRows.Next() {
+		var (
+			steamID int64
+			sample  SuspicionWeaponSample
+		)
+
+		if errScan := weaponRows.Scan(&steamID, &sample.WeaponID, &sample.Shots, &sample.Hits, &sample.Headshots); errScan != nil {
+			return nil, nil, Err(errScan)
+		}
+
+		sample.SteamID = steamid.New(steamID)
+		weaponSamples = append(weaponSamples, sample)
+	}
+
+	kdRows, errKDRows := db.Query(ctx, kdQuery, matchID)
+	if errKDRows != nil {
+		return nil, nil, Err(errKDRows)
+	}
+
+	defer kdRows.Close()
+
+	var kdSamples []SuspicionKDSample
+
+	for kdRows.Next() {
+		var (
+			steamID int64
+			sample  SuspicionKDSample
+		)
+
+		if errScan := kdRows.Scan(&steamID, &sample.Kills, &sample.Deaths); errScan != nil {
+			return nil, nil, Err(errScan)
+		}
+
+		sample.SteamID = steamid.New(steamID)
+		kdSamples = append(kdSamples, sample)
+	}
+
+	return weaponSamples, kdSamples, nil
+}
+
+// GetMatchDemoID attempts to find the demo recorded for the match. Demos are not directly linked
+// to matches so the server and time window are used instead.
+func (db *Store) GetMatchDemoID(ctx context.Context, matchID uuid.UUID) (int64, error) {
+	const query = `
+		SELECT d.demo_id
+		FROM match m
+		LEFT JOIN demo d ON d.server_id = m.server_id
+		WHERE m.match_id = $1
+		  AND d.created_on BETWEEN m.time_start AND m.time_end + interval '10 minutes'
+		ORDER BY d.created_on
+		LIMIT 1`
+
+	var demoID int64
+	if errQuery := db.QueryRow(ctx, query, matchID).Scan(&demoID); errQuery != nil {
+		return 0, Err(errQuery)
+	}
+
+	return demoID, nil
+}
+
+// SaveSuspicionFlag inserts a new flag, updating the score of an existing flag if the same player, match, weapon
+// and metric combination has already been flagged.
+func (db *Store) SaveSuspicionFlag(ctx context.Context, flag *SuspicionFlag) error {
+	var demoID, weaponID *int64
+
+	if flag.DemoID > 0 {
+		demoID = &flag.DemoID
+	}
+
+	if flag.WeaponID > 0 {
+		wid := int64(flag.WeaponID)
+		weaponID = &wid
+	}
+
+	flag.UpdatedOn = time.Now()
+	if flag.CreatedOn.IsZero() {
+		flag.CreatedOn = flag.UpdatedOn
+	}
+
+	return db.ExecInsertBuilderWithReturnValue(ctx, db.sb.
+		Insert("suspicion_flag").
+		SetMap(map[string]interface{}{
+			"steam_id":        flag.SteamID.Int64(),
+			"match_id":        flag.MatchID,
+			"demo_id":         demoID,
+			"weapon_id":       weaponID,
+			"metric":          flag.Metric,
+			"value":           flag.Value,
+			"baseline_mean":   flag.BaselineMean,
+			"baseline_stddev": flag.BaselineStdDev,
+			"score":           flag.Score,
+			"state":           flag.State,
+			"created_on":      flag.CreatedOn,
+			"updated_on":      flag.UpdatedOn,
+		}).
+		Suffix(`ON CONFLICT (steam_id, match_id, (coalesce(weapon_id, 0)), metric)
+			DO UPDATE SET value = EXCLUDED.value, baseline_mean = EXCLUDED.baseline_mean,
+				baseline_stddev = EXCLUDED.baseline_stddev, score = EXCLUDED.score, updated_on = EXCLUDED.updated_on
+			RETURNING suspicion_flag_id`), &flag.SuspicionFlagID)
+}
+
+func (db *Store) SetSuspicionFlagState(ctx context.Context, suspicionFlagID int64, state SuspicionFlagState) error {
+	return db.ExecUpdateBuilder(ctx, db.sb.
+		Update("suspicion_flag").
+		Set("state", state).
+		Set("updated_on", time.Now()).
+		Where(sq.Eq{"suspicion_flag_id": suspicionFlagID}))
+}
+
+// GetSuspicionFlags returns flags ranked by score, highest first, unless another order is requested.
+func (db *Store) GetSuspicionFlags(ctx context.Context, filter SuspicionFlagQueryFilter) ([]SuspicionFlag, int64, error) {
+	var constraints sq.And
+
+	if filter.State > SuspicionStateAny {
+		constraints = append(constraints, sq.Eq{"f.state": filter.State})
+	}
+
+	if filter.MinScore > 0 {
+		constraints = append(constraints, sq.GtOrEq{"f.score": filter.MinScore})
+	}
+
+	if filter.SteamID != "" {
+		steamID, errSteamID := filter.SteamID.SID64(ctx)
+		if errSteamID != nil {
+			return nil, 0, errSteamID
+		}
+
+		constraints = append(constraints, sq.Eq{"f.steam_id": steamID.Int64()})
+	}
+
+	if filter.OrderBy == "" {
+		filter.OrderBy = "score"
+		filter.Desc = true
+	}
+
+	builder := filter.applySafeOrder(db.sb.
+		Select("f.suspicion_flag_id", "f.steam_id", "p.personaname", "p.avatarhash", "f.match_id",
+			"coalesce(f.demo_id, 0)", "coalesce(f.weapon_id, 0)", "coalesce(w.name, '')", "f.metric", "f.value",
+			"f.baseline_mean", "f.baseline_stddev", "f.score", "f.state", "f.created_on", "f.updated_on").
+		From("suspicion_flag f").
+		LeftJoin("person p ON p.steam_id = f.steam_id").
+		LeftJoin("weapon w ON w.weapon_id = f.weapon_id").
+		Where(constraints), map[string][]string{
+		"f.": {"suspicion_flag_id", "steam_id", "metric", "value", "score", "state", "created_on", "updated_on"},
+	}, "score")
+
+	rows, errRows := db.QueryBuilder(ctx, filter.applyLimitOffsetDefault(builder))
+	if errRows != nil {
+		return nil, 0, errRows
+	}
+
+	defer rows.Close()
+
+	flags := make([]SuspicionFlag, 0)
+
+	for rows.Next() {
+		var (
+			flag    SuspicionFlag
+			steamID int64
+		)
+
+		if errScan := rows.Scan(&flag.SuspicionFlagID, &steamID, &flag.Personaname, &flag.AvatarHash, &flag.MatchID,
+			&flag.DemoID, &flag.WeaponID, &flag.WeaponName, &flag.Metric, &flag.Value, &flag.BaselineMean,
+			&flag.BaselineStdDev, &flag.Score, &flag.State, &flag.CreatedOn, &flag.UpdatedOn); errScan != nil {
+			return nil, 0, errors.Wrap(Err(errScan), "Failed to scan suspicion flag")
+		}
+
+		flag.SteamID = steamid.New(steamID)
+		flags = append(flags, flag)
+	}
+
+	count, errCount := db.GetCount(ctx, db.sb.
+		Select("count(f.suspicion_flag_id)").
+		From("suspicion_flag f").
+		Where(constraints))
+	if errCount != nil {
+		return nil, 0, errCount
+	}
+
+	return flags, count, nil
+}
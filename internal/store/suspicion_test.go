@@ -0,0 +1,25 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/gbans/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuspicionBaselineScore(t *testing.T) {
+	baseline := store.SuspicionBaseline{Mean: 0.3, StdDev: 0.05, Samples: 100}
+
+	require.InDelta(t, 0.0, baseline.Score(0.2), 0.001)
+	require.InDelta(t, 0.0, baseline.Score(0.3), 0.001)
+	require.InDelta(t, 2.0, baseline.Score(0.4), 0.001)
+	require.InDelta(t, 5.0, baseline.Score(0.55), 0.001)
+
+	require.InDelta(t, 0.0, store.SuspicionBaseline{Mean: 0.3}.Score(0.9), 0.001)
+	require.InDelta(t, 0.0, store.SuspicionBaseline{Mean: 0.3, StdDev: 0.1}.Score(0.9), 0.001)
+}
+
+func TestSuspicionKDSample(t *testing.T) {
+	require.InDelta(t, 10.0, store.SuspicionKDSample{Kills: 10}.KD(), 0.001)
+	require.InDelta(t, 2.5, store.SuspicionKDSample{Kills: 10, Deaths: 4}.KD(), 0.001)
+}
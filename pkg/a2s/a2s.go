@@ -0,0 +1,468 @@
+// Package a2s is a client for the valve A2S server queries. It implements the A2S_INFO, A2S_PLAYER and A2S_RULES
+// requests, including challenge handling and split responses.
+// https://developer.valvesoftware.com/wiki/Server_queries
+package a2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	headerSimple = -1
+	headerSplit  = -2
+
+	requestInfo    = 'T'
+	requestPlayers = 'U'
+	requestRules   = 'V'
+
+	responseChallenge = 'A'
+	responseInfo      = 'I'
+	responsePlayers   = 'D'
+	responseRules     = 'E'
+
+	// maxPacketSize is the largest packet the engine will send.
+	maxPacketSize = 1400
+	// maxChallenges limits how many challenges are accepted for a single request.
+	maxChallenges = 3
+	// compressedFlag is set on the id of split packets using bzip2 compression, which is only used by old engines.
+	compressedFlag = 0x80000000
+
+	infoPayload = "Source Engine Query\x00"
+)
+
+// Extra data flags included at the end of an A2S_INFO response.
+const (
+	edfGameID   = 0x01
+	edfSteamID  = 0x10
+	edfKeywords = 0x20
+	edfSTV      = 0x40
+	edfPort     = 0x80
+)
+
+var (
+	ErrInvalidHeader   = errors.New("Invalid packet header")
+	ErrInvalidResponse = errors.New("Unexpected response type")
+	ErrCompressed      = errors.New("Compressed responses are not supported")
+	ErrTruncated       = errors.New("Response is truncated")
+	ErrTooManyRequests = errors.New("Too many challenges received")
+)
+
+// Info is the response to an A2S_INFO query.
+type Info struct {
+	Protocol    uint8
+	Name        string
+	Map         string
+	Folder      string
+	Game        string
+	AppID       uint16
+	Players     int
+	MaxPlayers  int
+	Bots        int
+	ServerType  byte
+	Environment byte
+	Password    bool
+	VAC         bool
+	Version     string
+	Port        uint16
+	SteamID     uint64
+	STVPort     uint16
+	STVName     string
+	Keywords    []string
+	GameID      uint64
+}
+
+// Player is a single entry of the response to an A2S_PLAYER query. Players which are still connecting may have
+// an empty name.
+type Player struct {
+	Index    uint8
+	Name     string
+	Score    int32
+	Duration time.Duration
+}
+
+// Client queries a single server. It is not safe for concurrent use.
+type Client struct {
+	conn    *net.UDPConn
+	timeout time.Duration
+}
+
+// Dial creates a client for the server at addr, eg: 1.2.3.4:27015. The timeout applies to each request.
+func Dial(addr string, timeout time.Duration) (*Client, error) {
+	udpAddr, errResolve := net.ResolveUDPAddr("udp4", addr)
+	if errResolve != nil {
+		return nil, errors.Wrap(errResolve, "Failed to resolve server address")
+	}
+
+	conn, errDial := net.DialUDP("udp4", nil, udpAddr)
+	if errDial != nil {
+		return nil, errors.Wrap(errDial, "Failed to dial server")
+	}
+
+	return &Client{conn: conn, timeout: timeout}, nil
+}
+
+func (c *Client) Close() error {
+	return errors.Wrap(c.conn.Close(), "Failed to close connection")
+}
+
+// Info sends an A2S_INFO query.
+func (c *Client) Info() (Info, error) {
+	body, errQuery := c.query(requestInfo, []byte(infoPayload), responseInfo, false)
+	if errQuery != nil {
+		return Info{}, errQuery
+	}
+
+	return parseInfo(body)
+}
+
+// Players sends an A2S_PLAYER query.
+func (c *Client) Players() ([]Player, error) {
+	body, errQuery := c.query(requestPlayers, nil, responsePlayers, true)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+
+	return parsePlayers(body)
+}
+
+// Rules sends an A2S_RULES query, returning the public cvars of the server.
+func (c *Client) Rules() (map[string]string, error) {
+	body, errQuery := c.query(requestRules, nil, responseRules, true)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+
+	return parseRules(body)
+}
+
+// query sends the request, answering any challenges the server responds with. Requests which always require
+// a challenge start with the -1 placeholder.
+func (c *Client) query(request byte, payload []byte, expected byte, needsChallenge bool) ([]byte, error) {
+	var challenge []byte
+
+	if needsChallenge {
+		challenge = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	}
+
+	for attempt := 0; attempt < maxChallenges; attempt++ {
+		packet := []byte{0xFF, 0xFF, 0xFF, 0xFF, request}
+		packet = append(packet, payload...)
+		packet = append(packet, challenge...)
+
+		if errDeadline := c.conn.SetDeadline(time.Now().Add(c.timeout)); errDeadline != nil {
+			return nil, errors.Wrap(errDeadline, "Failed to set deadline")
+		}
+
+		if _, errWrite := c.conn.Write(packet); errWrite != nil {
+			return nil, errors.Wrap(errWrite, "Failed to send request")
+		}
+
+		body, errRead := c.readResponse()
+		if errRead != nil {
+			return nil, errRead
+		}
+
+		if len(body) == 0 {
+			return nil, ErrTruncated
+		}
+
+		switch body[0] {
+		case responseChallenge:
+			if len(body) < 5 {
+				return nil, ErrTruncated
+			}
+
+			challenge = body[1:5]
+		case expected:
+			return body[1:], nil
+		default:
+			return nil, errors.Wrapf(ErrInvalidResponse, "%c", body[0])
+		}
+	}
+
+	return nil, ErrTooManyRequests
+}
+
+// readResponse reads a full response, assembling the parts of split responses in order.
+func (c *Client) readResponse() ([]byte, error) {
+	var (
+		buf   = make([]byte, maxPacketSize*2)
+		parts [][]byte
+		id    int32
+		found int
+	)
+
+	for {
+		size, errRead := c.conn.Read(buf)
+		if errRead != nil {
+			return nil, errors.Wrap(errRead, "Failed to read response")
+		}
+
+		packet := buf[:size]
+		if len(packet) < 4 {
+			return nil, ErrTruncated
+		}
+
+		switch int32(binary.LittleEndian.Uint32(packet)) {
+		case headerSimple:
+			return append([]byte(nil), packet[4:]...), nil
+		case headerSplit:
+			split, errSplit := parseSplitHeader(packet[4:])
+			if errSplit != nil {
+				return nil, errSplit
+			}
+
+			if parts == nil {
+				parts = make([][]byte, split.total)
+				id = split.id
+			} else if split.id != id || int(split.total) != len(parts) {
+				// Stale part from a previous response.
+				continue
+			}
+
+			if int(split.number) >= len(parts) || parts[split.number] != nil {
+				continue
+			}
+
+			parts[split.number] = append([]byte(nil), split.payload...)
+			found++
+
+			if found < len(parts) {
+				continue
+			}
+
+			body := bytes.Join(parts, nil)
+			if len(body) < 4 || int32(binary.LittleEndian.Uint32(body)) != headerSimple {
+				return nil, ErrInvalidHeader
+			}
+
+			return body[4:], nil
+		default:
+			return nil, ErrInvalidHeader
+		}
+	}
+}
+
+type splitPacket struct {
+	id      int32
+	total   uint8
+	number  uint8
+	payload []byte
+}
+
+// parseSplitHeader parses the header of a source engine split packet, following the -2 header.
+func parseSplitHeader(packet []byte) (splitPacket, error) {
+	const headerLen = 8
+
+	if len(packet) < headerLen {
+		return splitPacket{}, ErrTruncated
+	}
+
+	split := splitPacket{
+		id:      int32(binary.LittleEndian.Uint32(packet)),
+		total:   packet[4],
+		number:  packet[5],
+		payload: packet[headerLen:],
+	}
+
+	if uint32(split.id)&compressedFlag != 0 {
+		return splitPacket{}, ErrCompressed
+	}
+
+	if split.total == 0 {
+		return splitPacket{}, ErrInvalidHeader
+	}
+
+	return split, nil
+}
+
+// reader reads the little endian values used by the protocol, recording the first error encountered.
+type reader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *reader) take(count int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if r.pos+count > len(r.buf) {
+		r.err = ErrTruncated
+
+		return nil
+	}
+
+	value := r.buf[r.pos : r.pos+count]
+	r.pos += count
+
+	return value
+}
+
+func (r *reader) more() bool {
+	return r.err == nil && r.pos < len(r.buf)
+}
+
+func (r *reader) byte() byte {
+	if value := r.take(1); value != nil {
+		return value[0]
+	}
+
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if value := r.take(2); value != nil {
+		return binary.LittleEndian.Uint16(value)
+	}
+
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if value := r.take(4); value != nil {
+		return binary.LittleEndian.Uint32(value)
+	}
+
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if value := r.take(8); value != nil {
+		return binary.LittleEndian.Uint64(value)
+	}
+
+	return 0
+}
+
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+
+	end := bytes.IndexByte(r.buf[r.pos:], 0)
+	if end < 0 {
+		r.err = ErrTruncated
+
+		return ""
+	}
+
+	value := string(r.buf[r.pos : r.pos+end])
+	r.pos += end + 1
+
+	return value
+}
+
+func parseInfo(body []byte) (Info, error) {
+	read := &reader{buf: body}
+
+	info := Info{
+		Protocol:    read.byte(),
+		Name:        read.string(),
+		Map:         read.string(),
+		Folder:      read.string(),
+		Game:        read.string(),
+		AppID:       read.uint16(),
+		Players:     int(read.byte()),
+		MaxPlayers:  int(read.byte()),
+		Bots:        int(read.byte()),
+		ServerType:  read.byte(),
+		Environment: read.byte(),
+		Password:    read.byte() == 1,
+		VAC:         read.byte() == 1,
+		Version:     read.string(),
+	}
+
+	if read.err != nil {
+		return Info{}, read.err
+	}
+
+	if !read.more() {
+		return info, nil
+	}
+
+	edf := read.byte()
+
+	if edf&edfPort != 0 {
+		info.Port = read.uint16()
+	}
+
+	if edf&edfSteamID != 0 {
+		info.SteamID = read.uint64()
+	}
+
+	if edf&edfSTV != 0 {
+		info.STVPort = read.uint16()
+		info.STVName = read.string()
+	}
+
+	if edf&edfKeywords != 0 {
+		for _, keyword := range bytes.Split([]byte(read.string()), []byte(",")) {
+			if len(keyword) > 0 {
+				info.Keywords = append(info.Keywords, string(keyword))
+			}
+		}
+	}
+
+	if edf&edfGameID != 0 {
+		info.GameID = read.uint64()
+	}
+
+	return info, read.err
+}
+
+func parsePlayers(body []byte) ([]Player, error) {
+	read := &reader{buf: body}
+	count := int(read.byte())
+	players := make([]Player, 0, count)
+
+	for idx := 0; idx < count && read.more(); idx++ {
+		player := Player{
+			Index: read.byte(),
+			Name:  read.string(),
+			Score: int32(read.uint32()),
+		}
+
+		seconds := math.Float32frombits(read.uint32())
+		player.Duration = time.Duration(float64(seconds) * float64(time.Second))
+
+		if read.err != nil {
+			return nil, read.err
+		}
+
+		players = append(players, player)
+	}
+
+	return players, read.err
+}
+
+func parseRules(body []byte) (map[string]string, error) {
+	read := &reader{buf: body}
+	count := int(read.uint16())
+	rules := make(map[string]string, count)
+
+	// Servers with many rules may truncate the response, so keep whatever was read successfully.
+	for idx := 0; idx < count && read.more(); idx++ {
+		name := read.string()
+		value := read.string()
+
+		if read.err != nil {
+			break
+		}
+
+		rules[name] = value
+	}
+
+	if len(rules) == 0 && read.err != nil {
+		return nil, read.err
+	}
+
+	return rules, nil
+}
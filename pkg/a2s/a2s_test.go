@@ -0,0 +1,168 @@
+package a2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testChallenge = []byte{0x01, 0x02, 0x03, 0x04}
+
+func cstring(value string) []byte {
+	return append([]byte(value), 0)
+}
+
+func testInfoBody() []byte {
+	var body bytes.Buffer
+
+	body.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, responseInfo, 17})
+	body.Write(cstring("Test Server"))
+	body.Write(cstring("pl_upward"))
+	body.Write(cstring("tf"))
+	body.Write(cstring("Team Fortress"))
+	_ = binary.Write(&body, binary.LittleEndian, uint16(440))
+	body.Write([]byte{18, 24, 2, 'd', 'l', 0, 1})
+	body.Write(cstring("8604241"))
+	body.WriteByte(edfPort | edfSteamID | edfSTV | edfKeywords | edfGameID)
+	_ = binary.Write(&body, binary.LittleEndian, uint16(27015))
+	_ = binary.Write(&body, binary.LittleEndian, uint64(85568392922039296))
+	_ = binary.Write(&body, binary.LittleEndian, uint16(27020))
+	body.Write(cstring("SourceTV"))
+	body.Write(cstring("alltalk,nocrits"))
+	_ = binary.Write(&body, binary.LittleEndian, uint64(440))
+
+	return body.Bytes()
+}
+
+func testPlayersBody() []byte {
+	var body bytes.Buffer
+
+	body.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, responsePlayers, 2})
+
+	for idx, name := range []string{"player one", "player two"} {
+		body.WriteByte(byte(idx))
+		body.Write(cstring(name))
+		_ = binary.Write(&body, binary.LittleEndian, int32(10*(idx+1)))
+		_ = binary.Write(&body, binary.LittleEndian, math.Float32bits(float32(60*(idx+1))))
+	}
+
+	return body.Bytes()
+}
+
+// splitBody splits the response into source engine split packets, returned in reverse order.
+func splitBody(body []byte, size int) [][]byte {
+	var chunks [][]byte
+
+	for len(body) > size {
+		chunks = append(chunks, body[:size])
+		body = body[size:]
+	}
+
+	chunks = append(chunks, body)
+
+	packets := make([][]byte, len(chunks))
+
+	for idx, chunk := range chunks {
+		var packet bytes.Buffer
+
+		_ = binary.Write(&packet, binary.LittleEndian, int32(headerSplit))
+		_ = binary.Write(&packet, binary.LittleEndian, int32(1234))
+		packet.Write([]byte{byte(len(chunks)), byte(idx)})
+		_ = binary.Write(&packet, binary.LittleEndian, uint16(maxPacketSize))
+		packet.Write(chunk)
+
+		packets[len(chunks)-1-idx] = packet.Bytes()
+	}
+
+	return packets
+}
+
+// testServer answers queries, requiring a challenge for every request type.
+func testServer(t *testing.T) string {
+	t.Helper()
+
+	conn, errListen := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, errListen)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+
+		for {
+			size, addr, errRead := conn.ReadFromUDP(buf)
+			if errRead != nil {
+				return
+			}
+
+			request := buf[:size]
+			if !bytes.HasSuffix(request, testChallenge) {
+				_, _ = conn.WriteToUDP(append([]byte{0xFF, 0xFF, 0xFF, 0xFF, responseChallenge}, testChallenge...), addr)
+
+				continue
+			}
+
+			var packets [][]byte
+
+			switch request[4] {
+			case requestInfo:
+				packets = splitBody(testInfoBody(), 20)
+			case requestPlayers:
+				packets = [][]byte{testPlayersBody()}
+			case requestRules:
+				packets = [][]byte{append([]byte{0xFF, 0xFF, 0xFF, 0xFF, responseRules, 2, 0},
+					append(cstring("sv_gravity"), append(cstring("800"), append(cstring("mp_timelimit"), cstring("30")...)...)...)...)}
+			}
+
+			for _, packet := range packets {
+				_, _ = conn.WriteToUDP(packet, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestClient(t *testing.T) {
+	client, errDial := Dial(testServer(t), time.Second)
+	require.NoError(t, errDial)
+
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	info, errInfo := client.Info()
+	require.NoError(t, errInfo)
+	require.Equal(t, Info{
+		Protocol: 17, Name: "Test Server", Map: "pl_upward", Folder: "tf", Game: "Team Fortress", AppID: 440,
+		Players: 18, MaxPlayers: 24, Bots: 2, ServerType: 'd', Environment: 'l', VAC: true, Version: "8604241",
+		Port: 27015, SteamID: 85568392922039296, STVPort: 27020, STVName: "SourceTV",
+		Keywords: []string{"alltalk", "nocrits"}, GameID: 440,
+	}, info)
+
+	players, errPlayers := client.Players()
+	require.NoError(t, errPlayers)
+	require.Len(t, players, 2)
+	require.Equal(t, "player two", players[1].Name)
+	require.Equal(t, int32(20), players[1].Score)
+	require.Equal(t, time.Minute*2, players[1].Duration)
+
+	rules, errRules := client.Rules()
+	require.NoError(t, errRules)
+	require.Equal(t, map[string]string{"sv_gravity": "800", "mp_timelimit": "30"}, rules)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, errInfo := parseInfo([]byte{17, 'n', 'a', 'm', 'e'})
+	require.ErrorIs(t, errInfo, ErrTruncated)
+
+	_, errSplit := parseSplitHeader([]byte{0x00, 0x00, 0x00, 0x80, 2, 0, 0, 0})
+	require.ErrorIs(t, errSplit, ErrCompressed)
+}
@@ -0,0 +1,208 @@
+// Package cron implements parsing of standard 5 field cron expressions and calculation of their next
+// scheduled time.
+//
+// Fields are minute (0-59), hour (0-23), day of month (1-31), month (1-12) and day of week (0-6, sunday = 0,
+// 7 is also accepted as sunday). Each field supports *, single values, ranges (1-5), lists (1,3,5) and
+// steps (*/15, 0-30/10). The @hourly, @daily, @weekly, @monthly and @yearly shortcuts are also supported.
+// When both day of month and day of week are restricted, a time matches if either matches, as in classic cron.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidExpression = errors.New("Invalid cron expression")
+	ErrNoMatch           = errors.New("Expression never matches")
+)
+
+// maxSearch limits how far into the future Next looks, which handles expressions such as 0 0 31 2 * that never match.
+const maxSearch = time.Hour * 24 * 366 * 5
+
+var shortcuts = map[string]string{ //nolint:gochecknoglobals
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type bounds struct {
+	min int
+	max int
+}
+
+var fieldBounds = []bounds{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}} //nolint:gochecknoglobals
+
+// Schedule is a parsed cron expression. Each field is a bitset of the matching values.
+type Schedule struct {
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	original string
+}
+
+// Parse parses a cron expression.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	fieldsExpr := expr
+	if shortcut, found := shortcuts[strings.ToLower(expr)]; found {
+		fieldsExpr = shortcut
+	}
+
+	fields := strings.Fields(fieldsExpr)
+	if len(fields) != len(fieldBounds) {
+		return nil, errors.Wrapf(ErrInvalidExpression, "expected %d fields, got %d", len(fieldBounds), len(fields))
+	}
+
+	sets := make([]uint64, len(fields))
+
+	for idx, field := range fields {
+		set, errField := parseField(field, fieldBounds[idx])
+		if errField != nil {
+			return nil, errField
+		}
+
+		sets[idx] = set
+	}
+
+	// Both 0 and 7 represent sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &Schedule{
+		minute:   sets[0],
+		hour:     sets[1],
+		dom:      sets[2],
+		month:    sets[3],
+		dow:      sets[4],
+		domStar:  strings.HasPrefix(fields[2], "*"),
+		dowStar:  strings.HasPrefix(fields[4], "*"),
+		original: expr,
+	}, nil
+}
+
+func parseField(field string, bound bounds) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		var (
+			rangeExpr = part
+			step      = 1
+		)
+
+		if rangePart, stepPart, found := strings.Cut(part, "/"); found {
+			parsedStep, errStep := strconv.Atoi(stepPart)
+			if errStep != nil || parsedStep <= 0 {
+				return 0, errors.Wrapf(ErrInvalidExpression, "invalid step: %s", part)
+			}
+
+			rangeExpr = rangePart
+			step = parsedStep
+		}
+
+		var low, high int
+
+		switch {
+		case rangeExpr == "*":
+			low, high = bound.min, bound.max
+		case strings.Contains(rangeExpr, "-"):
+			lowStr, highStr, _ := strings.Cut(rangeExpr, "-")
+
+			parsedLow, errLow := strconv.Atoi(lowStr)
+			parsedHigh, errHigh := strconv.Atoi(highStr)
+
+			if errLow != nil || errHigh != nil {
+				return 0, errors.Wrapf(ErrInvalidExpression, "invalid range: %s", part)
+			}
+
+			low, high = parsedLow, parsedHigh
+		default:
+			value, errValue := strconv.Atoi(rangeExpr)
+			if errValue != nil {
+				return 0, errors.Wrapf(ErrInvalidExpression, "invalid value: %s", part)
+			}
+
+			low, high = value, value
+			// A single value with a step such as 5/15 runs from the value until the maximum
+			if step > 1 {
+				high = bound.max
+			}
+		}
+
+		if low < bound.min || high > bound.max || low > high {
+			return 0, errors.Wrapf(ErrInvalidExpression, "value out of range: %s", part)
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+
+	return set, nil
+}
+
+func (s *Schedule) String() string {
+	return s.original
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Next returns the first time after t which matches the schedule, in the location of t.
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	var (
+		next  = t.Truncate(time.Minute).Add(time.Minute)
+		limit = t.Add(maxSearch)
+	)
+
+	for next.Before(limit) {
+		if s.month&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+
+			continue
+		}
+
+		if !s.dayMatches(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+
+			continue
+		}
+
+		if s.hour&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+
+			continue
+		}
+
+		if s.minute&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+
+			continue
+		}
+
+		return next, nil
+	}
+
+	return time.Time{}, ErrNoMatch
+}
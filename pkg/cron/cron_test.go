@@ -0,0 +1,48 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/gbans/pkg/cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	// Monday
+	start := time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC)
+
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * *", time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC)},
+		{"0 0 * * 6,7", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 5", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, testCase := range testCases {
+		schedule, errParse := cron.Parse(testCase.expr)
+		require.NoError(t, errParse, testCase.expr)
+
+		next, errNext := schedule.Next(start)
+		require.NoError(t, errNext, testCase.expr)
+		require.Equal(t, testCase.expected, next, testCase.expr)
+	}
+
+	for _, invalid := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, errParse := cron.Parse(invalid)
+		require.ErrorIs(t, errParse, cron.ErrInvalidExpression, invalid)
+	}
+
+	never, errNever := cron.Parse("0 0 31 2 *")
+	require.NoError(t, errNever)
+
+	_, errNext := never.Next(start)
+	require.ErrorIs(t, errNext, cron.ErrNoMatch)
+}
@@ -82,6 +82,10 @@ const (
 	ServerConfigExec EventType = 1009
 	SteamAuth        EventType = 1010
 	MapStarted       EventType = 1011
+
+	// Anti-cheat.
+
+	StacDetection EventType = 2000 // [StAC] "name<pid><sid><team>" triggered "aimsnap" (tick "1234") (demo "x.dem")
 )
 
 type CritType int
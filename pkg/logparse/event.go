@@ -93,6 +93,16 @@ type MapStartedEvt struct {
 	Map string `json:"map" mapstructure:"map"`
 }
 
+// StacDetectionEvt is emitted when the StAC anti-cheat plugin flags a player.
+type StacDetectionEvt struct {
+	TimeStamp
+	SourcePlayer
+	Detection string `json:"detection" mapstructure:"detection"`
+	Count     int    `json:"count" mapstructure:"count"`
+	Tick      int    `json:"tick" mapstructure:"tick"`
+	Demo      string `json:"demo" mapstructure:"demo"`
+}
+
 type JoinedTeamEvt struct {
 	TimeStamp
 	SourcePlayer
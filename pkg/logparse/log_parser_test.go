@@ -400,6 +400,27 @@ func TestParseSayEvt(t *testing.T) {
 		})
 }
 
+func TestParseStacDetectionEvt(t *testing.T) {
+	t.Parallel()
+
+	testLogLine(t, `L 02/21/2021 - 06:22:23: [StAC] "Hacksaw<12><[U:1:68745073]><Red>" triggered "aimsnap" (count "3") (tick "51234") (demo "auto-20210221-062000-pl_upward.dem")`,
+		logparse.StacDetectionEvt{
+			TimeStamp:    logparse.TimeStamp{CreatedOn: time.Date(2021, time.February, 21, 6, 22, 23, 0, time.UTC)},
+			SourcePlayer: logparse.SourcePlayer{Name: "Hacksaw", PID: 12, SID: steamid.New("[U:1:68745073]"), Team: logparse.RED},
+			Detection:    "aimsnap",
+			Count:        3,
+			Tick:         51234,
+			Demo:         "auto-20210221-062000-pl_upward.dem",
+		})
+
+	testLogLine(t, `L 02/21/2021 - 06:22:23: [StAC] "Hacksaw<12><[U:1:68745073]><Blue>" triggered "cmdnum_spike"`,
+		logparse.StacDetectionEvt{
+			TimeStamp:    logparse.TimeStamp{CreatedOn: time.Date(2021, time.February, 21, 6, 22, 23, 0, time.UTC)},
+			SourcePlayer: logparse.SourcePlayer{Name: "Hacksaw", PID: 12, SID: steamid.New("[U:1:68745073]"), Team: logparse.BLU},
+			Detection:    "cmdnum_spike",
+		})
+}
+
 func TestParseWIntermissionWinLimitEvt(t *testing.T) {
 	t.Parallel()
 
@@ -60,6 +60,9 @@ func (remoteSrc *UDPLogListener) SetSecrets(secrets map[int]ServerIDMap) {
 	remoteSrc.logger.Debug("Updated server id map")
 }
 
+// maxPendingLines limits how many unparsable lines are held for a server while waiting for its next event.
+const maxPendingLines = 100
+
 type ServerIDMap struct {
 	ServerID   int
 	ServerName string
@@ -155,9 +158,13 @@ func (remoteSrc *UDPLogListener) Start(ctx context.Context) {
 		}
 	}()
 
-	parser := NewLogParser()
-
-	rejects := map[int]time.Time{}
+	var (
+		parser  = NewLogParser()
+		rejects = map[int]time.Time{}
+		// pending holds the lines of each server which could not be parsed, until they can be attached to the
+		// next event of the server.
+		pending = map[int][]string{}
+	)
 
 	for {
 		select {
@@ -185,9 +192,22 @@ func (remoteSrc *UDPLogListener) Start(ctx context.Context) {
 					zap.String("body", logPayload.body),
 					zap.Error(errLogServerEvent))
 
+				lines := append(pending[server.ServerID], logPayload.body)
+				if len(lines) > maxPendingLines {
+					lines = lines[len(lines)-maxPendingLines:]
+				}
+
+				pending[server.ServerID] = lines
+
 				continue
 			}
 
+			if lines, ok := pending[server.ServerID]; ok {
+				event.Lines = append(lines, event.Lines...)
+
+				delete(pending, server.ServerID)
+			}
+
 			remoteSrc.onEvent(event.EventType, event)
 		}
 	}
@@ -197,6 +217,9 @@ func (remoteSrc *UDPLogListener) Start(ctx context.Context) {
 type ServerEvent struct {
 	ServerID   int
 	ServerName string
+	// Lines holds the raw log line of the event, preceded by any lines from the same server which did not
+	// produce an event of their own, so the original log can be rebuilt from the events.
+	Lines []string
 	*Results
 }
 
@@ -204,6 +227,7 @@ func logToServerEvent(parser *LogParser, serverID int, serverName string, msg st
 	event := ServerEvent{
 		ServerID:   serverID,
 		ServerName: serverName,
+		Lines:      []string{msg},
 	}
 	parseResult, errParse := parser.Parse(msg)
 
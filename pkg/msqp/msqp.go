@@ -1,19 +1,39 @@
-// Package msqp is a very simple client for the valve Master Server Query Protocol
-// It *only* implements the list request.
+// Package msqp is a client for the valve Master Server Query Protocol
+// It implements the list request along with the full filter language.
 // https://developer.valvesoftware.com/wiki/Master_Server_Query_Protocol
 package msqp
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 )
 
-const masterBrowserHost = "hl2master.steampowered.com:27011"
+// DefaultMasterHost is the master server operated by valve.
+const DefaultMasterHost = "hl2master.steampowered.com:27011"
+
+const (
+	queryHeader byte = 0x31
+	// endpointSize is the size of a single ipv4 address + port entry. The protocol has no support for ipv6.
+	endpointSize = 6
+	// maxPacketSize is larger than the largest response the master server will send.
+	maxPacketSize = 2048
+	// lastEndpoint marks the start and end of the list.
+	lastEndpoint = "0.0.0.0:0"
+)
+
+var (
+	replyHeader = []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
+
+	ErrMalformedResponse = errors.New("Query list response is malformed")
+	ErrMaxPages          = errors.New("Exceeded maximum page count")
+)
 
 // Region defines a part of the world where servers are located.
 type Region uint8
@@ -36,87 +56,283 @@ type ServerEndpoint struct {
 	Port uint16
 }
 
-func newServerEndpoint(buffer []byte) *ServerEndpoint {
-	return &ServerEndpoint{
+func newServerEndpoint(buffer []byte) ServerEndpoint {
+	return ServerEndpoint{
 		IP:   net.IPv4(buffer[0], buffer[1], buffer[2], buffer[3]),
 		Port: binary.BigEndian.Uint16(buffer[4:]),
 	}
 }
 
-func (c *ServerEndpoint) String() string {
+func (c ServerEndpoint) String() string {
 	return fmt.Sprintf("%s:%d", c.IP.String(), c.Port)
 }
 
-func List(c *net.UDPConn, regions []Region) ([]*ServerEndpoint, error) {
-	const endIp = "0.0.0.0:0"
-	lastIp := endIp
-	filter := "\\gamedir\\tf"
-	dedupeMap := map[string]*ServerEndpoint{}
+// Filter is a single condition of the filter language. Conditions are combined with AND unless they are
+// part of a Nor or Nand condition.
+type Filter struct {
+	key    string
+	value  string
+	nested []Filter
+}
+
+func (f Filter) String() string {
+	if f.nested == nil {
+		return fmt.Sprintf("\\%s\\%s", f.key, f.value)
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("\\%s\\%d", f.key, len(f.nested)))
+
+	for _, filter := range f.nested {
+		builder.WriteString(filter.String())
+	}
+
+	return builder.String()
+}
+
+// Term creates a raw key/value condition for filters without a helper.
+func Term(key string, value string) Filter {
+	return Filter{key: key, value: value}
+}
+
+// GameDir matches servers running the mod, eg: tf.
+func GameDir(dir string) Filter {
+	return Term("gamedir", dir)
+}
+
+// Map matches servers running the map.
+func Map(name string) Filter {
+	return Term("map", name)
+}
+
+// AppID matches servers running the app.
+func AppID(appID int) Filter {
+	return Term("appid", fmt.Sprintf("%d", appID))
+}
+
+// NotAppID matches servers not running the app.
+func NotAppID(appID int) Filter {
+	return Term("napp", fmt.Sprintf("%d", appID))
+}
+
+// NotEmpty matches servers with at least one player.
+func NotEmpty() Filter {
+	return Term("empty", "1")
+}
+
+// NotFull matches servers with at least one free slot.
+func NotFull() Filter {
+	return Term("full", "1")
+}
+
+// NoPlayers matches servers which are empty.
+func NoPlayers() Filter {
+	return Term("noplayers", "1")
+}
+
+// Dedicated matches dedicated servers.
+func Dedicated() Filter {
+	return Term("dedicated", "1")
+}
+
+// Secure matches servers using anti-cheat.
+func Secure() Filter {
+	return Term("secure", "1")
+}
+
+// NoPassword matches servers which are not password protected.
+func NoPassword() Filter {
+	return Term("password", "0")
+}
+
+// GameType matches servers with all the tags in sv_tags.
+func GameType(tags ...string) Filter {
+	return Term("gametype", strings.Join(tags, ","))
+}
+
+// NameMatch matches servers with a hostname matching the pattern, which may contain * wildcards.
+func NameMatch(pattern string) Filter {
+	return Term("name_match", pattern)
+}
+
+// Nor matches servers which match none of the conditions.
+func Nor(filters ...Filter) Filter {
+	return Filter{key: "nor", nested: filters}
+}
+
+// Nand matches servers which do not match all the conditions.
+func Nand(filters ...Filter) Filter {
+	return Filter{key: "nand", nested: filters}
+}
+
+// BuildFilter combines the conditions into a filter string, eg: \gamedir\tf\empty\1.
+func BuildFilter(filters ...Filter) string {
+	var builder strings.Builder
+
+	for _, filter := range filters {
+		builder.WriteString(filter.String())
+	}
+
+	return builder.String()
+}
+
+// Client pages through the master server list.
+type Client struct {
+	// Host is the address of the master server.
+	Host string
+	// Timeout is how long to wait for each page.
+	Timeout time.Duration
+	// Retries is the number of times a page is requested again after a timeout.
+	Retries int
+	// MaxPages limits the number of pages read per region.
+	MaxPages int
+}
+
+// New creates a client using the default timeouts. When host is empty the DefaultMasterHost is used.
+func New(host string) *Client {
+	if host == "" {
+		host = DefaultMasterHost
+	}
+
+	return &Client{
+		Host:     host,
+		Timeout:  time.Second * 2,
+		Retries:  3,
+		MaxPages: 500,
+	}
+}
+
+// List returns the unique endpoints in the regions matching the filters.
+func (c *Client) List(ctx context.Context, regions []Region, filters ...Filter) ([]ServerEndpoint, error) {
+	addr, errResolve := net.ResolveUDPAddr("udp4", c.Host)
+	if errResolve != nil {
+		return nil, errors.Wrap(errResolve, "Failed to resolve master server")
+	}
+
+	conn, errDial := net.DialUDP("udp4", nil, addr)
+	if errDial != nil {
+		return nil, errors.Wrap(errDial, "Failed to dial master server")
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var (
+		filter  = BuildFilter(filters...)
+		seen    = map[string]bool{}
+		results []ServerEndpoint
+	)
+
 	for _, region := range regions {
-		firstRequest := true
-		for firstRequest || lastIp != endIp {
-			r, errList := sendListRequest(c, lastIp, filter, region)
-			if errList != nil {
-				return nil, errors.Wrap(errList, "Failed to send list request")
+		seed := lastEndpoint
+
+		for page := 0; ; page++ {
+			if page >= c.MaxPages {
+				return nil, ErrMaxPages
 			}
-			if len(r) == 0 {
-				// Shouldn't happen?
-				break
+
+			if errCtx := ctx.Err(); errCtx != nil {
+				return nil, errors.Wrap(errCtx, "Query cancelled")
 			}
-			for _, result := range r {
-				dedupeMap[result.String()] = result
+
+			endpoints, errPage := c.page(conn, region, seed, filter)
+			if errPage != nil {
+				return nil, errPage
+			}
+
+			done := len(endpoints) == 0
+
+			for _, endpoint := range endpoints {
+				key := endpoint.String()
+				if key == lastEndpoint {
+					done = true
+
+					continue
+				}
+
+				if !seen[key] {
+					seen[key] = true
+
+					results = append(results, endpoint)
+				}
+			}
+
+			if done {
+				break
 			}
-			lastIp = r[len(r)-1].String()
-			firstRequest = false
-			// if lastIp == endIp {
-			//	fmt.Println("Got EOL")
-			//}
+
+			seed = endpoints[len(endpoints)-1].String()
 		}
 	}
-	var results []*ServerEndpoint
-	for _, v := range dedupeMap {
-		results = append(results, v)
-	}
 
 	return results, nil
 }
 
-func sendListRequest(conn *net.UDPConn, ipStart string, filter string, regionCode Region) ([]*ServerEndpoint, error) {
-	const queryHeader byte = 0x31
+// page requests the endpoints following seed, retrying when the master server does not respond in time.
+func (c *Client) page(conn *net.UDPConn, region Region, seed string, filter string) ([]ServerEndpoint, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		endpoints, errRequest := sendListRequest(conn, c.Timeout, seed, filter, region)
+		if errRequest == nil {
+			return endpoints, nil
+		}
+
+		lastErr = errRequest
+
+		var netErr net.Error
+		if !errors.As(errRequest, &netErr) || !netErr.Timeout() {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+func sendListRequest(conn *net.UDPConn, timeout time.Duration, seed string, filter string, regionCode Region) ([]ServerEndpoint, error) {
 	var buf bytes.Buffer
+
 	buf.WriteByte(queryHeader)
 	buf.WriteByte(byte(regionCode))
-	buf.WriteString(ipStart)
+	buf.WriteString(seed)
+	buf.WriteByte(0)
 	buf.WriteString(filter)
-	_, errWrite := conn.Write(buf.Bytes())
-	if errWrite != nil {
+	buf.WriteByte(0)
+
+	if _, errWrite := conn.Write(buf.Bytes()); errWrite != nil {
 		return nil, errors.Wrap(errWrite, "Failed to write udp bytes")
 	}
-	buffer := make([]byte, 1600)
-	if errDeadLine := conn.SetReadDeadline(time.Now().Add(time.Second)); errDeadLine != nil {
-		return nil, errDeadLine
+
+	buffer := make([]byte, maxPacketSize)
+
+	if errDeadLine := conn.SetReadDeadline(time.Now().Add(timeout)); errDeadLine != nil {
+		return nil, errors.Wrap(errDeadLine, "Failed to set read deadline")
 	}
-	readCount, _, errRead := conn.ReadFromUDP(buffer)
+
+	readCount, errRead := conn.Read(buffer)
 	if errRead != nil {
-		return nil, errors.Wrap(errWrite, "Failed to read udp bytes")
-	}
-	const structSize int = 6
-	if readCount%structSize > 0 {
-		return nil, errors.New("Query list response has a length which is not multiple of 6")
-	}
-	var endpoints []*ServerEndpoint
-	replyHeader := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
-	if !bytes.Equal(replyHeader, buffer[0:structSize]) {
-		return nil, errors.New("Query list response header is malformed")
-	}
-	res := buffer[structSize:readCount]
-	count := len(res)
-	i := 0
-	for count > 0 {
-		endpoint := newServerEndpoint(res[i*structSize : i*structSize+structSize])
-		count -= 6
-		i++
-		endpoints = append(endpoints, endpoint)
+		return nil, errors.Wrap(errRead, "Failed to read udp bytes")
+	}
+
+	return parseListResponse(buffer[:readCount])
+}
+
+func parseListResponse(response []byte) ([]ServerEndpoint, error) {
+	if len(response) < len(replyHeader) || !bytes.Equal(replyHeader, response[:len(replyHeader)]) {
+		return nil, errors.Wrap(ErrMalformedResponse, "Invalid header")
+	}
+
+	body := response[len(replyHeader):]
+	if len(body)%endpointSize > 0 {
+		return nil, errors.Wrap(ErrMalformedResponse, "Length is not a multiple of 6")
+	}
+
+	endpoints := make([]ServerEndpoint, 0, len(body)/endpointSize)
+
+	for offset := 0; offset < len(body); offset += endpointSize {
+		endpoints = append(endpoints, newServerEndpoint(body[offset:offset+endpointSize]))
 	}
 
 	return endpoints, nil
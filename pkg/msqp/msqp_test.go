@@ -1,18 +1,110 @@
 package msqp
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
 	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+// fakeMaster serves the endpoints two per page, ignoring the first request to exercise retries.
+func fakeMaster(t *testing.T, endpoints []string, filters chan<- string) string {
+	t.Helper()
+
+	conn, errListen := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, errListen)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	var requests atomic.Int32
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+
+		for {
+			size, addr, errRead := conn.ReadFromUDP(buf)
+			if errRead != nil {
+				return
+			}
+
+			if requests.Add(1) == 1 {
+				continue
+			}
+
+			parts := bytes.Split(buf[2:size], []byte{0})
+			seed := string(parts[0])
+			filters <- string(parts[1])
+
+			start := 0
+
+			for idx, endpoint := range endpoints {
+				if endpoint == seed {
+					start = idx + 1
+				}
+			}
+
+			page := endpoints[start:min(start+2, len(endpoints))]
+			if start+2 >= len(endpoints) {
+				page = append(page, lastEndpoint)
+			}
+
+			response := append([]byte(nil), replyHeader...)
+
+			for _, endpoint := range page {
+				udpAddr, _ := net.ResolveUDPAddr("udp4", endpoint)
+				response = append(response, udpAddr.IP.To4()...)
+				response = binary.BigEndian.AppendUint16(response, uint16(udpAddr.Port))
+			}
+
+			_, _ = conn.WriteToUDP(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
 func TestClient(t *testing.T) {
-	s, errResolve := net.ResolveUDPAddr("udp4", masterBrowserHost)
-	require.NoError(t, errResolve)
-	conn, errDial := net.DialUDP("udp4", nil, s)
-	require.NoError(t, errDial)
-	_, errList := List(conn, []Region{AllRegions})
+	var (
+		endpoints = []string{"1.1.1.1:27015", "2.2.2.2:27015", "3.3.3.3:27016", "4.4.4.4:27015", "5.5.5.5:27015"}
+		filters   = make(chan string, 10)
+		client    = New(fakeMaster(t, endpoints, filters))
+	)
+
+	client.Timeout = time.Millisecond * 200
+
+	results, errList := client.List(context.Background(), []Region{AllRegions}, GameDir("tf"), NotEmpty())
 	require.NoError(t, errList)
-	require.NoError(t, conn.Close())
+	require.Len(t, results, len(endpoints))
+
+	for idx, endpoint := range endpoints {
+		require.Equal(t, endpoint, results[idx].String())
+	}
+
+	require.Equal(t, `\gamedir\tf\empty\1`, <-filters)
+}
+
+func TestBuildFilter(t *testing.T) {
+	require.Equal(t, `\appid\440\map\pl_upward\nor\2\gametype\nocrits\napp\500`,
+		BuildFilter(AppID(440), Map("pl_upward"), Nor(GameType("nocrits"), NotAppID(500))))
+
+	require.Equal(t, `\nand\2\noplayers\1\nor\1\name_match\*uncletopia*`,
+		BuildFilter(Nand(NoPlayers(), Nor(NameMatch("*uncletopia*")))))
+
+	require.Equal(t, fmt.Sprintf(`\gametype\%s`, "alltalk,nocrits"), GameType("alltalk", "nocrits").String())
+}
+
+func TestParseListResponse(t *testing.T) {
+	_, errHeader := parseListResponse([]byte{0xFF, 0xFF})
+	require.ErrorIs(t, errHeader, ErrMalformedResponse)
+
+	_, errLength := parseListResponse(append(append([]byte(nil), replyHeader...), 1, 2, 3))
+	require.ErrorIs(t, errLength, ErrMalformedResponse)
 }